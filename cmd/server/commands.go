@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"ipl-be-svc/internal/service"
+)
+
+// commandUsage describes the subcommands the server binary accepts instead of serving HTTP
+const commandUsage = `Usage: server [command]
+
+Without a command the HTTP server is started.
+
+Commands:
+  reconcile-settlement -file <settlement.csv> [-from YYYY-MM-DD -to YYYY-MM-DD]
+        Reconcile a DOKU settlement CSV with stored payment transactions and print the report as JSON`
+
+// command is a parsed subcommand, run once the services it needs are set up
+type command func(settlementService service.SettlementService) error
+
+// parseCommand parses the subcommand named by args[0] and its flags. Help prints the usage and
+// returns a nil command, so neither help nor a usage error needs the database.
+func parseCommand(args []string) (command, error) {
+	switch args[0] {
+	case "reconcile-settlement":
+		return parseReconcileSettlement(args[1:])
+	case "help", "-h", "--help":
+		fmt.Println(commandUsage)
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown command %q\n\n%s", args[0], commandUsage)
+	}
+}
+
+// parseReconcileSettlement parses the flags of reconcile-settlement and returns the command that
+// reconciles the settlement file and writes the report to stdout
+func parseReconcileSettlement(args []string) (command, error) {
+	flags := flag.NewFlagSet("reconcile-settlement", flag.ContinueOnError)
+	filePath := flags.String("file", "", "DOKU settlement CSV")
+	from := flags.String("from", "", "start of the settlement period (YYYY-MM-DD, WIB)")
+	to := flags.String("to", "", "last day of the settlement period (YYYY-MM-DD, WIB)")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if *filePath == "" {
+		return nil, fmt.Errorf("-file is required")
+	}
+
+	period, err := service.ParseSettlementPeriod(*from, *to)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(settlementService service.SettlementService) error {
+		file, err := os.Open(*filePath)
+		if err != nil {
+			return fmt.Errorf("failed to open settlement file: %w", err)
+		}
+		defer file.Close()
+
+		report, err := settlementService.ReconcileSettlement(file, period)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}, nil
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -54,6 +55,22 @@ func main() {
 
 	// Initialize logger
 	appLogger := logger.NewLogger(cfg.Logger.Level, cfg.Logger.Format)
+
+	// CLI subcommands log to stderr so their output can be redirected. They are parsed before
+	// connecting, so help and usage errors do not need the database.
+	var cliCommand command
+	runningCommand := len(os.Args) > 1
+	if runningCommand {
+		appLogger.SetOutput(os.Stderr)
+		cliCommand, err = parseCommand(os.Args[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if cliCommand == nil {
+			return
+		}
+	}
 	appLogger.Info("Starting IPL Backend Service...")
 
 	// Set Gin mode
@@ -78,15 +95,108 @@ func main() {
 	userRepo := repository.NewUserRepository(db.DB)
 	masterMenuRepo := repository.NewMasterMenuRepository(db.DB)
 	roleMenuRepo := repository.NewRoleMenuRepository(db.DB)
+	paymentRepo := repository.NewPaymentRepository(db.DB)
+	installmentRepo := repository.NewInstallmentRepository(db.DB)
+	feeRuleRepo := repository.NewFeeRuleRepository(db.DB)
+	residentUnitRepo := repository.NewResidentUnitRepository(db.DB)
+	billingRunRepo := repository.NewBillingRunRepository(db.DB)
+	lateFeeRepo := repository.NewLateFeeRepository(db.DB)
+	adjustmentRepo := repository.NewAdjustmentRepository(db.DB)
+	discountRuleRepo := repository.NewDiscountRuleRepository(db.DB)
+	tariffRepo := repository.NewTariffRepository(db.DB)
+	settingScheduleRepo := repository.NewSettingScheduleRepository(db.DB)
 
 	// Initialize services
 	menuService := service.NewMenuService(menuRepo)
-	dokuService := service.NewDokuService(appLogger)
-	paymentService := service.NewPaymentService(billingRepo, dokuService, appLogger)
-	userService := service.NewUserService(userRepo, appLogger)
-	billingService := service.NewBillingService(billingRepo, db.DB)
+	paymentGateway, err := service.NewPaymentGateway(cfg, appLogger)
+	if err != nil {
+		appLogger.WithField("error", err).Fatal("Failed to initialize payment gateway")
+	}
+	paymentService := service.NewPaymentService(billingRepo, paymentRepo, installmentRepo, feeRuleRepo, paymentGateway, appLogger)
+	userService := service.NewUserService(userRepo, residentUnitRepo, appLogger)
+	billingService, err := service.NewBillingService(billingRepo, paymentRepo, discountRuleRepo, tariffRepo, service.BillingPolicy{
+		DueDay:    cfg.Billing.DueDay,
+		Proration: cfg.Billing.Proration,
+	}, appLogger)
+	if err != nil {
+		appLogger.WithField("error", err).Fatal("Failed to initialize billing service")
+	}
+	billingManagementService := service.NewBillingManagementService(billingRepo, paymentRepo, cfg.Billing.DueDay, appLogger)
 	masterMenuService := service.NewMasterMenuService(masterMenuRepo, appLogger)
 	roleMenuService := service.NewRoleMenuService(roleMenuRepo, masterMenuRepo, appLogger)
+	settlementService := service.NewSettlementService(paymentRepo, appLogger)
+	manualPaymentService := service.NewManualPaymentService(billingRepo, paymentRepo, cfg.Payment.ReceiptDir, appLogger)
+	refundService := service.NewRefundService(billingRepo, paymentRepo, appLogger)
+	installmentService := service.NewInstallmentService(billingRepo, paymentRepo, installmentRepo, appLogger)
+	feeRuleService := service.NewFeeRuleService(feeRuleRepo, appLogger)
+	adjustmentService := service.NewAdjustmentService(billingRepo, paymentRepo, adjustmentRepo, appLogger)
+	discountRuleService := service.NewDiscountRuleService(discountRuleRepo, appLogger)
+	tariffService := service.NewTariffService(tariffRepo, appLogger)
+	settingScheduleService := service.NewSettingScheduleService(billingRepo, settingScheduleRepo, appLogger)
+	receiptService, err := service.NewReceiptService(billingRepo, paymentRepo, residentUnitRepo, cfg.Payment.ReceiptSigningKey, appLogger)
+	if err != nil {
+		appLogger.WithField("error", err).Fatal("Failed to initialize receipt service")
+	}
+	billingScheduler, err := service.NewBillingScheduler(billingService, billingRunRepo, cfg.Billing.SchedulerCron, cfg.Billing.SchedulerDayOfMonth, appLogger)
+	if err != nil {
+		appLogger.WithField("error", err).Fatal("Failed to initialize billing scheduler")
+	}
+	lateFeeService, err := service.NewLateFeeService(billingRepo, paymentRepo, lateFeeRepo, billingRunRepo, cfg.Billing.LateFeeCron, service.LateFeePolicy{
+		DueDay: cfg.Billing.DueDay,
+		Type:   cfg.Billing.LateFeeType,
+		Amount: int64(cfg.Billing.LateFeeAmount),
+		Cap:    int64(cfg.Billing.LateFeeCap),
+	}, appLogger)
+	if err != nil {
+		appLogger.WithField("error", err).Fatal("Failed to initialize late fee service")
+	}
+
+	// Run a CLI subcommand instead of the server when one is given
+	if runningCommand {
+		err := cliCommand(settlementService)
+		if closeErr := db.Close(); closeErr != nil {
+			appLogger.WithField("error", closeErr).Error("Failed to close database connection")
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+
+	if cfg.Payment.ReconcilerEnabled {
+		paymentReconciler := service.NewPaymentReconciler(
+			paymentService,
+			time.Duration(cfg.Payment.ReconcileIntervalMinutes)*time.Minute,
+			time.Duration(cfg.Payment.ReconcileMinAgeMinutes)*time.Minute,
+			appLogger,
+		)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			paymentReconciler.Run(workerCtx)
+		}()
+	}
+
+	if cfg.Billing.SchedulerEnabled {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			billingScheduler.Run(workerCtx)
+		}()
+	}
+
+	if cfg.Billing.LateFeeEnabled {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			lateFeeService.Run(workerCtx)
+		}()
+	}
 
 	// Initialize Gin router
 	router := gin.New()
@@ -99,7 +209,7 @@ func main() {
 	router.NoMethod(middleware.NoMethodHandler())
 
 	// Setup routes
-	handler.SetupRoutes(router, menuService, paymentService, userService, billingService, masterMenuService, roleMenuService, appLogger)
+	handler.SetupRoutes(router, menuService, paymentService, manualPaymentService, refundService, installmentService, feeRuleService, receiptService, settlementService, userService, billingService, billingManagementService, billingScheduler, lateFeeService, adjustmentService, discountRuleService, tariffService, settingScheduleService, masterMenuService, roleMenuService, appLogger)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -134,6 +244,10 @@ func main() {
 		appLogger.WithField("error", err).Fatal("Server forced to shutdown")
 	}
 
+	// Stop background workers before closing the database they use
+	stopWorkers()
+	workers.Wait()
+
 	// Close database connection
 	if err := db.Close(); err != nil {
 		appLogger.WithField("error", err).Error("Failed to close database connection")
@@ -24,43 +24,122 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/billings/bulk-monthly": {
-            "post": {
-                "description": "Create monthly billings for specified user IDs or all penghuni users if user_ids is empty. Requires auth-token cookie.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/billings": {
+            "get": {
+                "description": "List the published billings, or with cancelled=true the cancelled ones, with their resident, status, kategori transaksi and due date. Filters combine; date ranges are YYYY-MM-DD and inclusive.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "billings"
                 ],
-                "summary": "Create bulk monthly billings",
+                "summary": "List billings",
                 "parameters": [
                     {
-                        "description": "Bulk billing request with month and year",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.BulkBillingRequest"
-                        }
+                        "type": "integer",
+                        "description": "Resident user ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Billing month 1-12",
+                        "name": "month",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Billing year",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status name, e.g. Belum Dibayar",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Kategori transaksi ID",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created on or after",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created on or before",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Due on or after",
+                        "name": "due_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Due on or before",
+                        "name": "due_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "List cancelled billings",
+                        "name": "cancelled",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "created_at, due_date, nominal or period",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Bulk billing creation result",
+                        "description": "Billings retrieved",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/utils.APIResponse"
+                                    "$ref": "#/definitions/utils.PaginatedResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/service.BulkBillingResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.BillingRecord"
+                                            }
                                         }
                                     }
                                 }
@@ -68,13 +147,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
-                        "schema": {
-                            "$ref": "#/definitions/utils.APIResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                        "description": "Invalid filter",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -88,9 +161,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/billings/penghuni": {
-            "get": {
-                "description": "Get all billing data for penghuni users with complete information including profile, role, and billing status. Nominal amounts are summed per user per billing period (month/year).",
+        "/api/v1/billings/adjustments/{adjustment_id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply a pending adjustment to its billing and update the billing status to the new amount due. The approving admin, read from the bearer token, must not be the one who requested it. Refused while the billing's payments exceed the adjusted amount.",
                 "consumes": [
                     "application/json"
                 ],
@@ -100,10 +178,27 @@ const docTemplate = `{
                 "tags": [
                     "billings"
                 ],
-                "summary": "Get billing penghuni list with summed nominals",
+                "summary": "Approve billing adjustment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Adjustment ID",
+                        "name": "adjustment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review note",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReviewAdjustmentRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Billing penghuni retrieved successfully",
+                        "description": "Adjustment approved",
                         "schema": {
                             "allOf": [
                                 {
@@ -113,16 +208,43 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.BillingPenghuniResponse"
-                                            }
+                                            "$ref": "#/definitions/models.BillingAdjustment"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Invalid adjustment",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Adjustment cannot be approved by its creator",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Adjustment not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Adjustment is not pending, the billing has a pending checkout, or payments exceed the adjusted amount",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -132,9 +254,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/master-menus": {
-            "get": {
-                "description": "Get all master menus with pagination",
+        "/api/v1/billings/adjustments/{adjustment_id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Close a pending adjustment without changing its billing. The admin is read from the bearer token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -142,47 +269,69 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "master-menus"
+                    "billings"
                 ],
-                "summary": "Get all master menus",
+                "summary": "Reject billing adjustment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
+                        "description": "Adjustment ID",
+                        "name": "adjustment_id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Number of items per page",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Review note",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReviewAdjustmentRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Master menus retrieved successfully",
+                        "description": "Adjustment rejected",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/utils.PaginatedResponse"
+                                    "$ref": "#/definitions/utils.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.MasterMenu"
-                                            }
+                                            "$ref": "#/definitions/models.BillingAdjustment"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Invalid adjustment ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Adjustment not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Adjustment is not pending",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -190,9 +339,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/billings/bulk-monthly": {
             "post": {
-                "description": "Create a new master menu with the provided information",
+                "description": "Create monthly billings for specified user IDs or all penghuni users if user_ids is empty, one per monthly (bulanan) setting billing and per yearly (tahunan) setting scheduled for the month. Generation is idempotent: billings that already exist for a user, month, year and setting are skipped, or updated to their current nominal while unpaid with existing=replace_unpaid. Each billing is priced by the first tariff of its setting matching the resident's unit (house type, cluster, land area), or the setting nominal when none does. Residents are billed only for the months their unit is occupied; the first and last are prorated by the billing proration policy (yearly settings are not), and each resident's discount rules are applied. The response lists per user each setting billing with its amount, tariff, proration and discount and whether it is created, replaced or skipped, plus totals and the users skipped for having no profile. With dry_run=true nothing is written and the response previews the run. Requires auth-token cookie.",
                 "consumes": [
                     "application/json"
                 ],
@@ -200,23 +351,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "master-menus"
+                    "billings"
                 ],
-                "summary": "Create a new master menu",
+                "summary": "Create bulk monthly billings",
                 "parameters": [
                     {
-                        "description": "Master menu data",
-                        "name": "master_menu",
+                        "description": "Bulk billing request with month and year",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.CreateMasterMenuRequest"
+                            "$ref": "#/definitions/handler.BulkBillingRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Master menu created successfully",
+                    "200": {
+                        "description": "Bulk billing creation result",
                         "schema": {
                             "allOf": [
                                 {
@@ -226,7 +377,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.MasterMenu"
+                                            "$ref": "#/definitions/service.BulkBillingResponse"
                                         }
                                     }
                                 }
@@ -234,7 +385,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -248,31 +405,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/master-menus/{id}": {
+        "/api/v1/billings/discount-rules": {
             "get": {
-                "description": "Get master menu information by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the discount rules of every resident, or of one",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "master-menus"
+                    "billings"
                 ],
-                "summary": "Get master menu by ID",
+                "summary": "Get discount rules",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Master Menu ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Resident user ID",
+                        "name": "user_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Master menu retrieved successfully",
+                        "description": "Discount rules retrieved",
                         "schema": {
                             "allOf": [
                                 {
@@ -282,7 +435,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.MasterMenu"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.BillingDiscountRule"
+                                            }
                                         }
                                     }
                                 }
@@ -290,13 +446,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid master menu ID",
-                        "schema": {
-                            "$ref": "#/definitions/utils.APIResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Master menu not found",
+                        "description": "Invalid user ID",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -309,8 +459,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "Update master menu information",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a recurring discount of a resident, e.g. 50% while a house is empty or an exemption for RT officials. Flat discounts are in rupiah; percentage discounts are in basis points (10000 exempts). Billings generated in its months, for its setting billing or all of them, get it as an approved adjustment. The admin, read from the bearer token, is its approver.",
                 "consumes": [
                     "application/json"
                 ],
@@ -318,30 +473,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "master-menus"
+                    "billings"
                 ],
-                "summary": "Update master menu",
+                "summary": "Create discount rule",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Master Menu ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Master menu update data",
-                        "name": "master_menu",
+                        "description": "Discount rule",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.UpdateMasterMenuRequest"
+                            "$ref": "#/definitions/service.DiscountRuleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Master menu updated successfully",
+                    "201": {
+                        "description": "Discount rule created",
                         "schema": {
                             "allOf": [
                                 {
@@ -351,7 +499,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.MasterMenu"
+                                            "$ref": "#/definitions/models.BillingDiscountRule"
                                         }
                                     }
                                 }
@@ -359,13 +507,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Invalid discount rule",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
-                    "404": {
-                        "description": "Master menu not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -377,9 +525,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete master menu by ID",
+            }
+        },
+        "/api/v1/billings/discount-rules/{id}": {
+            "put": {
+                "description": "Replace the settings of a discount rule. Billings already generated keep the discount they were given.",
                 "consumes": [
                     "application/json"
                 ],
@@ -387,33 +537,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "master-menus"
+                    "billings"
                 ],
-                "summary": "Delete master menu",
+                "summary": "Update discount rule",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Master Menu ID",
+                        "description": "Discount rule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Discount rule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.DiscountRuleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Master menu deleted successfully",
+                        "description": "Discount rule updated",
                         "schema": {
-                            "$ref": "#/definitions/utils.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.BillingDiscountRule"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid master menu ID",
+                        "description": "Invalid discount rule",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
                     "404": {
-                        "description": "Master menu not found",
+                        "description": "Discount rule not found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -425,33 +596,66 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/menus/user/{user_id}": {
-            "get": {
-                "description": "Get list of menus accessible by a specific user ID",
-                "consumes": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "Delete a discount rule; billings generated afterwards no longer get it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "menus"
+                    "billings"
                 ],
-                "summary": "Get menus by user ID",
+                "summary": "Delete discount rule",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "user_id",
+                        "description": "Discount rule ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Menus retrieved successfully",
+                        "description": "Discount rule deleted",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid discount rule ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Discount rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/billings/late-fees/assess": {
+            "post": {
+                "description": "Add the denda every overdue billing is owed as of today, as the daily job does. Running it again the same day adds nothing. The run is recorded in the billing run history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "billings"
+                ],
+                "summary": "Assess late fees",
+                "responses": {
+                    "200": {
+                        "description": "Late fees assessed",
                         "schema": {
                             "allOf": [
                                 {
@@ -461,24 +665,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/response.MenuResponse"
-                                            }
+                                            "$ref": "#/definitions/models.BillingRun"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid user ID",
-                        "schema": {
-                            "$ref": "#/definitions/utils.APIResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "No menus found",
+                    "409": {
+                        "description": "Assessment is already running",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -492,9 +687,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/payments/billing/link": {
+        "/api/v1/billings/one-off": {
             "post": {
-                "description": "Create a DOKU payment link for multiple billing records by IDs",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Bill a one-off charge, such as a 17-Agustusan contribution, to the given users: one unpaid billing each for the month and year, due on due_date or the billing due day of the month. Tariffs, proration and discount rules do not apply. Users without a resident profile are skipped and listed. The admin is read from the bearer token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -502,54 +702,63 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "payments"
+                    "billings"
                 ],
-                "summary": "Create payment link for multiple billings",
+                "summary": "Create one-off billings",
                 "parameters": [
                     {
-                        "description": "Billing IDs",
+                        "description": "One-off charge",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.CreatePaymentLinkMultipleRequest"
+                            "$ref": "#/definitions/handler.OneOffBillingRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Payment link created successfully",
+                    "201": {
+                        "description": "One-off billings created",
                         "schema": {
-                            "$ref": "#/definitions/service.PaymentLinkResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.OneOffBillingResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid billing IDs",
+                        "description": "Invalid request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
-                    "404": {
-                        "description": "Billing not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.APIResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/payments/billing/{id}/link": {
-            "post": {
-                "description": "Create a DOKU payment link for a billing record by ID",
+        "/api/v1/billings/penghuni": {
+            "get": {
+                "description": "Get all billing data for penghuni users with complete information including profile, role, and billing status. Nominal amounts are summed per user per billing period (month/year); components lists the billings of the period, one per setting billing (e.g. Keamanan, Kebersihan), with their own nominal and status.",
                 "consumes": [
                     "application/json"
                 ],
@@ -557,62 +766,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "payments"
-                ],
-                "summary": "Create payment link",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Billing ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "billings"
                 ],
+                "summary": "Get billing penghuni list with summed nominals",
                 "responses": {
                     "200": {
-                        "description": "Payment link created successfully",
-                        "schema": {
-                            "$ref": "#/definitions/service.PaymentLinkResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid billing ID",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "404": {
-                        "description": "Billing not found",
+                        "description": "Billing penghuni retrieved successfully",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.BillingPenghuniResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.APIResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/role-menus": {
+        "/api/v1/billings/runs": {
             "get": {
-                "description": "Get all role menus with pagination and relations",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the runs of the monthly billing scheduler (kind bulanan) and the late fee job (kind denda), newest first, with when each started and finished, its status and how many billings or penalties it created, replaced, skipped or failed to create",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Get all role menus",
+                "summary": "Get scheduled billing runs",
                 "parameters": [
                     {
                         "type": "integer",
@@ -631,7 +828,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Role menus retrieved successfully",
+                        "description": "Billing runs retrieved",
                         "schema": {
                             "allOf": [
                                 {
@@ -643,7 +840,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/models.RoleMenu"
+                                                "$ref": "#/definitions/models.BillingRun"
                                             }
                                         }
                                     }
@@ -658,33 +855,29 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new role menu with the provided information",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/v1/billings/tariffs": {
+            "get": {
+                "description": "Get the tariffs of every setting billing, or of one, in the order they are tried",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Create a new role menu",
+                "summary": "Get tariffs",
                 "parameters": [
                     {
-                        "description": "Role menu data",
-                        "name": "role_menu",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.CreateRoleMenuRequest"
-                        }
+                        "type": "integer",
+                        "description": "Setting billing ID",
+                        "name": "setting_billing_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Role menu created successfully",
+                    "200": {
+                        "description": "Tariffs retrieved",
                         "schema": {
                             "allOf": [
                                 {
@@ -694,7 +887,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.RoleMenu"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.SettingBillingTariff"
+                                            }
                                         }
                                     }
                                 }
@@ -702,7 +898,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Invalid setting billing ID",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -714,11 +910,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/role-menus/{id}": {
-            "get": {
-                "description": "Get role menu information by ID with master menus and roles",
+            },
+            "post": {
+                "description": "Create a price of a setting billing for the units matching its house type, cluster and land area filters. Flat tariffs charge amount, per_m2 tariffs charge amount per m² of land, and tiered tariffs charge the amount of the land area bracket. Bulk generation prices each billing with the first active tariff of its setting, by priority, matching the resident's unit, or the setting nominal when none does, and records the tariff on the billing.",
                 "consumes": [
                     "application/json"
                 ],
@@ -726,21 +920,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Get role menu by ID",
+                "summary": "Create tariff",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Role Menu ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                        "description": "Tariff",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.TariffRequest"
+                        }
+                    }
+                ],
                 "responses": {
-                    "200": {
-                        "description": "Role menu retrieved successfully",
+                    "201": {
+                        "description": "Tariff created",
                         "schema": {
                             "allOf": [
                                 {
@@ -750,7 +946,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.RoleMenu"
+                                            "$ref": "#/definitions/models.SettingBillingTariff"
                                         }
                                     }
                                 }
@@ -758,13 +954,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid role menu ID",
-                        "schema": {
-                            "$ref": "#/definitions/utils.APIResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Role menu not found",
+                        "description": "Invalid tariff",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -776,9 +966,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/billings/tariffs/{id}": {
             "put": {
-                "description": "Update role menu information",
+                "description": "Replace the filters and pricing of a tariff. Billings already generated keep their nominal.",
                 "consumes": [
                     "application/json"
                 ],
@@ -786,30 +978,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Update role menu",
+                "summary": "Update tariff",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role Menu ID",
+                        "description": "Tariff ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Role menu update data",
-                        "name": "role_menu",
+                        "description": "Tariff",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.UpdateRoleMenuRequest"
+                            "$ref": "#/definitions/service.TariffRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Role menu updated successfully",
+                        "description": "Tariff updated",
                         "schema": {
                             "allOf": [
                                 {
@@ -819,7 +1011,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.RoleMenu"
+                                            "$ref": "#/definitions/models.SettingBillingTariff"
                                         }
                                     }
                                 }
@@ -827,13 +1019,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Invalid tariff",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
                     "404": {
-                        "description": "Role menu not found",
+                        "description": "Tariff not found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -847,21 +1039,18 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete role menu by ID and its associations",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete a tariff; billings generated afterwards are priced without it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Delete role menu",
+                "summary": "Delete tariff",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role Menu ID",
+                        "description": "Tariff ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -869,19 +1058,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Role menu deleted successfully",
+                        "description": "Tariff deleted",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid role menu ID",
+                        "description": "Invalid tariff ID",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
                     "404": {
-                        "description": "Role menu not found",
+                        "description": "Tariff not found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -895,9 +1084,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/role-menus/{id}/master-menus": {
-            "post": {
-                "description": "Attach a master menu to a role menu with optional ordering",
+        "/api/v1/billings/yearly-schedules": {
+            "get": {
+                "description": "Get the month each scheduled yearly (tahunan) setting billing is billed in, by month",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "billings"
+                ],
+                "summary": "Get yearly setting billing schedules",
+                "responses": {
+                    "200": {
+                        "description": "Schedules retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.SettingBillingSchedule"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/billings/yearly-schedules/{setting_billing_id}": {
+            "put": {
+                "description": "Set the month a yearly (tahunan) setting billing is billed in, replacing its previous month. The generation of that month, scheduled or bulk, bills it once alongside the monthly settings.",
                 "consumes": [
                     "application/json"
                 ],
@@ -905,42 +1135,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Attach master menu to role menu",
+                "summary": "Schedule yearly setting billing",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role Menu ID",
-                        "name": "id",
+                        "description": "Setting billing ID",
+                        "name": "setting_billing_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Attach master menu data",
-                        "name": "attach_request",
+                        "description": "Schedule",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.AttachMasterMenuRequest"
+                            "$ref": "#/definitions/handler.YearlyScheduleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Master menu attached successfully",
+                        "description": "Schedule saved",
                         "schema": {
-                            "$ref": "#/definitions/utils.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.SettingBillingSchedule"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Invalid schedule",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
                     "404": {
-                        "description": "Role menu or master menu not found",
+                        "description": "Setting billing not found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -952,46 +1194,40 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/role-menus/{id}/master-menus/{master_menu_id}": {
+            },
             "delete": {
-                "description": "Detach a master menu from a role menu",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stop billing a yearly setting billing; billings already generated are kept",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Detach master menu from role menu",
+                "summary": "Delete yearly setting billing schedule",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role Menu ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Master Menu ID",
-                        "name": "master_menu_id",
+                        "description": "Setting billing ID",
+                        "name": "setting_billing_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Master menu detached successfully",
+                        "description": "Schedule deleted",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid setting billing ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule not found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -1005,52 +1241,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/role-menus/{id}/roles": {
-            "post": {
-                "description": "Attach a role to a role menu with optional ordering",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/billings/{id}": {
+            "get": {
+                "description": "Get a billing with its resident, status, kategori transaksi, source and due date and, while it is published, its adjustments, late fees and payments. Cancelled billings are returned with who cancelled them and why.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Attach role to role menu",
+                "summary": "Get billing",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role Menu ID",
+                        "description": "Billing ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Attach role data",
-                        "name": "attach_request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.AttachRoleRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Role attached successfully",
+                        "description": "Billing retrieved",
                         "schema": {
-                            "$ref": "#/definitions/utils.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.BillingRecordDetail"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Invalid billing ID",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
                     "404": {
-                        "description": "Role menu not found",
+                        "description": "Billing not found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -1062,11 +1298,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/role-menus/{id}/roles/{role_id}": {
-            "delete": {
-                "description": "Detach a role from a role menu",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Correct the nominal, due date or notes of a published billing. A new nominal must still cover what is paid, and the billing status follows it. Late fees already assessed are kept. The admin is read from the bearer token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1074,34 +1313,66 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Detach role from role menu",
+                "summary": "Update billing",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role Menu ID",
+                        "description": "Billing ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Role ID",
-                        "name": "role_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateBillingRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Role detached successfully",
+                        "description": "Billing updated",
                         "schema": {
-                            "$ref": "#/definitions/utils.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.BillingRecordDetail"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid update",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Billing is cancelled, has a pending checkout or its payments exceed the nominal",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -1115,31 +1386,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/roles/{role_id}/role-menus": {
+        "/api/v1/billings/{id}/adjustments": {
             "get": {
-                "description": "Get all role menus associated with a specific role",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get every adjustment of a billing, pending and reviewed, and the nominal the approved ones leave",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role-menus"
+                    "billings"
                 ],
-                "summary": "Get role menus by role ID",
+                "summary": "Get billing adjustments",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role ID",
-                        "name": "role_id",
+                        "description": "Billing ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Role menus retrieved successfully",
+                        "description": "Adjustments retrieved",
                         "schema": {
                             "allOf": [
                                 {
@@ -1149,10 +1417,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.RoleMenu"
-                                            }
+                                            "$ref": "#/definitions/service.BillingAdjustments"
                                         }
                                     }
                                 }
@@ -1160,23 +1425,32 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid role ID",
+                        "description": "Invalid billing ID",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Billing not found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
-                    }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
                 }
-            }
-        },
-        "/api/v1/users/penghuni": {
-            "get": {
-                "description": "Get list of all users with role type \"penghuni\"",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Request a discount, waiver, surcharge or correction of a billing. It stays pending, without changing the amount due, until another admin approves it. The admin is read from the bearer token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1184,12 +1458,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "billings"
+                ],
+                "summary": "Create billing adjustment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Billing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Adjustment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateAdjustmentRequest"
+                        }
+                    }
                 ],
-                "summary": "Get all penghuni users",
                 "responses": {
-                    "200": {
-                        "description": "Penghuni users retrieved successfully",
+                    "201": {
+                        "description": "Adjustment requested",
                         "schema": {
                             "allOf": [
                                 {
@@ -1199,16 +1491,31 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/response.PenghuniUserResponse"
-                                            }
+                                            "$ref": "#/definitions/models.BillingAdjustment"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Invalid adjustment",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -1218,9 +1525,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/profile/{user_id}": {
-            "get": {
-                "description": "Get detailed user information by profile ID",
+        "/api/v1/billings/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel a published billing without payments by unpublishing it. It leaves every listing, payment and late fee assessment, and bulk generation does not create it again. The admin is read from the bearer token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1228,21 +1540,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "billings"
                 ],
-                "summary": "Get user detail by profile ID",
+                "summary": "Cancel billing",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "user_id",
+                        "description": "Billing ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Cancellation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CancelBillingRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User detail retrieved successfully",
+                        "description": "Billing cancelled",
                         "schema": {
                             "allOf": [
                                 {
@@ -1252,7 +1573,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/handler.UserDetailResponse"
+                                            "$ref": "#/definitions/service.BillingRecordDetail"
                                         }
                                     }
                                 }
@@ -1260,13 +1581,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID",
+                        "description": "Invalid cancellation",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Billing not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Billing is already cancelled, has payments or has a pending checkout",
                         "schema": {
                             "$ref": "#/definitions/utils.APIResponse"
                         }
@@ -1279,467 +1612,5007 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.BulkBillingRequest": {
-            "type": "object",
-            "required": [
-                "month",
-                "year"
-            ],
-            "properties": {
-                "month": {
-                    "description": "Month 1-12",
-                    "type": "integer",
-                    "maximum": 12,
-                    "minimum": 1
-                },
-                "user_ids": {
-                    "description": "Empty means all penghuni users",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+        },
+        "/api/v1/billings/{id}/late-fees": {
+            "get": {
+                "description": "Get the due date of a billing, the denda assessed on it for every month it was overdue, and the unwaived total",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "billings"
+                ],
+                "summary": "Get billing late fees",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Billing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Late fees retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.BillingLateFees"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid billing ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
                     }
-                },
-                "year": {
-                    "description": "Reasonable year range",
-                    "type": "integer",
-                    "maximum": 2100,
-                    "minimum": 2020
                 }
             }
         },
-        "handler.CreatePaymentLinkMultipleRequest": {
-            "type": "object"
+        "/api/v1/billings/{id}/late-fees/waive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Waive the given penalties of a billing, or all of its unwaived penalties when none are given. The billing becomes paid when its payments already cover the rest. The admin is read from the bearer token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "billings"
+                ],
+                "summary": "Waive billing late fees",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Billing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Waiver",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.WaiveLateFeesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Late fees waived",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.BillingLateFees"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid waiver",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Billing has no late fees to waive or has a pending checkout",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
         },
-        "handler.UserDetailResponse": {
-            "type": "object",
-            "properties": {
-                "document_id": {
-                    "type": "string",
+        "/api/v1/installment-plans": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Split the outstanding balance of a resident's overdue billings into equal monthly parts, the remainder going to the last part. Each part is paid through its own payment link. A billing can only be in one active plan. The admin is read from the bearer token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "installments"
+                ],
+                "summary": "Create installment plan",
+                "parameters": [
+                    {
+                        "description": "Installment plan",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateInstallmentPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Installment plan created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.InstallmentPlanResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid installment plan",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Billing already paid or already in an installment plan",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Billing is not linked to a resident with a published profile",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/installment-plans/{id}": {
+            "get": {
+                "description": "Get an installment plan with its parts, their due dates and payment status, and its billings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "installments"
+                ],
+                "summary": "Get installment plan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Installment plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Installment plan retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.InstallmentPlanResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid installment plan ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Installment plan not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/master-menus": {
+            "get": {
+                "description": "Get all master menus with pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "master-menus"
+                ],
+                "summary": "Get all master menus",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Master menus retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.MasterMenu"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new master menu with the provided information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "master-menus"
+                ],
+                "summary": "Create a new master menu",
+                "parameters": [
+                    {
+                        "description": "Master menu data",
+                        "name": "master_menu",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateMasterMenuRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Master menu created successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.MasterMenu"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/master-menus/{id}": {
+            "get": {
+                "description": "Get master menu information by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "master-menus"
+                ],
+                "summary": "Get master menu by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Master Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Master menu retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.MasterMenu"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid master menu ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Master menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update master menu information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "master-menus"
+                ],
+                "summary": "Update master menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Master Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Master menu update data",
+                        "name": "master_menu",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateMasterMenuRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Master menu updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.MasterMenu"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Master menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete master menu by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "master-menus"
+                ],
+                "summary": "Delete master menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Master Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Master menu deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid master menu ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Master menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/menus/user/{user_id}": {
+            "get": {
+                "description": "Get list of menus accessible by a specific user ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menus"
+                ],
+                "summary": "Get menus by user ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Menus retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/response.MenuResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No menus found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/billing/link": {
+            "post": {
+                "description": "Create a DOKU payment link for multiple billing records by IDs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Create payment link for multiple billings",
+                "parameters": [
+                    {
+                        "description": "Billing IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreatePaymentLinkMultipleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee",
+                        "name": "payment_method_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Returns the checkout created earlier with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payment link created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/service.PaymentLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid billing IDs",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Billing already paid, has a pending checkout or idempotency key conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Billing is not linked to a resident with a published profile",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Payment gateway unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/billing/{id}/balance": {
+            "get": {
+                "description": "Get the nominal, approved adjustments, unwaived late fee (denda), amount due, paid amount, outstanding balance and status of a billing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Get billing balance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Billing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Billing balance retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.BillingBalance"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid billing ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/billing/{id}/link": {
+            "post": {
+                "description": "Create a DOKU payment link for a billing record by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Create payment link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Billing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee",
+                        "name": "payment_method_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Returns the checkout created earlier with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payment link created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/service.PaymentLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid billing ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Billing already paid, has a pending checkout or idempotency key conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Billing is not linked to a resident with a published profile",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Payment gateway unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/billing/{id}/partial-link": {
+            "post": {
+                "description": "Create a payment link for part of the outstanding balance of a billing. The billing becomes \"Dibayar Sebagian\" once the payment succeeds and \"Sudah Dibayar\" when its balance is fully paid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Create partial payment link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Billing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreatePartialPaymentLinkRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee",
+                        "name": "payment_method_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Returns the checkout created earlier with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payment link created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.PaymentLinkResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid billing ID or amount",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Billing already paid, has a pending checkout or idempotency key conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Billing is not linked to a resident with a published profile",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Payment gateway unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/doku/notification": {
+            "post": {
+                "description": "Webhook called by the configured payment gateway after a checkout payment. For DOKU the Signature header is verified with the HMACSHA256 scheme and Request-Timestamp must be within 5 minutes of server time. On a successful transaction the payment transaction and the billings behind the invoice are marked as paid. /doku/notification is registered when PAYMENT_GATEWAY=doku and the unsigned /notification only when PAYMENT_GATEWAY=fake.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Receive payment notification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "DOKU client ID",
+                        "name": "Client-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "DOKU request ID",
+                        "name": "Request-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "DOKU request timestamp",
+                        "name": "Request-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "HMACSHA256 signature",
+                        "name": "Signature",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notification processed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.PaymentNotificationResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid notification",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature or stale Request-Timestamp",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/fee-rules": {
+            "get": {
+                "description": "Get the fee rules of every payment method type",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fee-rules"
+                ],
+                "summary": "Get fee rules",
+                "responses": {
+                    "200": {
+                        "description": "Fee rules retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PaymentFeeRule"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a convenience fee charged on checkouts limited to a DOKU payment method type. Flat fees are in rupiah; percentage fees are in basis points (250 = 2.5%) of the billing amounts, rounded up and optionally capped by max_fee. The fee is stored apart from the billing nominal.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fee-rules"
+                ],
+                "summary": "Create fee rule",
+                "parameters": [
+                    {
+                        "description": "Fee rule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.FeeRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Fee rule created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PaymentFeeRule"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid fee rule",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Fee rule already exists for payment method type",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/fee-rules/{id}": {
+            "put": {
+                "description": "Replace the payment method type, fee and cap of a fee rule. Pending checkouts keep the fee they were created with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fee-rules"
+                ],
+                "summary": "Update fee rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Fee rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fee rule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.FeeRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Fee rule updated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PaymentFeeRule"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid fee rule",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Fee rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Fee rule already exists for payment method type",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a fee rule; checkouts for its payment method type no longer charge a fee",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fee-rules"
+                ],
+                "summary": "Delete fee rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Fee rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Fee rule deleted",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid fee rule ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Fee rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/installments/{part_id}/link": {
+            "post": {
+                "description": "Create a payment link for an installment part. Parts are paid in sequence and pay the plan's billings oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Create installment payment link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Installment part ID",
+                        "name": "part_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee",
+                        "name": "payment_method_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Returns the checkout created earlier with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payment link created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.PaymentLinkResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid installment part ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Installment part not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Part already paid, previous part unpaid or plan not active",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Payment gateway unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/manual": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record a cash or bank transfer payment collected outside the payment gateway. The billings are marked as paid and a paid payment transaction is stored like an online payment. The admin is read from the bearer token.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Record manual payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated billing IDs",
+                        "name": "billing_ids",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Partial amount for a single billing, defaults to the outstanding balance",
+                        "name": "amount",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cash or transfer",
+                        "name": "method",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transfer reference, required for transfers",
+                        "name": "reference_number",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payment date (YYYY-MM-DD), defaults to now",
+                        "name": "paid_date",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Notes",
+                        "name": "notes",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Receipt (JPG, PNG or PDF, max 5 MB)",
+                        "name": "receipt",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Manual payment recorded",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ManualPaymentResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Billing not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Billing already paid",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/notification": {
+            "post": {
+                "description": "Webhook called by the configured payment gateway after a checkout payment. For DOKU the Signature header is verified with the HMACSHA256 scheme and Request-Timestamp must be within 5 minutes of server time. On a successful transaction the payment transaction and the billings behind the invoice are marked as paid. /doku/notification is registered when PAYMENT_GATEWAY=doku and the unsigned /notification only when PAYMENT_GATEWAY=fake.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Receive payment notification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "DOKU client ID",
+                        "name": "Client-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "DOKU request ID",
+                        "name": "Request-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "DOKU request timestamp",
+                        "name": "Request-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "HMACSHA256 signature",
+                        "name": "Signature",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notification processed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.PaymentNotificationResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid notification",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature or stale Request-Timestamp",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/outstanding/link": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create one checkout for every unpaid published billing of the resident in the bearer token, optionally limited to a range of months. Billings in an active installment plan are left out. Used by the app's \"Bayar Semua\" button.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Pay all outstanding billings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First billing month (YYYY-MM)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last billing month (YYYY-MM)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee",
+                        "name": "payment_method_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Returns the checkout created earlier with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payment link created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.PaymentLinkResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid month range",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No outstanding billings",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency key conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Billing is not linked to a resident with a published profile",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Payment gateway unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/settlements/reconcile": {
+            "post": {
+                "description": "Upload a settlement CSV downloaded from the DOKU back office. Rows are matched against payment transactions by invoice number and amount and reported as matched, missing in our database, missing in DOKU (paid in the period but not settled), amount mismatch or status mismatch (settled in full while our transaction is not paid). The period defaults to the days of the file's transaction dates.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Reconcile DOKU settlement file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "DOKU settlement CSV",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the settlement period (YYYY-MM-DD, WIB)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day of the settlement period (YYYY-MM-DD, WIB)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Settlement reconciled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.SettlementReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid settlement file or period",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/transactions/{invoice_number}/cancel": {
+            "post": {
+                "description": "Cancel a pending checkout so it is no longer reused for its billings. The gateway is asked to close the link; DOKU Checkout cannot, so gateway_cancelled is false and the link stays open until it expires. A payment made through it is still applied.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Cancel payment link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice number",
+                        "name": "invoice_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payment link cancelled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.PaymentCancelResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Payment transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Payment transaction is not pending",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Payment gateway unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/transactions/{invoice_number}/receipt": {
+            "get": {
+                "description": "Download the PDF receipt of a paid payment transaction with the resident's name and house, the billed months and amounts, the payment method, the invoice number and a verification code. The receipt is rendered from stored data on every request, so downloading it again gives the same document.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Download payment receipt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice number",
+                        "name": "invoice_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF receipt",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Payment transaction is not paid",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/transactions/{invoice_number}/receipt/verify": {
+            "get": {
+                "description": "Check that a verification code printed on a receipt belongs to the payment transaction. A valid code returns the resident, amount and payment date to compare with the printed receipt.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Verify payment receipt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice number",
+                        "name": "invoice_number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Verification code, e.g. 1A2B-3C4D-5E6F-7A8B",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Receipt verified",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ReceiptVerification"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Missing verification code",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Payment transaction is not paid",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/transactions/{invoice_number}/refunds": {
+            "get": {
+                "description": "Get the refunds recorded on a payment transaction, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Get refund history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice number",
+                        "name": "invoice_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Refunds retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PaymentRefund"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Payment transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record money returned on a paid payment transaction. Without amount the remaining amount is refunded. billing_ids are set back to unpaid; a full refund reopens every billing of the payment when none are given. Billings also paid by another transaction stay paid. The admin is read from the bearer token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Refund payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice number",
+                        "name": "invoice_number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Refund",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefundPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Refund recorded",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.RefundResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid refund",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Payment transaction is not paid",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role-menus": {
+            "get": {
+                "description": "Get all role menus with pagination and relations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Get all role menus",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role menus retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.RoleMenu"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new role menu with the provided information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Create a new role menu",
+                "parameters": [
+                    {
+                        "description": "Role menu data",
+                        "name": "role_menu",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateRoleMenuRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Role menu created successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.RoleMenu"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role-menus/{id}": {
+            "get": {
+                "description": "Get role menu information by ID with master menus and roles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Get role menu by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role menu retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.RoleMenu"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid role menu ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update role menu information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Update role menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role menu update data",
+                        "name": "role_menu",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateRoleMenuRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role menu updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.RoleMenu"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete role menu by ID and its associations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Delete role menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role menu deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid role menu ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role-menus/{id}/master-menus": {
+            "post": {
+                "description": "Attach a master menu to a role menu with optional ordering",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Attach master menu to role menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Attach master menu data",
+                        "name": "attach_request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.AttachMasterMenuRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Master menu attached successfully",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role menu or master menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role-menus/{id}/master-menus/{master_menu_id}": {
+            "delete": {
+                "description": "Detach a master menu from a role menu",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Detach master menu from role menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Master Menu ID",
+                        "name": "master_menu_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Master menu detached successfully",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role-menus/{id}/roles": {
+            "post": {
+                "description": "Attach a role to a role menu with optional ordering",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Attach role to role menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Attach role data",
+                        "name": "attach_request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.AttachRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role attached successfully",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role-menus/{id}/roles/{role_id}": {
+            "delete": {
+                "description": "Detach a role from a role menu",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Detach role from role menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "role_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role detached successfully",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/roles/{role_id}/role-menus": {
+            "get": {
+                "description": "Get all role menus associated with a specific role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role-menus"
+                ],
+                "summary": "Get role menus by role ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "role_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role menus retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.RoleMenu"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid role ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/penghuni": {
+            "get": {
+                "description": "Get list of all users with role type \"penghuni\"",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all penghuni users",
+                "responses": {
+                    "200": {
+                        "description": "Penghuni users retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/response.PenghuniUserResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/profile/{user_id}": {
+            "get": {
+                "description": "Get detailed user information by profile ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user detail by profile ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User detail retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.UserDetailResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/units/{profile_id}": {
+            "get": {
+                "description": "Get the house a resident profile occupies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get resident unit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Profile ID",
+                        "name": "profile_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resident unit retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ResidentUnit"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid profile ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Resident unit not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create or replace the house a resident profile occupies, printed on payment receipts, its occupancy dates, and the house type, cluster and land area its tariffs are chosen by. Billings are generated only for the months it is occupied, the first and last prorated by the billing proration policy.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Save resident unit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Profile ID",
+                        "name": "profile_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resident unit",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.ResidentUnitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resident unit saved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ResidentUnit"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid resident unit",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Profile not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handler.BulkBillingRequest": {
+            "type": "object",
+            "required": [
+                "month",
+                "year"
+            ],
+            "properties": {
+                "dry_run": {
+                    "description": "DryRun previews the billings per user, with amounts and totals, without creating them",
+                    "type": "boolean"
+                },
+                "existing": {
+                    "description": "Existing decides what happens to billings that already exist for a user, period and\nsetting: \"skip\" (default) or \"replace_unpaid\" to update unpaid ones to the current nominal",
+                    "type": "string",
+                    "example": "skip"
+                },
+                "month": {
+                    "description": "Month 1-12",
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1
+                },
+                "user_ids": {
+                    "description": "Empty means all penghuni users",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "year": {
+                    "description": "Reasonable year range",
+                    "type": "integer",
+                    "maximum": 2100,
+                    "minimum": 2020
+                }
+            }
+        },
+        "handler.CancelBillingRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Tagihan ganda"
+                }
+            }
+        },
+        "handler.CreateAdjustmentRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "type"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Size of the change; signed for corrections; omitted waives the whole nominal",
+                    "type": "integer",
+                    "example": 25000
+                },
+                "reason": {
+                    "description": "Why the billing is adjusted",
+                    "type": "string",
+                    "example": "Rumah kosong bulan Maret"
+                },
+                "type": {
+                    "description": "discount, waiver, surcharge or correction",
+                    "type": "string",
+                    "example": "discount"
+                }
+            }
+        },
+        "handler.CreateInstallmentPlanRequest": {
+            "type": "object",
+            "required": [
+                "billing_ids",
+                "first_due_date",
+                "parts"
+            ],
+            "properties": {
+                "billing_ids": {
+                    "description": "Overdue billings of one resident",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3,
+                        4,
+                        5
+                    ]
+                },
+                "first_due_date": {
+                    "description": "Due date of the first part (YYYY-MM-DD, WIB)",
+                    "type": "string",
+                    "example": "2025-11-10"
+                },
+                "notes": {
+                    "description": "Optional notes",
+                    "type": "string",
+                    "example": "Disepakati dengan bendahara RT"
+                },
+                "parts": {
+                    "description": "Number of monthly parts, 2 to 24",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "handler.CreatePartialPaymentLinkRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount to pay, at most the outstanding balance",
+                    "type": "integer",
+                    "example": 50000
+                }
+            }
+        },
+        "handler.CreatePaymentLinkMultipleRequest": {
+            "type": "object"
+        },
+        "handler.OneOffBillingRequest": {
+            "type": "object",
+            "required": [
+                "month",
+                "nama_billing",
+                "nominal",
+                "user_ids",
+                "year"
+            ],
+            "properties": {
+                "due_date": {
+                    "description": "YYYY-MM-DD; defaults to the billing due day of the month",
+                    "type": "string",
+                    "example": "2026-08-10"
+                },
+                "keterangan": {
+                    "type": "string",
+                    "example": "Lomba dan tasyakuran RT"
+                },
+                "month": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1
+                },
+                "nama_billing": {
+                    "type": "string",
+                    "example": "Iuran 17 Agustus"
+                },
+                "nominal": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 75000
+                },
+                "user_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        10,
+                        11
+                    ]
+                },
+                "year": {
+                    "type": "integer",
+                    "maximum": 2100,
+                    "minimum": 2020
+                }
+            }
+        },
+        "handler.RefundPaymentRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "0 or omitted refunds the remaining amount",
+                    "type": "integer",
+                    "example": 150000
+                },
+                "billing_ids": {
+                    "description": "Billings to set back to unpaid",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        6
+                    ]
+                },
+                "reason": {
+                    "description": "Why the money is returned",
+                    "type": "string",
+                    "example": "Pembayaran ganda"
+                }
+            }
+        },
+        "handler.ReviewAdjustmentRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "example": "Sesuai keputusan rapat RT"
+                }
+            }
+        },
+        "handler.UpdateBillingRequest": {
+            "type": "object",
+            "properties": {
+                "due_date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string",
+                    "example": "2026-03-15"
+                },
+                "nominal": {
+                    "type": "integer",
+                    "example": 125000
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Nominal dikoreksi sesuai tipe rumah"
+                }
+            }
+        },
+        "handler.UserDetailResponse": {
+            "type": "object",
+            "properties": {
+                "document_id": {
+                    "type": "string",
+                    "example": "abc123def456"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "nama_penghuni": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "no_hp": {
+                    "type": "string",
+                    "example": "+6281234567890"
+                },
+                "no_telp": {
+                    "type": "string",
+                    "example": "021-12345678"
+                },
+                "role_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "role_name": {
+                    "type": "string",
+                    "example": "Administrator"
+                },
+                "role_type": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 123
+                }
+            }
+        },
+        "handler.WaiveLateFeesRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "penalty_ids": {
+                    "description": "Omitted waives every unwaived penalty",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3
+                    ]
+                },
+                "reason": {
+                    "description": "Why the denda is waived",
+                    "type": "string",
+                    "example": "Keterlambatan karena bencana"
+                }
+            }
+        },
+        "handler.YearlyScheduleRequest": {
+            "type": "object",
+            "required": [
+                "billing_month"
+            ],
+            "properties": {
+                "billing_month": {
+                    "description": "Month 1-12 the setting is billed in",
+                    "type": "integer",
+                    "example": 8
+                }
+            }
+        },
+        "models.BillingAdjustment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Change to the amount due: negative for discounts and waivers",
+                    "type": "integer"
+                },
+                "billing_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "discount_rule_id": {
+                    "description": "Rule the adjustment was generated from",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "review_note": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by_id": {
+                    "description": "Admin who approved or rejected it",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BillingCharge": {
+            "type": "object",
+            "properties": {
+                "bulan": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "due_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "keterangan": {
+                    "type": "string"
+                },
+                "nama_billing": {
+                    "type": "string"
+                },
+                "nominal": {
+                    "type": "integer"
+                },
+                "tahun": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BillingComponent": {
+            "type": "object",
+            "properties": {
+                "adjustment": {
+                    "description": "Sum of the approved adjustments",
+                    "type": "integer",
+                    "example": -50000
+                },
+                "amount_due": {
+                    "description": "Nominal plus adjustment and late fee",
+                    "type": "integer",
+                    "example": 50000
+                },
+                "billing_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "late_fee": {
+                    "description": "Sum of the unwaived denda",
+                    "type": "integer",
+                    "example": 0
+                },
+                "nama_billing": {
+                    "type": "string",
+                    "example": "Keamanan"
+                },
+                "nominal": {
+                    "type": "integer",
+                    "example": 100000
+                },
+                "setting_billing_id": {
+                    "description": "Null for billings generated before settings were tracked",
+                    "type": "integer",
+                    "example": 3
+                },
+                "status_billing": {
+                    "type": "string",
+                    "example": "Belum Dibayar"
+                }
+            }
+        },
+        "models.BillingDiscountRule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "discount_type": {
+                    "description": "flat or percentage",
+                    "type": "string"
+                },
+                "from_month": {
+                    "description": "First billing month, YYYY-MM; empty is open",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "setting_billing_id": {
+                    "description": "Null applies to every setting billing",
+                    "type": "integer"
+                },
+                "to_month": {
+                    "description": "Last billing month, YYYY-MM; empty is open",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "Rupiah for flat, basis points for percentage (10000 exempts)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BillingPenalty": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "billing_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "due_date": {
+                    "description": "Due date the penalty was assessed against",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "months_late": {
+                    "type": "integer"
+                },
+                "waive_reason": {
+                    "type": "string"
+                },
+                "waived_at": {
+                    "type": "string"
+                },
+                "waived_by_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BillingPenghuniResponse": {
+            "type": "object",
+            "properties": {
+                "bulan": {
+                    "description": "Month name",
+                    "type": "string",
+                    "example": "November"
+                },
+                "components": {
+                    "description": "Billings summed into Nominal, one per setting billing",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BillingComponent"
+                    }
+                },
+                "document_id": {
+                    "description": "User document ID",
+                    "type": "string",
+                    "example": "abc123def456"
+                },
+                "email": {
+                    "description": "User email address",
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "id": {
+                    "description": "User ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "nama_penghuni": {
+                    "description": "Resident name",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "no_hp": {
+                    "description": "Phone number",
+                    "type": "string",
+                    "example": "+6281234567890"
+                },
+                "no_telp": {
+                    "description": "Telephone number",
+                    "type": "string",
+                    "example": "021-12345678"
+                },
+                "nominal": {
+                    "description": "Total nominal after approved adjustments (summed per billing period)",
+                    "type": "integer",
+                    "example": 500000
+                },
+                "role_id": {
+                    "description": "Role ID",
+                    "type": "integer",
+                    "example": 5
+                },
+                "role_name": {
+                    "description": "Role name",
+                    "type": "string",
+                    "example": "Penghuni"
+                },
+                "role_type": {
+                    "description": "Role type",
+                    "type": "string",
+                    "example": "penghuni"
+                },
+                "status_billing": {
+                    "description": "Billing status",
+                    "type": "string",
+                    "example": "Belum Dibayar"
+                },
+                "tahun": {
+                    "description": "Year",
+                    "type": "integer",
+                    "example": 2025
+                },
+                "username": {
+                    "description": "Username",
+                    "type": "string",
+                    "example": "john_doe"
+                }
+            }
+        },
+        "models.BillingRecord": {
+            "type": "object",
+            "properties": {
+                "bulan": {
+                    "type": "integer"
+                },
+                "cancel_reason": {
+                    "type": "string"
+                },
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "cancelled_by_id": {
+                    "type": "integer"
+                },
+                "charge_id": {
+                    "description": "One-off charge it bills",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "due_date": {
+                    "description": "The configured due day of the period when the billing has no detail row",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kategori_transaksi": {
+                    "type": "string"
+                },
+                "kategori_transaksi_id": {
+                    "type": "integer"
+                },
+                "nama_billing": {
+                    "type": "string"
+                },
+                "nama_penghuni": {
+                    "type": "string"
+                },
+                "nominal": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "published_at": {
+                    "description": "Null once cancelled",
+                    "type": "string"
+                },
+                "setting_billing_id": {
+                    "description": "Setting the billing was generated from",
+                    "type": "integer"
+                },
+                "status_id": {
+                    "type": "integer"
+                },
+                "status_name": {
+                    "type": "string"
+                },
+                "tahun": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BillingRun": {
+            "type": "object",
+            "properties": {
+                "bulan": {
+                    "type": "integer"
+                },
+                "created_count": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed_count": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "What was generated, e.g. \"bulanan\" or \"denda\"",
+                    "type": "string"
+                },
+                "replaced_count": {
+                    "type": "integer"
+                },
+                "skipped_count": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tahun": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.InstallmentPart": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "due_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "integer"
+                },
+                "sequence": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MasterMenu": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "kode_menu": {
+                    "type": "string"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "nama_menu": {
+                    "type": "string"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "urutan_menu": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaymentFeeRule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "fee_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "max_fee": {
+                    "description": "Optional cap of a percentage fee",
+                    "type": "integer"
+                },
+                "payment_method_type": {
+                    "description": "DOKU payment method type, e.g. CREDIT_CARD",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Rupiah for flat fees, basis points (1/100 %) for percentage fees",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaymentRefund": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "billings": {
+                    "description": "What the refund took back from each billing",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PaymentRefundBilling"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payment_transaction_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "refunded_by_id": {
+                    "type": "integer"
+                },
+                "reopened_billing_key": {
+                    "description": "Sorted, comma separated billing IDs set back to unpaid",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PaymentRefundBilling": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "billing_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payment_refund_id": {
+                    "type": "integer"
+                },
+                "payment_transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResidentUnit": {
+            "type": "object",
+            "properties": {
+                "cluster": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "house_number": {
+                    "description": "Block and number, e.g. \"A-12\"",
+                    "type": "string"
+                },
+                "house_type": {
+                    "description": "Building type, e.g. \"36\", \"45\" or \"72\"",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "land_area": {
+                    "description": "m²; 0 if unknown",
+                    "type": "integer"
+                },
+                "occupied_from": {
+                    "description": "Move-in day; null if unknown",
+                    "type": "string"
+                },
+                "occupied_until": {
+                    "description": "Last day before moving out; null while occupied",
+                    "type": "string"
+                },
+                "profile_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Role": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RoleMenu": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "master_menus": {
+                    "description": "Relationships",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MasterMenu"
+                    }
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "role_menu_ord": {
+                    "type": "number"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Role"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SettingBillingSchedule": {
+            "type": "object",
+            "properties": {
+                "billing_month": {
+                    "description": "1 to 12",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nama_billing": {
+                    "description": "Read only, joined from setting_billings by the queries that need it",
+                    "type": "string"
+                },
+                "setting_billing_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SettingBillingTariff": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Rupiah for flat, rupiah per m² for per_m2",
+                    "type": "integer"
+                },
+                "cluster": {
+                    "description": "Empty matches any",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "house_type": {
+                    "description": "e.g. \"36\"; empty matches any",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "max_land_area": {
+                    "description": "m², inclusive",
+                    "type": "integer"
+                },
+                "min_land_area": {
+                    "description": "m², inclusive",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pricing_type": {
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "Lower is tried first",
+                    "type": "integer"
+                },
+                "setting_billing_id": {
+                    "type": "integer"
+                },
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TariffTier"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TariffTier": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 150000
+                },
+                "up_to_area": {
+                    "description": "Largest land area of the bracket in m²; 0 leaves the last bracket open",
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "response.MenuResponse": {
+            "type": "object",
+            "properties": {
+                "document_id": {
+                    "type": "string",
+                    "example": "mo5qqs8ezbruui07t91p6da8"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "is_active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "kode_menu": {
+                    "type": "string",
+                    "example": "master-data"
+                },
+                "nama_menu": {
+                    "type": "string",
+                    "example": "Master Data"
+                },
+                "published_at": {
+                    "type": "string",
+                    "example": "2025-10-23T15:16:28.206Z"
+                },
+                "urutan_menu": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "response.PenghuniUserResponse": {
+            "type": "object",
+            "properties": {
+                "document_id": {
+                    "type": "string",
                     "example": "abc123def456"
                 },
-                "email": {
-                    "type": "string",
-                    "example": "john.doe@example.com"
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "nama_penghuni": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "no_hp": {
+                    "type": "string",
+                    "example": "+6281234567890"
+                },
+                "no_telp": {
+                    "type": "string",
+                    "example": "021-12345678"
+                },
+                "role_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "role_name": {
+                    "type": "string",
+                    "example": "Penghuni"
+                },
+                "role_type": {
+                    "type": "string",
+                    "example": "penghuni"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "john_doe"
+                }
+            }
+        },
+        "service.AttachMasterMenuRequest": {
+            "type": "object",
+            "required": [
+                "master_menu_id"
+            ],
+            "properties": {
+                "master_menu_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "order": {
+                    "type": "number",
+                    "example": 1
+                }
+            }
+        },
+        "service.AttachRoleRequest": {
+            "type": "object",
+            "required": [
+                "role_id"
+            ],
+            "properties": {
+                "order": {
+                    "type": "number",
+                    "example": 1
+                },
+                "role_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "service.BillingAdjustments": {
+            "type": "object",
+            "properties": {
+                "adjusted_nominal": {
+                    "description": "Nominal plus adjustment, before late fees",
+                    "type": "integer"
+                },
+                "adjustment": {
+                    "description": "Sum of the approved adjustments",
+                    "type": "integer"
+                },
+                "adjustments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BillingAdjustment"
+                    }
+                },
+                "billing_id": {
+                    "type": "integer"
+                },
+                "nominal": {
+                    "description": "As generated",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.BillingBalance": {
+            "type": "object",
+            "properties": {
+                "adjustment": {
+                    "description": "Approved discounts, waivers, surcharges and corrections",
+                    "type": "integer"
+                },
+                "amount_due": {
+                    "description": "Nominal plus adjustment and late fee",
+                    "type": "integer"
+                },
+                "billing_id": {
+                    "type": "integer"
+                },
+                "late_fee": {
+                    "description": "Unwaived denda",
+                    "type": "integer"
+                },
+                "nominal": {
+                    "type": "integer"
+                },
+                "outstanding": {
+                    "type": "integer"
+                },
+                "paid_amount": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.BillingLateFees": {
+            "type": "object",
+            "properties": {
+                "billing_id": {
+                    "type": "integer"
+                },
+                "due_date": {
+                    "type": "string"
+                },
+                "late_fee": {
+                    "description": "Sum of the unwaived penalties",
+                    "type": "integer"
+                },
+                "penalties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BillingPenalty"
+                    }
+                }
+            }
+        },
+        "service.BillingProration": {
+            "type": "object",
+            "properties": {
+                "base_nominal": {
+                    "description": "Nominal of a full month",
+                    "type": "integer"
+                },
+                "days_in_month": {
+                    "type": "integer"
+                },
+                "occupied_days": {
+                    "type": "integer"
+                },
+                "policy": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.BillingRecordBalance": {
+            "type": "object",
+            "properties": {
+                "adjustment": {
+                    "description": "Approved adjustments, negative for discounts",
+                    "type": "integer"
+                },
+                "amount_due": {
+                    "description": "Nominal plus adjustment and late fee",
+                    "type": "integer"
+                },
+                "late_fee": {
+                    "description": "Unwaived late fees",
+                    "type": "integer"
+                },
+                "outstanding": {
+                    "type": "integer"
+                },
+                "paid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.BillingRecordDetail": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "$ref": "#/definitions/service.BillingRecordBalance"
+                },
+                "bulan": {
+                    "type": "integer"
+                },
+                "cancel_reason": {
+                    "type": "string"
+                },
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "cancelled_by_id": {
+                    "type": "integer"
+                },
+                "charge_id": {
+                    "description": "One-off charge it bills",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "due_date": {
+                    "description": "The configured due day of the period when the billing has no detail row",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kategori_transaksi": {
+                    "type": "string"
+                },
+                "kategori_transaksi_id": {
+                    "type": "integer"
+                },
+                "nama_billing": {
+                    "type": "string"
+                },
+                "nama_penghuni": {
+                    "type": "string"
+                },
+                "nominal": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "published_at": {
+                    "description": "Null once cancelled",
+                    "type": "string"
+                },
+                "setting_billing_id": {
+                    "description": "Setting the billing was generated from",
+                    "type": "integer"
+                },
+                "status_id": {
+                    "type": "integer"
+                },
+                "status_name": {
+                    "type": "string"
+                },
+                "tahun": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.BulkBillingItem": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "create, replace or skip",
+                    "type": "string"
+                },
+                "billing_id": {
+                    "description": "Existing billing that is replaced or skipped",
+                    "type": "integer"
+                },
+                "discount": {
+                    "description": "Given by the resident's discount rules",
+                    "type": "integer"
+                },
+                "nama_billing": {
+                    "type": "string"
+                },
+                "nominal": {
+                    "type": "integer"
+                },
+                "previous_nominal": {
+                    "description": "Nominal of the existing billing",
+                    "type": "integer"
+                },
+                "proration": {
+                    "description": "How Nominal was prorated for a partly occupied month",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.BillingProration"
+                        }
+                    ]
+                },
+                "reason": {
+                    "description": "Why an unpaid billing was not replaced",
+                    "type": "string"
+                },
+                "setting_billing_id": {
+                    "type": "integer"
+                },
+                "tariff_id": {
+                    "description": "Tariff that priced the billing instead of the setting nominal",
+                    "type": "integer"
+                },
+                "tariff_name": {
+                    "description": "Its name",
+                    "type": "string"
+                }
+            }
+        },
+        "service.BulkBillingResponse": {
+            "type": "object",
+            "properties": {
+                "created_count": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failed_count": {
+                    "type": "integer"
+                },
+                "replaced_count": {
+                    "type": "integer"
+                },
+                "skipped_count": {
+                    "type": "integer"
+                },
+                "skipped_user_ids": {
+                    "description": "Users not found or without a resident profile",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "success_count": {
+                    "type": "integer"
                 },
-                "id": {
-                    "type": "integer",
-                    "example": 1
+                "total_billings": {
+                    "type": "integer"
                 },
-                "nama_penghuni": {
-                    "type": "string",
-                    "example": "John Doe"
+                "total_discount": {
+                    "description": "Sum of their discount rule adjustments",
+                    "type": "integer"
                 },
-                "no_hp": {
-                    "type": "string",
-                    "example": "+6281234567890"
+                "total_nominal": {
+                    "description": "Sum of the created and replaced billings",
+                    "type": "integer"
                 },
-                "no_telp": {
-                    "type": "string",
-                    "example": "021-12345678"
+                "total_users": {
+                    "type": "integer"
                 },
-                "role_id": {
-                    "type": "integer",
-                    "example": 1
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BulkBillingUserResult"
+                    }
+                }
+            }
+        },
+        "service.BulkBillingUserResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
                 },
-                "role_name": {
-                    "type": "string",
-                    "example": "Administrator"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BulkBillingItem"
+                    }
                 },
-                "role_type": {
-                    "type": "string",
-                    "example": "admin"
+                "reason": {
+                    "description": "Why every billing of the resident was skipped",
+                    "type": "string"
+                },
+                "replaced": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "total_discount": {
+                    "description": "Sum of their discount rule adjustments",
+                    "type": "integer"
+                },
+                "total_nominal": {
+                    "description": "Sum of the created and replaced billings",
+                    "type": "integer"
                 },
                 "user_id": {
-                    "type": "integer",
-                    "example": 123
+                    "type": "integer"
                 }
             }
         },
-        "models.BillingPenghuniResponse": {
+        "service.CreateMasterMenuRequest": {
             "type": "object",
+            "required": [
+                "kode_menu",
+                "nama_menu"
+            ],
             "properties": {
-                "bulan": {
-                    "description": "Month name",
+                "document_id": {
                     "type": "string",
-                    "example": "November"
+                    "example": "menu001"
                 },
-                "document_id": {
-                    "description": "User document ID",
+                "is_active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "kode_menu": {
                     "type": "string",
-                    "example": "abc123def456"
+                    "example": "DASHBOARD"
                 },
-                "email": {
-                    "description": "User email address",
+                "locale": {
                     "type": "string",
-                    "example": "john.doe@example.com"
+                    "example": "id"
                 },
-                "id": {
-                    "description": "User ID",
+                "nama_menu": {
+                    "type": "string",
+                    "example": "Dashboard"
+                },
+                "urutan_menu": {
                     "type": "integer",
                     "example": 1
-                },
-                "nama_penghuni": {
-                    "description": "Resident name",
+                }
+            }
+        },
+        "service.CreateRoleMenuRequest": {
+            "type": "object"
+        },
+        "service.DiscountRuleRequest": {
+            "type": "object",
+            "required": [
+                "discount_type",
+                "name",
+                "user_id",
+                "value"
+            ],
+            "properties": {
+                "discount_type": {
+                    "description": "flat or percentage",
                     "type": "string",
-                    "example": "John Doe"
+                    "example": "percentage"
                 },
-                "no_hp": {
-                    "description": "Phone number",
+                "from_month": {
+                    "description": "First billing month, YYYY-MM; omitted is open",
                     "type": "string",
-                    "example": "+6281234567890"
+                    "example": "2026-01"
                 },
-                "no_telp": {
-                    "description": "Telephone number",
+                "is_active": {
+                    "description": "Defaults to true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
                     "type": "string",
-                    "example": "021-12345678"
+                    "example": "Rumah kosong"
                 },
-                "nominal": {
-                    "description": "Total nominal amount (summed per billing period)",
+                "setting_billing_id": {
+                    "description": "Omitted applies to every setting billing",
                     "type": "integer",
-                    "example": 500000
+                    "example": 1
                 },
-                "role_id": {
-                    "description": "Role ID",
+                "to_month": {
+                    "description": "Last billing month, YYYY-MM; omitted is open",
+                    "type": "string",
+                    "example": "2026-12"
+                },
+                "user_id": {
                     "type": "integer",
-                    "example": 5
+                    "example": 7
                 },
-                "role_name": {
-                    "description": "Role name",
+                "value": {
+                    "description": "Rupiah for flat discounts, basis points for percentage discounts",
+                    "type": "integer",
+                    "example": 5000
+                }
+            }
+        },
+        "service.FeeRuleRequest": {
+            "type": "object",
+            "required": [
+                "fee_type",
+                "payment_method_type",
+                "value"
+            ],
+            "properties": {
+                "description": {
                     "type": "string",
-                    "example": "Penghuni"
+                    "example": "Biaya kartu kredit 2,5%"
                 },
-                "role_type": {
-                    "description": "Role type",
+                "fee_type": {
+                    "description": "flat or percentage",
                     "type": "string",
-                    "example": "penghuni"
+                    "example": "percentage"
                 },
-                "status_billing": {
-                    "description": "Billing status",
-                    "type": "string",
-                    "example": "Belum Dibayar"
+                "is_active": {
+                    "description": "Defaults to true",
+                    "type": "boolean",
+                    "example": true
                 },
-                "tahun": {
-                    "description": "Year",
+                "max_fee": {
+                    "description": "Optional cap of a percentage fee",
                     "type": "integer",
-                    "example": 2025
+                    "example": 10000
                 },
-                "username": {
-                    "description": "Username",
+                "payment_method_type": {
                     "type": "string",
-                    "example": "john_doe"
+                    "example": "CREDIT_CARD"
+                },
+                "value": {
+                    "description": "Rupiah for flat fees, basis points for percentage fees",
+                    "type": "integer",
+                    "example": 250
                 }
             }
         },
-        "models.MasterMenu": {
+        "service.InstallmentPlanResponse": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "billing_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "billing_key": {
+                    "description": "Sorted, comma separated billing IDs",
                     "type": "string"
                 },
-                "document_id": {
+                "created_at": {
                     "type": "string"
                 },
+                "created_by_id": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "is_active": {
+                "notes": {
+                    "type": "string"
+                },
+                "parts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InstallmentPart"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ManualPaymentResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "billing_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "invoice_number": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "receipt_path": {
+                    "type": "string"
+                },
+                "reference_number": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.OneOffBillingResponse": {
+            "type": "object",
+            "properties": {
+                "billing_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "charge": {
+                    "$ref": "#/definitions/models.BillingCharge"
+                },
+                "created_count": {
+                    "type": "integer"
+                },
+                "skipped_user_ids": {
+                    "description": "Users not found or without a resident profile",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "total_nominal": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.PaymentCancelResult": {
+            "type": "object",
+            "properties": {
+                "billing_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "gateway_cancelled": {
+                    "description": "False when the gateway cannot cancel and the link stays open until it expires",
                     "type": "boolean"
                 },
-                "kode_menu": {
+                "invoice_number": {
+                    "type": "string"
+                },
+                "payment_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.PaymentLinkResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Billing amounts plus the fee",
+                    "type": "integer"
+                },
+                "billing_id": {
+                    "type": "integer"
+                },
+                "billing_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "description": {
                     "type": "string"
                 },
-                "locale": {
+                "expired_at": {
                     "type": "string"
                 },
-                "nama_menu": {
-                    "type": "string"
+                "fee_amount": {
+                    "description": "Convenience fee of the payment method type",
+                    "type": "integer"
                 },
-                "published_at": {
+                "invoice_number": {
                     "type": "string"
                 },
-                "updated_at": {
+                "payment_url": {
                     "type": "string"
                 },
-                "urutan_menu": {
+                "reused": {
+                    "description": "True when an existing pending checkout was returned",
+                    "type": "boolean"
+                },
+                "transaction_id": {
                     "type": "integer"
                 }
             }
         },
-        "models.Role": {
+        "service.PaymentNotificationResult": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "created_by_id": {
-                    "type": "integer"
+                "billing_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "description": {
+                "invoice_number": {
                     "type": "string"
                 },
-                "document_id": {
+                "payment_status": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "locale": {
+                "transaction_status": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
+                "updated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.ReceiptVerification": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
                 },
-                "published_at": {
+                "invoice_number": {
                     "type": "string"
                 },
-                "type": {
+                "paid_at": {
                     "type": "string"
                 },
-                "updated_at": {
+                "resident_name": {
                     "type": "string"
                 },
-                "updated_by_id": {
-                    "type": "integer"
+                "valid": {
+                    "type": "boolean"
                 }
             }
         },
-        "models.RoleMenu": {
+        "service.RefundResponse": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "created_by_id": {
+                "amount": {
                     "type": "integer"
                 },
-                "document_id": {
+                "invoice_number": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "is_active": {
-                    "type": "boolean"
-                },
-                "master_menus": {
-                    "description": "Relationships",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.MasterMenu"
-                    }
-                },
-                "published_at": {
+                "payment_status": {
                     "type": "string"
                 },
-                "role_menu_ord": {
-                    "type": "number"
+                "refund_id": {
+                    "type": "integer"
                 },
-                "roles": {
+                "refunded_amount": {
+                    "description": "Total refunded on the transaction so far",
+                    "type": "integer"
+                },
+                "reopened_billing_ids": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Role"
+                        "type": "integer"
                     }
-                },
-                "updated_at": {
-                    "type": "string"
-                },
-                "updated_by_id": {
-                    "type": "integer"
                 }
             }
         },
-        "response.MenuResponse": {
+        "service.ResidentUnitRequest": {
             "type": "object",
+            "required": [
+                "house_number"
+            ],
             "properties": {
-                "document_id": {
+                "cluster": {
                     "type": "string",
-                    "example": "mo5qqs8ezbruui07t91p6da8"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "is_active": {
-                    "type": "boolean",
-                    "example": true
+                    "example": "Cluster Melati"
                 },
-                "kode_menu": {
+                "house_number": {
                     "type": "string",
-                    "example": "master-data"
+                    "example": "A-12"
                 },
-                "nama_menu": {
+                "house_type": {
                     "type": "string",
-                    "example": "Master Data"
+                    "example": "45"
                 },
-                "published_at": {
+                "land_area": {
+                    "description": "m²; omitted if unknown",
+                    "type": "integer",
+                    "example": 120
+                },
+                "occupied_from": {
+                    "description": "Move-in day; omitted if unknown",
                     "type": "string",
-                    "example": "2025-10-23T15:16:28.206Z"
+                    "example": "2026-03-16"
                 },
-                "urutan_menu": {
-                    "type": "integer",
-                    "example": 1
+                "occupied_until": {
+                    "description": "Last day before moving out; omitted while occupied",
+                    "type": "string",
+                    "example": "2026-12-31"
                 }
             }
         },
-        "response.PenghuniUserResponse": {
+        "service.SettlementReport": {
             "type": "object",
             "properties": {
-                "document_id": {
-                    "type": "string",
-                    "example": "abc123def456"
-                },
-                "email": {
-                    "type": "string",
-                    "example": "john.doe@example.com"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 1
+                "amount_mismatch": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SettlementReportRow"
+                    }
                 },
-                "nama_penghuni": {
-                    "type": "string",
-                    "example": "John Doe"
+                "matched": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SettlementReportRow"
+                    }
                 },
-                "no_hp": {
-                    "type": "string",
-                    "example": "+6281234567890"
+                "missing_in_db": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SettlementReportRow"
+                    }
                 },
-                "no_telp": {
-                    "type": "string",
-                    "example": "021-12345678"
+                "missing_in_doku": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SettlementReportRow"
+                    }
                 },
-                "role_id": {
-                    "type": "integer",
-                    "example": 5
+                "period_from": {
+                    "type": "string"
                 },
-                "role_name": {
-                    "type": "string",
-                    "example": "Penghuni"
+                "period_to": {
+                    "type": "string"
                 },
-                "role_type": {
-                    "type": "string",
-                    "example": "penghuni"
+                "status_mismatch": {
+                    "description": "Settled in full while our transaction is not paid",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SettlementReportRow"
+                    }
                 },
-                "username": {
-                    "type": "string",
-                    "example": "john_doe"
+                "summary": {
+                    "$ref": "#/definitions/service.SettlementReportSummary"
                 }
             }
         },
-        "service.AttachMasterMenuRequest": {
+        "service.SettlementReportRow": {
             "type": "object",
-            "required": [
-                "master_menu_id"
-            ],
             "properties": {
-                "master_menu_id": {
-                    "type": "integer",
-                    "example": 1
+                "invoice_number": {
+                    "type": "string"
                 },
-                "order": {
-                    "type": "number",
-                    "example": 1
-                }
-            }
-        },
-        "service.AttachRoleRequest": {
-            "type": "object",
-            "required": [
-                "role_id"
-            ],
-            "properties": {
-                "order": {
-                    "type": "number",
-                    "example": 1
+                "line": {
+                    "type": "integer"
                 },
-                "role_id": {
-                    "type": "integer",
-                    "example": 1
+                "paid_at": {
+                    "type": "string"
+                },
+                "settlement_amount": {
+                    "type": "integer"
+                },
+                "transaction_amount": {
+                    "type": "integer"
+                },
+                "transaction_date": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "transaction_status": {
+                    "type": "string"
                 }
             }
         },
-        "service.BulkBillingResponse": {
+        "service.SettlementReportSummary": {
             "type": "object",
             "properties": {
-                "errors": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "amount_mismatch": {
+                    "type": "integer"
                 },
-                "failed_count": {
+                "matched": {
                     "type": "integer"
                 },
-                "success_count": {
+                "missing_in_db": {
                     "type": "integer"
                 },
-                "total_billings": {
+                "missing_in_doku": {
                     "type": "integer"
                 },
-                "total_users": {
+                "settlement_amount": {
+                    "type": "integer"
+                },
+                "status_mismatch": {
+                    "type": "integer"
+                },
+                "total_rows": {
                     "type": "integer"
                 }
             }
         },
-        "service.CreateMasterMenuRequest": {
+        "service.TariffRequest": {
             "type": "object",
             "required": [
-                "kode_menu",
-                "nama_menu"
+                "name",
+                "pricing_type",
+                "setting_billing_id"
             ],
             "properties": {
-                "document_id": {
+                "amount": {
+                    "description": "Rupiah for flat, rupiah per m² for per_m2",
+                    "type": "integer",
+                    "example": 1500
+                },
+                "cluster": {
+                    "description": "Omitted matches any",
                     "type": "string",
-                    "example": "menu001"
+                    "example": "Cluster Melati"
+                },
+                "house_type": {
+                    "description": "Omitted matches any",
+                    "type": "string",
+                    "example": "72"
                 },
                 "is_active": {
+                    "description": "Defaults to true",
                     "type": "boolean",
                     "example": true
                 },
-                "kode_menu": {
-                    "type": "string",
-                    "example": "DASHBOARD"
+                "max_land_area": {
+                    "description": "m², inclusive",
+                    "type": "integer",
+                    "example": 200
                 },
-                "locale": {
+                "min_land_area": {
+                    "description": "m², inclusive",
+                    "type": "integer",
+                    "example": 100
+                },
+                "name": {
                     "type": "string",
-                    "example": "id"
+                    "example": "Keamanan tipe 72"
                 },
-                "nama_menu": {
+                "pricing_type": {
+                    "description": "flat, per_m2 or tiered",
                     "type": "string",
-                    "example": "Dashboard"
+                    "example": "per_m2"
                 },
-                "urutan_menu": {
+                "priority": {
+                    "description": "Lower is tried first",
                     "type": "integer",
-                    "example": 1
-                }
-            }
-        },
-        "service.CreateRoleMenuRequest": {
-            "type": "object"
-        },
-        "service.PaymentLinkResponse": {
-            "type": "object",
-            "properties": {
-                "amount": {
-                    "type": "integer"
+                    "example": 10
                 },
-                "billing_id": {
-                    "type": "integer"
+                "setting_billing_id": {
+                    "type": "integer",
+                    "example": 1
                 },
-                "billing_ids": {
+                "tiers": {
+                    "description": "Land area brackets of a tiered tariff, the last open",
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/models.TariffTier"
                     }
-                },
-                "description": {
-                    "type": "string"
-                },
-                "payment_url": {
-                    "type": "string"
                 }
             }
         },
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +15,8 @@ type Config struct {
 	Database DatabaseConfig
 	Logger   LoggerConfig
 	Doku     DokuConfig
+	Payment  PaymentConfig
+	Billing  BillingConfig
 	JWT      JWTConfig
 	CORS     CORSConfig
 }
@@ -42,9 +45,46 @@ type LoggerConfig struct {
 
 // DokuConfig holds DOKU payment configuration
 type DokuConfig struct {
-	ClientID  string
-	SecretKey string
-	BaseURL   string
+	ClientID          string
+	SecretKey         string
+	BaseURL           string
+	CallbackURL       string // Where DOKU redirects the resident after paying
+	CallbackURLCancel string // Where DOKU redirects the resident after cancelling
+	PaymentDueMinutes int
+
+	// PaymentMethodTypes are the DOKU payment method types a checkout offers by default; empty
+	// offers every method enabled for the merchant and fails checkouts while a fee rule is active
+	PaymentMethodTypes []string
+
+	RequestTimeoutSeconds  int // Timeout of a single DOKU API request
+	MaxRetries             int // Retries of status queries that time out or get a 5xx response
+	BreakerFailures        int // Consecutive failed calls after which DOKU calls fail fast
+	BreakerCooldownSeconds int // How long calls fail fast before DOKU is tried again
+}
+
+// PaymentConfig holds payment gateway selection and background reconciliation settings
+type PaymentConfig struct {
+	Gateway                  string // "doku" or "fake"
+	ReconcilerEnabled        bool
+	ReconcileIntervalMinutes int
+	ReconcileMinAgeMinutes   int    // Pending transactions younger than this are left to notifications
+	ReceiptDir               string // Where receipt files of offline payments are stored
+	ReceiptSigningKey        string // Signs the verification codes printed on PDF receipts; required
+}
+
+// BillingConfig holds scheduled billing generation and late fee (denda) settings
+type BillingConfig struct {
+	SchedulerEnabled    bool
+	SchedulerCron       string // When the scheduler checks for due runs, a cron expression in WIB
+	SchedulerDayOfMonth int    // Day of the month from which the month's billings are generated
+	Proration           string // How the first and last month of an occupancy are charged: "none", "daily" or "half_month"
+
+	DueDay         int    // Day of its month a billing is due; denda is charged from the day after
+	LateFeeEnabled bool   // Whether the daily late fee job runs
+	LateFeeCron    string // When the late fee job runs, a cron expression in WIB
+	LateFeeType    string // "flat" or "percentage"
+	LateFeeAmount  int    // Rupiah per month overdue for flat, basis points of the nominal for percentage
+	LateFeeCap     int    // Most denda a billing is charged in total, 0 for no cap
 }
 
 // JWTConfig holds JWT configuration
@@ -62,7 +102,7 @@ func Load() (*Config, error) {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
 		// It's okay if .env file doesn't exist
-		fmt.Println("No .env file found, using environment variables")
+		fmt.Fprintln(os.Stderr, "No .env file found, using environment variables")
 	}
 
 	config := &Config{
@@ -83,9 +123,40 @@ func Load() (*Config, error) {
 			Format: getEnv("LOG_FORMAT", "json"),
 		},
 		Doku: DokuConfig{
-			ClientID:  getEnv("DOKU_CLIENT_ID", "BRN-0241-1762176502792"),
-			SecretKey: getEnv("DOKU_SECRET_KEY", "SK-PaILsZudZTytTSTNCmUV"),
-			BaseURL:   getEnv("DOKU_BASE_URL", "https://api-sandbox.doku.com"),
+			ClientID:          getEnv("DOKU_CLIENT_ID", "BRN-0241-1762176502792"),
+			SecretKey:         getEnv("DOKU_SECRET_KEY", "SK-PaILsZudZTytTSTNCmUV"),
+			BaseURL:           getEnv("DOKU_BASE_URL", "https://api-sandbox.doku.com"),
+			CallbackURL:       getEnv("DOKU_CALLBACK_URL", "http://localhost:3000/payments/finish"),
+			CallbackURLCancel: getEnv("DOKU_CALLBACK_URL_CANCEL", "http://localhost:3000/payments/cancel"),
+			PaymentDueMinutes: getEnvAsInt("DOKU_PAYMENT_DUE_MINUTES", 60),
+
+			PaymentMethodTypes: getEnvAsList("DOKU_PAYMENT_METHOD_TYPES"),
+
+			RequestTimeoutSeconds:  getEnvAsPositiveInt("DOKU_REQUEST_TIMEOUT_SECONDS", 10),
+			MaxRetries:             getEnvAsInt("DOKU_MAX_RETRIES", 2),
+			BreakerFailures:        getEnvAsPositiveInt("DOKU_BREAKER_FAILURES", 5),
+			BreakerCooldownSeconds: getEnvAsPositiveInt("DOKU_BREAKER_COOLDOWN_SECONDS", 30),
+		},
+		Payment: PaymentConfig{
+			Gateway:                  getEnv("PAYMENT_GATEWAY", "doku"),
+			ReconcilerEnabled:        getEnvAsBool("PAYMENT_RECONCILER_ENABLED", true),
+			ReconcileIntervalMinutes: getEnvAsPositiveInt("PAYMENT_RECONCILE_INTERVAL_MINUTES", 5),
+			ReconcileMinAgeMinutes:   getEnvAsInt("PAYMENT_RECONCILE_MIN_AGE_MINUTES", 15),
+			ReceiptDir:               getEnv("PAYMENT_RECEIPT_DIR", "uploads/receipts"),
+			ReceiptSigningKey:        getEnv("PAYMENT_RECEIPT_SIGNING_KEY", ""),
+		},
+		Billing: BillingConfig{
+			SchedulerEnabled:    getEnvAsBool("BILLING_SCHEDULER_ENABLED", false),
+			SchedulerCron:       getEnv("BILLING_SCHEDULER_CRON", "0 1 * * *"),
+			SchedulerDayOfMonth: getEnvAsPositiveInt("BILLING_SCHEDULER_DAY_OF_MONTH", 1),
+			Proration:           getEnv("BILLING_PRORATION", "none"),
+
+			DueDay:         getEnvAsPositiveInt("BILLING_DUE_DAY", 10),
+			LateFeeEnabled: getEnvAsBool("BILLING_LATE_FEE_ENABLED", false),
+			LateFeeCron:    getEnv("BILLING_LATE_FEE_CRON", "0 2 * * *"),
+			LateFeeType:    getEnv("BILLING_LATE_FEE_TYPE", "flat"),
+			LateFeeAmount:  getEnvAsInt("BILLING_LATE_FEE_AMOUNT", 0),
+			LateFeeCap:     getEnvAsInt("BILLING_LATE_FEE_CAP", 0),
 		},
 		JWT: JWTConfig{
 			Secret: getEnv("JWT_SECRET", "your-secret-key"),
@@ -123,3 +194,33 @@ func getEnvAsInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+// getEnvAsPositiveInt gets an environment variable as integer, using the fallback value when it is
+// missing, invalid or not greater than zero
+func getEnvAsPositiveInt(key string, fallback int) int {
+	if value := getEnvAsInt(key, fallback); value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// getEnvAsList gets a comma separated environment variable as a list, skipping empty entries
+func getEnvAsList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvAsBool gets an environment variable as boolean with a fallback value
+func getEnvAsBool(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,17 @@
+package config
+
+import "testing"
+
+func TestLoad_NonPositiveReconcileIntervalFallsBack(t *testing.T) {
+	for _, value := range []string{"0", "-5", "soon"} {
+		t.Setenv("PAYMENT_RECONCILE_INTERVAL_MINUTES", value)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Payment.ReconcileIntervalMinutes != 5 {
+			t.Errorf("interval for %q = %d, want 5", value, cfg.Payment.ReconcileIntervalMinutes)
+		}
+	}
+}
@@ -44,6 +44,24 @@ func NewDatabase(cfg *config.DatabaseConfig) (*Database, error) {
 func (d *Database) AutoMigrate() error {
 	return d.DB.AutoMigrate(
 		&models.MasterMenu{},
+		&models.PaymentTransaction{},
+		&models.PaymentTransactionBillingLink{},
+		&models.PaymentRefund{},
+		&models.PaymentRefundBilling{},
+		&models.InstallmentPlan{},
+		&models.InstallmentPart{},
+		&models.InstallmentPlanBillingLink{},
+		&models.PaymentFeeRule{},
+		&models.ResidentUnit{},
+		&models.BillingSource{},
+		&models.BillingRun{},
+		&models.BillingDetail{},
+		&models.BillingPenalty{},
+		&models.BillingAdjustment{},
+		&models.BillingDiscountRule{},
+		&models.SettingBillingTariff{},
+		&models.SettingBillingSchedule{},
+		&models.BillingCharge{},
 		// Add more models here as needed
 	)
 }
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAdjustmentRequest represents the request body for an adjustment of a billing
+type CreateAdjustmentRequest struct {
+	Type   string `json:"type" binding:"required" example:"discount"`                   // discount, waiver, surcharge or correction
+	Amount int64  `json:"amount" example:"25000"`                                       // Size of the change; signed for corrections; omitted waives the whole nominal
+	Reason string `json:"reason" binding:"required" example:"Rumah kosong bulan Maret"` // Why the billing is adjusted
+}
+
+// ReviewAdjustmentRequest represents the request body for approving or rejecting an adjustment
+type ReviewAdjustmentRequest struct {
+	Note string `json:"note" example:"Sesuai keputusan rapat RT"`
+}
+
+// AdjustmentHandler handles billing adjustment HTTP requests
+type AdjustmentHandler struct {
+	adjustmentService service.AdjustmentService
+	logger            *logger.Logger
+}
+
+// NewAdjustmentHandler creates a new AdjustmentHandler instance
+func NewAdjustmentHandler(adjustmentService service.AdjustmentService, logger *logger.Logger) *AdjustmentHandler {
+	return &AdjustmentHandler{
+		adjustmentService: adjustmentService,
+		logger:            logger,
+	}
+}
+
+// CreateAdjustment requests an adjustment of a billing
+// @Summary Create billing adjustment
+// @Description Request a discount, waiver, surcharge or correction of a billing. It stays pending, without changing the amount due, until another admin approves it. The admin is read from the bearer token.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Billing ID"
+// @Param request body CreateAdjustmentRequest true "Adjustment"
+// @Success 201 {object} utils.APIResponse{data=models.BillingAdjustment} "Adjustment requested"
+// @Failure 400 {object} utils.APIResponse "Invalid adjustment"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/{id}/adjustments [post]
+func (h *AdjustmentHandler) CreateAdjustment(c *gin.Context) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	billingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid billing ID", err)
+		return
+	}
+
+	var request CreateAdjustmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "type and reason are required", err)
+		return
+	}
+
+	adjustment, err := h.adjustmentService.CreateAdjustment(&service.CreateAdjustmentRequest{
+		BillingID:   uint(billingID),
+		Type:        request.Type,
+		Amount:      request.Amount,
+		Reason:      request.Reason,
+		CreatedByID: adminID,
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to create billing adjustment")
+		h.respondError(c, err, "Failed to create billing adjustment")
+		return
+	}
+
+	utils.CreatedResponse(c, "Adjustment requested", adjustment)
+}
+
+// GetBillingAdjustments returns the adjustments of a billing
+// @Summary Get billing adjustments
+// @Description Get every adjustment of a billing, pending and reviewed, and the nominal the approved ones leave
+// @Tags billings
+// @Produce json
+// @Param id path int true "Billing ID"
+// @Success 200 {object} utils.APIResponse{data=service.BillingAdjustments} "Adjustments retrieved"
+// @Failure 400 {object} utils.APIResponse "Invalid billing ID"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/{id}/adjustments [get]
+func (h *AdjustmentHandler) GetBillingAdjustments(c *gin.Context) {
+	billingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid billing ID", err)
+		return
+	}
+
+	adjustments, err := h.adjustmentService.GetBillingAdjustments(uint(billingID))
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to get billing adjustments")
+		h.respondError(c, err, "Failed to get billing adjustments")
+		return
+	}
+
+	utils.SuccessResponse(c, "Adjustments retrieved", adjustments)
+}
+
+// ApproveAdjustment approves a pending adjustment
+// @Summary Approve billing adjustment
+// @Description Apply a pending adjustment to its billing and update the billing status to the new amount due. The approving admin, read from the bearer token, must not be the one who requested it. Refused while the billing's payments exceed the adjusted amount.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param adjustment_id path int true "Adjustment ID"
+// @Param request body ReviewAdjustmentRequest false "Review note"
+// @Success 200 {object} utils.APIResponse{data=models.BillingAdjustment} "Adjustment approved"
+// @Failure 400 {object} utils.APIResponse "Invalid adjustment"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 403 {object} utils.APIResponse "Adjustment cannot be approved by its creator"
+// @Failure 404 {object} utils.APIResponse "Adjustment not found"
+// @Failure 409 {object} utils.APIResponse "Adjustment is not pending, the billing has a pending checkout, or payments exceed the adjusted amount"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/adjustments/{adjustment_id}/approve [post]
+func (h *AdjustmentHandler) ApproveAdjustment(c *gin.Context) {
+	h.review(c, true)
+}
+
+// RejectAdjustment rejects a pending adjustment
+// @Summary Reject billing adjustment
+// @Description Close a pending adjustment without changing its billing. The admin is read from the bearer token.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param adjustment_id path int true "Adjustment ID"
+// @Param request body ReviewAdjustmentRequest false "Review note"
+// @Success 200 {object} utils.APIResponse{data=models.BillingAdjustment} "Adjustment rejected"
+// @Failure 400 {object} utils.APIResponse "Invalid adjustment ID"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 404 {object} utils.APIResponse "Adjustment not found"
+// @Failure 409 {object} utils.APIResponse "Adjustment is not pending"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/adjustments/{adjustment_id}/reject [post]
+func (h *AdjustmentHandler) RejectAdjustment(c *gin.Context) {
+	h.review(c, false)
+}
+
+// review approves or rejects the adjustment of the request
+func (h *AdjustmentHandler) review(c *gin.Context, approve bool) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("adjustment_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid adjustment ID", err)
+		return
+	}
+
+	// The note is optional, so an empty body is accepted
+	var request ReviewAdjustmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.BadRequestResponse(c, "Invalid request body", err)
+			return
+		}
+	}
+
+	if approve {
+		adjustment, err := h.adjustmentService.ApproveAdjustment(uint(id), adminID, request.Note)
+		if err != nil {
+			h.logger.WithError(err).WithField("adjustment_id", id).Error("Failed to approve billing adjustment")
+			h.respondError(c, err, "Failed to approve billing adjustment")
+			return
+		}
+		utils.SuccessResponse(c, "Adjustment approved", adjustment)
+		return
+	}
+
+	adjustment, err := h.adjustmentService.RejectAdjustment(uint(id), adminID, request.Note)
+	if err != nil {
+		h.logger.WithError(err).WithField("adjustment_id", id).Error("Failed to reject billing adjustment")
+		h.respondError(c, err, "Failed to reject billing adjustment")
+		return
+	}
+	utils.SuccessResponse(c, "Adjustment rejected", adjustment)
+}
+
+// respondError writes the response for an adjustment service error
+func (h *AdjustmentHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBillingAdjustment):
+		utils.BadRequestResponse(c, "Invalid adjustment", err)
+	case err.Error() == "billing not found", errors.Is(err, service.ErrBillingNotFound):
+		utils.NotFoundResponse(c, "Billing not found")
+	case err.Error() == "adjustment not found":
+		utils.NotFoundResponse(c, "Adjustment not found")
+	case err.Error() == "adjustment cannot be approved by its creator":
+		utils.ForbiddenResponse(c, "Adjustment cannot be approved by its creator")
+	case err.Error() == "adjustment is not pending":
+		utils.ConflictResponse(c, "Adjustment is not pending", err)
+	case err.Error() == "billing payments exceed the adjusted amount":
+		utils.ConflictResponse(c, "Billing payments exceed the adjusted amount", err)
+	case errors.Is(err, service.ErrPendingCheckout):
+		utils.ConflictResponse(c, "Billing has a pending checkout", err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"ipl-be-svc/internal/service"
 	"ipl-be-svc/pkg/logger"
 	"ipl-be-svc/pkg/utils"
@@ -13,6 +15,24 @@ type BulkBillingRequest struct {
 	UserIDs []uint `json:"user_ids,omitempty"`                        // Empty means all penghuni users
 	Month   int    `json:"month" binding:"required,min=1,max=12"`     // Month 1-12
 	Year    int    `json:"year" binding:"required,min=2020,max=2100"` // Reasonable year range
+
+	// Existing decides what happens to billings that already exist for a user, period and
+	// setting: "skip" (default) or "replace_unpaid" to update unpaid ones to the current nominal
+	Existing string `json:"existing,omitempty" example:"skip"`
+
+	// DryRun previews the billings per user, with amounts and totals, without creating them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// OneOffBillingRequest represents the request for billing a one-off charge to residents
+type OneOffBillingRequest struct {
+	UserIDs     []uint `json:"user_ids" binding:"required,min=1" example:"10,11"`
+	NamaBilling string `json:"nama_billing" binding:"required" example:"Iuran 17 Agustus"`
+	Nominal     int64  `json:"nominal" binding:"required,min=1" example:"75000"`
+	Month       int    `json:"month" binding:"required,min=1,max=12"`
+	Year        int    `json:"year" binding:"required,min=2020,max=2100"`
+	DueDate     string `json:"due_date,omitempty" example:"2026-08-10"` // YYYY-MM-DD; defaults to the billing due day of the month
+	Keterangan  string `json:"keterangan,omitempty" example:"Lomba dan tasyakuran RT"`
 }
 
 // BulkBillingHandler handles bulk billing-related HTTP requests
@@ -31,7 +51,7 @@ func NewBulkBillingHandler(billingService service.BillingService, logger *logger
 
 // CreateBulkMonthlyBillings creates monthly billings for specified users or all penghuni users
 // @Summary Create bulk monthly billings
-// @Description Create monthly billings for specified user IDs or all penghuni users if user_ids is empty. Requires auth-token cookie.
+// @Description Create monthly billings for specified user IDs or all penghuni users if user_ids is empty, one per monthly (bulanan) setting billing and per yearly (tahunan) setting scheduled for the month. Generation is idempotent: billings that already exist for a user, month, year and setting are skipped, or updated to their current nominal while unpaid with existing=replace_unpaid. Each billing is priced by the first tariff of its setting matching the resident's unit (house type, cluster, land area), or the setting nominal when none does. Residents are billed only for the months their unit is occupied; the first and last are prorated by the billing proration policy (yearly settings are not), and each resident's discount rules are applied. The response lists per user each setting billing with its amount, tariff, proration and discount and whether it is created, replaced or skipped, plus totals and the users skipped for having no profile. With dry_run=true nothing is written and the response previews the run. Requires auth-token cookie.
 // @Tags billings
 // @Accept json
 // @Produce json
@@ -51,34 +71,94 @@ func (h *BulkBillingHandler) CreateBulkMonthlyBillings(c *gin.Context) {
 
 	var response *service.BulkBillingResponse
 	var serviceErr error
+	opts := service.BulkBillingOptions{Existing: req.Existing, DryRun: req.DryRun}
 
 	if len(req.UserIDs) > 0 {
 		// Create for specific users
-		response, serviceErr = h.billingService.CreateBulkMonthlyBillings(req.UserIDs, req.Month, req.Year)
+		response, serviceErr = h.billingService.CreateBulkMonthlyBillings(req.UserIDs, req.Month, req.Year, opts)
 	} else {
 		// Create for all penghuni users
-		response, serviceErr = h.billingService.CreateBulkMonthlyBillingsForAllUsers(req.Month, req.Year)
+		response, serviceErr = h.billingService.CreateBulkMonthlyBillingsForAllUsers(req.Month, req.Year, opts)
 	}
 
 	if serviceErr != nil {
 		h.logger.WithError(serviceErr).Error("Failed to create bulk billings")
+		if errors.Is(serviceErr, service.ErrInvalidBulkBilling) {
+			utils.BadRequestResponse(c, "Invalid bulk billing request", serviceErr)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to create billings", serviceErr)
 		return
 	}
 
+	if response.DryRun {
+		utils.SuccessResponse(c, "Bulk billing preview generated", response)
+		return
+	}
+
 	h.logger.WithFields(map[string]interface{}{
 		"total_users":    response.TotalUsers,
 		"total_billings": response.TotalBillings,
 		"success_count":  response.SuccessCount,
 		"failed_count":   response.FailedCount,
+		"skipped_count":  response.SkippedCount,
 	}).Info("Bulk billings created successfully")
 
 	utils.SuccessResponse(c, "Bulk billings created successfully", response)
 }
 
+// CreateOneOffBillings bills a one-off charge to residents
+// @Summary Create one-off billings
+// @Description Bill a one-off charge, such as a 17-Agustusan contribution, to the given users: one unpaid billing each for the month and year, due on due_date or the billing due day of the month. Tariffs, proration and discount rules do not apply. Users without a resident profile are skipped and listed. The admin is read from the bearer token.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body OneOffBillingRequest true "One-off charge"
+// @Success 201 {object} utils.APIResponse{data=service.OneOffBillingResponse} "One-off billings created"
+// @Failure 400 {object} utils.APIResponse "Invalid request"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/one-off [post]
+func (h *BulkBillingHandler) CreateOneOffBillings(c *gin.Context) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	var req OneOffBillingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "user_ids, nama_billing, nominal, month and year are required", err)
+		return
+	}
+
+	response, err := h.billingService.CreateOneOffBillings(&service.CreateOneOffBillingRequest{
+		UserIDs:     req.UserIDs,
+		NamaBilling: req.NamaBilling,
+		Nominal:     req.Nominal,
+		Month:       req.Month,
+		Year:        req.Year,
+		DueDate:     req.DueDate,
+		Keterangan:  req.Keterangan,
+		CreatedByID: adminID,
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create one-off billings")
+		if errors.Is(err, service.ErrInvalidOneOffBilling) {
+			utils.BadRequestResponse(c, "Invalid one-off billing request", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to create one-off billings", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "One-off billings created", response)
+}
+
 // GetBillingPenghuni retrieves all billing data for penghuni users
 // @Summary Get billing penghuni list with summed nominals
-// @Description Get all billing data for penghuni users with complete information including profile, role, and billing status. Nominal amounts are summed per user per billing period (month/year).
+// @Description Get all billing data for penghuni users with complete information including profile, role, and billing status. Nominal amounts are summed per user per billing period (month/year); components lists the billings of the period, one per setting billing (e.g. Keamanan, Kebersihan), with their own nominal and status.
 // @Tags billings
 // @Accept json
 // @Produce json
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateBillingRequest represents the request body for correcting a billing; omitted fields are
+// left unchanged
+type UpdateBillingRequest struct {
+	Nominal *int64  `json:"nominal" example:"125000"`
+	DueDate *string `json:"due_date" example:"2026-03-15"` // YYYY-MM-DD
+	Notes   *string `json:"notes" example:"Nominal dikoreksi sesuai tipe rumah"`
+}
+
+// CancelBillingRequest represents the request body for cancelling a billing
+type CancelBillingRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Tagihan ganda"`
+}
+
+// BillingManagementHandler handles billing listing, detail, update and cancel HTTP requests
+type BillingManagementHandler struct {
+	billingManagementService service.BillingManagementService
+	logger                   *logger.Logger
+}
+
+// NewBillingManagementHandler creates a new BillingManagementHandler instance
+func NewBillingManagementHandler(billingManagementService service.BillingManagementService, logger *logger.Logger) *BillingManagementHandler {
+	return &BillingManagementHandler{
+		billingManagementService: billingManagementService,
+		logger:                   logger,
+	}
+}
+
+// ListBillings returns a page of billings
+// @Summary List billings
+// @Description List the published billings, or with cancelled=true the cancelled ones, with their resident, status, kategori transaksi and due date. Filters combine; date ranges are YYYY-MM-DD and inclusive.
+// @Tags billings
+// @Produce json
+// @Param user_id query int false "Resident user ID"
+// @Param month query int false "Billing month 1-12"
+// @Param year query int false "Billing year"
+// @Param status query string false "Status name, e.g. Belum Dibayar"
+// @Param category_id query int false "Kategori transaksi ID"
+// @Param created_from query string false "Created on or after"
+// @Param created_to query string false "Created on or before"
+// @Param due_from query string false "Due on or after"
+// @Param due_to query string false "Due on or before"
+// @Param cancelled query bool false "List cancelled billings"
+// @Param sort query string false "created_at, due_date, nominal or period" default(created_at)
+// @Param order query string false "asc or desc" default(desc)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} utils.PaginatedResponse{data=[]models.BillingRecord} "Billings retrieved"
+// @Failure 400 {object} utils.APIResponse "Invalid filter"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings [get]
+func (h *BillingManagementHandler) ListBillings(c *gin.Context) {
+	page, limit := utils.GetPaginationParams(c)
+
+	request := &service.ListBillingsRequest{
+		Status:      c.Query("status"),
+		CreatedFrom: c.Query("created_from"),
+		CreatedTo:   c.Query("created_to"),
+		DueFrom:     c.Query("due_from"),
+		DueTo:       c.Query("due_to"),
+		Sort:        c.Query("sort"),
+		Order:       c.Query("order"),
+		Limit:       limit,
+		Offset:      (page - 1) * limit,
+	}
+
+	var err error
+	if request.UserID, err = uintQuery(c, "user_id"); err == nil {
+		if request.CategoryID, err = uintQuery(c, "category_id"); err == nil {
+			if request.Month, err = intQuery(c, "month"); err == nil {
+				request.Year, err = intQuery(c, "year")
+			}
+		}
+	}
+	if err == nil && c.Query("cancelled") != "" {
+		request.Cancelled, err = strconv.ParseBool(c.Query("cancelled"))
+	}
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid filter", err)
+		return
+	}
+
+	billings, total, err := h.billingManagementService.ListBillings(request)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list billings")
+		h.respondError(c, err, "Failed to get billings")
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Billings retrieved", billings, page, limit, total)
+}
+
+// GetBilling returns a billing
+// @Summary Get billing
+// @Description Get a billing with its resident, status, kategori transaksi, source and due date and, while it is published, its adjustments, late fees and payments. Cancelled billings are returned with who cancelled them and why.
+// @Tags billings
+// @Produce json
+// @Param id path int true "Billing ID"
+// @Success 200 {object} utils.APIResponse{data=service.BillingRecordDetail} "Billing retrieved"
+// @Failure 400 {object} utils.APIResponse "Invalid billing ID"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/{id} [get]
+func (h *BillingManagementHandler) GetBilling(c *gin.Context) {
+	id, err := utils.GetIDParam(c)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid billing ID", err)
+		return
+	}
+
+	billing, err := h.billingManagementService.GetBilling(id)
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_id", id).Error("Failed to get billing")
+		h.respondError(c, err, "Failed to get billing")
+		return
+	}
+
+	utils.SuccessResponse(c, "Billing retrieved", billing)
+}
+
+// UpdateBilling corrects a billing
+// @Summary Update billing
+// @Description Correct the nominal, due date or notes of a published billing. A new nominal must still cover what is paid, and the billing status follows it. Late fees already assessed are kept. The admin is read from the bearer token.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Billing ID"
+// @Param request body UpdateBillingRequest true "Changes"
+// @Success 200 {object} utils.APIResponse{data=service.BillingRecordDetail} "Billing updated"
+// @Failure 400 {object} utils.APIResponse "Invalid update"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 409 {object} utils.APIResponse "Billing is cancelled, has a pending checkout or its payments exceed the nominal"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/{id} [put]
+func (h *BillingManagementHandler) UpdateBilling(c *gin.Context) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	id, err := utils.GetIDParam(c)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid billing ID", err)
+		return
+	}
+
+	var request UpdateBillingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "Request body must be valid JSON", err)
+		return
+	}
+
+	billing, err := h.billingManagementService.UpdateBilling(id, &service.UpdateBillingRequest{
+		Nominal:     request.Nominal,
+		DueDate:     request.DueDate,
+		Notes:       request.Notes,
+		UpdatedByID: adminID,
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_id", id).Error("Failed to update billing")
+		h.respondError(c, err, "Failed to update billing")
+		return
+	}
+
+	utils.SuccessResponse(c, "Billing updated", billing)
+}
+
+// CancelBilling cancels a billing
+// @Summary Cancel billing
+// @Description Cancel a published billing without payments by unpublishing it. It leaves every listing, payment and late fee assessment, and bulk generation does not create it again. The admin is read from the bearer token.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Billing ID"
+// @Param request body CancelBillingRequest true "Cancellation"
+// @Success 200 {object} utils.APIResponse{data=service.BillingRecordDetail} "Billing cancelled"
+// @Failure 400 {object} utils.APIResponse "Invalid cancellation"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 409 {object} utils.APIResponse "Billing is already cancelled, has payments or has a pending checkout"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/{id}/cancel [post]
+func (h *BillingManagementHandler) CancelBilling(c *gin.Context) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	id, err := utils.GetIDParam(c)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid billing ID", err)
+		return
+	}
+
+	var request CancelBillingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "reason is required", err)
+		return
+	}
+
+	billing, err := h.billingManagementService.CancelBilling(id, &service.CancelBillingRequest{
+		Reason:        request.Reason,
+		CancelledByID: adminID,
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_id", id).Error("Failed to cancel billing")
+		h.respondError(c, err, "Failed to cancel billing")
+		return
+	}
+
+	utils.SuccessResponse(c, "Billing cancelled", billing)
+}
+
+// respondError writes the response for a billing management service error
+func (h *BillingManagementHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBillingUpdate):
+		utils.BadRequestResponse(c, "Invalid billing request", err)
+	case err.Error() == "billing not found", errors.Is(err, service.ErrBillingNotFound):
+		utils.NotFoundResponse(c, "Billing not found")
+	case err.Error() == "billing is cancelled":
+		utils.ConflictResponse(c, "Billing is cancelled", err)
+	case err.Error() == "billing has payments":
+		utils.ConflictResponse(c, "Billing has payments", err)
+	case err.Error() == "billing payments exceed the updated nominal":
+		utils.ConflictResponse(c, "Billing payments exceed the updated nominal", err)
+	case errors.Is(err, service.ErrPendingCheckout):
+		utils.ConflictResponse(c, "Billing has a pending checkout", err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
+
+// uintQuery parses an optional unsigned integer query parameter
+func uintQuery(c *gin.Context, name string) (*uint, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a positive integer", name)
+	}
+	result := uint(parsed)
+	return &result, nil
+}
+
+// intQuery parses an optional integer query parameter
+func intQuery(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", name)
+	}
+	return &parsed, nil
+}
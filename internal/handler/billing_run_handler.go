@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BillingRunHandler handles scheduled billing run HTTP requests
+type BillingRunHandler struct {
+	billingScheduler service.BillingScheduler
+	logger           *logger.Logger
+}
+
+// NewBillingRunHandler creates a new BillingRunHandler instance
+func NewBillingRunHandler(billingScheduler service.BillingScheduler, logger *logger.Logger) *BillingRunHandler {
+	return &BillingRunHandler{
+		billingScheduler: billingScheduler,
+		logger:           logger,
+	}
+}
+
+// GetBillingRuns returns the history of scheduled billing generation runs
+// @Summary Get scheduled billing runs
+// @Description Get the runs of the monthly billing scheduler (kind bulanan) and the late fee job (kind denda), newest first, with when each started and finished, its status and how many billings or penalties it created, replaced, skipped or failed to create
+// @Tags billings
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} utils.PaginatedResponse{data=[]models.BillingRun} "Billing runs retrieved"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/runs [get]
+func (h *BillingRunHandler) GetBillingRuns(c *gin.Context) {
+	page, limit := utils.GetPaginationParams(c)
+
+	runs, total, err := h.billingScheduler.GetRuns(limit, (page-1)*limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get billing runs")
+		utils.InternalServerErrorResponse(c, "Failed to get billing runs", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Billing runs retrieved", runs, page, limit, total)
+}
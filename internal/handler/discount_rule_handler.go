@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiscountRuleHandler handles billing discount rule HTTP requests
+type DiscountRuleHandler struct {
+	discountRuleService service.DiscountRuleService
+	logger              *logger.Logger
+}
+
+// NewDiscountRuleHandler creates a new DiscountRuleHandler instance
+func NewDiscountRuleHandler(discountRuleService service.DiscountRuleService, logger *logger.Logger) *DiscountRuleHandler {
+	return &DiscountRuleHandler{
+		discountRuleService: discountRuleService,
+		logger:              logger,
+	}
+}
+
+// CreateDiscountRule creates a recurring discount of a resident
+// @Summary Create discount rule
+// @Description Create a recurring discount of a resident, e.g. 50% while a house is empty or an exemption for RT officials. Flat discounts are in rupiah; percentage discounts are in basis points (10000 exempts). Billings generated in its months, for its setting billing or all of them, get it as an approved adjustment. The admin, read from the bearer token, is its approver.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.DiscountRuleRequest true "Discount rule"
+// @Success 201 {object} utils.APIResponse{data=models.BillingDiscountRule} "Discount rule created"
+// @Failure 400 {object} utils.APIResponse "Invalid discount rule"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/discount-rules [post]
+func (h *DiscountRuleHandler) CreateDiscountRule(c *gin.Context) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	var request service.DiscountRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "name, user_id, discount_type and value are required", err)
+		return
+	}
+
+	rule, err := h.discountRuleService.CreateDiscountRule(&request, adminID)
+	if err != nil {
+		h.logger.WithError(err).WithField("user_id", request.UserID).Error("Failed to create discount rule")
+		h.respondError(c, err, "Failed to create discount rule")
+		return
+	}
+
+	utils.CreatedResponse(c, "Discount rule created", rule)
+}
+
+// GetDiscountRules returns the discount rules
+// @Summary Get discount rules
+// @Description Get the discount rules of every resident, or of one
+// @Tags billings
+// @Produce json
+// @Param user_id query int false "Resident user ID"
+// @Success 200 {object} utils.APIResponse{data=[]models.BillingDiscountRule} "Discount rules retrieved"
+// @Failure 400 {object} utils.APIResponse "Invalid user ID"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/discount-rules [get]
+func (h *DiscountRuleHandler) GetDiscountRules(c *gin.Context) {
+	var userID *uint
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid user ID", err)
+			return
+		}
+		uid := uint(id)
+		userID = &uid
+	}
+
+	rules, err := h.discountRuleService.GetDiscountRules(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get discount rules")
+		utils.InternalServerErrorResponse(c, "Failed to get discount rules", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Discount rules retrieved", rules)
+}
+
+// UpdateDiscountRule replaces the settings of a discount rule
+// @Summary Update discount rule
+// @Description Replace the settings of a discount rule. Billings already generated keep the discount they were given.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Param id path int true "Discount rule ID"
+// @Param request body service.DiscountRuleRequest true "Discount rule"
+// @Success 200 {object} utils.APIResponse{data=models.BillingDiscountRule} "Discount rule updated"
+// @Failure 400 {object} utils.APIResponse "Invalid discount rule"
+// @Failure 404 {object} utils.APIResponse "Discount rule not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/discount-rules/{id} [put]
+func (h *DiscountRuleHandler) UpdateDiscountRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid discount rule ID", err)
+		return
+	}
+
+	var request service.DiscountRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "name, user_id, discount_type and value are required", err)
+		return
+	}
+
+	rule, err := h.discountRuleService.UpdateDiscountRule(uint(id), &request)
+	if err != nil {
+		h.logger.WithError(err).WithField("id", id).Error("Failed to update discount rule")
+		h.respondError(c, err, "Failed to update discount rule")
+		return
+	}
+
+	utils.SuccessResponse(c, "Discount rule updated", rule)
+}
+
+// DeleteDiscountRule deletes a discount rule
+// @Summary Delete discount rule
+// @Description Delete a discount rule; billings generated afterwards no longer get it
+// @Tags billings
+// @Produce json
+// @Param id path int true "Discount rule ID"
+// @Success 200 {object} utils.APIResponse "Discount rule deleted"
+// @Failure 400 {object} utils.APIResponse "Invalid discount rule ID"
+// @Failure 404 {object} utils.APIResponse "Discount rule not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/discount-rules/{id} [delete]
+func (h *DiscountRuleHandler) DeleteDiscountRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid discount rule ID", err)
+		return
+	}
+
+	if err := h.discountRuleService.DeleteDiscountRule(uint(id)); err != nil {
+		h.logger.WithError(err).WithField("id", id).Error("Failed to delete discount rule")
+		h.respondError(c, err, "Failed to delete discount rule")
+		return
+	}
+
+	utils.SuccessResponse(c, "Discount rule deleted", nil)
+}
+
+// respondError writes the response for a discount rule service error
+func (h *DiscountRuleHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDiscountRule):
+		utils.BadRequestResponse(c, "Invalid discount rule", err)
+	case err.Error() == "discount rule not found":
+		utils.NotFoundResponse(c, "Discount rule not found")
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
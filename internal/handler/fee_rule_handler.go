@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeeRuleHandler handles payment fee rule HTTP requests
+type FeeRuleHandler struct {
+	feeRuleService service.FeeRuleService
+	logger         *logger.Logger
+}
+
+// NewFeeRuleHandler creates a new FeeRuleHandler instance
+func NewFeeRuleHandler(feeRuleService service.FeeRuleService, logger *logger.Logger) *FeeRuleHandler {
+	return &FeeRuleHandler{
+		feeRuleService: feeRuleService,
+		logger:         logger,
+	}
+}
+
+// CreateFeeRule creates the fee rule of a payment method type
+// @Summary Create fee rule
+// @Description Create a convenience fee charged on checkouts limited to a DOKU payment method type. Flat fees are in rupiah; percentage fees are in basis points (250 = 2.5%) of the billing amounts, rounded up and optionally capped by max_fee. The fee is stored apart from the billing nominal.
+// @Tags fee-rules
+// @Accept json
+// @Produce json
+// @Param request body service.FeeRuleRequest true "Fee rule"
+// @Success 201 {object} utils.APIResponse{data=models.PaymentFeeRule} "Fee rule created"
+// @Failure 400 {object} utils.APIResponse "Invalid fee rule"
+// @Failure 409 {object} utils.APIResponse "Fee rule already exists for payment method type"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/fee-rules [post]
+func (h *FeeRuleHandler) CreateFeeRule(c *gin.Context) {
+	var request service.FeeRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "payment_method_type, fee_type and value are required", err)
+		return
+	}
+
+	rule, err := h.feeRuleService.CreateFeeRule(&request)
+	if err != nil {
+		h.logger.WithError(err).WithField("payment_method_type", request.PaymentMethodType).Error("Failed to create fee rule")
+		h.respondError(c, err, "Failed to create fee rule")
+		return
+	}
+
+	utils.CreatedResponse(c, "Fee rule created", rule)
+}
+
+// GetFeeRules returns every fee rule
+// @Summary Get fee rules
+// @Description Get the fee rules of every payment method type
+// @Tags fee-rules
+// @Produce json
+// @Success 200 {object} utils.APIResponse{data=[]models.PaymentFeeRule} "Fee rules retrieved"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/fee-rules [get]
+func (h *FeeRuleHandler) GetFeeRules(c *gin.Context) {
+	rules, err := h.feeRuleService.GetFeeRules()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get fee rules")
+		utils.InternalServerErrorResponse(c, "Failed to get fee rules", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Fee rules retrieved", rules)
+}
+
+// UpdateFeeRule replaces the settings of a fee rule
+// @Summary Update fee rule
+// @Description Replace the payment method type, fee and cap of a fee rule. Pending checkouts keep the fee they were created with.
+// @Tags fee-rules
+// @Accept json
+// @Produce json
+// @Param id path int true "Fee rule ID"
+// @Param request body service.FeeRuleRequest true "Fee rule"
+// @Success 200 {object} utils.APIResponse{data=models.PaymentFeeRule} "Fee rule updated"
+// @Failure 400 {object} utils.APIResponse "Invalid fee rule"
+// @Failure 404 {object} utils.APIResponse "Fee rule not found"
+// @Failure 409 {object} utils.APIResponse "Fee rule already exists for payment method type"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/fee-rules/{id} [put]
+func (h *FeeRuleHandler) UpdateFeeRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid fee rule ID", err)
+		return
+	}
+
+	var request service.FeeRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "payment_method_type, fee_type and value are required", err)
+		return
+	}
+
+	rule, err := h.feeRuleService.UpdateFeeRule(uint(id), &request)
+	if err != nil {
+		h.logger.WithError(err).WithField("id", id).Error("Failed to update fee rule")
+		h.respondError(c, err, "Failed to update fee rule")
+		return
+	}
+
+	utils.SuccessResponse(c, "Fee rule updated", rule)
+}
+
+// DeleteFeeRule deletes a fee rule
+// @Summary Delete fee rule
+// @Description Delete a fee rule; checkouts for its payment method type no longer charge a fee
+// @Tags fee-rules
+// @Produce json
+// @Param id path int true "Fee rule ID"
+// @Success 200 {object} utils.APIResponse "Fee rule deleted"
+// @Failure 400 {object} utils.APIResponse "Invalid fee rule ID"
+// @Failure 404 {object} utils.APIResponse "Fee rule not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/fee-rules/{id} [delete]
+func (h *FeeRuleHandler) DeleteFeeRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid fee rule ID", err)
+		return
+	}
+
+	if err := h.feeRuleService.DeleteFeeRule(uint(id)); err != nil {
+		h.logger.WithError(err).WithField("id", id).Error("Failed to delete fee rule")
+		h.respondError(c, err, "Failed to delete fee rule")
+		return
+	}
+
+	utils.SuccessResponse(c, "Fee rule deleted", nil)
+}
+
+// respondError writes the response for a fee rule service error
+func (h *FeeRuleHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidFeeRule):
+		utils.BadRequestResponse(c, "Invalid fee rule", err)
+	case err.Error() == "fee rule not found":
+		utils.NotFoundResponse(c, "Fee rule not found")
+	case err.Error() == "fee rule already exists for payment method type":
+		utils.ConflictResponse(c, "Fee rule already exists for payment method type", err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
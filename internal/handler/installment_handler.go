@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInstallmentPlanRequest represents the request body for creating an installment plan
+type CreateInstallmentPlanRequest struct {
+	BillingIDs   []uint `json:"billing_ids" binding:"required" example:"3,4,5"`         // Overdue billings of one resident
+	Parts        int    `json:"parts" binding:"required" example:"3"`                   // Number of monthly parts, 2 to 24
+	FirstDueDate string `json:"first_due_date" binding:"required" example:"2025-11-10"` // Due date of the first part (YYYY-MM-DD, WIB)
+	Notes        string `json:"notes" example:"Disepakati dengan bendahara RT"`         // Optional notes
+}
+
+// InstallmentHandler handles installment plan HTTP requests
+type InstallmentHandler struct {
+	installmentService service.InstallmentService
+	logger             *logger.Logger
+}
+
+// NewInstallmentHandler creates a new InstallmentHandler instance
+func NewInstallmentHandler(installmentService service.InstallmentService, logger *logger.Logger) *InstallmentHandler {
+	return &InstallmentHandler{
+		installmentService: installmentService,
+		logger:             logger,
+	}
+}
+
+// CreateInstallmentPlan splits a resident's overdue billings into installment parts
+// @Summary Create installment plan
+// @Description Split the outstanding balance of a resident's overdue billings into equal monthly parts, the remainder going to the last part. Each part is paid through its own payment link. A billing can only be in one active plan. The admin is read from the bearer token.
+// @Tags installments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateInstallmentPlanRequest true "Installment plan"
+// @Success 201 {object} utils.APIResponse{data=service.InstallmentPlanResponse} "Installment plan created"
+// @Failure 400 {object} utils.APIResponse "Invalid installment plan"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 409 {object} utils.APIResponse "Billing already paid or already in an installment plan"
+// @Failure 422 {object} utils.APIResponse "Billing is not linked to a resident with a published profile"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/installment-plans [post]
+func (h *InstallmentHandler) CreateInstallmentPlan(c *gin.Context) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	var request CreateInstallmentPlanRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "billing_ids, parts and first_due_date are required", err)
+		return
+	}
+
+	firstDueDate, err := service.ParseWIBDate(request.FirstDueDate)
+	if err != nil {
+		utils.BadRequestResponse(c, "first_due_date must be YYYY-MM-DD", err)
+		return
+	}
+
+	plan, err := h.installmentService.CreateInstallmentPlan(&service.InstallmentPlanRequest{
+		BillingIDs:   request.BillingIDs,
+		Parts:        request.Parts,
+		FirstDueDate: firstDueDate,
+		Notes:        request.Notes,
+		CreatedByID:  adminID,
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_ids", request.BillingIDs).Error("Failed to create installment plan")
+
+		switch {
+		case errors.Is(err, service.ErrInvalidInstallmentPlan):
+			utils.BadRequestResponse(c, "Invalid installment plan", err)
+		case errors.Is(err, service.ErrBillingOwnerNotFound):
+			utils.ErrorResponse(c, http.StatusUnprocessableEntity, "Billing has no resident", err)
+		case err.Error() == "billing already paid" || err.Error() == "billing already in an installment plan":
+			utils.ConflictResponse(c, err.Error(), err)
+		case errors.Is(err, service.ErrBillingNotFound) || errors.Is(err, service.ErrInvalidBillingNominal):
+			utils.NotFoundResponse(c, "Billing not found")
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to create installment plan", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Installment plan created", plan)
+}
+
+// GetInstallmentPlan returns an installment plan with its parts
+// @Summary Get installment plan
+// @Description Get an installment plan with its parts, their due dates and payment status, and its billings
+// @Tags installments
+// @Produce json
+// @Param id path int true "Installment plan ID"
+// @Success 200 {object} utils.APIResponse{data=service.InstallmentPlanResponse} "Installment plan retrieved"
+// @Failure 400 {object} utils.APIResponse "Invalid installment plan ID"
+// @Failure 404 {object} utils.APIResponse "Installment plan not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/installment-plans/{id} [get]
+func (h *InstallmentHandler) GetInstallmentPlan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid installment plan ID", err)
+		return
+	}
+
+	plan, err := h.installmentService.GetInstallmentPlan(uint(id))
+	if err != nil {
+		h.logger.WithError(err).WithField("plan_id", id).Error("Failed to get installment plan")
+
+		if err.Error() == "installment plan not found" {
+			utils.NotFoundResponse(c, "Installment plan not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get installment plan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Installment plan retrieved", plan)
+}
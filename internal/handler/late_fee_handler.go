@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WaiveLateFeesRequest represents the request body for waiving late fees of a billing
+type WaiveLateFeesRequest struct {
+	PenaltyIDs []uint `json:"penalty_ids" example:"3"`                                          // Omitted waives every unwaived penalty
+	Reason     string `json:"reason" binding:"required" example:"Keterlambatan karena bencana"` // Why the denda is waived
+}
+
+// LateFeeHandler handles late fee (denda) HTTP requests
+type LateFeeHandler struct {
+	lateFeeService service.LateFeeService
+	logger         *logger.Logger
+}
+
+// NewLateFeeHandler creates a new LateFeeHandler instance
+func NewLateFeeHandler(lateFeeService service.LateFeeService, logger *logger.Logger) *LateFeeHandler {
+	return &LateFeeHandler{
+		lateFeeService: lateFeeService,
+		logger:         logger,
+	}
+}
+
+// GetBillingLateFees returns the due date of a billing and its late fees
+// @Summary Get billing late fees
+// @Description Get the due date of a billing, the denda assessed on it for every month it was overdue, and the unwaived total
+// @Tags billings
+// @Produce json
+// @Param id path int true "Billing ID"
+// @Success 200 {object} utils.APIResponse{data=service.BillingLateFees} "Late fees retrieved"
+// @Failure 400 {object} utils.APIResponse "Invalid billing ID"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/{id}/late-fees [get]
+func (h *LateFeeHandler) GetBillingLateFees(c *gin.Context) {
+	billingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid billing ID", err)
+		return
+	}
+
+	lateFees, err := h.lateFeeService.GetBillingLateFees(uint(billingID))
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to get late fees")
+
+		if err.Error() == "billing not found" {
+			utils.NotFoundResponse(c, "Billing not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get late fees", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Late fees retrieved", lateFees)
+}
+
+// WaiveLateFees waives late fees of a billing
+// @Summary Waive billing late fees
+// @Description Waive the given penalties of a billing, or all of its unwaived penalties when none are given. The billing becomes paid when its payments already cover the rest. The admin is read from the bearer token.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Billing ID"
+// @Param request body WaiveLateFeesRequest true "Waiver"
+// @Success 200 {object} utils.APIResponse{data=service.BillingLateFees} "Late fees waived"
+// @Failure 400 {object} utils.APIResponse "Invalid waiver"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 409 {object} utils.APIResponse "Billing has no late fees to waive or has a pending checkout"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/{id}/late-fees/waive [post]
+func (h *LateFeeHandler) WaiveLateFees(c *gin.Context) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	billingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid billing ID", err)
+		return
+	}
+
+	var request WaiveLateFeesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "reason is required", err)
+		return
+	}
+
+	lateFees, err := h.lateFeeService.WaiveLateFees(&service.WaiveLateFeesRequest{
+		BillingID:  uint(billingID),
+		PenaltyIDs: request.PenaltyIDs,
+		Reason:     request.Reason,
+		WaivedByID: adminID,
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to waive late fees")
+
+		switch {
+		case errors.Is(err, service.ErrInvalidLateFeeWaiver):
+			utils.BadRequestResponse(c, "Invalid waiver", err)
+		case err.Error() == "billing not found":
+			utils.NotFoundResponse(c, "Billing not found")
+		case err.Error() == "billing has no late fees to waive":
+			utils.ConflictResponse(c, "Billing has no late fees to waive", err)
+		case errors.Is(err, service.ErrPendingCheckout):
+			utils.ConflictResponse(c, "Billing has a pending checkout", err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to waive late fees", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, "Late fees waived", lateFees)
+}
+
+// AssessLateFees runs the late fee assessment now
+// @Summary Assess late fees
+// @Description Add the denda every overdue billing is owed as of today, as the daily job does. Running it again the same day adds nothing. The run is recorded in the billing run history.
+// @Tags billings
+// @Produce json
+// @Success 200 {object} utils.APIResponse{data=models.BillingRun} "Late fees assessed"
+// @Failure 409 {object} utils.APIResponse "Assessment is already running"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/late-fees/assess [post]
+func (h *LateFeeHandler) AssessLateFees(c *gin.Context) {
+	run, err := h.lateFeeService.AssessLateFees(time.Now())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to assess late fees")
+		utils.InternalServerErrorResponse(c, "Failed to assess late fees", err)
+		return
+	}
+	if run == nil {
+		utils.ConflictResponse(c, "Assessment is already running", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, "Late fees assessed", run)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxReceiptSize is the largest receipt file accepted with a manual payment
+const maxReceiptSize = 5 << 20
+
+// RecordManualPaymentRequest represents the form fields of a manual payment
+type RecordManualPaymentRequest struct {
+	BillingIDs      string `form:"billing_ids" binding:"required" example:"6,2"`  // Comma separated billing IDs
+	Amount          int64  `form:"amount" example:"50000"`                        // Partial amount for a single billing, defaults to the outstanding balance
+	Method          string `form:"method" binding:"required" example:"cash"`      // cash or transfer
+	ReferenceNumber string `form:"reference_number" example:"TRF-20251103-001"`   // Required for transfers
+	PaidDate        string `form:"paid_date" example:"2025-11-03"`                // YYYY-MM-DD, defaults to now
+	Notes           string `form:"notes" example:"Dibayar tunai ke bendahara RT"` // Free text
+}
+
+// ManualPaymentHandler handles HTTP requests for payments recorded by admins
+type ManualPaymentHandler struct {
+	manualPaymentService service.ManualPaymentService
+	logger               *logger.Logger
+}
+
+// NewManualPaymentHandler creates a new ManualPaymentHandler instance
+func NewManualPaymentHandler(manualPaymentService service.ManualPaymentService, logger *logger.Logger) *ManualPaymentHandler {
+	return &ManualPaymentHandler{
+		manualPaymentService: manualPaymentService,
+		logger:               logger,
+	}
+}
+
+// RecordManualPayment records a cash or bank transfer payment against one or more billings
+// @Summary Record manual payment
+// @Description Record a cash or bank transfer payment collected outside the payment gateway. The billings are marked as paid and a paid payment transaction is stored like an online payment. The admin is read from the bearer token.
+// @Tags payments
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param billing_ids formData string true "Comma separated billing IDs"
+// @Param amount formData int false "Partial amount for a single billing, defaults to the outstanding balance"
+// @Param method formData string true "cash or transfer"
+// @Param reference_number formData string false "Transfer reference, required for transfers"
+// @Param paid_date formData string false "Payment date (YYYY-MM-DD), defaults to now"
+// @Param notes formData string false "Notes"
+// @Param receipt formData file false "Receipt (JPG, PNG or PDF, max 5 MB)"
+// @Success 201 {object} utils.APIResponse{data=service.ManualPaymentResponse} "Manual payment recorded"
+// @Failure 400 {object} utils.APIResponse "Invalid request"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 409 {object} utils.APIResponse "Billing already paid"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/manual [post]
+func (h *ManualPaymentHandler) RecordManualPayment(c *gin.Context) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	var form RecordManualPaymentRequest
+	if err := c.ShouldBind(&form); err != nil {
+		utils.BadRequestResponse(c, "billing_ids and method are required", err)
+		return
+	}
+
+	billingIDs, err := parseIDList(form.BillingIDs)
+	if err != nil {
+		utils.BadRequestResponse(c, "billing_ids must be comma separated numbers", err)
+		return
+	}
+
+	paidAt := time.Now()
+	if form.PaidDate != "" {
+		paidAt, err = service.ParseWIBDate(form.PaidDate)
+		if err != nil {
+			utils.BadRequestResponse(c, "paid_date must be formatted YYYY-MM-DD", err)
+			return
+		}
+	}
+
+	req := &service.ManualPaymentRequest{
+		BillingIDs:      billingIDs,
+		Amount:          form.Amount,
+		Method:          form.Method,
+		ReferenceNumber: strings.TrimSpace(form.ReferenceNumber),
+		PaidAt:          paidAt,
+		Notes:           form.Notes,
+		RecordedByID:    adminID,
+	}
+
+	if fileHeader, err := c.FormFile("receipt"); err == nil {
+		if fileHeader.Size > maxReceiptSize {
+			utils.BadRequestResponse(c, "Receipt file is larger than 5 MB", nil)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			utils.BadRequestResponse(c, "Failed to read receipt file", err)
+			return
+		}
+		defer file.Close()
+		req.Receipt = &service.ManualPaymentReceipt{FileName: fileHeader.Filename, Content: file}
+	}
+
+	response, err := h.manualPaymentService.RecordManualPayment(req)
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_ids", billingIDs).Error("Failed to record manual payment")
+
+		switch {
+		case errors.Is(err, service.ErrInvalidManualPayment):
+			utils.BadRequestResponse(c, "Invalid manual payment", err)
+		case errors.Is(err, service.ErrBillingNotFound) || errors.Is(err, service.ErrInvalidBillingNominal):
+			utils.NotFoundResponse(c, err.Error())
+		case err.Error() == "billing already paid":
+			utils.ConflictResponse(c, "Billing already paid", err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to record manual payment", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Manual payment recorded", response)
+}
+
+// parseIDList parses a comma separated list of IDs such as "6,2"
+func parseIDList(value string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(id))
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("no IDs given")
+	}
+	return ids, nil
+}
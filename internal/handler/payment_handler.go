@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"ipl-be-svc/internal/service"
 	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,10 +41,15 @@ func NewPaymentHandler(paymentService service.PaymentService, logger *logger.Log
 // @Accept json
 // @Produce json
 // @Param id path int true "Billing ID"
+// @Param payment_method_type query string false "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee"
+// @Param Idempotency-Key header string false "Returns the checkout created earlier with the same key"
 // @Success 200 {object} service.PaymentLinkResponse "Payment link created successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid billing ID"
 // @Failure 404 {object} map[string]interface{} "Billing not found"
+// @Failure 409 {object} map[string]interface{} "Billing already paid, has a pending checkout or idempotency key conflict"
+// @Failure 422 {object} map[string]interface{} "Billing is not linked to a resident with a published profile"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 503 {object} map[string]interface{} "Payment gateway unavailable"
 // @Router /api/v1/payments/billing/{id}/link [post]
 func (h *PaymentHandler) CreatePaymentLink(c *gin.Context) {
 	// Get billing ID from path parameter
@@ -57,12 +66,36 @@ func (h *PaymentHandler) CreatePaymentLink(c *gin.Context) {
 	}
 
 	// Create payment link
-	response, err := h.paymentService.CreatePaymentLink(uint(billingID))
+	response, err := h.paymentService.CreatePaymentLink(c.Request.Context(), uint(billingID), checkoutOptions(c))
 	if err != nil {
 		h.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to create payment link")
 
+		if isPaymentLinkConflict(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Conflict",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, service.ErrBillingOwnerNotFound) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   "Billing has no resident",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, service.ErrGatewayUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Payment gateway unavailable",
+				"message": "The payment gateway is not responding, please try again later",
+			})
+			return
+		}
+
 		// Check if it's a not found error
-		if err.Error() == "billing record not found" || err.Error() == "invalid billing nominal" {
+		if errors.Is(err, service.ErrBillingNotFound) || errors.Is(err, service.ErrInvalidBillingNominal) {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Billing not found",
 				"message": err.Error(),
@@ -93,10 +126,15 @@ func (h *PaymentHandler) CreatePaymentLink(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body CreatePaymentLinkMultipleRequest true "Billing IDs"
+// @Param payment_method_type query string false "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee"
+// @Param Idempotency-Key header string false "Returns the checkout created earlier with the same key"
 // @Success 200 {object} service.PaymentLinkResponse "Payment link created successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid billing IDs"
 // @Failure 404 {object} map[string]interface{} "Billing not found"
+// @Failure 409 {object} map[string]interface{} "Billing already paid, has a pending checkout or idempotency key conflict"
+// @Failure 422 {object} map[string]interface{} "Billing is not linked to a resident with a published profile"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 503 {object} map[string]interface{} "Payment gateway unavailable"
 // @Router /api/v1/payments/billing/link [post]
 func (h *PaymentHandler) CreatePaymentLinkMultiple(c *gin.Context) {
 	var request CreatePaymentLinkMultipleRequest
@@ -119,12 +157,44 @@ func (h *PaymentHandler) CreatePaymentLinkMultiple(c *gin.Context) {
 	}
 
 	// Create payment link
-	response, err := h.paymentService.CreatePaymentLinkMultiple(request.BillingIDs)
+	response, err := h.paymentService.CreatePaymentLinkMultiple(c.Request.Context(), request.BillingIDs, checkoutOptions(c))
 	if err != nil {
 		h.logger.WithError(err).WithField("billing_ids", request.BillingIDs).Error("Failed to create payment link")
 
+		if isPaymentLinkConflict(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Conflict",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		if err.Error() == "billings belong to different residents" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, service.ErrBillingOwnerNotFound) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   "Billing has no resident",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, service.ErrGatewayUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Payment gateway unavailable",
+				"message": "The payment gateway is not responding, please try again later",
+			})
+			return
+		}
+
 		// Check if it's a not found error
-		if err.Error() == "billing record not found" || err.Error() == "invalid billing nominal" {
+		if errors.Is(err, service.ErrBillingNotFound) || errors.Is(err, service.ErrInvalidBillingNominal) {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Billing not found",
 				"message": err.Error(),
@@ -147,3 +217,301 @@ func (h *PaymentHandler) CreatePaymentLinkMultiple(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// isPaymentLinkConflict reports whether a payment link error should be answered with 409
+func isPaymentLinkConflict(err error) bool {
+	return errors.Is(err, service.ErrPendingCheckout) ||
+		err.Error() == "billing already paid" || err.Error() == "idempotency key reused with different billings"
+}
+
+// HandleNotification receives payment gateway HTTP notifications and marks paid billings
+// @Summary Receive payment notification
+// @Description Webhook called by the configured payment gateway after a checkout payment. For DOKU the Signature header is verified with the HMACSHA256 scheme and Request-Timestamp must be within 5 minutes of server time. On a successful transaction the payment transaction and the billings behind the invoice are marked as paid. /doku/notification is registered when PAYMENT_GATEWAY=doku and the unsigned /notification only when PAYMENT_GATEWAY=fake.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param Client-Id header string true "DOKU client ID"
+// @Param Request-Id header string true "DOKU request ID"
+// @Param Request-Timestamp header string true "DOKU request timestamp"
+// @Param Signature header string true "HMACSHA256 signature"
+// @Success 200 {object} utils.APIResponse{data=service.PaymentNotificationResult} "Notification processed"
+// @Failure 400 {object} utils.APIResponse "Invalid notification"
+// @Failure 401 {object} utils.APIResponse "Invalid signature or stale Request-Timestamp"
+// @Failure 404 {object} utils.APIResponse "Payment transaction not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/notification [post]
+// @Router /api/v1/payments/doku/notification [post]
+func (h *PaymentHandler) HandleNotification(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read payment notification body")
+		utils.BadRequestResponse(c, "Failed to read request body", err)
+		return
+	}
+
+	result, err := h.paymentService.HandleNotification(&service.GatewayNotificationRequest{
+		Headers:       c.Request.Header,
+		RequestTarget: c.Request.URL.Path,
+		Body:          body,
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("request_id", c.GetHeader("Request-Id")).Error("Failed to process payment notification")
+
+		switch {
+		case err.Error() == "invalid notification signature":
+			utils.UnauthorizedResponse(c, "Invalid signature")
+		case err.Error() == "notification timestamp out of range":
+			utils.UnauthorizedResponse(c, "Notification timestamp out of range")
+		case strings.HasPrefix(err.Error(), "invalid notification body"):
+			utils.BadRequestResponse(c, "Invalid notification body", err)
+		case errors.Is(err, service.ErrPaymentTransactionNotFound):
+			utils.NotFoundResponse(c, "Payment transaction not found")
+		case err.Error() == "amount mismatch":
+			utils.BadRequestResponse(c, "Paid amount does not match billings", err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to process notification", err)
+		}
+		return
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"invoice_number":     result.InvoiceNumber,
+		"transaction_status": result.TransactionStatus,
+		"updated":            result.Updated,
+	}).Info("Payment notification processed")
+
+	utils.SuccessResponse(c, "Notification processed", result)
+}
+
+// CancelPaymentLink cancels a pending checkout
+// @Summary Cancel payment link
+// @Description Cancel a pending checkout so it is no longer reused for its billings. The gateway is asked to close the link; DOKU Checkout cannot, so gateway_cancelled is false and the link stays open until it expires. A payment made through it is still applied.
+// @Tags payments
+// @Produce json
+// @Param invoice_number path string true "Invoice number"
+// @Success 200 {object} utils.APIResponse{data=service.PaymentCancelResult} "Payment link cancelled"
+// @Failure 404 {object} utils.APIResponse "Payment transaction not found"
+// @Failure 409 {object} utils.APIResponse "Payment transaction is not pending"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Failure 503 {object} utils.APIResponse "Payment gateway unavailable"
+// @Router /api/v1/payments/transactions/{invoice_number}/cancel [post]
+func (h *PaymentHandler) CancelPaymentLink(c *gin.Context) {
+	invoiceNumber := c.Param("invoice_number")
+
+	result, err := h.paymentService.CancelPaymentLink(c.Request.Context(), invoiceNumber)
+	if err != nil {
+		h.logger.WithError(err).WithField("invoice_number", invoiceNumber).Error("Failed to cancel payment link")
+
+		switch {
+		case errors.Is(err, service.ErrPaymentTransactionNotFound):
+			utils.NotFoundResponse(c, "Payment transaction not found")
+		case err.Error() == "payment transaction is not pending":
+			utils.ConflictResponse(c, "Payment transaction is not pending", err)
+		case errors.Is(err, service.ErrGatewayUnavailable):
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "Payment gateway unavailable", err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to cancel payment link", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, "Payment link cancelled", result)
+}
+
+// CreatePartialPaymentLinkRequest represents the request body for paying part of a billing
+type CreatePartialPaymentLinkRequest struct {
+	Amount int64 `json:"amount" binding:"required" example:"50000"` // Amount to pay, at most the outstanding balance
+}
+
+// CreatePartialPaymentLink creates a payment link for part of a billing's outstanding balance
+// @Summary Create partial payment link
+// @Description Create a payment link for part of the outstanding balance of a billing. The billing becomes "Dibayar Sebagian" once the payment succeeds and "Sudah Dibayar" when its balance is fully paid.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Billing ID"
+// @Param request body CreatePartialPaymentLinkRequest true "Amount"
+// @Param payment_method_type query string false "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee"
+// @Param Idempotency-Key header string false "Returns the checkout created earlier with the same key"
+// @Success 200 {object} utils.APIResponse{data=service.PaymentLinkResponse} "Payment link created"
+// @Failure 400 {object} utils.APIResponse "Invalid billing ID or amount"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 409 {object} utils.APIResponse "Billing already paid, has a pending checkout or idempotency key conflict"
+// @Failure 422 {object} utils.APIResponse "Billing is not linked to a resident with a published profile"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Failure 503 {object} utils.APIResponse "Payment gateway unavailable"
+// @Router /api/v1/payments/billing/{id}/partial-link [post]
+func (h *PaymentHandler) CreatePartialPaymentLink(c *gin.Context) {
+	billingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid billing ID", err)
+		return
+	}
+
+	var request CreatePartialPaymentLinkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "amount is required", err)
+		return
+	}
+
+	response, err := h.paymentService.CreatePartialPaymentLink(c.Request.Context(), uint(billingID), request.Amount, checkoutOptions(c))
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to create partial payment link")
+		respondPaymentLinkError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Payment link created", response)
+}
+
+// CreateInstallmentPaymentLink creates a payment link for one part of an installment plan
+// @Summary Create installment payment link
+// @Description Create a payment link for an installment part. Parts are paid in sequence and pay the plan's billings oldest first.
+// @Tags payments
+// @Produce json
+// @Param part_id path int true "Installment part ID"
+// @Param payment_method_type query string false "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee"
+// @Param Idempotency-Key header string false "Returns the checkout created earlier with the same key"
+// @Success 200 {object} utils.APIResponse{data=service.PaymentLinkResponse} "Payment link created"
+// @Failure 400 {object} utils.APIResponse "Invalid installment part ID"
+// @Failure 404 {object} utils.APIResponse "Installment part not found"
+// @Failure 409 {object} utils.APIResponse "Part already paid, previous part unpaid or plan not active"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Failure 503 {object} utils.APIResponse "Payment gateway unavailable"
+// @Router /api/v1/payments/installments/{part_id}/link [post]
+func (h *PaymentHandler) CreateInstallmentPaymentLink(c *gin.Context) {
+	partID, err := strconv.ParseUint(c.Param("part_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid installment part ID", err)
+		return
+	}
+
+	response, err := h.paymentService.CreateInstallmentPaymentLink(c.Request.Context(), uint(partID), checkoutOptions(c))
+	if err != nil {
+		h.logger.WithError(err).WithField("part_id", partID).Error("Failed to create installment payment link")
+
+		switch err.Error() {
+		case "installment part not found":
+			utils.NotFoundResponse(c, "Installment part not found")
+		case "installment plan is not active", "installment part already paid", "previous installment part not paid":
+			utils.ConflictResponse(c, err.Error(), err)
+		default:
+			respondPaymentLinkError(c, err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, "Payment link created", response)
+}
+
+// GetBillingBalance returns what has been paid of a billing and what is outstanding
+// @Summary Get billing balance
+// @Description Get the nominal, approved adjustments, unwaived late fee (denda), amount due, paid amount, outstanding balance and status of a billing
+// @Tags payments
+// @Produce json
+// @Param id path int true "Billing ID"
+// @Success 200 {object} utils.APIResponse{data=service.BillingBalance} "Billing balance retrieved"
+// @Failure 400 {object} utils.APIResponse "Invalid billing ID"
+// @Failure 404 {object} utils.APIResponse "Billing not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/billing/{id}/balance [get]
+func (h *PaymentHandler) GetBillingBalance(c *gin.Context) {
+	billingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid billing ID", err)
+		return
+	}
+
+	balance, err := h.paymentService.GetBillingBalance(uint(billingID))
+	if err != nil {
+		h.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to get billing balance")
+
+		if errors.Is(err, service.ErrBillingNotFound) || errors.Is(err, service.ErrInvalidBillingNominal) {
+			utils.NotFoundResponse(c, "Billing not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get billing balance", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Billing balance retrieved", balance)
+}
+
+// CreateOutstandingPaymentLink creates one payment link for every unpaid billing of the logged-in resident
+// @Summary Pay all outstanding billings
+// @Description Create one checkout for every unpaid published billing of the resident in the bearer token, optionally limited to a range of months. Billings in an active installment plan are left out. Used by the app's "Bayar Semua" button.
+// @Tags payments
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First billing month (YYYY-MM)"
+// @Param to query string false "Last billing month (YYYY-MM)"
+// @Param payment_method_type query string false "Limit the checkout to one DOKU payment method type, e.g. CREDIT_CARD, adding its fee"
+// @Param Idempotency-Key header string false "Returns the checkout created earlier with the same key"
+// @Success 200 {object} utils.APIResponse{data=service.PaymentLinkResponse} "Payment link created"
+// @Failure 400 {object} utils.APIResponse "Invalid month range"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 404 {object} utils.APIResponse "No outstanding billings"
+// @Failure 409 {object} utils.APIResponse "Idempotency key conflict"
+// @Failure 422 {object} utils.APIResponse "Billing is not linked to a resident with a published profile"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Failure 503 {object} utils.APIResponse "Payment gateway unavailable"
+// @Router /api/v1/payments/outstanding/link [post]
+func (h *PaymentHandler) CreateOutstandingPaymentLink(c *gin.Context) {
+	userID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	var months service.BillingMonthRange
+	if months.From, err = service.ParseBillingMonth(c.Query("from")); err == nil {
+		months.To, err = service.ParseBillingMonth(c.Query("to"))
+	}
+	if err != nil {
+		utils.BadRequestResponse(c, "from and to must be formatted YYYY-MM", err)
+		return
+	}
+
+	response, err := h.paymentService.CreateOutstandingPaymentLink(c.Request.Context(), userID, months, checkoutOptions(c))
+	if err != nil {
+		h.logger.WithError(err).WithField("user_id", userID).Error("Failed to create outstanding payment link")
+
+		switch {
+		case errors.Is(err, service.ErrInvalidBillingMonth):
+			utils.BadRequestResponse(c, "Invalid month range", err)
+		case errors.Is(err, service.ErrNoOutstandingBillings):
+			utils.NotFoundResponse(c, "No outstanding billings")
+		default:
+			respondPaymentLinkError(c, err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, "Payment link created", response)
+}
+
+// checkoutOptions reads the payment method type and Idempotency-Key of a payment link request
+func checkoutOptions(c *gin.Context) service.CheckoutOptions {
+	return service.CheckoutOptions{
+		PaymentMethodType: c.Query("payment_method_type"),
+		IdempotencyKey:    c.GetHeader("Idempotency-Key"),
+	}
+}
+
+// respondPaymentLinkError writes the response for an error creating a payment link
+func respondPaymentLinkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPaymentAmount):
+		utils.BadRequestResponse(c, "Invalid payment amount", err)
+	case isPaymentLinkConflict(err):
+		utils.ConflictResponse(c, err.Error(), err)
+	case errors.Is(err, service.ErrBillingOwnerNotFound):
+		utils.ErrorResponse(c, http.StatusUnprocessableEntity, "Billing has no resident", err)
+	case errors.Is(err, service.ErrGatewayUnavailable):
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Payment gateway unavailable", err)
+	case errors.Is(err, service.ErrBillingNotFound) || errors.Is(err, service.ErrInvalidBillingNominal):
+		utils.NotFoundResponse(c, "Billing not found")
+	default:
+		utils.InternalServerErrorResponse(c, "Failed to create payment link", err)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReceiptHandler handles payment receipt HTTP requests
+type ReceiptHandler struct {
+	receiptService service.ReceiptService
+	logger         *logger.Logger
+}
+
+// NewReceiptHandler creates a new ReceiptHandler instance
+func NewReceiptHandler(receiptService service.ReceiptService, logger *logger.Logger) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptService: receiptService,
+		logger:         logger,
+	}
+}
+
+// DownloadReceipt returns the PDF receipt (kwitansi) of a paid payment transaction
+// @Summary Download payment receipt
+// @Description Download the PDF receipt of a paid payment transaction with the resident's name and house, the billed months and amounts, the payment method, the invoice number and a verification code. The receipt is rendered from stored data on every request, so downloading it again gives the same document.
+// @Tags payments
+// @Produce application/pdf
+// @Param invoice_number path string true "Invoice number"
+// @Success 200 {file} file "PDF receipt"
+// @Failure 404 {object} utils.APIResponse "Payment transaction not found"
+// @Failure 409 {object} utils.APIResponse "Payment transaction is not paid"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/transactions/{invoice_number}/receipt [get]
+func (h *ReceiptHandler) DownloadReceipt(c *gin.Context) {
+	invoiceNumber := c.Param("invoice_number")
+
+	receipt, err := h.receiptService.GetReceipt(invoiceNumber)
+	if err != nil {
+		h.respondReceiptError(c, invoiceNumber, err, "Failed to get receipt")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", receipt.FileName))
+	c.Data(http.StatusOK, "application/pdf", receipt.Content)
+}
+
+// VerifyReceipt checks the verification code printed on a receipt
+// @Summary Verify payment receipt
+// @Description Check that a verification code printed on a receipt belongs to the payment transaction. A valid code returns the resident, amount and payment date to compare with the printed receipt.
+// @Tags payments
+// @Produce json
+// @Param invoice_number path string true "Invoice number"
+// @Param code query string true "Verification code, e.g. 1A2B-3C4D-5E6F-7A8B"
+// @Success 200 {object} utils.APIResponse{data=service.ReceiptVerification} "Receipt verified"
+// @Failure 400 {object} utils.APIResponse "Missing verification code"
+// @Failure 404 {object} utils.APIResponse "Payment transaction not found"
+// @Failure 409 {object} utils.APIResponse "Payment transaction is not paid"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/transactions/{invoice_number}/receipt/verify [get]
+func (h *ReceiptHandler) VerifyReceipt(c *gin.Context) {
+	invoiceNumber := c.Param("invoice_number")
+	code := c.Query("code")
+	if code == "" {
+		utils.BadRequestResponse(c, "code is required", nil)
+		return
+	}
+
+	verification, err := h.receiptService.VerifyReceipt(invoiceNumber, code)
+	if err != nil {
+		h.respondReceiptError(c, invoiceNumber, err, "Failed to verify receipt")
+		return
+	}
+
+	utils.SuccessResponse(c, "Receipt verified", verification)
+}
+
+// respondReceiptError maps a receipt service error to its HTTP response
+func (h *ReceiptHandler) respondReceiptError(c *gin.Context, invoiceNumber string, err error, message string) {
+	h.logger.WithError(err).WithField("invoice_number", invoiceNumber).Error(message)
+
+	switch {
+	case errors.Is(err, service.ErrPaymentTransactionNotFound):
+		utils.NotFoundResponse(c, "Payment transaction not found")
+	case errors.Is(err, service.ErrPaymentTransactionNotPaid):
+		utils.ConflictResponse(c, "Payment transaction is not paid", err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefundPaymentRequest represents the request body for refunding a payment transaction
+type RefundPaymentRequest struct {
+	Amount     int64  `json:"amount" example:"150000"`                              // 0 or omitted refunds the remaining amount
+	Reason     string `json:"reason" binding:"required" example:"Pembayaran ganda"` // Why the money is returned
+	BillingIDs []uint `json:"billing_ids" example:"6"`                              // Billings to set back to unpaid
+}
+
+// RefundHandler handles refund HTTP requests
+type RefundHandler struct {
+	refundService service.RefundService
+	logger        *logger.Logger
+}
+
+// NewRefundHandler creates a new RefundHandler instance
+func NewRefundHandler(refundService service.RefundService, logger *logger.Logger) *RefundHandler {
+	return &RefundHandler{
+		refundService: refundService,
+		logger:        logger,
+	}
+}
+
+// RefundPayment records a full or partial refund of a paid payment transaction
+// @Summary Refund payment
+// @Description Record money returned on a paid payment transaction. Without amount the remaining amount is refunded. billing_ids are set back to unpaid; a full refund reopens every billing of the payment when none are given. Billings also paid by another transaction stay paid. The admin is read from the bearer token.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param invoice_number path string true "Invoice number"
+// @Param request body RefundPaymentRequest true "Refund"
+// @Success 201 {object} utils.APIResponse{data=service.RefundResponse} "Refund recorded"
+// @Failure 400 {object} utils.APIResponse "Invalid refund"
+// @Failure 401 {object} utils.APIResponse "Unauthorized"
+// @Failure 404 {object} utils.APIResponse "Payment transaction not found"
+// @Failure 409 {object} utils.APIResponse "Payment transaction is not paid"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/transactions/{invoice_number}/refunds [post]
+func (h *RefundHandler) RefundPayment(c *gin.Context) {
+	adminID, err := utils.ExtractUserIDFromToken(c.GetHeader("Authorization"))
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or missing token")
+		return
+	}
+
+	var request RefundPaymentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "reason is required", err)
+		return
+	}
+
+	invoiceNumber := c.Param("invoice_number")
+	response, err := h.refundService.RefundTransaction(&service.RefundRequest{
+		InvoiceNumber: invoiceNumber,
+		Amount:        request.Amount,
+		Reason:        request.Reason,
+		BillingIDs:    request.BillingIDs,
+		RefundedByID:  adminID,
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("invoice_number", invoiceNumber).Error("Failed to refund payment")
+
+		switch {
+		case errors.Is(err, service.ErrInvalidRefund):
+			utils.BadRequestResponse(c, "Invalid refund", err)
+		case errors.Is(err, service.ErrPaymentTransactionNotFound):
+			utils.NotFoundResponse(c, "Payment transaction not found")
+		case errors.Is(err, service.ErrPaymentTransactionNotPaid):
+			utils.ConflictResponse(c, "Payment transaction is not paid", err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to refund payment", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Refund recorded", response)
+}
+
+// GetRefunds returns the refund history of a payment transaction
+// @Summary Get refund history
+// @Description Get the refunds recorded on a payment transaction, oldest first
+// @Tags payments
+// @Produce json
+// @Param invoice_number path string true "Invoice number"
+// @Success 200 {object} utils.APIResponse{data=[]models.PaymentRefund} "Refunds retrieved"
+// @Failure 404 {object} utils.APIResponse "Payment transaction not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/transactions/{invoice_number}/refunds [get]
+func (h *RefundHandler) GetRefunds(c *gin.Context) {
+	invoiceNumber := c.Param("invoice_number")
+
+	refunds, err := h.refundService.GetRefunds(invoiceNumber)
+	if err != nil {
+		h.logger.WithError(err).WithField("invoice_number", invoiceNumber).Error("Failed to get refunds")
+
+		if errors.Is(err, service.ErrPaymentTransactionNotFound) {
+			utils.NotFoundResponse(c, "Payment transaction not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get refunds", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Refunds retrieved", refunds)
+}
@@ -14,8 +14,21 @@ func SetupRoutes(
 	router *gin.Engine,
 	menuService service.MenuService,
 	paymentService service.PaymentService,
+	manualPaymentService service.ManualPaymentService,
+	refundService service.RefundService,
+	installmentService service.InstallmentService,
+	feeRuleService service.FeeRuleService,
+	receiptService service.ReceiptService,
+	settlementService service.SettlementService,
 	userService service.UserService,
 	billingService service.BillingService,
+	billingManagementService service.BillingManagementService,
+	billingScheduler service.BillingScheduler,
+	lateFeeService service.LateFeeService,
+	adjustmentService service.AdjustmentService,
+	discountRuleService service.DiscountRuleService,
+	tariffService service.TariffService,
+	settingScheduleService service.SettingScheduleService,
 	masterMenuService service.MasterMenuService,
 	roleMenuService service.RoleMenuService,
 	logger *logger.Logger,
@@ -23,8 +36,21 @@ func SetupRoutes(
 	// Initialize handlers
 	menuHandler := NewMenuHandler(menuService, logger)
 	paymentHandler := NewPaymentHandler(paymentService, logger)
+	manualPaymentHandler := NewManualPaymentHandler(manualPaymentService, logger)
+	refundHandler := NewRefundHandler(refundService, logger)
+	installmentHandler := NewInstallmentHandler(installmentService, logger)
+	feeRuleHandler := NewFeeRuleHandler(feeRuleService, logger)
+	receiptHandler := NewReceiptHandler(receiptService, logger)
+	settlementHandler := NewSettlementHandler(settlementService, logger)
 	userHandler := NewUserHandler(userService, logger)
 	bulkBillingHandler := NewBulkBillingHandler(billingService, logger)
+	billingManagementHandler := NewBillingManagementHandler(billingManagementService, logger)
+	billingRunHandler := NewBillingRunHandler(billingScheduler, logger)
+	lateFeeHandler := NewLateFeeHandler(lateFeeService, logger)
+	adjustmentHandler := NewAdjustmentHandler(adjustmentService, logger)
+	discountRuleHandler := NewDiscountRuleHandler(discountRuleService, logger)
+	tariffHandler := NewTariffHandler(tariffService, logger)
+	settingScheduleHandler := NewSettingScheduleHandler(settingScheduleService, logger)
 	masterMenuHandler := NewMasterMenuHandler(masterMenuService, logger)
 	roleMenuHandler := NewRoleMenuHandler(roleMenuService, logger)
 
@@ -48,6 +74,36 @@ func SetupRoutes(
 		{
 			payments.POST("/billing/:id/link", paymentHandler.CreatePaymentLink)
 			payments.POST("/billing/link", paymentHandler.CreatePaymentLinkMultiple)
+			payments.POST("/billing/:id/partial-link", paymentHandler.CreatePartialPaymentLink)
+			payments.GET("/billing/:id/balance", paymentHandler.GetBillingBalance)
+			payments.POST("/installments/:part_id/link", paymentHandler.CreateInstallmentPaymentLink)
+			payments.POST("/outstanding/link", paymentHandler.CreateOutstandingPaymentLink)
+			payments.POST("/manual", manualPaymentHandler.RecordManualPayment)
+			payments.POST("/settlements/reconcile", settlementHandler.ReconcileSettlement)
+			payments.POST("/transactions/:invoice_number/cancel", paymentHandler.CancelPaymentLink)
+			payments.POST("/transactions/:invoice_number/refunds", refundHandler.RefundPayment)
+			payments.GET("/transactions/:invoice_number/refunds", refundHandler.GetRefunds)
+			payments.GET("/transactions/:invoice_number/receipt", receiptHandler.DownloadReceipt)
+			payments.GET("/transactions/:invoice_number/receipt/verify", receiptHandler.VerifyReceipt)
+			payments.POST("/fee-rules", feeRuleHandler.CreateFeeRule)
+			payments.GET("/fee-rules", feeRuleHandler.GetFeeRules)
+			payments.PUT("/fee-rules/:id", feeRuleHandler.UpdateFeeRule)
+			payments.DELETE("/fee-rules/:id", feeRuleHandler.DeleteFeeRule)
+
+			// Only the selected gateway's notifications are accepted; fake notifications are unsigned
+			switch paymentService.GatewayName() {
+			case service.PaymentGatewayDoku:
+				payments.POST("/doku/notification", paymentHandler.HandleNotification)
+			case service.PaymentGatewayFake:
+				payments.POST("/notification", paymentHandler.HandleNotification)
+			}
+		}
+
+		// Installment plan routes
+		installmentPlans := v1.Group("/installment-plans")
+		{
+			installmentPlans.POST("", installmentHandler.CreateInstallmentPlan)
+			installmentPlans.GET("/:id", installmentHandler.GetInstallmentPlan)
 		}
 
 		// User routes
@@ -55,13 +111,39 @@ func SetupRoutes(
 		{
 			users.GET("/profile/:user_id", userHandler.GetUserDetailByProfileID)
 			users.GET("/penghuni", userHandler.GetPenghuniUsers)
+			users.GET("/units/:profile_id", userHandler.GetResidentUnit)
+			users.PUT("/units/:profile_id", userHandler.SaveResidentUnit)
 		}
 
 		// Billing routes
 		billings := v1.Group("/billings")
 		{
+			billings.GET("", billingManagementHandler.ListBillings)
+			billings.GET("/:id", billingManagementHandler.GetBilling)
+			billings.PUT("/:id", billingManagementHandler.UpdateBilling)
+			billings.POST("/:id/cancel", billingManagementHandler.CancelBilling)
 			billings.POST("/bulk-monthly", bulkBillingHandler.CreateBulkMonthlyBillings)
+			billings.POST("/one-off", bulkBillingHandler.CreateOneOffBillings)
 			billings.GET("/penghuni", bulkBillingHandler.GetBillingPenghuni)
+			billings.GET("/runs", billingRunHandler.GetBillingRuns)
+			billings.POST("/late-fees/assess", lateFeeHandler.AssessLateFees)
+			billings.GET("/:id/late-fees", lateFeeHandler.GetBillingLateFees)
+			billings.POST("/:id/late-fees/waive", lateFeeHandler.WaiveLateFees)
+			billings.POST("/:id/adjustments", adjustmentHandler.CreateAdjustment)
+			billings.GET("/:id/adjustments", adjustmentHandler.GetBillingAdjustments)
+			billings.POST("/adjustments/:adjustment_id/approve", adjustmentHandler.ApproveAdjustment)
+			billings.POST("/adjustments/:adjustment_id/reject", adjustmentHandler.RejectAdjustment)
+			billings.POST("/discount-rules", discountRuleHandler.CreateDiscountRule)
+			billings.GET("/discount-rules", discountRuleHandler.GetDiscountRules)
+			billings.PUT("/discount-rules/:id", discountRuleHandler.UpdateDiscountRule)
+			billings.DELETE("/discount-rules/:id", discountRuleHandler.DeleteDiscountRule)
+			billings.POST("/tariffs", tariffHandler.CreateTariff)
+			billings.GET("/tariffs", tariffHandler.GetTariffs)
+			billings.PUT("/tariffs/:id", tariffHandler.UpdateTariff)
+			billings.DELETE("/tariffs/:id", tariffHandler.DeleteTariff)
+			billings.GET("/yearly-schedules", settingScheduleHandler.GetYearlySchedules)
+			billings.PUT("/yearly-schedules/:setting_billing_id", settingScheduleHandler.SetYearlySchedule)
+			billings.DELETE("/yearly-schedules/:setting_billing_id", settingScheduleHandler.DeleteYearlySchedule)
 		}
 
 		// Master Menu routes
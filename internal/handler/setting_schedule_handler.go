@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// YearlyScheduleRequest represents the request body for scheduling a yearly setting billing
+type YearlyScheduleRequest struct {
+	BillingMonth int `json:"billing_month" binding:"required" example:"8"` // Month 1-12 the setting is billed in
+}
+
+// SettingScheduleHandler handles yearly setting billing schedule HTTP requests
+type SettingScheduleHandler struct {
+	settingScheduleService service.SettingScheduleService
+	logger                 *logger.Logger
+}
+
+// NewSettingScheduleHandler creates a new SettingScheduleHandler instance
+func NewSettingScheduleHandler(settingScheduleService service.SettingScheduleService, logger *logger.Logger) *SettingScheduleHandler {
+	return &SettingScheduleHandler{
+		settingScheduleService: settingScheduleService,
+		logger:                 logger,
+	}
+}
+
+// SetYearlySchedule sets the month a yearly setting billing is billed in
+// @Summary Schedule yearly setting billing
+// @Description Set the month a yearly (tahunan) setting billing is billed in, replacing its previous month. The generation of that month, scheduled or bulk, bills it once alongside the monthly settings.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Param setting_billing_id path int true "Setting billing ID"
+// @Param request body YearlyScheduleRequest true "Schedule"
+// @Success 200 {object} utils.APIResponse{data=models.SettingBillingSchedule} "Schedule saved"
+// @Failure 400 {object} utils.APIResponse "Invalid schedule"
+// @Failure 404 {object} utils.APIResponse "Setting billing not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/yearly-schedules/{setting_billing_id} [put]
+func (h *SettingScheduleHandler) SetYearlySchedule(c *gin.Context) {
+	settingBillingID, err := strconv.ParseUint(c.Param("setting_billing_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid setting billing ID", err)
+		return
+	}
+
+	var request YearlyScheduleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "billing_month is required", err)
+		return
+	}
+
+	schedule, err := h.settingScheduleService.SetYearlySchedule(uint(settingBillingID), request.BillingMonth)
+	if err != nil {
+		h.logger.WithError(err).WithField("setting_billing_id", settingBillingID).Error("Failed to save setting billing schedule")
+		h.respondError(c, err, "Failed to save schedule")
+		return
+	}
+
+	utils.SuccessResponse(c, "Schedule saved", schedule)
+}
+
+// GetYearlySchedules returns the schedules of the yearly setting billings
+// @Summary Get yearly setting billing schedules
+// @Description Get the month each scheduled yearly (tahunan) setting billing is billed in, by month
+// @Tags billings
+// @Produce json
+// @Success 200 {object} utils.APIResponse{data=[]models.SettingBillingSchedule} "Schedules retrieved"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/yearly-schedules [get]
+func (h *SettingScheduleHandler) GetYearlySchedules(c *gin.Context) {
+	schedules, err := h.settingScheduleService.GetYearlySchedules()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get setting billing schedules")
+		utils.InternalServerErrorResponse(c, "Failed to get schedules", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Schedules retrieved", schedules)
+}
+
+// DeleteYearlySchedule removes the schedule of a yearly setting billing
+// @Summary Delete yearly setting billing schedule
+// @Description Stop billing a yearly setting billing; billings already generated are kept
+// @Tags billings
+// @Produce json
+// @Param setting_billing_id path int true "Setting billing ID"
+// @Success 200 {object} utils.APIResponse "Schedule deleted"
+// @Failure 400 {object} utils.APIResponse "Invalid setting billing ID"
+// @Failure 404 {object} utils.APIResponse "Schedule not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/yearly-schedules/{setting_billing_id} [delete]
+func (h *SettingScheduleHandler) DeleteYearlySchedule(c *gin.Context) {
+	settingBillingID, err := strconv.ParseUint(c.Param("setting_billing_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid setting billing ID", err)
+		return
+	}
+
+	if err := h.settingScheduleService.DeleteYearlySchedule(uint(settingBillingID)); err != nil {
+		h.logger.WithError(err).WithField("setting_billing_id", settingBillingID).Error("Failed to delete setting billing schedule")
+		h.respondError(c, err, "Failed to delete schedule")
+		return
+	}
+
+	utils.SuccessResponse(c, "Schedule deleted", nil)
+}
+
+// respondError writes the response for a setting schedule service error
+func (h *SettingScheduleHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSettingSchedule):
+		utils.BadRequestResponse(c, "Invalid schedule", err)
+	case err.Error() == "setting billing not found":
+		utils.NotFoundResponse(c, "Setting billing not found")
+	case err.Error() == "schedule not found":
+		utils.NotFoundResponse(c, "Schedule not found")
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
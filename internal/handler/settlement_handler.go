@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SettlementHandler handles DOKU settlement report HTTP requests
+type SettlementHandler struct {
+	settlementService service.SettlementService
+	logger            *logger.Logger
+}
+
+// NewSettlementHandler creates a new SettlementHandler instance
+func NewSettlementHandler(settlementService service.SettlementService, logger *logger.Logger) *SettlementHandler {
+	return &SettlementHandler{
+		settlementService: settlementService,
+		logger:            logger,
+	}
+}
+
+// ReconcileSettlement reconciles an uploaded DOKU settlement file with stored payment transactions
+// @Summary Reconcile DOKU settlement file
+// @Description Upload a settlement CSV downloaded from the DOKU back office. Rows are matched against payment transactions by invoice number and amount and reported as matched, missing in our database, missing in DOKU (paid in the period but not settled), amount mismatch or status mismatch (settled in full while our transaction is not paid). The period defaults to the days of the file's transaction dates.
+// @Tags payments
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "DOKU settlement CSV"
+// @Param from query string false "Start of the settlement period (YYYY-MM-DD, WIB)"
+// @Param to query string false "Last day of the settlement period (YYYY-MM-DD, WIB)"
+// @Success 200 {object} utils.APIResponse{data=service.SettlementReport} "Settlement reconciled"
+// @Failure 400 {object} utils.APIResponse "Invalid settlement file or period"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/payments/settlements/reconcile [post]
+func (h *SettlementHandler) ReconcileSettlement(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequestResponse(c, "Settlement file is required", err)
+		return
+	}
+
+	period, err := service.ParseSettlementPeriod(c.Query("from"), c.Query("to"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid settlement period", err)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.BadRequestResponse(c, "Failed to read settlement file", err)
+		return
+	}
+	defer file.Close()
+
+	report, err := h.settlementService.ReconcileSettlement(file, period)
+	if err != nil {
+		h.logger.WithError(err).WithField("file", fileHeader.Filename).Error("Failed to reconcile settlement")
+
+		if errors.Is(err, service.ErrInvalidSettlementFile) {
+			utils.BadRequestResponse(c, "Invalid settlement file", err)
+			return
+		}
+
+		utils.InternalServerErrorResponse(c, "Failed to reconcile settlement", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Settlement reconciled", report)
+}
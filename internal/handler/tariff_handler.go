@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"ipl-be-svc/internal/service"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TariffHandler handles setting billing tariff HTTP requests
+type TariffHandler struct {
+	tariffService service.TariffService
+	logger        *logger.Logger
+}
+
+// NewTariffHandler creates a new TariffHandler instance
+func NewTariffHandler(tariffService service.TariffService, logger *logger.Logger) *TariffHandler {
+	return &TariffHandler{
+		tariffService: tariffService,
+		logger:        logger,
+	}
+}
+
+// CreateTariff creates a tariff of a setting billing
+// @Summary Create tariff
+// @Description Create a price of a setting billing for the units matching its house type, cluster and land area filters. Flat tariffs charge amount, per_m2 tariffs charge amount per m² of land, and tiered tariffs charge the amount of the land area bracket. Bulk generation prices each billing with the first active tariff of its setting, by priority, matching the resident's unit, or the setting nominal when none does, and records the tariff on the billing.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Param request body service.TariffRequest true "Tariff"
+// @Success 201 {object} utils.APIResponse{data=models.SettingBillingTariff} "Tariff created"
+// @Failure 400 {object} utils.APIResponse "Invalid tariff"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/tariffs [post]
+func (h *TariffHandler) CreateTariff(c *gin.Context) {
+	var request service.TariffRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "setting_billing_id, name and pricing_type are required", err)
+		return
+	}
+
+	tariff, err := h.tariffService.CreateTariff(&request)
+	if err != nil {
+		h.logger.WithError(err).WithField("setting_billing_id", request.SettingBillingID).Error("Failed to create tariff")
+		h.respondError(c, err, "Failed to create tariff")
+		return
+	}
+
+	utils.CreatedResponse(c, "Tariff created", tariff)
+}
+
+// GetTariffs returns the tariffs
+// @Summary Get tariffs
+// @Description Get the tariffs of every setting billing, or of one, in the order they are tried
+// @Tags billings
+// @Produce json
+// @Param setting_billing_id query int false "Setting billing ID"
+// @Success 200 {object} utils.APIResponse{data=[]models.SettingBillingTariff} "Tariffs retrieved"
+// @Failure 400 {object} utils.APIResponse "Invalid setting billing ID"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/tariffs [get]
+func (h *TariffHandler) GetTariffs(c *gin.Context) {
+	var settingBillingID *uint
+	if value := c.Query("setting_billing_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid setting billing ID", err)
+			return
+		}
+		sid := uint(id)
+		settingBillingID = &sid
+	}
+
+	tariffs, err := h.tariffService.GetTariffs(settingBillingID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get tariffs")
+		utils.InternalServerErrorResponse(c, "Failed to get tariffs", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Tariffs retrieved", tariffs)
+}
+
+// UpdateTariff replaces the settings of a tariff
+// @Summary Update tariff
+// @Description Replace the filters and pricing of a tariff. Billings already generated keep their nominal.
+// @Tags billings
+// @Accept json
+// @Produce json
+// @Param id path int true "Tariff ID"
+// @Param request body service.TariffRequest true "Tariff"
+// @Success 200 {object} utils.APIResponse{data=models.SettingBillingTariff} "Tariff updated"
+// @Failure 400 {object} utils.APIResponse "Invalid tariff"
+// @Failure 404 {object} utils.APIResponse "Tariff not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/tariffs/{id} [put]
+func (h *TariffHandler) UpdateTariff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tariff ID", err)
+		return
+	}
+
+	var request service.TariffRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "setting_billing_id, name and pricing_type are required", err)
+		return
+	}
+
+	tariff, err := h.tariffService.UpdateTariff(uint(id), &request)
+	if err != nil {
+		h.logger.WithError(err).WithField("id", id).Error("Failed to update tariff")
+		h.respondError(c, err, "Failed to update tariff")
+		return
+	}
+
+	utils.SuccessResponse(c, "Tariff updated", tariff)
+}
+
+// DeleteTariff deletes a tariff
+// @Summary Delete tariff
+// @Description Delete a tariff; billings generated afterwards are priced without it
+// @Tags billings
+// @Produce json
+// @Param id path int true "Tariff ID"
+// @Success 200 {object} utils.APIResponse "Tariff deleted"
+// @Failure 400 {object} utils.APIResponse "Invalid tariff ID"
+// @Failure 404 {object} utils.APIResponse "Tariff not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/billings/tariffs/{id} [delete]
+func (h *TariffHandler) DeleteTariff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tariff ID", err)
+		return
+	}
+
+	if err := h.tariffService.DeleteTariff(uint(id)); err != nil {
+		h.logger.WithError(err).WithField("id", id).Error("Failed to delete tariff")
+		h.respondError(c, err, "Failed to delete tariff")
+		return
+	}
+
+	utils.SuccessResponse(c, "Tariff deleted", nil)
+}
+
+// respondError writes the response for a tariff service error
+func (h *TariffHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTariff):
+		utils.BadRequestResponse(c, "Invalid tariff", err)
+	case err.Error() == "tariff not found":
+		utils.NotFoundResponse(c, "Tariff not found")
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
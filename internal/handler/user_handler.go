@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"ipl-be-svc/internal/models/response"
@@ -138,3 +139,79 @@ func (h *UserHandler) GetPenghuniUsers(c *gin.Context) {
 
 	utils.SuccessResponse(c, "Penghuni users retrieved successfully", responses)
 }
+
+// GetResidentUnit handles GET /api/v1/users/units/:profile_id
+// @Summary Get resident unit
+// @Description Get the house a resident profile occupies
+// @Tags users
+// @Produce json
+// @Param profile_id path int true "Profile ID"
+// @Success 200 {object} utils.APIResponse{data=models.ResidentUnit} "Resident unit retrieved"
+// @Failure 400 {object} utils.APIResponse "Invalid profile ID"
+// @Failure 404 {object} utils.APIResponse "Resident unit not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/users/units/{profile_id} [get]
+func (h *UserHandler) GetResidentUnit(c *gin.Context) {
+	profileID, err := strconv.ParseUint(c.Param("profile_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid profile ID", err)
+		return
+	}
+
+	unit, err := h.userService.GetResidentUnit(uint(profileID))
+	if err != nil {
+		if err.Error() == "resident unit not found" {
+			utils.NotFoundResponse(c, "Resident unit not found")
+			return
+		}
+		h.logger.WithError(err).WithField("profile_id", profileID).Error("Failed to get resident unit")
+		utils.InternalServerErrorResponse(c, "Failed to get resident unit", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Resident unit retrieved", unit)
+}
+
+// SaveResidentUnit handles PUT /api/v1/users/units/:profile_id
+// @Summary Save resident unit
+// @Description Create or replace the house a resident profile occupies, printed on payment receipts, its occupancy dates, and the house type, cluster and land area its tariffs are chosen by. Billings are generated only for the months it is occupied, the first and last prorated by the billing proration policy.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param profile_id path int true "Profile ID"
+// @Param request body service.ResidentUnitRequest true "Resident unit"
+// @Success 200 {object} utils.APIResponse{data=models.ResidentUnit} "Resident unit saved"
+// @Failure 400 {object} utils.APIResponse "Invalid resident unit"
+// @Failure 404 {object} utils.APIResponse "Profile not found"
+// @Failure 500 {object} utils.APIResponse "Internal server error"
+// @Router /api/v1/users/units/{profile_id} [put]
+func (h *UserHandler) SaveResidentUnit(c *gin.Context) {
+	profileID, err := strconv.ParseUint(c.Param("profile_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid profile ID", err)
+		return
+	}
+
+	var request service.ResidentUnitRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.BadRequestResponse(c, "house_number is required", err)
+		return
+	}
+
+	unit, err := h.userService.SaveResidentUnit(uint(profileID), &request)
+	if err != nil {
+		h.logger.WithError(err).WithField("profile_id", profileID).Error("Failed to save resident unit")
+
+		switch {
+		case errors.Is(err, service.ErrInvalidResidentUnit):
+			utils.BadRequestResponse(c, "Invalid resident unit", err)
+		case err.Error() == "profile not found":
+			utils.NotFoundResponse(c, "Profile not found")
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to save resident unit", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, "Resident unit saved", unit)
+}
@@ -17,6 +17,11 @@ type Billing struct {
 	CreatedByID *int       `json:"created_by_id"`
 	UpdatedByID *int       `json:"updated_by_id"`
 	Locale      *string    `json:"locale"`
+
+	// Read only, joined from billing_sources and billing_details by the queries that need it
+	SettingBillingID *uint      `json:"setting_billing_id,omitempty" gorm:"->;column:setting_billing_id"`
+	NamaBilling      *string    `json:"nama_billing,omitempty" gorm:"->;column:nama_billing"`
+	DueDate          *time.Time `json:"due_date,omitempty" gorm:"->;column:due_date"`
 }
 
 // TableName sets the insert table name for Billing
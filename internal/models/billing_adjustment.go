@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// Billing adjustment types
+const (
+	AdjustmentTypeDiscount   = "discount"   // Lowers the amount due, e.g. 50% for an empty house
+	AdjustmentTypeWaiver     = "waiver"     // Writes off the amount due, e.g. for RT officials
+	AdjustmentTypeSurcharge  = "surcharge"  // Raises the amount due
+	AdjustmentTypeCorrection = "correction" // Fixes a billing after generation, either way
+)
+
+// Billing adjustment statuses. Only approved adjustments change the amount due.
+const (
+	AdjustmentStatusPending  = "pending"
+	AdjustmentStatusApproved = "approved"
+	AdjustmentStatusRejected = "rejected"
+)
+
+// BillingAdjustment represents the billing_adjustments table, a change to the amount due of a
+// billing on top of its nominal. It takes effect once another admin approves it; adjustments of
+// a discount rule are approved when generated.
+type BillingAdjustment struct {
+	ID             uint       `json:"id" gorm:"primarykey"`
+	BillingID      uint       `json:"billing_id" gorm:"column:billing_id;index"`
+	Type           string     `json:"type" gorm:"column:type;size:16"`
+	Amount         int64      `json:"amount" gorm:"column:amount"` // Change to the amount due: negative for discounts and waivers
+	Reason         string     `json:"reason" gorm:"column:reason;type:text"`
+	Status         string     `json:"status" gorm:"column:status;size:16;index"`
+	DiscountRuleID *uint      `json:"discount_rule_id" gorm:"column:discount_rule_id;index"` // Rule the adjustment was generated from
+	CreatedByID    uint       `json:"created_by_id" gorm:"column:created_by_id"`
+	ReviewedByID   *uint      `json:"reviewed_by_id" gorm:"column:reviewed_by_id"` // Admin who approved or rejected it
+	ReviewedAt     *time.Time `json:"reviewed_at" gorm:"column:reviewed_at"`
+	ReviewNote     string     `json:"review_note,omitempty" gorm:"column:review_note;type:text"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName sets the insert table name for BillingAdjustment
+func (BillingAdjustment) TableName() string {
+	return "billing_adjustments"
+}
+
+// BillingDiscountRule represents the billing_discount_rules table, a recurring discount of a
+// resident applied to the billings generated for them in its periods
+type BillingDiscountRule struct {
+	ID               uint      `json:"id" gorm:"primarykey"`
+	Name             string    `json:"name" gorm:"column:name;size:128"`
+	UserID           uint      `json:"user_id" gorm:"column:user_id;index"`
+	SettingBillingID *uint     `json:"setting_billing_id" gorm:"column:setting_billing_id"`  // Null applies to every setting billing
+	DiscountType     string    `json:"discount_type" gorm:"column:discount_type;size:16"`    // flat or percentage
+	Value            int64     `json:"value" gorm:"column:value"`                            // Rupiah for flat, basis points for percentage (10000 exempts)
+	FromMonth        string    `json:"from_month,omitempty" gorm:"column:from_month;size:7"` // First billing month, YYYY-MM; empty is open
+	ToMonth          string    `json:"to_month,omitempty" gorm:"column:to_month;size:7"`     // Last billing month, YYYY-MM; empty is open
+	IsActive         bool      `json:"is_active" gorm:"column:is_active;not null;default:true"`
+	CreatedByID      uint      `json:"created_by_id" gorm:"column:created_by_id"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName sets the insert table name for BillingDiscountRule
+func (BillingDiscountRule) TableName() string {
+	return "billing_discount_rules"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// BillingCharge represents the billing_charges table, a one-off charge billed to a set of
+// residents outside the setting billings. Each resident gets a billing whose source records the
+// charge.
+type BillingCharge struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	NamaBilling string    `json:"nama_billing" gorm:"column:nama_billing;size:128"`
+	Nominal     int64     `json:"nominal" gorm:"column:nominal"`
+	Bulan       int       `json:"bulan" gorm:"column:bulan"`
+	Tahun       int       `json:"tahun" gorm:"column:tahun"`
+	DueDate     time.Time `json:"due_date" gorm:"column:due_date;type:date"`
+	Keterangan  string    `json:"keterangan,omitempty" gorm:"column:keterangan;type:text"`
+	CreatedByID uint      `json:"created_by_id" gorm:"column:created_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName sets the insert table name for BillingCharge
+func (BillingCharge) TableName() string {
+	return "billing_charges"
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// BillingDetail represents the billing_details table, which holds what this service tracks about
+// a billing beyond the Strapi columns. Billings without a due date predate this service; they are
+// shown due on the configured due day but never charged denda.
+// A billing cancelled through this service is unpublished and keeps its row as the record of it.
+type BillingDetail struct {
+	ID            uint       `json:"id" gorm:"primarykey"`
+	BillingID     uint       `json:"billing_id" gorm:"column:billing_id;uniqueIndex"`
+	DueDate       *time.Time `json:"due_date" gorm:"column:due_date;type:date"` // Last day the billing can be paid without denda
+	Notes         string     `json:"notes,omitempty" gorm:"column:notes;type:text"`
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty" gorm:"column:cancelled_at"`
+	CancelledByID *uint      `json:"cancelled_by_id,omitempty" gorm:"column:cancelled_by_id"`
+	CancelReason  string     `json:"cancel_reason,omitempty" gorm:"column:cancel_reason;type:text"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName sets the insert table name for BillingDetail
+func (BillingDetail) TableName() string {
+	return "billing_details"
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// BillingPenalty represents the billing_penalties table, the late fees (denda) assessed on an
+// overdue billing. A billing gets at most one penalty for each month it is overdue; MonthsLate
+// numbers them from 1, the month starting the day after the due date.
+type BillingPenalty struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	BillingID   uint       `json:"billing_id" gorm:"column:billing_id;uniqueIndex:idx_billing_penalties_month"`
+	MonthsLate  int        `json:"months_late" gorm:"column:months_late;uniqueIndex:idx_billing_penalties_month"`
+	Amount      int64      `json:"amount" gorm:"column:amount"`
+	DueDate     time.Time  `json:"due_date" gorm:"column:due_date;type:date"` // Due date the penalty was assessed against
+	WaivedAt    *time.Time `json:"waived_at" gorm:"column:waived_at"`
+	WaivedByID  *uint      `json:"waived_by_id" gorm:"column:waived_by_id"`
+	WaiveReason string     `json:"waive_reason,omitempty" gorm:"column:waive_reason;type:text"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName sets the insert table name for BillingPenalty
+func (BillingPenalty) TableName() string {
+	return "billing_penalties"
+}
+
+// OverdueBilling is an unpaid published billing past its due date, with the penalties it
+// already has
+type OverdueBilling struct {
+	BillingID     uint      `json:"billing_id" gorm:"column:billing_id"`
+	Nominal       int64     `json:"nominal" gorm:"column:nominal"`
+	DueDate       time.Time `json:"due_date" gorm:"column:due_date"`
+	MonthsCharged int       `json:"months_charged" gorm:"column:months_charged"` // Highest MonthsLate assessed, waived or not
+	PenaltyTotal  int64     `json:"penalty_total" gorm:"column:penalty_total"`   // Sum of the penalties assessed, waived or not
+}
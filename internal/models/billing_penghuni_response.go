@@ -12,8 +12,21 @@ type BillingPenghuniResponse struct {
 	RoleName       string `json:"role_name" example:"Penghuni"`               // Role name
 	RoleType       string `json:"role_type" example:"penghuni"`              // Role type
 	Username       string `json:"username" example:"john_doe"`               // Username
-	Nominal        int64  `json:"nominal" example:"500000"`                  // Total nominal amount (summed per billing period)
+	Nominal        int64  `json:"nominal" example:"500000"`                  // Total nominal after approved adjustments (summed per billing period)
 	StatusBilling  string `json:"status_billing" example:"Belum Dibayar"`    // Billing status
 	Bulan          string `json:"bulan" example:"November"`                  // Month name
 	Tahun          int    `json:"tahun" example:"2025"`                      // Year
+	Components     []*BillingComponent `json:"components"`                   // Billings summed into Nominal, one per setting billing
+}
+
+// BillingComponent is one billing of a billing period, e.g. "Keamanan" or "Kebersihan"
+type BillingComponent struct {
+	BillingID        uint   `json:"billing_id" example:"12"`
+	SettingBillingID *uint  `json:"setting_billing_id" example:"3"` // Null for billings generated before settings were tracked
+	NamaBilling      string `json:"nama_billing" example:"Keamanan"`
+	Nominal          int64  `json:"nominal" example:"100000"`
+	Adjustment       int64  `json:"adjustment" example:"-50000"` // Sum of the approved adjustments
+	LateFee          int64  `json:"late_fee" example:"0"`        // Sum of the unwaived denda
+	AmountDue        int64  `json:"amount_due" example:"50000"`  // Nominal plus adjustment and late fee
+	StatusBilling    string `json:"status_billing" example:"Belum Dibayar"`
 }
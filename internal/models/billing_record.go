@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// BillingRecord is a billing with its resident, status, kategori transaksi and source, as the
+// billing API lists it
+type BillingRecord struct {
+	ID          uint       `json:"id" gorm:"column:id"`
+	DocumentID  *string    `json:"document_id" gorm:"column:document_id"`
+	Bulan       *int       `json:"bulan" gorm:"column:bulan"`
+	Tahun       *int       `json:"tahun" gorm:"column:tahun"`
+	Nominal     int64      `json:"nominal" gorm:"column:nominal"`
+	DueDate     *time.Time `json:"due_date" gorm:"column:due_date"` // The configured due day of the period when the billing has no detail row
+	Notes       string     `json:"notes,omitempty" gorm:"column:notes"`
+	CreatedAt   *time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   *time.Time `json:"updated_at" gorm:"column:updated_at"`
+	PublishedAt *time.Time `json:"published_at" gorm:"column:published_at"` // Null once cancelled
+
+	UserID       *uint  `json:"user_id" gorm:"column:user_id"`
+	Username     string `json:"username,omitempty" gorm:"column:username"`
+	Email        string `json:"email,omitempty" gorm:"column:email"`
+	NamaPenghuni string `json:"nama_penghuni,omitempty" gorm:"column:nama_penghuni"`
+
+	StatusID            *uint  `json:"status_id" gorm:"column:status_id"`
+	StatusName          string `json:"status_name" gorm:"column:status_name"`
+	KategoriTransaksiID *uint  `json:"kategori_transaksi_id" gorm:"column:kategori_transaksi_id"`
+	KategoriTransaksi   string `json:"kategori_transaksi,omitempty" gorm:"column:kategori_transaksi"`
+
+	SettingBillingID *uint  `json:"setting_billing_id,omitempty" gorm:"column:setting_billing_id"` // Setting the billing was generated from
+	ChargeID         *uint  `json:"charge_id,omitempty" gorm:"column:charge_id"`                   // One-off charge it bills
+	NamaBilling      string `json:"nama_billing,omitempty" gorm:"column:nama_billing"`
+
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty" gorm:"column:cancelled_at"`
+	CancelledByID *uint      `json:"cancelled_by_id,omitempty" gorm:"column:cancelled_by_id"`
+	CancelReason  string     `json:"cancel_reason,omitempty" gorm:"column:cancel_reason"`
+}
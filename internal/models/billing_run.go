@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// Billing run statuses
+const (
+	BillingRunRunning   = "running"
+	BillingRunSucceeded = "succeeded"
+	BillingRunFailed    = "failed"
+)
+
+// BillingRun represents the billing_runs table, the history of scheduled billing generation and
+// late fee assessment runs
+type BillingRun struct {
+	ID            uint       `json:"id" gorm:"primarykey"`
+	Kind          string     `json:"kind" gorm:"column:kind;size:32;index:idx_billing_runs_period"` // What was generated, e.g. "bulanan" or "denda"
+	Bulan         int        `json:"bulan" gorm:"column:bulan;index:idx_billing_runs_period"`
+	Tahun         int        `json:"tahun" gorm:"column:tahun;index:idx_billing_runs_period"`
+	Status        string     `json:"status" gorm:"column:status;size:16;index"`
+	CreatedCount  int        `json:"created_count" gorm:"column:created_count"`
+	ReplacedCount int        `json:"replaced_count" gorm:"column:replaced_count"`
+	SkippedCount  int        `json:"skipped_count" gorm:"column:skipped_count"`
+	FailedCount   int        `json:"failed_count" gorm:"column:failed_count"`
+	Error         string     `json:"error,omitempty" gorm:"column:error;type:text"`
+	StartedAt     time.Time  `json:"started_at" gorm:"column:started_at"`
+	FinishedAt    *time.Time `json:"finished_at" gorm:"column:finished_at"`
+}
+
+// TableName sets the insert table name for BillingRun
+func (BillingRun) TableName() string {
+	return "billing_runs"
+}
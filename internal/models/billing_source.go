@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// BillingSource represents the billing_sources table, which records the setting_billings row or
+// one-off charge a generated billing was created from and how its nominal was calculated. Its
+// unique key stops a billing period from being generated twice for the same resident and setting
+// or charge.
+type BillingSource struct {
+	ID               uint      `json:"id" gorm:"primarykey"`
+	BillingID        uint      `json:"billing_id" gorm:"column:billing_id;uniqueIndex"`
+	UserID           uint      `json:"user_id" gorm:"column:user_id;uniqueIndex:idx_billing_sources_period"`
+	Bulan            int       `json:"bulan" gorm:"column:bulan;uniqueIndex:idx_billing_sources_period"`
+	Tahun            int       `json:"tahun" gorm:"column:tahun;uniqueIndex:idx_billing_sources_period"`
+	SettingBillingID uint      `json:"setting_billing_id" gorm:"column:setting_billing_id;uniqueIndex:idx_billing_sources_period"` // 0 for a one-off charge
+	ChargeID         uint      `json:"charge_id,omitempty" gorm:"column:charge_id;not null;default:0;uniqueIndex:idx_billing_sources_period"`
+	NamaBilling      string    `json:"nama_billing" gorm:"column:nama_billing"`                  // Name of the setting when the billing was generated
+	TariffID         *uint     `json:"tariff_id" gorm:"column:tariff_id"`                        // Tariff that priced the billing; null for the setting nominal
+	TariffName       string    `json:"tariff_name,omitempty" gorm:"column:tariff_name;size:128"` // Name of the tariff when the billing was generated
+	BaseNominal      int64     `json:"base_nominal" gorm:"column:base_nominal"`                  // Nominal of a full month, before proration
+	Proration        string    `json:"proration,omitempty" gorm:"column:proration;size:16"`
+	OccupiedDays     int       `json:"occupied_days,omitempty" gorm:"column:occupied_days"` // Days of the month the unit was occupied, when prorated
+	DaysInMonth      int       `json:"days_in_month,omitempty" gorm:"column:days_in_month"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName sets the insert table name for BillingSource
+func (BillingSource) TableName() string {
+	return "billing_sources"
+}
+
+// PeriodBilling is a published or cancelled billing of a resident in a billing period, with the
+// setting it was generated from if it is known
+type PeriodBilling struct {
+	BillingID        uint   `json:"billing_id" gorm:"column:billing_id"`
+	UserID           uint   `json:"user_id" gorm:"column:user_id"`
+	SettingBillingID *uint  `json:"setting_billing_id" gorm:"column:setting_billing_id"`
+	Nominal          int64  `json:"nominal" gorm:"column:nominal"`
+	StatusName       string `json:"status_name" gorm:"column:status_name"`
+	Cancelled        bool   `json:"cancelled" gorm:"column:cancelled"`
+}
+
+// GeneratedBilling is a billing written by bulk generation with the rows that go with it
+type GeneratedBilling struct {
+	Billing     *Billing
+	Source      *BillingSource
+	Detail      *BillingDetail
+	Adjustments []*BillingAdjustment // Approved adjustments of the resident's discount rules
+	StatusID    uint
+}
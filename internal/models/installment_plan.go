@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// Installment plan statuses
+const (
+	InstallmentPlanActive    = "active"
+	InstallmentPlanCompleted = "completed"
+)
+
+// Installment part statuses
+const (
+	InstallmentPartUnpaid = "unpaid"
+	InstallmentPartPaid   = "paid"
+)
+
+// InstallmentPlan represents the installment_plans table, an admin-defined schedule that splits
+// the outstanding balance of a resident's overdue billings into parts
+type InstallmentPlan struct {
+	ID          uint               `json:"id" gorm:"primarykey"`
+	UserID      uint               `json:"user_id" gorm:"column:user_id;index"`
+	BillingKey  string             `json:"billing_key" gorm:"column:billing_key"` // Sorted, comma separated billing IDs
+	TotalAmount int64              `json:"total_amount" gorm:"column:total_amount"`
+	Status      string             `json:"status" gorm:"column:status;size:16;index"`
+	Notes       string             `json:"notes,omitempty" gorm:"column:notes;type:text"`
+	CreatedByID uint               `json:"created_by_id" gorm:"column:created_by_id"`
+	Parts       []*InstallmentPart `json:"parts" gorm:"foreignKey:PlanID"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// TableName sets the insert table name for InstallmentPlan
+func (InstallmentPlan) TableName() string {
+	return "installment_plans"
+}
+
+// InstallmentPart represents the installment_parts table, one scheduled payment of a plan
+type InstallmentPart struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	PlanID    uint       `json:"plan_id" gorm:"column:plan_id;index"`
+	Sequence  int        `json:"sequence" gorm:"column:sequence"`
+	Amount    int64      `json:"amount" gorm:"column:amount"`
+	DueDate   time.Time  `json:"due_date" gorm:"column:due_date;type:date"`
+	Status    string     `json:"status" gorm:"column:status;size:16"`
+	PaidAt    *time.Time `json:"paid_at" gorm:"column:paid_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName sets the insert table name for InstallmentPart
+func (InstallmentPart) TableName() string {
+	return "installment_parts"
+}
+
+// InstallmentPlanBillingLink represents the installment_plans_billing_lnk table
+type InstallmentPlanBillingLink struct {
+	ID        uint `json:"id" gorm:"primarykey"`
+	PlanID    uint `json:"plan_id" gorm:"column:plan_id;index"`
+	BillingID uint `json:"t_billing_id" gorm:"column:t_billing_id;index"`
+}
+
+// TableName sets the insert table name for InstallmentPlanBillingLink
+func (InstallmentPlanBillingLink) TableName() string {
+	return "installment_plans_billing_lnk"
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Fee rule types
+const (
+	FeeTypeFlat       = "flat"
+	FeeTypePercentage = "percentage"
+)
+
+// PaymentFeeRule represents the payment_fee_rules table, a convenience fee passed on to residents
+// who pay with a given payment method type
+type PaymentFeeRule struct {
+	ID                uint      `json:"id" gorm:"primarykey"`
+	PaymentMethodType string    `json:"payment_method_type" gorm:"column:payment_method_type;size:64;uniqueIndex"` // DOKU payment method type, e.g. CREDIT_CARD
+	FeeType           string    `json:"fee_type" gorm:"column:fee_type;size:16"`
+	Value             int64     `json:"value" gorm:"column:value"`     // Rupiah for flat fees, basis points (1/100 %) for percentage fees
+	MaxFee            *int64    `json:"max_fee" gorm:"column:max_fee"` // Optional cap of a percentage fee
+	IsActive          bool      `json:"is_active" gorm:"column:is_active;not null;default:true"`
+	Description       string    `json:"description,omitempty" gorm:"column:description"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// TableName sets the insert table name for PaymentFeeRule
+func (PaymentFeeRule) TableName() string {
+	return "payment_fee_rules"
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// PaymentRefund represents the payment_refunds table, the history of money returned on a paid
+// payment transaction
+type PaymentRefund struct {
+	ID                   uint                    `json:"id" gorm:"primarykey"`
+	PaymentTransactionID uint                    `json:"payment_transaction_id" gorm:"column:payment_transaction_id;index"`
+	Amount               int64                   `json:"amount" gorm:"column:amount"`
+	Reason               string                  `json:"reason" gorm:"column:reason;type:text"`
+	ReopenedBillingKey   string                  `json:"reopened_billing_key" gorm:"column:reopened_billing_key"` // Sorted, comma separated billing IDs set back to unpaid
+	RefundedByID         uint                    `json:"refunded_by_id" gorm:"column:refunded_by_id"`
+	CreatedAt            time.Time               `json:"created_at"`
+	Billings             []*PaymentRefundBilling `json:"billings,omitempty" gorm:"foreignKey:PaymentRefundID"` // What the refund took back from each billing
+}
+
+// TableName sets the insert table name for PaymentRefund
+func (PaymentRefund) TableName() string {
+	return "payment_refunds"
+}
+
+// PaymentRefundBilling represents the payment_refund_billings table, the part of a billing's
+// allocation on a payment transaction that a refund reversed. The allocation itself is kept as
+// the payment history; a billing's paid amount is its allocations minus these reversals.
+type PaymentRefundBilling struct {
+	ID                   uint  `json:"id" gorm:"primarykey"`
+	PaymentRefundID      uint  `json:"payment_refund_id" gorm:"column:payment_refund_id;index"`
+	PaymentTransactionID uint  `json:"payment_transaction_id" gorm:"column:payment_transaction_id;index:idx_payment_refund_billings_link"`
+	BillingID            uint  `json:"billing_id" gorm:"column:billing_id;index:idx_payment_refund_billings_link"`
+	Amount               int64 `json:"amount" gorm:"column:amount"`
+}
+
+// TableName sets the insert table name for PaymentRefundBilling
+func (PaymentRefundBilling) TableName() string {
+	return "payment_refund_billings"
+}
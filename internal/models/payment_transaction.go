@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// Payment transaction statuses
+const (
+	PaymentStatusPending = "pending"
+	PaymentStatusPaid    = "paid"
+	PaymentStatusExpired = "expired"
+	PaymentStatusFailed  = "failed"
+
+	// PaymentStatusCancelled marks a pending checkout cancelled by an admin
+	PaymentStatusCancelled = "cancelled"
+	// PaymentStatusRefunded marks a paid transaction whose whole amount was refunded
+	PaymentStatusRefunded = "refunded"
+)
+
+// PaymentTransaction represents the payment_transactions table
+type PaymentTransaction struct {
+	ID                uint       `json:"id" gorm:"primarykey"`
+	InvoiceNumber     string     `json:"invoice_number" gorm:"column:invoice_number;size:64;uniqueIndex"`
+	IdempotencyKey    *string    `json:"idempotency_key,omitempty" gorm:"column:idempotency_key;size:128;uniqueIndex"`
+	BillingKey        string     `json:"billing_key" gorm:"column:billing_key;index"` // Sorted, comma separated billing IDs
+	Description       string     `json:"description" gorm:"column:description"`
+	RequestID         string     `json:"request_id" gorm:"column:request_id;size:64"`
+	TokenID           string     `json:"token_id" gorm:"column:token_id"`
+	SessionID         string     `json:"session_id" gorm:"column:session_id"`
+	PaymentURL        string     `json:"payment_url" gorm:"column:payment_url"`
+	Gateway           string     `json:"gateway" gorm:"column:gateway;size:32"`
+	PaymentMethod     string     `json:"payment_method,omitempty" gorm:"column:payment_method;size:64"` // Offline method or the gateway payment method type the checkout is limited to
+	ReferenceNumber   string     `json:"reference_number,omitempty" gorm:"column:reference_number;size:128"`
+	ReceiptPath       string     `json:"receipt_path,omitempty" gorm:"column:receipt_path"`
+	Notes             string     `json:"notes,omitempty" gorm:"column:notes;type:text"`
+	RecordedByID      *uint      `json:"recorded_by_id,omitempty" gorm:"column:recorded_by_id"` // Admin who recorded an offline payment
+	InstallmentPartID *uint      `json:"installment_part_id,omitempty" gorm:"column:installment_part_id;index"`
+	Status            string     `json:"status" gorm:"column:status;size:16;index"`
+	Amount            int64      `json:"amount" gorm:"column:amount"`                            // Billing amounts plus FeeAmount
+	FeeAmount         int64      `json:"fee_amount" gorm:"column:fee_amount;not null;default:0"` // Convenience fee charged for the payment method
+	RefundedAmount    int64      `json:"refunded_amount" gorm:"column:refunded_amount;not null;default:0"`
+	ExpiredAt         *time.Time `json:"expired_at" gorm:"column:expired_at"`
+	PaidAt            *time.Time `json:"paid_at" gorm:"column:paid_at"`
+	RawResponse       string     `json:"raw_response,omitempty" gorm:"column:raw_response;type:text"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// TableName sets the insert table name for PaymentTransaction
+func (PaymentTransaction) TableName() string {
+	return "payment_transactions"
+}
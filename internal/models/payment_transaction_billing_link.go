@@ -0,0 +1,17 @@
+package models
+
+// PaymentTransactionBillingLink represents the payment_transactions_billing_lnk table
+type PaymentTransactionBillingLink struct {
+	ID                   uint `json:"id" gorm:"primarykey"`
+	PaymentTransactionID uint `json:"payment_transaction_id" gorm:"column:payment_transaction_id;index"`
+	BillingID            uint `json:"t_billing_id" gorm:"column:t_billing_id;index"`
+
+	// Amount is the part of the billing paid by the transaction. Links written before partial
+	// payments existed have none and cover the billing's whole nominal.
+	Amount *int64 `json:"amount" gorm:"column:amount"`
+}
+
+// TableName sets the insert table name for PaymentTransactionBillingLink
+func (PaymentTransactionBillingLink) TableName() string {
+	return "payment_transactions_billing_lnk"
+}
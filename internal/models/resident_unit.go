@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// ResidentUnit represents the resident_units table, the house a resident profile occupies, since
+// when, and what decides its tariffs
+type ResidentUnit struct {
+	ID            uint       `json:"id" gorm:"primarykey"`
+	ProfileID     uint       `json:"profile_id" gorm:"column:profile_id;uniqueIndex"`
+	HouseNumber   string     `json:"house_number" gorm:"column:house_number;size:32"`       // Block and number, e.g. "A-12"
+	HouseType     string     `json:"house_type,omitempty" gorm:"column:house_type;size:16"` // Building type, e.g. "36", "45" or "72"
+	Cluster       string     `json:"cluster,omitempty" gorm:"column:cluster;size:64"`
+	LandArea      int        `json:"land_area,omitempty" gorm:"column:land_area"`           // m²; 0 if unknown
+	OccupiedFrom  *time.Time `json:"occupied_from" gorm:"column:occupied_from;type:date"`   // Move-in day; null if unknown
+	OccupiedUntil *time.Time `json:"occupied_until" gorm:"column:occupied_until;type:date"` // Last day before moving out; null while occupied
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName sets the insert table name for ResidentUnit
+func (ResidentUnit) TableName() string {
+	return "resident_units"
+}
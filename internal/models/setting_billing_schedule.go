@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Kinds of setting billings (setting_billings.jenis_billing) that billings are generated from
+const (
+	JenisBillingBulanan = "bulanan" // Billed every month
+	JenisBillingTahunan = "tahunan" // Billed once a year, in the month of its schedule
+)
+
+// SettingBillingSchedule represents the setting_billing_schedules table, which holds the month a
+// yearly (tahunan) setting billing is generated in. Yearly settings without a schedule are not
+// generated.
+type SettingBillingSchedule struct {
+	ID               uint      `json:"id" gorm:"primarykey"`
+	SettingBillingID uint      `json:"setting_billing_id" gorm:"column:setting_billing_id;uniqueIndex"`
+	BillingMonth     int       `json:"billing_month" gorm:"column:billing_month"` // 1 to 12
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	// Read only, joined from setting_billings by the queries that need it
+	NamaBilling *string `json:"nama_billing,omitempty" gorm:"->;column:nama_billing"`
+}
+
+// TableName sets the insert table name for SettingBillingSchedule
+func (SettingBillingSchedule) TableName() string {
+	return "setting_billing_schedules"
+}
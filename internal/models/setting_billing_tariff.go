@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// Tariff pricing types
+const (
+	TariffPricingFlat   = "flat"   // Amount for every matching unit
+	TariffPricingPerM2  = "per_m2" // Amount per square metre of land
+	TariffPricingTiered = "tiered" // Amount of the land area bracket the unit falls in
+)
+
+// TariffTier is a land area bracket of a tiered tariff
+type TariffTier struct {
+	UpToArea int   `json:"up_to_area" example:"120"` // Largest land area of the bracket in m²; 0 leaves the last bracket open
+	Amount   int64 `json:"amount" example:"150000"`
+}
+
+// SettingBillingTariff represents the setting_billing_tariffs table, a price of a setting billing
+// for the units it applies to. The first active tariff of a setting, by priority, whose filters
+// match a resident's unit prices the billing; units no tariff matches pay the setting nominal.
+type SettingBillingTariff struct {
+	ID               uint         `json:"id" gorm:"primarykey"`
+	SettingBillingID uint         `json:"setting_billing_id" gorm:"column:setting_billing_id;index"`
+	Name             string       `json:"name" gorm:"column:name;size:128"`
+	Priority         int          `json:"priority" gorm:"column:priority"`                       // Lower is tried first
+	HouseType        string       `json:"house_type,omitempty" gorm:"column:house_type;size:16"` // e.g. "36"; empty matches any
+	Cluster          string       `json:"cluster,omitempty" gorm:"column:cluster;size:64"`       // Empty matches any
+	MinLandArea      *int         `json:"min_land_area" gorm:"column:min_land_area"`             // m², inclusive
+	MaxLandArea      *int         `json:"max_land_area" gorm:"column:max_land_area"`             // m², inclusive
+	PricingType      string       `json:"pricing_type" gorm:"column:pricing_type;size:16"`
+	Amount           int64        `json:"amount" gorm:"column:amount"` // Rupiah for flat, rupiah per m² for per_m2
+	Tiers            []TariffTier `json:"tiers,omitempty" gorm:"column:tiers;type:text;serializer:json"`
+	IsActive         bool         `json:"is_active" gorm:"column:is_active;not null;default:true"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+// TableName sets the insert table name for SettingBillingTariff
+func (SettingBillingTariff) TableName() string {
+	return "setting_billing_tariffs"
+}
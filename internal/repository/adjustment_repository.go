@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"time"
+
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AdjustmentRepository defines the interface for billing adjustment data operations
+type AdjustmentRepository interface {
+	Create(adjustment *models.BillingAdjustment) error
+	GetByID(id uint) (*models.BillingAdjustment, error)
+	GetByBillingID(billingID uint) ([]*models.BillingAdjustment, error)
+	Review(adjustment *models.BillingAdjustment, billingStatusID uint) (bool, error)
+}
+
+// adjustmentRepository implements AdjustmentRepository
+type adjustmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAdjustmentRepository creates a new instance of AdjustmentRepository
+func NewAdjustmentRepository(db *gorm.DB) AdjustmentRepository {
+	return &adjustmentRepository{
+		db: db,
+	}
+}
+
+// Create creates a new billing adjustment
+func (r *adjustmentRepository) Create(adjustment *models.BillingAdjustment) error {
+	return r.db.Create(adjustment).Error
+}
+
+// GetByID retrieves a billing adjustment by ID
+func (r *adjustmentRepository) GetByID(id uint) (*models.BillingAdjustment, error) {
+	var adjustment models.BillingAdjustment
+	err := r.db.First(&adjustment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+// GetByBillingID retrieves the adjustments of a billing, oldest first
+func (r *adjustmentRepository) GetByBillingID(billingID uint) ([]*models.BillingAdjustment, error) {
+	var adjustments []*models.BillingAdjustment
+	err := r.db.Where("billing_id = ?", billingID).Order("id ASC").Find(&adjustments).Error
+	if err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+// Review records the outcome of a pending adjustment: its status, reviewer, review time and note.
+// An approved adjustment also moves its billing to billingStatusID. It returns false, writing
+// nothing, when the adjustment was no longer pending.
+func (r *adjustmentRepository) Review(adjustment *models.BillingAdjustment, billingStatusID uint) (bool, error) {
+	var reviewed bool
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.BillingAdjustment{}).
+			Where("id = ? AND status = ?", adjustment.ID, models.AdjustmentStatusPending).
+			Updates(map[string]interface{}{
+				"status":         adjustment.Status,
+				"reviewed_by_id": adjustment.ReviewedByID,
+				"reviewed_at":    adjustment.ReviewedAt,
+				"review_note":    adjustment.ReviewNote,
+				"updated_at":     time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		reviewed = true
+
+		if adjustment.Status != models.AdjustmentStatusApproved {
+			return nil
+		}
+		if err := setBillingStatus(tx, adjustment.BillingID, billingStatusID); err != nil {
+			return err
+		}
+		return tx.Model(&models.Billing{}).Where("id = ?", adjustment.BillingID).Update("updated_at", time.Now()).Error
+	})
+
+	return reviewed, err
+}
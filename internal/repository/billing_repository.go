@@ -1,19 +1,67 @@
 package repository
 
 import (
+	"fmt"
+	"time"
+
 	"ipl-be-svc/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BillingRepository defines the interface for billing data operations
 type BillingRepository interface {
 	GetBillingByID(id uint) (*models.Billing, error)
 	GetUsersWithPenghuniRole() ([]*models.User, error)
-	GetActiveMonthlySettingBillings() ([]*models.SettingBilling, error)
+	GetActiveSettingBillingsForMonth(month int) ([]*models.SettingBilling, error)
+	GetSettingBillingByID(id uint) (*models.SettingBilling, error)
 	CreateBulkBillings(billings []*models.Billing) error
 	CreateBulkBillingProfileLinks(links []*models.BillingProfileLink) error
 	GetBillingPenghuni() ([]*models.BillingPenghuniResponse, error)
+	GetStatusByName(statusName string) (*models.MasterGeneralStatus, error)
+	UpdateBillingsStatus(billingIDs []uint, statusID uint) error
+	GetBillingOwner(billingID uint) (*models.UserDetail, error)
+	GetBillingStatusNames(billingIDs []uint) (map[uint]string, error)
+	GetUnpaidBillingIDsByUser(userID uint, paidStatusName string, fromPeriod, toPeriod int) ([]uint, error)
+	WithGenerationLock(lockKey string, fn func() error) error
+	GetUsersWithProfile(userIDs []uint) ([]*models.User, error)
+	GetUserUnits(userIDs []uint) (map[uint]*models.ResidentUnit, error)
+	GetPeriodBillings(userIDs []uint, month, year int) ([]*models.PeriodBilling, error)
+	CreateGeneratedBillings(generated []*models.GeneratedBilling) error
+	CreateBillingCharge(charge *models.BillingCharge, generated []*models.GeneratedBilling) error
+	UpdateGeneratedBillings(replacements []*models.GeneratedBilling) error
+	GetPenaltyTotals(billingIDs []uint) (map[uint]int64, error)
+	GetAdjustmentTotals(billingIDs []uint) (map[uint]int64, error)
+	ListBillings(filter *BillingFilter, limit, offset int) ([]*models.BillingRecord, int64, error)
+	GetBillingRecord(id uint, dueDay int) (*models.BillingRecord, error)
+	UpdateBilling(billingID uint, nominal int64, detail *models.BillingDetail, statusID uint) error
+	CancelBilling(detail *models.BillingDetail) error
+}
+
+// BillingFilter selects the billings ListBillings returns. Nil and empty fields do not filter.
+type BillingFilter struct {
+	UserID      *uint
+	Month       *int
+	Year        *int
+	StatusName  string
+	CategoryID  *uint
+	CreatedFrom *time.Time // Inclusive
+	CreatedTo   *time.Time // Exclusive
+	DueFrom     *time.Time // Inclusive
+	DueTo       *time.Time // Inclusive
+	Cancelled   bool       // Cancelled billings instead of the published ones
+	DueDay      int        // Due day of the billings without a detail row
+	Sort        string     // created_at (default), due_date, nominal or period
+	Descending  bool
+}
+
+// billingSortColumns maps the BillingFilter sort fields to the columns they order by
+var billingSortColumns = map[string]string{
+	"created_at": "b.created_at",
+	"due_date":   "due_date",
+	"nominal":    "nominal",
+	"period":     "b.tahun %[1]s, b.bulan",
 }
 
 // billingRepository implements BillingRepository
@@ -32,7 +80,11 @@ func NewBillingRepository(db *gorm.DB) BillingRepository {
 func (r *billingRepository) GetBillingByID(id uint) (*models.Billing, error) {
 	var billing models.Billing
 
-	err := r.db.Where("id = ?", id).First(&billing).Error
+	err := r.db.Select("billings.*, bs.setting_billing_id, bs.nama_billing, bd.due_date").
+		Joins("LEFT JOIN billing_sources bs ON bs.billing_id = billings.id").
+		Joins("LEFT JOIN billing_details bd ON bd.billing_id = billings.id").
+		Where("billings.id = ?", id).
+		First(&billing).Error
 	if err != nil {
 		return nil, err
 	}
@@ -57,11 +109,16 @@ func (r *billingRepository) GetUsersWithPenghuniRole() ([]*models.User, error) {
 	return users, nil
 }
 
-// GetActiveMonthlySettingBillings retrieves all active monthly setting billings
-func (r *billingRepository) GetActiveMonthlySettingBillings() ([]*models.SettingBilling, error) {
+// GetActiveSettingBillingsForMonth retrieves the active setting billings generated in a month:
+// every monthly setting and the yearly settings scheduled for the month
+func (r *billingRepository) GetActiveSettingBillingsForMonth(month int) ([]*models.SettingBilling, error) {
 	var settings []*models.SettingBilling
 
-	err := r.db.Where("jenis_billing = ? AND is_active = ? AND published_at IS NOT NULL", "bulanan", true).Find(&settings).Error
+	err := r.db.Where("is_active = ? AND published_at IS NOT NULL", true).
+		Where("jenis_billing = ? OR (jenis_billing = ? AND id IN (SELECT setting_billing_id FROM setting_billing_schedules WHERE billing_month = ?))",
+			models.JenisBillingBulanan, models.JenisBillingTahunan, month).
+		Order("id").
+		Find(&settings).Error
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +126,18 @@ func (r *billingRepository) GetActiveMonthlySettingBillings() ([]*models.Setting
 	return settings, nil
 }
 
+// GetSettingBillingByID retrieves a published setting billing by ID
+func (r *billingRepository) GetSettingBillingByID(id uint) (*models.SettingBilling, error) {
+	var setting models.SettingBilling
+
+	err := r.db.Where("id = ? AND published_at IS NOT NULL", id).First(&setting).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &setting, nil
+}
+
 // CreateBulkBillings creates multiple billing records in a transaction
 func (r *billingRepository) CreateBulkBillings(billings []*models.Billing) error {
 	return r.db.CreateInBatches(billings, 100).Error
@@ -101,7 +170,7 @@ func (r *billingRepository) GetBillingPenghuni() ([]*models.BillingPenghuniRespo
 			r.name as role_name,
 			r.type as role_type,
 			u.username,
-			SUM(COALESCE(b.nominal, 0)) as nominal,
+			SUM(COALESCE(b.nominal, 0) + COALESCE(adj.total, 0)) as nominal,
 			COALESCE(MAX(mgs.status_name), 'Belum Dibayar') as status_billing,
 			COALESCE(b.bulan, 0) as bulan,
 			COALESCE(b.tahun, 0) as tahun
@@ -112,6 +181,10 @@ func (r *billingRepository) GetBillingPenghuni() ([]*models.BillingPenghuniRespo
 		INNER JOIN profiles p ON pul.profile_id = p.id
 		LEFT JOIN billings_profile_id_lnk bpl ON u.id = bpl.user_id
 		LEFT JOIN billings b ON bpl.t_billing_id = b.id
+		LEFT JOIN (
+			SELECT billing_id, SUM(amount) as total FROM billing_adjustments
+			WHERE status = 'approved' GROUP BY billing_id
+		) adj ON adj.billing_id = b.id
 		LEFT JOIN billings_status_bill_lnk bsbl ON b.id = bsbl.t_billing_id
 		LEFT JOIN master_general_statuses mgs ON bsbl.master_general_status_id = mgs.id
 		WHERE r.type = 'penghuni'
@@ -127,6 +200,7 @@ func (r *billingRepository) GetBillingPenghuni() ([]*models.BillingPenghuniRespo
 	}
 	defer rows.Close()
 
+	periods := make(map[string]*models.BillingPenghuniResponse)
 	for rows.Next() {
 		var result models.BillingPenghuniResponse
 		var bulan int
@@ -158,8 +232,615 @@ func (r *billingRepository) GetBillingPenghuni() ([]*models.BillingPenghuniRespo
 			result.Bulan = ""
 		}
 
+		result.Components = []*models.BillingComponent{}
+		periods[fmt.Sprintf("%d:%d:%d", result.ID, bulan, result.Tahun)] = &result
 		results = append(results, &result)
 	}
 
+	if err := r.addBillingComponents(periods); err != nil {
+		return nil, err
+	}
+
 	return results, nil
 }
+
+// addBillingComponents attaches to each user's billing period, keyed "user:bulan:tahun", the
+// billings it sums with their approved adjustments and unwaived late fees
+func (r *billingRepository) addBillingComponents(periods map[string]*models.BillingPenghuniResponse) error {
+	if len(periods) == 0 {
+		return nil
+	}
+
+	query := `
+		SELECT bpl.user_id, b.bulan, b.tahun, b.id as billing_id, bs.setting_billing_id,
+			   COALESCE(bs.nama_billing, 'IPL') as nama_billing, COALESCE(b.nominal, 0) as nominal,
+			   COALESCE(adj.total, 0) as adjustment, COALESCE(pen.total, 0) as late_fee,
+			   COALESCE(mgs.status_name, 'Belum Dibayar') as status_billing
+		FROM billings b
+		INNER JOIN billings_profile_id_lnk bpl ON bpl.t_billing_id = b.id
+		LEFT JOIN billing_sources bs ON bs.billing_id = b.id
+		LEFT JOIN (
+			SELECT billing_id, SUM(amount) as total FROM billing_adjustments
+			WHERE status = 'approved' GROUP BY billing_id
+		) adj ON adj.billing_id = b.id
+		LEFT JOIN (
+			SELECT billing_id, SUM(amount) as total FROM billing_penalties
+			WHERE waived_at IS NULL GROUP BY billing_id
+		) pen ON pen.billing_id = b.id
+		LEFT JOIN billings_status_bill_lnk bsbl ON bsbl.t_billing_id = b.id
+		LEFT JOIN master_general_statuses mgs ON mgs.id = bsbl.master_general_status_id
+		WHERE b.published_at IS NOT NULL
+		ORDER BY b.id
+	`
+
+	var rows []struct {
+		UserID uint
+		Bulan  int
+		Tahun  int
+		models.BillingComponent
+	}
+	if err := r.db.Raw(query).Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if period, ok := periods[fmt.Sprintf("%d:%d:%d", row.UserID, row.Bulan, row.Tahun)]; ok {
+			component := row.BillingComponent
+			component.AmountDue = component.Nominal + component.Adjustment + component.LateFee
+			period.Components = append(period.Components, &component)
+		}
+	}
+
+	return nil
+}
+
+// GetStatusByName retrieves a published master general status by its status name
+func (r *billingRepository) GetStatusByName(statusName string) (*models.MasterGeneralStatus, error) {
+	var status models.MasterGeneralStatus
+
+	err := r.db.Where("status_name = ? AND published_at IS NOT NULL", statusName).First(&status).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// UpdateBillingsStatus points the status link of every given billing to statusID in a transaction.
+// Billings without a status link get one created.
+func (r *billingRepository) UpdateBillingsStatus(billingIDs []uint, statusID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, billingID := range billingIDs {
+			if err := setBillingStatus(tx, billingID, statusID); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.Model(&models.Billing{}).Where("id IN ?", billingIDs).Update("updated_at", now).Error
+	})
+}
+
+// GetBillingOwner retrieves the resident a billing belongs to through billings_profile_id_lnk
+func (r *billingRepository) GetBillingOwner(billingID uint) (*models.UserDetail, error) {
+	var owner models.UserDetail
+
+	query := `
+		select p.id, p.nama_penghuni, COALESCE(p.no_hp, '') as no_hp, COALESCE(p.no_telp, '') as no_telp, p.document_id,
+			   uu.email, uu.username, uu.id as user_id
+		from billings_profile_id_lnk bpl
+		inner join up_users uu on uu.id = bpl.user_id
+		inner join profiles_user_lnk pul on pul.user_id = uu.id
+		inner join profiles p on p.id = pul.profile_id
+		where bpl.t_billing_id = ?
+		and p.published_at IS NOT NULL
+		limit 1
+	`
+
+	result := r.db.Raw(query, billingID).Scan(&owner)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &owner, nil
+}
+
+// GetBillingStatusNames retrieves the current status name of each billing, keyed by billing ID.
+// Billings without a status link are left out.
+func (r *billingRepository) GetBillingStatusNames(billingIDs []uint) (map[uint]string, error) {
+	var rows []struct {
+		BillingID  uint
+		StatusName string
+	}
+
+	err := r.db.Table("billings_status_bill_lnk bsbl").
+		Select("bsbl.t_billing_id as billing_id, mgs.status_name").
+		Joins("JOIN master_general_statuses mgs ON bsbl.master_general_status_id = mgs.id").
+		Where("bsbl.t_billing_id IN ?", billingIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[uint]string, len(rows))
+	for _, row := range rows {
+		statuses[row.BillingID] = row.StatusName
+	}
+
+	return statuses, nil
+}
+
+// GetUnpaidBillingIDsByUser retrieves the published billings linked to a user through
+// billings_profile_id_lnk whose status is not paidStatusName, oldest period first. Periods are
+// year*12+month; a zero fromPeriod or toPeriod leaves that end of the range open.
+func (r *billingRepository) GetUnpaidBillingIDsByUser(userID uint, paidStatusName string, fromPeriod, toPeriod int) ([]uint, error) {
+	var billingIDs []uint
+
+	query := r.db.Table("billings b").
+		Joins("JOIN billings_profile_id_lnk bpl ON bpl.t_billing_id = b.id").
+		Joins("LEFT JOIN billings_status_bill_lnk bsbl ON bsbl.t_billing_id = b.id").
+		Joins("LEFT JOIN master_general_statuses mgs ON mgs.id = bsbl.master_general_status_id").
+		Where("bpl.user_id = ? AND b.published_at IS NOT NULL", userID).
+		Where("mgs.status_name IS NULL OR mgs.status_name <> ?", paidStatusName)
+	if fromPeriod > 0 {
+		query = query.Where("b.tahun * 12 + b.bulan >= ?", fromPeriod)
+	}
+	if toPeriod > 0 {
+		query = query.Where("b.tahun * 12 + b.bulan <= ?", toPeriod)
+	}
+
+	err := query.Order("b.tahun ASC, b.bulan ASC, b.id ASC").Pluck("b.id", &billingIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return billingIDs, nil
+}
+
+// WithGenerationLock runs fn while holding a PostgreSQL advisory lock on lockKey, so billing
+// generation for the same period never runs twice at once across replicas
+func (r *billingRepository) WithGenerationLock(lockKey string, fn func() error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", lockKey).Error; err != nil {
+			return fmt.Errorf("failed to lock billing generation: %w", err)
+		}
+
+		return fn()
+	})
+}
+
+// GetUsersWithProfile retrieves the given users that have a resident profile
+func (r *billingRepository) GetUsersWithProfile(userIDs []uint) ([]*models.User, error) {
+	var users []*models.User
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+
+	err := r.db.Where("id IN ?", userIDs).
+		Where("EXISTS (SELECT 1 FROM profiles_user_lnk pul JOIN profiles p ON p.id = pul.profile_id WHERE pul.user_id = up_users.id)").
+		Order("id").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// GetUserUnits retrieves the unit of each of the given users' resident profiles, keyed by user
+// ID. Users without a unit are left out; a user with several profiles gets the unit of the first.
+func (r *billingRepository) GetUserUnits(userIDs []uint) (map[uint]*models.ResidentUnit, error) {
+	units := make(map[uint]*models.ResidentUnit)
+	if len(userIDs) == 0 {
+		return units, nil
+	}
+
+	var rows []struct {
+		UserID uint
+		models.ResidentUnit
+	}
+	err := r.db.Table("resident_units ru").
+		Select("pul.user_id, ru.*").
+		Joins("JOIN profiles_user_lnk pul ON pul.profile_id = ru.profile_id").
+		Where("pul.user_id IN ?", userIDs).
+		Order("pul.user_id, ru.profile_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		if _, ok := units[rows[i].UserID]; !ok {
+			units[rows[i].UserID] = &rows[i].ResidentUnit
+		}
+	}
+	return units, nil
+}
+
+// GetPeriodBillings retrieves the published and cancelled billings of the given users in a
+// billing period, with the setting each was generated from and its status. One-off charges are
+// left out.
+func (r *billingRepository) GetPeriodBillings(userIDs []uint, month, year int) ([]*models.PeriodBilling, error) {
+	var billings []*models.PeriodBilling
+	if len(userIDs) == 0 {
+		return billings, nil
+	}
+
+	query := `
+		select b.id as billing_id, bpl.user_id, bs.setting_billing_id, COALESCE(b.nominal, 0) as nominal,
+			   COALESCE(mgs.status_name, '') as status_name, b.published_at IS NULL as cancelled
+		from billings b
+		inner join billings_profile_id_lnk bpl on bpl.t_billing_id = b.id
+		left join billing_sources bs on bs.billing_id = b.id
+		left join billing_details bd on bd.billing_id = b.id
+		left join billings_status_bill_lnk bsbl on bsbl.t_billing_id = b.id
+		left join master_general_statuses mgs on mgs.id = bsbl.master_general_status_id
+		where bpl.user_id IN ?
+		and b.bulan = ? and b.tahun = ?
+		and (b.published_at IS NOT NULL or bd.cancelled_at IS NOT NULL)
+		and COALESCE(bs.charge_id, 0) = 0
+		order by b.id
+	`
+
+	if err := r.db.Raw(query, userIDs, month, year).Scan(&billings).Error; err != nil {
+		return nil, err
+	}
+
+	return billings, nil
+}
+
+// CreateGeneratedBillings creates billings with their profile, status, kategori transaksi and
+// source links, details and adjustments in a transaction. Sources left behind by billings deleted
+// or unpublished in Strapi are removed first so the period can be generated again.
+func (r *billingRepository) CreateGeneratedBillings(generated []*models.GeneratedBilling) error {
+	if len(generated) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createGeneratedBillings(tx, generated)
+	})
+}
+
+// CreateBillingCharge creates a one-off charge and, in the same transaction, the billings it
+// charges with the same writes as CreateGeneratedBillings
+func (r *billingRepository) CreateBillingCharge(charge *models.BillingCharge, generated []*models.GeneratedBilling) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(charge).Error; err != nil {
+			return fmt.Errorf("failed to create billing charge: %w", err)
+		}
+
+		for _, g := range generated {
+			g.Source.ChargeID = charge.ID
+		}
+		return createGeneratedBillings(tx, generated)
+	})
+}
+
+// createGeneratedBillings writes generated billings and the rows that go with them within tx
+func createGeneratedBillings(tx *gorm.DB, generated []*models.GeneratedBilling) error {
+	if len(generated) == 0 {
+		return nil
+	}
+
+	billings := make([]*models.Billing, len(generated))
+	for i, g := range generated {
+		err := tx.Where("user_id = ? AND bulan = ? AND tahun = ? AND setting_billing_id = ? AND charge_id = ?", g.Source.UserID, g.Source.Bulan, g.Source.Tahun, g.Source.SettingBillingID, g.Source.ChargeID).
+			Where("billing_id NOT IN (SELECT id FROM billings WHERE published_at IS NOT NULL)").
+			Delete(&models.BillingSource{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to remove stale billing sources: %w", err)
+		}
+		billings[i] = g.Billing
+	}
+
+	if err := tx.CreateInBatches(billings, 100).Error; err != nil {
+		return fmt.Errorf("failed to create billings: %w", err)
+	}
+
+	links := make([]*models.BillingProfileLink, len(generated))
+	statusLinks := make([]*models.BillingStatusBillLink, len(generated))
+	kategoriLinks := make([]*models.BillingKategoriTransaksiLink, len(generated))
+	sources := make([]*models.BillingSource, len(generated))
+	details := make([]*models.BillingDetail, len(generated))
+	var adjustments []*models.BillingAdjustment
+	for i, g := range generated {
+		billingID := g.Billing.ID
+		g.Source.BillingID = billingID
+		g.Detail.BillingID = billingID
+		links[i] = &models.BillingProfileLink{BillingID: billingID, ProfileID: g.Source.UserID}
+		statusLinks[i] = &models.BillingStatusBillLink{BillingID: billingID, MasterGeneralStatusID: g.StatusID}
+		kategoriLinks[i] = &models.BillingKategoriTransaksiLink{BillingID: billingID, MasterKategoriTransaksiID: 1}
+		sources[i] = g.Source
+		details[i] = g.Detail
+		for _, adjustment := range g.Adjustments {
+			adjustment.BillingID = billingID
+			adjustments = append(adjustments, adjustment)
+		}
+	}
+
+	if err := tx.CreateInBatches(links, 100).Error; err != nil {
+		return fmt.Errorf("failed to create billing profile links: %w", err)
+	}
+	if err := tx.CreateInBatches(statusLinks, 100).Error; err != nil {
+		return fmt.Errorf("failed to create billing status bill links: %w", err)
+	}
+	if err := tx.CreateInBatches(kategoriLinks, 100).Error; err != nil {
+		return fmt.Errorf("failed to create billing kategori transaksi links: %w", err)
+	}
+	if err := tx.CreateInBatches(sources, 100).Error; err != nil {
+		return fmt.Errorf("failed to create billing sources: %w", err)
+	}
+	if err := tx.CreateInBatches(details, 100).Error; err != nil {
+		return fmt.Errorf("failed to create billing details: %w", err)
+	}
+	if len(adjustments) > 0 {
+		if err := tx.CreateInBatches(adjustments, 100).Error; err != nil {
+			return fmt.Errorf("failed to create billing adjustments: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateGeneratedBillings gives existing billings the nominal, calculation, status and discount
+// rule adjustments of a new generation in a transaction. Adjustments not generated from a
+// discount rule are kept.
+func (r *billingRepository) UpdateGeneratedBillings(replacements []*models.GeneratedBilling) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, g := range replacements {
+			billingID := g.Billing.ID
+			err := tx.Model(&models.Billing{}).Where("id = ?", billingID).
+				Updates(map[string]interface{}{"nominal": g.Billing.Nominal, "updated_at": now}).Error
+			if err != nil {
+				return err
+			}
+
+			if g.Source != nil {
+				err = tx.Model(&models.BillingSource{}).Where("billing_id = ?", billingID).
+					Select("tariff_id", "tariff_name", "base_nominal", "proration", "occupied_days", "days_in_month").
+					Updates(g.Source).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			err = tx.Where("billing_id = ? AND discount_rule_id IS NOT NULL", billingID).Delete(&models.BillingAdjustment{}).Error
+			if err != nil {
+				return err
+			}
+			for _, adjustment := range g.Adjustments {
+				adjustment.BillingID = billingID
+				if err := tx.Create(adjustment).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := setBillingStatus(tx, billingID, g.StatusID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetAdjustmentTotals retrieves the sum of the approved adjustments of each billing, keyed by
+// billing ID. Billings without approved adjustments are left out.
+func (r *billingRepository) GetAdjustmentTotals(billingIDs []uint) (map[uint]int64, error) {
+	var rows []struct {
+		BillingID uint
+		Total     int64
+	}
+
+	err := r.db.Model(&models.BillingAdjustment{}).
+		Select("billing_id, SUM(amount) as total").
+		Where("billing_id IN ? AND status = ?", billingIDs, models.AdjustmentStatusApproved).
+		Group("billing_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		totals[row.BillingID] = row.Total
+	}
+
+	return totals, nil
+}
+
+// GetPenaltyTotals retrieves the sum of the unwaived late fees of each billing, keyed by billing
+// ID. Billings without penalties are left out.
+func (r *billingRepository) GetPenaltyTotals(billingIDs []uint) (map[uint]int64, error) {
+	var rows []struct {
+		BillingID uint
+		Total     int64
+	}
+
+	err := r.db.Model(&models.BillingPenalty{}).
+		Select("billing_id, SUM(amount) as total").
+		Where("billing_id IN ? AND waived_at IS NULL", billingIDs).
+		Group("billing_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		totals[row.BillingID] = row.Total
+	}
+
+	return totals, nil
+}
+
+// setBillingStatus points the status link of a billing to statusID within tx, creating the link
+// when the billing has none
+func setBillingStatus(tx *gorm.DB, billingID, statusID uint) error {
+	result := tx.Model(&models.BillingStatusBillLink{}).
+		Where("t_billing_id = ?", billingID).
+		Update("master_general_status_id", statusID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		link := &models.BillingStatusBillLink{
+			BillingID:             billingID,
+			MasterGeneralStatusID: statusID,
+		}
+		return tx.Create(link).Error
+	}
+	return nil
+}
+
+// billingRecords returns a query over billings joined with their resident, status, kategori
+// transaksi, source and detail, selecting a models.BillingRecord. Billings without a detail row
+// are due on dueDay of their period.
+func (r *billingRepository) billingRecords(dueDay int) *gorm.DB {
+	return r.db.Table("billings b").
+		Select(`b.id, b.document_id, b.bulan, b.tahun, COALESCE(b.nominal, 0) as nominal,
+			COALESCE(bd.due_date, make_date(b.tahun, b.bulan, ?)) as due_date, COALESCE(bd.notes, '') as notes,
+			b.created_at, b.updated_at, b.published_at,
+			bpl.user_id, COALESCE(u.username, '') as username, COALESCE(u.email, '') as email,
+			COALESCE((SELECT p.nama_penghuni FROM profiles_user_lnk pul JOIN profiles p ON p.id = pul.profile_id
+				WHERE pul.user_id = bpl.user_id ORDER BY p.id LIMIT 1), '') as nama_penghuni,
+			mgs.id as status_id, COALESCE(mgs.status_name, 'Belum Dibayar') as status_name,
+			mkt.id as kategori_transaksi_id, COALESCE(mkt.nama, '') as kategori_transaksi,
+			bs.setting_billing_id, NULLIF(bs.charge_id, 0) as charge_id, COALESCE(bs.nama_billing, '') as nama_billing,
+			bd.cancelled_at, bd.cancelled_by_id, COALESCE(bd.cancel_reason, '') as cancel_reason`, dueDay).
+		Joins("LEFT JOIN billings_profile_id_lnk bpl ON bpl.t_billing_id = b.id").
+		Joins("LEFT JOIN up_users u ON u.id = bpl.user_id").
+		Joins("LEFT JOIN billings_status_bill_lnk bsbl ON bsbl.t_billing_id = b.id").
+		Joins("LEFT JOIN master_general_statuses mgs ON mgs.id = bsbl.master_general_status_id").
+		Joins("LEFT JOIN billings_master_kategori_transaksi_lnk bkl ON bkl.t_billing_id = b.id").
+		Joins("LEFT JOIN master_kategori_transaksis mkt ON mkt.id = bkl.master_kategori_transaksi_id").
+		Joins("LEFT JOIN billing_sources bs ON bs.billing_id = b.id").
+		Joins("LEFT JOIN billing_details bd ON bd.billing_id = b.id")
+}
+
+// ListBillings retrieves a page of the billings matching filter and how many match in total.
+// Only published billings are listed, or only those cancelled through this service.
+func (r *billingRepository) ListBillings(filter *BillingFilter, limit, offset int) ([]*models.BillingRecord, int64, error) {
+	query := r.billingRecords(filter.DueDay)
+	if filter.Cancelled {
+		query = query.Where("b.published_at IS NULL AND bd.cancelled_at IS NOT NULL")
+	} else {
+		query = query.Where("b.published_at IS NOT NULL")
+	}
+
+	if filter.UserID != nil {
+		query = query.Where("bpl.user_id = ?", *filter.UserID)
+	}
+	if filter.Month != nil {
+		query = query.Where("b.bulan = ?", *filter.Month)
+	}
+	if filter.Year != nil {
+		query = query.Where("b.tahun = ?", *filter.Year)
+	}
+	if filter.StatusName != "" {
+		query = query.Where("COALESCE(mgs.status_name, 'Belum Dibayar') = ?", filter.StatusName)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("bkl.master_kategori_transaksi_id = ?", *filter.CategoryID)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("b.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("b.created_at < ?", *filter.CreatedTo)
+	}
+	dueDate := "COALESCE(bd.due_date, make_date(b.tahun, b.bulan, ?))"
+	if filter.DueFrom != nil {
+		query = query.Where(dueDate+" >= ?", filter.DueDay, *filter.DueFrom)
+	}
+	if filter.DueTo != nil {
+		query = query.Where(dueDate+" <= ?", filter.DueDay, *filter.DueTo)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+	column, ok := billingSortColumns[filter.Sort]
+	if !ok {
+		column = billingSortColumns["created_at"]
+	}
+
+	var billings []*models.BillingRecord
+	err := query.Order(fmt.Sprintf(column+" %[1]s, b.id %[1]s", direction)).
+		Limit(limit).
+		Offset(offset).
+		Scan(&billings).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return billings, total, nil
+}
+
+// GetBillingRecord retrieves a billing with its links, published or not
+func (r *billingRepository) GetBillingRecord(id uint, dueDay int) (*models.BillingRecord, error) {
+	var billings []*models.BillingRecord
+	if err := r.billingRecords(dueDay).Where("b.id = ?", id).Limit(1).Scan(&billings).Error; err != nil {
+		return nil, err
+	}
+	if len(billings) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return billings[0], nil
+}
+
+// UpdateBilling gives a billing a new nominal and notes, and a new due date unless the detail has
+// none, and sets its status in a transaction. The detail row is created if the billing has none.
+func (r *billingRepository) UpdateBilling(billingID uint, nominal int64, detail *models.BillingDetail, statusID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Billing{}).Where("id = ?", billingID).
+			Updates(map[string]interface{}{"nominal": nominal, "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+
+		columns := []string{"notes", "updated_at"}
+		if detail.DueDate != nil {
+			columns = append(columns, "due_date")
+		}
+		detail.BillingID = billingID
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "billing_id"}},
+			DoUpdates: clause.AssignmentColumns(columns),
+		}).Create(detail).Error
+		if err != nil {
+			return err
+		}
+
+		return setBillingStatus(tx, billingID, statusID)
+	})
+}
+
+// CancelBilling unpublishes a billing and records who cancelled it and why on its detail row, in
+// a transaction. The detail row is created if the billing has none.
+func (r *billingRepository) CancelBilling(detail *models.BillingDetail) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Billing{}).Where("id = ? AND published_at IS NOT NULL", detail.BillingID).
+			Updates(map[string]interface{}{"published_at": nil, "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "billing_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"cancelled_at", "cancelled_by_id", "cancel_reason", "updated_at"}),
+		}).Create(detail).Error
+	})
+}
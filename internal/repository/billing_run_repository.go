@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"fmt"
+
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BillingRunRepository defines the interface for billing run history data operations
+type BillingRunRepository interface {
+	TryLock(lockKey string, fn func() error) (bool, error)
+	Create(run *models.BillingRun) error
+	Update(run *models.BillingRun) error
+	GetAll(limit, offset int) ([]*models.BillingRun, int64, error)
+	HasSucceeded(kind string, month, year int) (bool, error)
+}
+
+// billingRunRepository implements BillingRunRepository
+type billingRunRepository struct {
+	db *gorm.DB
+}
+
+// NewBillingRunRepository creates a new instance of BillingRunRepository
+func NewBillingRunRepository(db *gorm.DB) BillingRunRepository {
+	return &billingRunRepository{
+		db: db,
+	}
+}
+
+// TryLock runs fn if the PostgreSQL advisory lock on lockKey is free, holding it until fn
+// returns. It reports false without running fn when another replica holds the lock.
+func (r *billingRunRepository) TryLock(lockKey string, fn func() error) (bool, error) {
+	acquired := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", lockKey).Scan(&acquired).Error; err != nil {
+			return fmt.Errorf("failed to lock billing run: %w", err)
+		}
+		if !acquired {
+			return nil
+		}
+
+		return fn()
+	})
+	return acquired, err
+}
+
+// Create creates a billing run
+func (r *billingRunRepository) Create(run *models.BillingRun) error {
+	return r.db.Create(run).Error
+}
+
+// Update updates a billing run
+func (r *billingRunRepository) Update(run *models.BillingRun) error {
+	return r.db.Save(run).Error
+}
+
+// GetAll retrieves billing runs, newest first, with pagination
+func (r *billingRunRepository) GetAll(limit, offset int) ([]*models.BillingRun, int64, error) {
+	var runs []*models.BillingRun
+	var total int64
+
+	if err := r.db.Model(&models.BillingRun{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.Order("started_at DESC, id DESC").Limit(limit).Offset(offset).Find(&runs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+// HasSucceeded reports whether a run of kind succeeded for a billing period
+func (r *billingRunRepository) HasSucceeded(kind string, month, year int) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.BillingRun{}).
+		Where("kind = ? AND bulan = ? AND tahun = ? AND status = ?", kind, month, year, models.BillingRunSucceeded).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// DiscountRuleRepository defines the interface for billing discount rule data operations
+type DiscountRuleRepository interface {
+	Create(rule *models.BillingDiscountRule) error
+	GetByID(id uint) (*models.BillingDiscountRule, error)
+	GetAll(userID *uint) ([]*models.BillingDiscountRule, error)
+	Update(rule *models.BillingDiscountRule) error
+	Delete(id uint) error
+	GetActiveByUserIDs(userIDs []uint) ([]*models.BillingDiscountRule, error)
+}
+
+// discountRuleRepository implements DiscountRuleRepository
+type discountRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewDiscountRuleRepository creates a new instance of DiscountRuleRepository
+func NewDiscountRuleRepository(db *gorm.DB) DiscountRuleRepository {
+	return &discountRuleRepository{
+		db: db,
+	}
+}
+
+// Create creates a new discount rule
+func (r *discountRuleRepository) Create(rule *models.BillingDiscountRule) error {
+	return r.db.Create(rule).Error
+}
+
+// GetByID retrieves a discount rule by ID
+func (r *discountRuleRepository) GetByID(id uint) (*models.BillingDiscountRule, error) {
+	var rule models.BillingDiscountRule
+	err := r.db.First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAll retrieves the discount rules, of one user when userID is set, ordered by user and ID
+func (r *discountRuleRepository) GetAll(userID *uint) ([]*models.BillingDiscountRule, error) {
+	var rules []*models.BillingDiscountRule
+	query := r.db.Order("user_id ASC, id ASC")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if err := query.Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Update updates a discount rule
+func (r *discountRuleRepository) Update(rule *models.BillingDiscountRule) error {
+	return r.db.Save(rule).Error
+}
+
+// Delete deletes a discount rule by ID. Adjustments it already generated are kept.
+func (r *discountRuleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.BillingDiscountRule{}, id).Error
+}
+
+// GetActiveByUserIDs retrieves the active discount rules of the given users, in the order they
+// are applied
+func (r *discountRuleRepository) GetActiveByUserIDs(userIDs []uint) ([]*models.BillingDiscountRule, error) {
+	var rules []*models.BillingDiscountRule
+	if len(userIDs) == 0 {
+		return rules, nil
+	}
+
+	err := r.db.Where("user_id IN ? AND is_active = ?", userIDs, true).
+		Order("id ASC").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// FeeRuleRepository defines the interface for payment fee rule data operations
+type FeeRuleRepository interface {
+	Create(rule *models.PaymentFeeRule) error
+	GetByID(id uint) (*models.PaymentFeeRule, error)
+	GetAll() ([]*models.PaymentFeeRule, error)
+	Update(rule *models.PaymentFeeRule) error
+	Delete(id uint) error
+	GetByPaymentMethodType(paymentMethodType string) (*models.PaymentFeeRule, error)
+	GetActiveByPaymentMethodType(paymentMethodType string) (*models.PaymentFeeRule, error)
+	GetActivePaymentMethodTypes() ([]string, error)
+}
+
+// feeRuleRepository implements FeeRuleRepository
+type feeRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewFeeRuleRepository creates a new instance of FeeRuleRepository
+func NewFeeRuleRepository(db *gorm.DB) FeeRuleRepository {
+	return &feeRuleRepository{
+		db: db,
+	}
+}
+
+// Create creates a new fee rule
+func (r *feeRuleRepository) Create(rule *models.PaymentFeeRule) error {
+	return r.db.Create(rule).Error
+}
+
+// GetByID retrieves a fee rule by ID
+func (r *feeRuleRepository) GetByID(id uint) (*models.PaymentFeeRule, error) {
+	var rule models.PaymentFeeRule
+	err := r.db.First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAll retrieves every fee rule ordered by payment method type
+func (r *feeRuleRepository) GetAll() ([]*models.PaymentFeeRule, error) {
+	var rules []*models.PaymentFeeRule
+	err := r.db.Order("payment_method_type ASC").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Update updates a fee rule
+func (r *feeRuleRepository) Update(rule *models.PaymentFeeRule) error {
+	return r.db.Save(rule).Error
+}
+
+// Delete deletes a fee rule by ID
+func (r *feeRuleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.PaymentFeeRule{}, id).Error
+}
+
+// GetByPaymentMethodType retrieves the fee rule of a payment method type, active or not
+func (r *feeRuleRepository) GetByPaymentMethodType(paymentMethodType string) (*models.PaymentFeeRule, error) {
+	var rule models.PaymentFeeRule
+	err := r.db.Where("payment_method_type = ?", paymentMethodType).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetActiveByPaymentMethodType retrieves the active fee rule of a payment method type
+func (r *feeRuleRepository) GetActiveByPaymentMethodType(paymentMethodType string) (*models.PaymentFeeRule, error) {
+	var rule models.PaymentFeeRule
+	err := r.db.Where("payment_method_type = ? AND is_active = ?", paymentMethodType, true).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetActivePaymentMethodTypes retrieves the payment method types that have an active fee rule
+func (r *feeRuleRepository) GetActivePaymentMethodTypes() ([]string, error) {
+	var types []string
+	err := r.db.Model(&models.PaymentFeeRule{}).
+		Where("is_active = ?", true).
+		Order("payment_method_type ASC").
+		Pluck("payment_method_type", &types).Error
+	if err != nil {
+		return nil, err
+	}
+	return types, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"time"
+
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// InstallmentRepository defines the interface for installment plan data operations
+type InstallmentRepository interface {
+	CreatePlan(plan *models.InstallmentPlan, billingIDs []uint) error
+	GetPlanByID(id uint) (*models.InstallmentPlan, error)
+	GetPlanBillingIDs(planID uint) ([]uint, error)
+	GetPartByID(id uint) (*models.InstallmentPart, error)
+	GetActivePlanBillingIDs(billingIDs []uint) ([]uint, error)
+}
+
+// installmentRepository implements InstallmentRepository
+type installmentRepository struct {
+	db *gorm.DB
+}
+
+// NewInstallmentRepository creates a new instance of InstallmentRepository
+func NewInstallmentRepository(db *gorm.DB) InstallmentRepository {
+	return &installmentRepository{
+		db: db,
+	}
+}
+
+// CreatePlan creates an installment plan with its parts and billing links in a transaction
+func (r *installmentRepository) CreatePlan(plan *models.InstallmentPlan, billingIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(plan).Error; err != nil {
+			return err
+		}
+
+		links := make([]*models.InstallmentPlanBillingLink, 0, len(billingIDs))
+		for _, billingID := range billingIDs {
+			links = append(links, &models.InstallmentPlanBillingLink{
+				PlanID:    plan.ID,
+				BillingID: billingID,
+			})
+		}
+
+		return tx.CreateInBatches(links, 100).Error
+	})
+}
+
+// GetPlanByID retrieves an installment plan with its parts in sequence order
+func (r *installmentRepository) GetPlanByID(id uint) (*models.InstallmentPlan, error) {
+	var plan models.InstallmentPlan
+
+	err := r.db.Preload("Parts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).Where("id = ?", id).First(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// GetPlanBillingIDs retrieves the billing IDs of an installment plan
+func (r *installmentRepository) GetPlanBillingIDs(planID uint) ([]uint, error) {
+	var billingIDs []uint
+
+	err := r.db.Model(&models.InstallmentPlanBillingLink{}).
+		Where("plan_id = ?", planID).
+		Order("t_billing_id").
+		Pluck("t_billing_id", &billingIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return billingIDs, nil
+}
+
+// GetPartByID retrieves an installment part by ID
+func (r *installmentRepository) GetPartByID(id uint) (*models.InstallmentPart, error) {
+	var part models.InstallmentPart
+
+	err := r.db.Where("id = ?", id).First(&part).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &part, nil
+}
+
+// GetActivePlanBillingIDs returns the billings among billingIDs that belong to an active plan
+func (r *installmentRepository) GetActivePlanBillingIDs(billingIDs []uint) ([]uint, error) {
+	var planned []uint
+
+	if len(billingIDs) == 0 {
+		return planned, nil
+	}
+
+	err := r.db.Model(&models.InstallmentPlanBillingLink{}).
+		Joins("JOIN installment_plans ip ON ip.id = installment_plans_billing_lnk.plan_id").
+		Where("installment_plans_billing_lnk.t_billing_id IN ? AND ip.status = ?", billingIDs, models.InstallmentPlanActive).
+		Distinct().
+		Pluck("installment_plans_billing_lnk.t_billing_id", &planned).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return planned, nil
+}
+
+// markInstallmentPartPaid marks an installment part paid and completes its plan once every part
+// is paid. It runs inside the transaction that marks the paying transaction paid.
+func markInstallmentPartPaid(tx *gorm.DB, partID uint, paidAt time.Time) error {
+	now := time.Now()
+
+	var part models.InstallmentPart
+	if err := tx.Where("id = ?", partID).First(&part).Error; err != nil {
+		return err
+	}
+
+	err := tx.Model(&models.InstallmentPart{}).
+		Where("id = ?", partID).
+		Updates(map[string]interface{}{
+			"status":     models.InstallmentPartPaid,
+			"paid_at":    paidAt,
+			"updated_at": now,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&models.InstallmentPlan{}).
+		Where("id = ? AND NOT EXISTS (SELECT 1 FROM installment_parts WHERE plan_id = ? AND status <> ?)", part.PlanID, part.PlanID, models.InstallmentPartPaid).
+		Updates(map[string]interface{}{
+			"status":     models.InstallmentPlanCompleted,
+			"updated_at": now,
+		}).Error
+}
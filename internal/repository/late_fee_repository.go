@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"time"
+
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LateFeeRepository defines the interface for late fee (denda) data operations
+type LateFeeRepository interface {
+	GetOverdueBillings(today time.Time, paidStatusName string) ([]*models.OverdueBilling, error)
+	CreatePenalties(penalties []*models.BillingPenalty) (int64, error)
+	GetPenaltiesByBillingID(billingID uint) ([]*models.BillingPenalty, error)
+	WaivePenalties(billingID uint, penaltyIDs []uint, waivedByID uint, reason string, waivedAt time.Time, billingStatusID uint) (int64, error)
+}
+
+// lateFeeRepository implements LateFeeRepository
+type lateFeeRepository struct {
+	db *gorm.DB
+}
+
+// NewLateFeeRepository creates a new instance of LateFeeRepository
+func NewLateFeeRepository(db *gorm.DB) LateFeeRepository {
+	return &lateFeeRepository{
+		db: db,
+	}
+}
+
+// GetOverdueBillings retrieves the published billings not yet paidStatusName whose due date is
+// before today, with their nominal after approved adjustments and the penalties they already
+// have. Only billings with a due date in billing_details are charged, so those that predate
+// due dates never get denda for months nobody told the resident about. Billings in an active
+// installment plan are left out, as the plan sets when they are paid.
+func (r *lateFeeRepository) GetOverdueBillings(today time.Time, paidStatusName string) ([]*models.OverdueBilling, error) {
+	var billings []*models.OverdueBilling
+
+	query := `
+		select b.id as billing_id, b.nominal + COALESCE(adj.total, 0) as nominal,
+			   bd.due_date,
+			   COALESCE(MAX(bp.months_late), 0) as months_charged,
+			   COALESCE(SUM(bp.amount), 0) as penalty_total
+		from billings b
+		join billing_details bd on bd.billing_id = b.id
+		left join (
+			select billing_id, SUM(amount) as total from billing_adjustments
+			where status = @adjustment_approved group by billing_id
+		) adj on adj.billing_id = b.id
+		left join billings_status_bill_lnk bsbl on bsbl.t_billing_id = b.id
+		left join master_general_statuses mgs on mgs.id = bsbl.master_general_status_id
+		left join billing_penalties bp on bp.billing_id = b.id
+		where b.published_at IS NOT NULL
+		and b.nominal + COALESCE(adj.total, 0) > 0
+		and (mgs.status_name IS NULL OR mgs.status_name <> @paid_status)
+		and bd.due_date < CAST(@today AS date)
+		and NOT EXISTS (
+			select 1 from installment_plans_billing_lnk ipl
+			join installment_plans ip on ip.id = ipl.plan_id
+			where ipl.t_billing_id = b.id and ip.status = @plan_active
+		)
+		group by b.id, b.nominal, adj.total, bd.due_date
+		order by b.id
+	`
+
+	err := r.db.Raw(query, map[string]interface{}{
+		"paid_status":         paidStatusName,
+		"today":               today.Format("2006-01-02"),
+		"plan_active":         models.InstallmentPlanActive,
+		"adjustment_approved": models.AdjustmentStatusApproved,
+	}).Scan(&billings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return billings, nil
+}
+
+// CreatePenalties creates penalties, skipping any a billing already has for the same month, and
+// returns how many were created
+func (r *lateFeeRepository) CreatePenalties(penalties []*models.BillingPenalty) (int64, error) {
+	if len(penalties) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "billing_id"}, {Name: "months_late"}},
+		DoNothing: true,
+	}).CreateInBatches(penalties, 100)
+
+	return result.RowsAffected, result.Error
+}
+
+// GetPenaltiesByBillingID retrieves the penalties of a billing, oldest first
+func (r *lateFeeRepository) GetPenaltiesByBillingID(billingID uint) ([]*models.BillingPenalty, error) {
+	var penalties []*models.BillingPenalty
+
+	err := r.db.Where("billing_id = ?", billingID).Order("months_late ASC").Find(&penalties).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return penalties, nil
+}
+
+// WaivePenalties marks the unwaived penalties of a billing among penaltyIDs as waived and points
+// the billing's status link to billingStatusID in a transaction. Empty penaltyIDs waives every
+// unwaived penalty of the billing. It returns how many penalties were waived.
+func (r *lateFeeRepository) WaivePenalties(billingID uint, penaltyIDs []uint, waivedByID uint, reason string, waivedAt time.Time, billingStatusID uint) (int64, error) {
+	var waived int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.BillingPenalty{}).Where("billing_id = ? AND waived_at IS NULL", billingID)
+		if len(penaltyIDs) > 0 {
+			query = query.Where("id IN ?", penaltyIDs)
+		}
+
+		result := query.Updates(map[string]interface{}{
+			"waived_at":    waivedAt,
+			"waived_by_id": waivedByID,
+			"waive_reason": reason,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		waived = result.RowsAffected
+
+		if err := setBillingStatus(tx, billingID, billingStatusID); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Billing{}).Where("id = ?", billingID).Update("updated_at", waivedAt).Error
+	})
+
+	return waived, err
+}
@@ -0,0 +1,481 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"time"
+
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PaymentRepository defines the interface for payment transaction data operations
+type PaymentRepository interface {
+	WithCheckoutLock(lockKeys []string, fn func() error) error
+	CreateTransaction(transaction *models.PaymentTransaction, allocations map[uint]int64) error
+	CreatePaidTransaction(transaction *models.PaymentTransaction, allocations map[uint]int64, billingStatusIDs map[uint]uint) error
+	GetTransactionByInvoiceNumber(invoiceNumber string) (*models.PaymentTransaction, error)
+	GetTransactionByIdempotencyKey(idempotencyKey string) (*models.PaymentTransaction, error)
+	GetPendingTransactionsByBillingIDs(billingIDs []uint, now time.Time) ([]*models.PaymentTransaction, error)
+	GetPendingTransactionsCreatedBefore(before time.Time, afterID uint, limit int) ([]*models.PaymentTransaction, error)
+	GetTransactionsByInvoiceNumbers(invoiceNumbers []string) ([]*models.PaymentTransaction, error)
+	GetPaidTransactionsBetween(gateway string, from, to time.Time) ([]*models.PaymentTransaction, error)
+	GetBillingIDsByTransactionID(transactionID uint) ([]uint, error)
+	GetTransactionAllocations(transactionID uint) (map[uint]int64, error)
+	GetTransactionReversals(transactionID uint) (map[uint]int64, error)
+	GetBillingPaidAmounts(billingIDs []uint) (map[uint]int64, error)
+	UpdatePendingTransactionStatus(transactionID uint, status string) (bool, error)
+	MarkTransactionPaid(transactionID uint, billingStatusIDs map[uint]uint, paidAt time.Time) (bool, error)
+	CreateRefund(refund *models.PaymentRefund, status string, reopenedStatusIDs map[uint]uint) error
+	GetRefundsByTransactionID(transactionID uint) ([]*models.PaymentRefund, error)
+}
+
+// paymentRepository implements PaymentRepository
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepository creates a new instance of PaymentRepository
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{
+		db: db,
+	}
+}
+
+// Bounds of the wait between attempts to take busy checkout locks
+const (
+	checkoutLockMinWait = 10 * time.Millisecond
+	checkoutLockMaxWait = 500 * time.Millisecond
+)
+
+// WithCheckoutLock runs fn while holding a PostgreSQL advisory lock for each key. The locks are
+// shared by every replica and released when fn returns. They are session locks taken together on
+// a dedicated connection: when any key is busy the ones already taken are released and the
+// connection goes back to the pool while waiting, so waiting callers never hold the connections
+// fn needs.
+func (r *paymentRepository) WithCheckoutLock(lockKeys []string, fn func() error) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to lock checkout: %w", err)
+	}
+
+	ctx := context.Background()
+	wait := checkoutLockMinWait
+	for {
+		conn, err := tryCheckoutLocks(ctx, sqlDB, lockKeys)
+		if err != nil {
+			return fmt.Errorf("failed to lock checkout: %w", err)
+		}
+		if conn != nil {
+			defer releaseCheckoutLocks(ctx, conn)
+			return fn()
+		}
+
+		time.Sleep(wait)
+		wait = min(wait*2, checkoutLockMaxWait)
+	}
+}
+
+// tryCheckoutLocks takes the advisory lock of every key on a connection of its own and returns
+// that connection, or nil when a key is held by someone else
+func tryCheckoutLocks(ctx context.Context, sqlDB *sql.DB, lockKeys []string) (*sql.Conn, error) {
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range lockKeys {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&locked); err != nil {
+			releaseCheckoutLocks(ctx, conn)
+			return nil, err
+		}
+		if !locked {
+			releaseCheckoutLocks(ctx, conn)
+			return nil, nil
+		}
+	}
+
+	return conn, nil
+}
+
+// releaseCheckoutLocks releases the advisory locks of a connection and returns it to the pool.
+// A connection whose locks cannot be released is closed, which releases them too.
+func releaseCheckoutLocks(ctx context.Context, conn *sql.Conn) {
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock_all()"); err != nil {
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	conn.Close()
+}
+
+// CreateTransaction creates a payment transaction and its billing links in a transaction.
+// allocations maps each billing ID to the part of it the transaction pays.
+func (r *paymentRepository) CreateTransaction(transaction *models.PaymentTransaction, allocations map[uint]int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createTransactionWithLinks(tx, transaction, allocations)
+	})
+}
+
+// CreatePaidTransaction creates an already paid payment transaction and its billing links, and
+// moves the status link of each billing to its entry in billingStatusIDs, in a single transaction
+func (r *paymentRepository) CreatePaidTransaction(transaction *models.PaymentTransaction, allocations map[uint]int64, billingStatusIDs map[uint]uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := createTransactionWithLinks(tx, transaction, allocations); err != nil {
+			return err
+		}
+
+		return updateBillingStatuses(tx, billingStatusIDs)
+	})
+}
+
+// createTransactionWithLinks inserts a payment transaction and links it to its billings
+func createTransactionWithLinks(tx *gorm.DB, transaction *models.PaymentTransaction, allocations map[uint]int64) error {
+	if err := tx.Create(transaction).Error; err != nil {
+		return err
+	}
+
+	billingIDs := make([]uint, 0, len(allocations))
+	for billingID := range allocations {
+		billingIDs = append(billingIDs, billingID)
+	}
+	sort.Slice(billingIDs, func(i, j int) bool { return billingIDs[i] < billingIDs[j] })
+
+	links := make([]*models.PaymentTransactionBillingLink, 0, len(billingIDs))
+	for _, billingID := range billingIDs {
+		amount := allocations[billingID]
+		links = append(links, &models.PaymentTransactionBillingLink{
+			PaymentTransactionID: transaction.ID,
+			BillingID:            billingID,
+			Amount:               &amount,
+		})
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	return tx.CreateInBatches(links, 100).Error
+}
+
+// GetTransactionByInvoiceNumber retrieves a payment transaction by invoice number
+func (r *paymentRepository) GetTransactionByInvoiceNumber(invoiceNumber string) (*models.PaymentTransaction, error) {
+	var transaction models.PaymentTransaction
+
+	err := r.db.Where("invoice_number = ?", invoiceNumber).First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// GetTransactionByIdempotencyKey retrieves a payment transaction by the Idempotency-Key it was created with
+func (r *paymentRepository) GetTransactionByIdempotencyKey(idempotencyKey string) (*models.PaymentTransaction, error) {
+	var transaction models.PaymentTransaction
+
+	err := r.db.Where("idempotency_key = ?", idempotencyKey).First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// GetPendingTransactionsByBillingIDs retrieves the pending, unexpired payment transactions that
+// pay any of the given billings, newest first
+func (r *paymentRepository) GetPendingTransactionsByBillingIDs(billingIDs []uint, now time.Time) ([]*models.PaymentTransaction, error) {
+	var transactions []*models.PaymentTransaction
+
+	if len(billingIDs) == 0 {
+		return transactions, nil
+	}
+
+	linked := r.db.Model(&models.PaymentTransactionBillingLink{}).
+		Select("payment_transaction_id").
+		Where("t_billing_id IN ?", billingIDs)
+
+	err := r.db.Where("id IN (?) AND status = ?", linked, models.PaymentStatusPending).
+		Where("expired_at IS NULL OR expired_at > ?", now).
+		Order("id DESC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// GetPendingTransactionsCreatedBefore retrieves pending payment transactions created before the given time
+// with an ID above afterID, in ID order. Callers page through all of them by passing the last ID seen.
+func (r *paymentRepository) GetPendingTransactionsCreatedBefore(before time.Time, afterID uint, limit int) ([]*models.PaymentTransaction, error) {
+	var transactions []*models.PaymentTransaction
+
+	query := r.db.Where("status = ? AND created_at < ? AND id > ?", models.PaymentStatusPending, before, afterID).Order("id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// GetTransactionsByInvoiceNumbers retrieves payment transactions by invoice numbers
+func (r *paymentRepository) GetTransactionsByInvoiceNumbers(invoiceNumbers []string) ([]*models.PaymentTransaction, error) {
+	var transactions []*models.PaymentTransaction
+
+	if len(invoiceNumbers) == 0 {
+		return transactions, nil
+	}
+
+	err := r.db.Where("invoice_number IN ?", invoiceNumbers).Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// GetPaidTransactionsBetween retrieves transactions of a gateway paid in [from, to), including
+// ones refunded later since the gateway still settled them
+func (r *paymentRepository) GetPaidTransactionsBetween(gateway string, from, to time.Time) ([]*models.PaymentTransaction, error) {
+	var transactions []*models.PaymentTransaction
+
+	err := r.db.Where("gateway = ? AND status IN ? AND paid_at >= ? AND paid_at < ?", gateway, []string{models.PaymentStatusPaid, models.PaymentStatusRefunded}, from, to).
+		Order("paid_at ASC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// GetBillingIDsByTransactionID retrieves the billing IDs linked to a payment transaction
+func (r *paymentRepository) GetBillingIDsByTransactionID(transactionID uint) ([]uint, error) {
+	var billingIDs []uint
+
+	err := r.db.Model(&models.PaymentTransactionBillingLink{}).
+		Where("payment_transaction_id = ?", transactionID).
+		Order("t_billing_id").
+		Pluck("t_billing_id", &billingIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return billingIDs, nil
+}
+
+// GetTransactionAllocations retrieves the amount a payment transaction pays of each of its billings
+func (r *paymentRepository) GetTransactionAllocations(transactionID uint) (map[uint]int64, error) {
+	var rows []struct {
+		BillingID uint
+		Amount    int64
+	}
+
+	err := r.db.Table("payment_transactions_billing_lnk ptbl").
+		Select("ptbl.t_billing_id AS billing_id, COALESCE(ptbl.amount, b.nominal, 0) AS amount").
+		Joins("JOIN billings b ON b.id = ptbl.t_billing_id").
+		Where("ptbl.payment_transaction_id = ?", transactionID).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		allocations[row.BillingID] = row.Amount
+	}
+
+	return allocations, nil
+}
+
+// GetTransactionReversals sums what the refunds of a payment transaction took back from each of
+// its billings. Billings no refund reopened are missing from the result.
+func (r *paymentRepository) GetTransactionReversals(transactionID uint) (map[uint]int64, error) {
+	var rows []struct {
+		BillingID uint
+		Amount    int64
+	}
+
+	err := r.db.Model(&models.PaymentRefundBilling{}).
+		Select("billing_id, SUM(amount) AS amount").
+		Where("payment_transaction_id = ?", transactionID).
+		Group("billing_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	reversals := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		reversals[row.BillingID] = row.Amount
+	}
+
+	return reversals, nil
+}
+
+// GetBillingPaidAmounts sums what settled transactions have paid of each billing: the allocations
+// of paid and refunded transactions minus what refunds took back from the billing. A refunded
+// transaction still pays the billings its refunds did not reopen. Billings without payments are
+// missing from the result.
+func (r *paymentRepository) GetBillingPaidAmounts(billingIDs []uint) (map[uint]int64, error) {
+	var rows []struct {
+		BillingID uint
+		Amount    int64
+	}
+
+	paidAmounts := make(map[uint]int64)
+	if len(billingIDs) == 0 {
+		return paidAmounts, nil
+	}
+
+	reversals := r.db.Model(&models.PaymentRefundBilling{}).
+		Select("payment_transaction_id, billing_id, SUM(amount) AS amount").
+		Where("billing_id IN ?", billingIDs).
+		Group("payment_transaction_id, billing_id")
+
+	err := r.db.Table("payment_transactions_billing_lnk ptbl").
+		Select("ptbl.t_billing_id AS billing_id, SUM(COALESCE(ptbl.amount, b.nominal, 0) - COALESCE(rv.amount, 0)) AS amount").
+		Joins("JOIN payment_transactions pt ON pt.id = ptbl.payment_transaction_id").
+		Joins("JOIN billings b ON b.id = ptbl.t_billing_id").
+		Joins("LEFT JOIN (?) rv ON rv.payment_transaction_id = ptbl.payment_transaction_id AND rv.billing_id = ptbl.t_billing_id", reversals).
+		Where("ptbl.t_billing_id IN ? AND pt.status IN ?", billingIDs, []string{models.PaymentStatusPaid, models.PaymentStatusRefunded}).
+		Group("ptbl.t_billing_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if row.Amount > 0 {
+			paidAmounts[row.BillingID] = row.Amount
+		}
+	}
+
+	return paidAmounts, nil
+}
+
+// UpdatePendingTransactionStatus moves a payment transaction that is still pending to status.
+// It reports false when the transaction was no longer pending, e.g. because a notification
+// marked it paid in the meantime.
+func (r *paymentRepository) UpdatePendingTransactionStatus(transactionID uint, status string) (bool, error) {
+	result := r.db.Model(&models.PaymentTransaction{}).
+		Where("id = ? AND status = ?", transactionID, models.PaymentStatusPending).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// MarkTransactionPaid marks a payment transaction as paid, moves the status link of each billing
+// to its entry in billingStatusIDs and marks the installment part it pays, if any, in a single
+// transaction. A transaction marked expired, failed or cancelled can still become paid when the
+// gateway reports the money arrived; one that is already paid or refunded is left alone and false
+// is returned.
+func (r *paymentRepository) MarkTransactionPaid(transactionID uint, billingStatusIDs map[uint]uint, paidAt time.Time) (bool, error) {
+	updated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PaymentTransaction{}).
+			Where("id = ? AND status NOT IN ?", transactionID, []string{models.PaymentStatusPaid, models.PaymentStatusRefunded}).
+			Updates(map[string]interface{}{
+				"status":     models.PaymentStatusPaid,
+				"paid_at":    paidAt,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		updated = true
+		if err := updateBillingStatuses(tx, billingStatusIDs); err != nil {
+			return err
+		}
+
+		var transaction models.PaymentTransaction
+		if err := tx.Select("installment_part_id").Where("id = ?", transactionID).First(&transaction).Error; err != nil {
+			return err
+		}
+		if transaction.InstallmentPartID == nil {
+			return nil
+		}
+		return markInstallmentPartPaid(tx, *transaction.InstallmentPartID, paidAt)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return updated, nil
+}
+
+// CreateRefund records a refund of a paid transaction in a single transaction: it adds the amount
+// to the transaction's refunded_amount, moves the transaction to status and stores the refund with
+// the reversals in refund.Billings. The billing links keep their allocations as payment history.
+// Each reopened billing gets its entry in reopenedStatusIDs.
+func (r *paymentRepository) CreateRefund(refund *models.PaymentRefund, status string, reopenedStatusIDs map[uint]uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PaymentTransaction{}).
+			Where("id = ? AND status = ? AND refunded_amount + ? <= amount", refund.PaymentTransactionID, models.PaymentStatusPaid, refund.Amount).
+			Updates(map[string]interface{}{
+				"refunded_amount": gorm.Expr("refunded_amount + ?", refund.Amount),
+				"status":          status,
+				"updated_at":      time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("payment transaction is no longer refundable")
+		}
+
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+
+		return updateBillingStatuses(tx, reopenedStatusIDs)
+	})
+}
+
+// GetRefundsByTransactionID retrieves the refunds of a payment transaction, oldest first
+func (r *paymentRepository) GetRefundsByTransactionID(transactionID uint) ([]*models.PaymentRefund, error) {
+	var refunds []*models.PaymentRefund
+
+	err := r.db.Preload("Billings", func(db *gorm.DB) *gorm.DB {
+		return db.Order("billing_id ASC")
+	}).Where("payment_transaction_id = ?", transactionID).Order("id ASC").Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// updateBillingStatuses moves the status link of each billing to its entry in billingStatusIDs
+func updateBillingStatuses(tx *gorm.DB, billingStatusIDs map[uint]uint) error {
+	byStatus := make(map[uint][]uint)
+	for billingID, statusID := range billingStatusIDs {
+		byStatus[statusID] = append(byStatus[statusID], billingID)
+	}
+
+	billingRepo := NewBillingRepository(tx)
+	for statusID, billingIDs := range byStatus {
+		if err := billingRepo.UpdateBillingsStatus(billingIDs, statusID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the PostgreSQL database in TEST_DATABASE_DSN and migrates the tables the
+// payment repository uses into a schema of its own, dropped when the test ends. Tests using it are
+// skipped when TEST_DATABASE_DSN is not set.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	schema := fmt.Sprintf("test_payment_repository_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	db, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect to schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	err = db.AutoMigrate(
+		&models.Billing{},
+		&models.BillingStatusBillLink{},
+		&models.PaymentTransaction{},
+		&models.PaymentTransactionBillingLink{},
+		&models.PaymentRefund{},
+		&models.PaymentRefundBilling{},
+	)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestPaymentRepository_FullRefundKeepsUnreopenedBillingsPaid(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPaymentRepository(db)
+
+	nominal := int64(150000)
+	for _, id := range []uint{1, 2} {
+		if err := db.Create(&models.Billing{ID: id, Nominal: &nominal}).Error; err != nil {
+			t.Fatalf("create billing: %v", err)
+		}
+	}
+
+	paidAt := time.Now()
+	transaction := &models.PaymentTransaction{
+		InvoiceNumber: "INV-1",
+		BillingKey:    "1,2",
+		Gateway:       "manual",
+		Status:        models.PaymentStatusPaid,
+		Amount:        300000,
+		PaidAt:        &paidAt,
+	}
+	if err := repo.CreatePaidTransaction(transaction, map[uint]int64{1: 150000, 2: 150000}, map[uint]uint{1: 2, 2: 2}); err != nil {
+		t.Fatalf("create paid transaction: %v", err)
+	}
+
+	// The whole amount is returned but only billing 1 is reopened
+	refund := &models.PaymentRefund{
+		PaymentTransactionID: transaction.ID,
+		Amount:               300000,
+		Reason:               "Salah transfer",
+		ReopenedBillingKey:   "1",
+		Billings: []*models.PaymentRefundBilling{
+			{PaymentTransactionID: transaction.ID, BillingID: 1, Amount: 150000},
+		},
+	}
+	if err := repo.CreateRefund(refund, models.PaymentStatusRefunded, map[uint]uint{1: 1}); err != nil {
+		t.Fatalf("create refund: %v", err)
+	}
+
+	paidAmounts, err := repo.GetBillingPaidAmounts([]uint{1, 2})
+	if err != nil {
+		t.Fatalf("get paid amounts: %v", err)
+	}
+	if len(paidAmounts) != 1 || paidAmounts[2] != 150000 {
+		t.Errorf("paid amounts = %v, want only billing 2 still paid 150000", paidAmounts)
+	}
+
+	allocations, err := repo.GetTransactionAllocations(transaction.ID)
+	if err != nil {
+		t.Fatalf("get allocations: %v", err)
+	}
+	if allocations[1] != 150000 || allocations[2] != 150000 {
+		t.Errorf("allocations = %v, want the payment history kept", allocations)
+	}
+
+	reversals, err := repo.GetTransactionReversals(transaction.ID)
+	if err != nil {
+		t.Fatalf("get reversals: %v", err)
+	}
+	if len(reversals) != 1 || reversals[1] != 150000 {
+		t.Errorf("reversals = %v, want 150000 of billing 1", reversals)
+	}
+
+	refunds, err := repo.GetRefundsByTransactionID(transaction.ID)
+	if err != nil {
+		t.Fatalf("get refunds: %v", err)
+	}
+	if len(refunds) != 1 || len(refunds[0].Billings) != 1 || refunds[0].Billings[0].BillingID != 1 {
+		t.Errorf("refunds = %+v, want one refund reversing billing 1", refunds)
+	}
+
+	if err := repo.CreateRefund(&models.PaymentRefund{PaymentTransactionID: transaction.ID, Amount: 1}, models.PaymentStatusRefunded, nil); err == nil {
+		t.Error("refunding a refunded transaction succeeded, want an error")
+	}
+}
+
+func TestPaymentRepository_CheckoutLockWaitersDoNotHoldConnections(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPaymentRepository(db)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(2)
+
+	// The holder uses the pool inside the lock while the others wait for it; a waiter holding a
+	// connection for its whole wait would leave the holder none
+	holding := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 4)
+	go func() {
+		done <- repo.WithCheckoutLock([]string{"billing:1"}, func() error {
+			close(holding)
+			<-release
+			var count int64
+			return db.Model(&models.PaymentTransaction{}).Count(&count).Error
+		})
+	}()
+	<-holding
+
+	var ran int
+	var mu sync.Mutex
+	for i := 0; i < 3; i++ {
+		go func() {
+			done <- repo.WithCheckoutLock([]string{"billing:1", "billing:2"}, func() error {
+				mu.Lock()
+				ran++
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	for i := 0; i < 4; i++ {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("locked work: %v", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("checkout locks deadlocked the connection pool")
+		}
+	}
+	if ran != 3 {
+		t.Errorf("%d waiters ran, want 3", ran)
+	}
+}
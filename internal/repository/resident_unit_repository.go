@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ResidentUnitRepository defines the interface for resident unit data operations
+type ResidentUnitRepository interface {
+	GetByProfileID(profileID uint) (*models.ResidentUnit, error)
+	Save(unit *models.ResidentUnit) error
+	ProfileExists(profileID uint) (bool, error)
+}
+
+// residentUnitRepository implements ResidentUnitRepository
+type residentUnitRepository struct {
+	db *gorm.DB
+}
+
+// NewResidentUnitRepository creates a new instance of ResidentUnitRepository
+func NewResidentUnitRepository(db *gorm.DB) ResidentUnitRepository {
+	return &residentUnitRepository{
+		db: db,
+	}
+}
+
+// GetByProfileID retrieves the unit of a resident profile
+func (r *residentUnitRepository) GetByProfileID(profileID uint) (*models.ResidentUnit, error) {
+	var unit models.ResidentUnit
+	err := r.db.Where("profile_id = ?", profileID).First(&unit).Error
+	if err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// Save creates or updates a resident unit
+func (r *residentUnitRepository) Save(unit *models.ResidentUnit) error {
+	return r.db.Save(unit).Error
+}
+
+// ProfileExists reports whether a published resident profile exists
+func (r *residentUnitRepository) ProfileExists(profileID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Profile{}).Where("id = ? AND published_at IS NOT NULL", profileID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SettingScheduleRepository defines the interface for yearly setting billing schedule data operations
+type SettingScheduleRepository interface {
+	Save(schedule *models.SettingBillingSchedule) error
+	GetBySettingID(settingBillingID uint) (*models.SettingBillingSchedule, error)
+	GetAll() ([]*models.SettingBillingSchedule, error)
+	Delete(settingBillingID uint) error
+}
+
+// settingScheduleRepository implements SettingScheduleRepository
+type settingScheduleRepository struct {
+	db *gorm.DB
+}
+
+// NewSettingScheduleRepository creates a new instance of SettingScheduleRepository
+func NewSettingScheduleRepository(db *gorm.DB) SettingScheduleRepository {
+	return &settingScheduleRepository{
+		db: db,
+	}
+}
+
+// Save creates the schedule of a setting billing, or replaces its month if it has one
+func (r *settingScheduleRepository) Save(schedule *models.SettingBillingSchedule) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "setting_billing_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"billing_month", "updated_at"}),
+	}).Create(schedule).Error
+}
+
+// GetBySettingID retrieves the schedule of a setting billing
+func (r *settingScheduleRepository) GetBySettingID(settingBillingID uint) (*models.SettingBillingSchedule, error) {
+	var schedule models.SettingBillingSchedule
+	err := r.db.Select("setting_billing_schedules.*, sb.nama_billing").
+		Joins("LEFT JOIN setting_billings sb ON sb.id = setting_billing_schedules.setting_billing_id").
+		Where("setting_billing_schedules.setting_billing_id = ?", settingBillingID).
+		First(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// GetAll retrieves the schedules with the names of their setting billings, by month
+func (r *settingScheduleRepository) GetAll() ([]*models.SettingBillingSchedule, error) {
+	var schedules []*models.SettingBillingSchedule
+	err := r.db.Select("setting_billing_schedules.*, sb.nama_billing").
+		Joins("LEFT JOIN setting_billings sb ON sb.id = setting_billing_schedules.setting_billing_id").
+		Order("setting_billing_schedules.billing_month ASC, setting_billing_schedules.setting_billing_id ASC").
+		Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// Delete deletes the schedule of a setting billing
+func (r *settingScheduleRepository) Delete(settingBillingID uint) error {
+	return r.db.Where("setting_billing_id = ?", settingBillingID).Delete(&models.SettingBillingSchedule{}).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"ipl-be-svc/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TariffRepository defines the interface for setting billing tariff data operations
+type TariffRepository interface {
+	Create(tariff *models.SettingBillingTariff) error
+	GetByID(id uint) (*models.SettingBillingTariff, error)
+	GetAll(settingBillingID *uint) ([]*models.SettingBillingTariff, error)
+	Update(tariff *models.SettingBillingTariff) error
+	Delete(id uint) error
+	GetActiveBySettingIDs(settingBillingIDs []uint) ([]*models.SettingBillingTariff, error)
+}
+
+// tariffRepository implements TariffRepository
+type tariffRepository struct {
+	db *gorm.DB
+}
+
+// NewTariffRepository creates a new instance of TariffRepository
+func NewTariffRepository(db *gorm.DB) TariffRepository {
+	return &tariffRepository{
+		db: db,
+	}
+}
+
+// Create creates a new tariff
+func (r *tariffRepository) Create(tariff *models.SettingBillingTariff) error {
+	return r.db.Create(tariff).Error
+}
+
+// GetByID retrieves a tariff by ID
+func (r *tariffRepository) GetByID(id uint) (*models.SettingBillingTariff, error) {
+	var tariff models.SettingBillingTariff
+	err := r.db.First(&tariff, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tariff, nil
+}
+
+// GetAll retrieves the tariffs, of one setting billing when settingBillingID is set, in the order
+// they are tried
+func (r *tariffRepository) GetAll(settingBillingID *uint) ([]*models.SettingBillingTariff, error) {
+	var tariffs []*models.SettingBillingTariff
+	query := r.db.Order("setting_billing_id ASC, priority ASC, id ASC")
+	if settingBillingID != nil {
+		query = query.Where("setting_billing_id = ?", *settingBillingID)
+	}
+	if err := query.Find(&tariffs).Error; err != nil {
+		return nil, err
+	}
+	return tariffs, nil
+}
+
+// Update updates a tariff
+func (r *tariffRepository) Update(tariff *models.SettingBillingTariff) error {
+	return r.db.Save(tariff).Error
+}
+
+// Delete deletes a tariff by ID
+func (r *tariffRepository) Delete(id uint) error {
+	return r.db.Delete(&models.SettingBillingTariff{}, id).Error
+}
+
+// GetActiveBySettingIDs retrieves the active tariffs of the given setting billings, in the order
+// they are tried
+func (r *tariffRepository) GetActiveBySettingIDs(settingBillingIDs []uint) ([]*models.SettingBillingTariff, error) {
+	var tariffs []*models.SettingBillingTariff
+	if len(settingBillingIDs) == 0 {
+		return tariffs, nil
+	}
+
+	err := r.db.Where("setting_billing_id IN ? AND is_active = ?", settingBillingIDs, true).
+		Order("priority ASC, id ASC").
+		Find(&tariffs).Error
+	if err != nil {
+		return nil, err
+	}
+	return tariffs, nil
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidBillingAdjustment is returned when a billing adjustment request fails validation
+var ErrInvalidBillingAdjustment = errors.New("invalid billing adjustment")
+
+// AdjustmentService defines the interface for changing the amount due of billings after they
+// are generated. Every adjustment takes effect once an admin other than its creator approves it.
+type AdjustmentService interface {
+	CreateAdjustment(req *CreateAdjustmentRequest) (*models.BillingAdjustment, error)
+	GetBillingAdjustments(billingID uint) (*BillingAdjustments, error)
+	ApproveAdjustment(id, reviewerID uint, note string) (*models.BillingAdjustment, error)
+	RejectAdjustment(id, reviewerID uint, note string) (*models.BillingAdjustment, error)
+}
+
+// CreateAdjustmentRequest represents a request for an adjustment of a billing. Amount is the
+// size of a discount, waiver or surcharge, or the signed change of a correction; a waiver
+// without an amount writes off the whole adjusted nominal.
+type CreateAdjustmentRequest struct {
+	BillingID   uint
+	Type        string
+	Amount      int64
+	Reason      string
+	CreatedByID uint
+}
+
+// BillingAdjustments represents the adjustments of a billing and the nominal they leave
+type BillingAdjustments struct {
+	BillingID       uint                        `json:"billing_id"`
+	Nominal         int64                       `json:"nominal"`          // As generated
+	Adjustment      int64                       `json:"adjustment"`       // Sum of the approved adjustments
+	AdjustedNominal int64                       `json:"adjusted_nominal"` // Nominal plus adjustment, before late fees
+	Adjustments     []*models.BillingAdjustment `json:"adjustments"`
+}
+
+// adjustmentService implements AdjustmentService
+type adjustmentService struct {
+	billingRepo    repository.BillingRepository
+	paymentRepo    repository.PaymentRepository
+	adjustmentRepo repository.AdjustmentRepository
+	logger         *logger.Logger
+}
+
+// NewAdjustmentService creates a new instance of AdjustmentService
+func NewAdjustmentService(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, adjustmentRepo repository.AdjustmentRepository, logger *logger.Logger) AdjustmentService {
+	return &adjustmentService{
+		billingRepo:    billingRepo,
+		paymentRepo:    paymentRepo,
+		adjustmentRepo: adjustmentRepo,
+		logger:         logger,
+	}
+}
+
+// CreateAdjustment records a pending adjustment of a billing
+func (s *adjustmentService) CreateAdjustment(req *CreateAdjustmentRequest) (*models.BillingAdjustment, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidBillingAdjustment)
+	}
+
+	current, err := s.GetBillingAdjustments(req.BillingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var amount int64
+	switch req.Type {
+	case models.AdjustmentTypeDiscount, models.AdjustmentTypeSurcharge:
+		if req.Amount <= 0 {
+			return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidBillingAdjustment)
+		}
+		amount = req.Amount
+		if req.Type == models.AdjustmentTypeDiscount {
+			amount = -req.Amount
+		}
+	case models.AdjustmentTypeWaiver:
+		if req.Amount < 0 {
+			return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidBillingAdjustment)
+		}
+		amount = -req.Amount
+		if req.Amount == 0 {
+			amount = -current.AdjustedNominal
+		}
+		if amount == 0 {
+			return nil, fmt.Errorf("%w: billing has nothing left to waive", ErrInvalidBillingAdjustment)
+		}
+	case models.AdjustmentTypeCorrection:
+		if req.Amount == 0 {
+			return nil, fmt.Errorf("%w: a correction must change the amount", ErrInvalidBillingAdjustment)
+		}
+		amount = req.Amount
+	default:
+		return nil, fmt.Errorf("%w: type must be %s, %s, %s or %s", ErrInvalidBillingAdjustment,
+			models.AdjustmentTypeDiscount, models.AdjustmentTypeWaiver, models.AdjustmentTypeSurcharge, models.AdjustmentTypeCorrection)
+	}
+	if current.AdjustedNominal+amount < 0 {
+		return nil, fmt.Errorf("%w: adjustment exceeds the adjusted nominal of %d", ErrInvalidBillingAdjustment, current.AdjustedNominal)
+	}
+
+	adjustment := &models.BillingAdjustment{
+		BillingID:   req.BillingID,
+		Type:        req.Type,
+		Amount:      amount,
+		Reason:      reason,
+		Status:      models.AdjustmentStatusPending,
+		CreatedByID: req.CreatedByID,
+	}
+	if err := s.adjustmentRepo.Create(adjustment); err != nil {
+		s.logger.WithError(err).WithField("billing_id", req.BillingID).Error("Failed to create billing adjustment")
+		return nil, fmt.Errorf("failed to create billing adjustment: %w", err)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"id":            adjustment.ID,
+		"billing_id":    adjustment.BillingID,
+		"type":          adjustment.Type,
+		"amount":        adjustment.Amount,
+		"created_by_id": adjustment.CreatedByID,
+	}).Info("Billing adjustment requested")
+
+	return adjustment, nil
+}
+
+// GetBillingAdjustments returns every adjustment of a billing and its adjusted nominal
+func (s *adjustmentService) GetBillingAdjustments(billingID uint) (*BillingAdjustments, error) {
+	billing, err := s.billingRepo.GetBillingByID(billingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("billing not found")
+		}
+		return nil, fmt.Errorf("failed to get billing: %w", err)
+	}
+
+	adjustments, err := s.adjustmentRepo.GetByBillingID(billingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billing adjustments: %w", err)
+	}
+
+	result := &BillingAdjustments{BillingID: billingID, Adjustments: adjustments}
+	if billing.Nominal != nil {
+		result.Nominal = *billing.Nominal
+	}
+	for _, adjustment := range adjustments {
+		if adjustment.Status == models.AdjustmentStatusApproved {
+			result.Adjustment += adjustment.Amount
+		}
+	}
+	result.AdjustedNominal = result.Nominal + result.Adjustment
+
+	return result, nil
+}
+
+// ApproveAdjustment applies a pending adjustment to its billing and updates the billing status
+// to the new amount due. It is refused when the billing's payments would exceed that amount;
+// the payments have to be refunded first. It is also refused while the billing has a pending
+// checkout, which would still collect the old amount.
+func (s *adjustmentService) ApproveAdjustment(id, reviewerID uint, note string) (*models.BillingAdjustment, error) {
+	adjustment, err := s.getPendingAdjustment(id)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.CreatedByID == reviewerID {
+		return nil, fmt.Errorf("adjustment cannot be approved by its creator")
+	}
+
+	err = s.paymentRepo.WithCheckoutLock(billingLockKeys([]uint{adjustment.BillingID}), func() error {
+		balances, err := loadBillingBalances(s.billingRepo, s.paymentRepo, []uint{adjustment.BillingID})
+		if err != nil {
+			return err
+		}
+		balance := balances[adjustment.BillingID]
+
+		if *balance.billing.Nominal+balance.adjustment+adjustment.Amount < 0 {
+			return fmt.Errorf("%w: adjustment exceeds the adjusted nominal of %d", ErrInvalidBillingAdjustment, *balance.billing.Nominal+balance.adjustment)
+		}
+		if balance.paid > max(balance.due+adjustment.Amount, 0) {
+			return fmt.Errorf("billing payments exceed the adjusted amount")
+		}
+		if err := ensureNoPendingCheckout(s.paymentRepo, []uint{adjustment.BillingID}); err != nil {
+			return err
+		}
+
+		statusID, err := billingStatusAfterChange(s.billingRepo, balance, adjustment.Amount)
+		if err != nil {
+			return err
+		}
+
+		return s.review(adjustment, models.AdjustmentStatusApproved, reviewerID, note, statusID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// RejectAdjustment closes a pending adjustment without changing its billing. Its creator may
+// reject it to withdraw the request.
+func (s *adjustmentService) RejectAdjustment(id, reviewerID uint, note string) (*models.BillingAdjustment, error) {
+	adjustment, err := s.getPendingAdjustment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.review(adjustment, models.AdjustmentStatusRejected, reviewerID, note, 0); err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// getPendingAdjustment loads an adjustment that is still waiting for review
+func (s *adjustmentService) getPendingAdjustment(id uint) (*models.BillingAdjustment, error) {
+	adjustment, err := s.adjustmentRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("adjustment not found")
+		}
+		return nil, fmt.Errorf("failed to get billing adjustment: %w", err)
+	}
+	if adjustment.Status != models.AdjustmentStatusPending {
+		return nil, fmt.Errorf("adjustment is not pending")
+	}
+	return adjustment, nil
+}
+
+// review records the outcome of a pending adjustment, moving its billing to billingStatusID
+// when it is approved
+func (s *adjustmentService) review(adjustment *models.BillingAdjustment, status string, reviewerID uint, note string, billingStatusID uint) error {
+	reviewedAt := time.Now()
+	adjustment.Status = status
+	adjustment.ReviewedByID = &reviewerID
+	adjustment.ReviewedAt = &reviewedAt
+	adjustment.ReviewNote = strings.TrimSpace(note)
+
+	reviewed, err := s.adjustmentRepo.Review(adjustment, billingStatusID)
+	if err != nil {
+		return fmt.Errorf("failed to review billing adjustment: %w", err)
+	}
+	if !reviewed {
+		return fmt.Errorf("adjustment is not pending")
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"id":             adjustment.ID,
+		"billing_id":     adjustment.BillingID,
+		"status":         status,
+		"amount":         adjustment.Amount,
+		"reviewed_by_id": reviewerID,
+	}).Info("Billing adjustment reviewed")
+	return nil
+}
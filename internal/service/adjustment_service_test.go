@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// newTestAdjustmentService wires an AdjustmentService to in-memory repositories holding an
+// unpaid 150000 billing
+func newTestAdjustmentService(t *testing.T) (AdjustmentService, *memoryBillingRepository, *memoryPaymentRepository) {
+	t.Helper()
+
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	billingRepo.addBilling(1, 150000, 3, 2026, testResident)
+
+	return NewAdjustmentService(billingRepo, paymentRepo, &memoryAdjustmentRepository{billingRepo: billingRepo}, newTestLogger()), billingRepo, paymentRepo
+}
+
+// outstandingOf returns what is still owed of a billing
+func outstandingOf(t *testing.T, billingRepo *memoryBillingRepository, paymentRepo *memoryPaymentRepository, billingID uint) int64 {
+	t.Helper()
+
+	balances, err := loadBillingBalances(billingRepo, paymentRepo, []uint{billingID})
+	if err != nil {
+		t.Fatalf("load balance: %v", err)
+	}
+	return balances[billingID].outstanding
+}
+
+func TestApproveAdjustment_ChangesAmountDue(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestAdjustmentService(t)
+	manualPayments := NewManualPaymentService(billingRepo, paymentRepo, t.TempDir(), newTestLogger())
+
+	discount, err := svc.CreateAdjustment(&CreateAdjustmentRequest{BillingID: 1, Type: models.AdjustmentTypeDiscount, Amount: 50000, Reason: "Rumah kosong", CreatedByID: 3})
+	if err != nil {
+		t.Fatalf("create discount: %v", err)
+	}
+	if discount.Amount != -50000 || discount.Status != models.AdjustmentStatusPending {
+		t.Errorf("discount = %+v, want a pending -50000", discount)
+	}
+	if outstanding := outstandingOf(t, billingRepo, paymentRepo, 1); outstanding != 150000 {
+		t.Errorf("outstanding while pending = %d, want 150000", outstanding)
+	}
+
+	if _, err := svc.ApproveAdjustment(discount.ID, 3, ""); err == nil || err.Error() != "adjustment cannot be approved by its creator" {
+		t.Errorf("self approval: err = %v, want adjustment cannot be approved by its creator", err)
+	}
+	approved, err := svc.ApproveAdjustment(discount.ID, 4, "Disetujui")
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if approved.Status != models.AdjustmentStatusApproved || *approved.ReviewedByID != 4 || approved.ReviewNote != "Disetujui" {
+		t.Errorf("approved = %+v, want approved by 4 with its note", approved)
+	}
+	if _, err := svc.ApproveAdjustment(discount.ID, 4, ""); err == nil || err.Error() != "adjustment is not pending" {
+		t.Errorf("approve twice: err = %v, want adjustment is not pending", err)
+	}
+
+	// Payments settle the adjusted amount
+	if _, err := manualPayments.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{1}, Amount: 100000, Method: ManualPaymentMethodCash, PaidAt: time.Now()}); err != nil {
+		t.Fatalf("pay the adjusted amount: %v", err)
+	}
+	if status := billingRepo.statusName(1); status != StatusSudahDibayar {
+		t.Fatalf("status after paying the adjusted amount = %q, want %q", status, StatusSudahDibayar)
+	}
+
+	surcharge, err := svc.CreateAdjustment(&CreateAdjustmentRequest{BillingID: 1, Type: models.AdjustmentTypeSurcharge, Amount: 20000, Reason: "Perbaikan pagar", CreatedByID: 3})
+	if err != nil {
+		t.Fatalf("create surcharge: %v", err)
+	}
+	if _, err := svc.ApproveAdjustment(surcharge.ID, 4, ""); err != nil {
+		t.Fatalf("approve surcharge: %v", err)
+	}
+	if status, outstanding := billingRepo.statusName(1), outstandingOf(t, billingRepo, paymentRepo, 1); status != StatusDibayarSebagian || outstanding != 20000 {
+		t.Errorf("after the surcharge = %q owing %d, want %q owing 20000", status, outstanding, StatusDibayarSebagian)
+	}
+
+	adjustments, err := svc.GetBillingAdjustments(1)
+	if err != nil {
+		t.Fatalf("get adjustments: %v", err)
+	}
+	if len(adjustments.Adjustments) != 2 || adjustments.Adjustment != -30000 || adjustments.AdjustedNominal != 120000 {
+		t.Errorf("adjustments = %+v, want 2 adjusting 150000 to 120000", adjustments)
+	}
+}
+
+func TestApproveAdjustment_WaiverAndPaymentsAboveAdjustedAmount(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestAdjustmentService(t)
+	billingRepo.addBilling(2, 150000, 4, 2026, testResident)
+	manualPayments := NewManualPaymentService(billingRepo, paymentRepo, t.TempDir(), newTestLogger())
+
+	waiver, err := svc.CreateAdjustment(&CreateAdjustmentRequest{BillingID: 1, Type: models.AdjustmentTypeWaiver, Reason: "Pengurus RT", CreatedByID: 3})
+	if err != nil {
+		t.Fatalf("create waiver: %v", err)
+	}
+	if waiver.Amount != -150000 {
+		t.Errorf("waiver amount = %d, want the whole -150000", waiver.Amount)
+	}
+	if _, err := svc.ApproveAdjustment(waiver.ID, 4, ""); err != nil {
+		t.Fatalf("approve waiver: %v", err)
+	}
+	if status := billingRepo.statusName(1); status != StatusSudahDibayar {
+		t.Errorf("waived billing status = %q, want %q", status, StatusSudahDibayar)
+	}
+
+	if _, err := manualPayments.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{2}, Amount: 150000, Method: ManualPaymentMethodCash, PaidAt: time.Now()}); err != nil {
+		t.Fatalf("pay billing 2: %v", err)
+	}
+	discount, err := svc.CreateAdjustment(&CreateAdjustmentRequest{BillingID: 2, Type: models.AdjustmentTypeDiscount, Amount: 50000, Reason: "Rumah kosong", CreatedByID: 3})
+	if err != nil {
+		t.Fatalf("create discount: %v", err)
+	}
+	if _, err := svc.ApproveAdjustment(discount.ID, 4, ""); err == nil || err.Error() != "billing payments exceed the adjusted amount" {
+		t.Errorf("approve below the paid amount: err = %v, want billing payments exceed the adjusted amount", err)
+	}
+
+	rejected, err := svc.RejectAdjustment(discount.ID, 3, "Ditarik")
+	if err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if rejected.Status != models.AdjustmentStatusRejected || billingRepo.statusName(2) != StatusSudahDibayar {
+		t.Errorf("after rejecting = %+v with billing %q, want rejected and the billing untouched", rejected, billingRepo.statusName(2))
+	}
+}
+
+func TestApproveAdjustment_RefusedWhileACheckoutIsPending(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestAdjustmentService(t)
+	addPendingTransaction(t, paymentRepo, "INV-PENDING", 1, time.Now().Add(time.Hour))
+
+	discount, err := svc.CreateAdjustment(&CreateAdjustmentRequest{BillingID: 1, Type: models.AdjustmentTypeDiscount, Amount: 50000, Reason: "Rumah kosong", CreatedByID: 3})
+	if err != nil {
+		t.Fatalf("create discount: %v", err)
+	}
+	if _, err := svc.ApproveAdjustment(discount.ID, 4, ""); !errors.Is(err, ErrPendingCheckout) {
+		t.Errorf("approve with a pending checkout: err = %v, want ErrPendingCheckout", err)
+	}
+	if outstanding := outstandingOf(t, billingRepo, paymentRepo, 1); outstanding != 150000 {
+		t.Errorf("outstanding = %d, want 150000 while the discount waits", outstanding)
+	}
+
+	if _, err := paymentRepo.UpdatePendingTransactionStatus(paymentRepo.transaction("INV-PENDING").ID, models.PaymentStatusCancelled); err != nil {
+		t.Fatalf("cancel checkout: %v", err)
+	}
+	if _, err := svc.ApproveAdjustment(discount.ID, 4, ""); err != nil {
+		t.Fatalf("approve after the checkout is cancelled: %v", err)
+	}
+}
+
+func TestCreateAdjustment_RejectsInvalidRequests(t *testing.T) {
+	svc, _, _ := newTestAdjustmentService(t)
+
+	for _, req := range []*CreateAdjustmentRequest{
+		{BillingID: 1, Type: models.AdjustmentTypeDiscount, Amount: 50000, Reason: " "},
+		{BillingID: 1, Type: "rebate", Amount: 50000, Reason: "Rumah kosong"},
+		{BillingID: 1, Type: models.AdjustmentTypeDiscount, Amount: -50000, Reason: "Rumah kosong"},
+		{BillingID: 1, Type: models.AdjustmentTypeDiscount, Amount: 150001, Reason: "Rumah kosong"},
+		{BillingID: 1, Type: models.AdjustmentTypeCorrection, Reason: "Salah input"},
+		{BillingID: 1, Type: models.AdjustmentTypeCorrection, Amount: -200000, Reason: "Salah input"},
+	} {
+		if _, err := svc.CreateAdjustment(req); !errors.Is(err, ErrInvalidBillingAdjustment) {
+			t.Errorf("create %+v: err = %v, want ErrInvalidBillingAdjustment", req, err)
+		}
+	}
+
+	if _, err := svc.CreateAdjustment(&CreateAdjustmentRequest{BillingID: 9, Type: models.AdjustmentTypeSurcharge, Amount: 1000, Reason: "Denda"}); err == nil || err.Error() != "billing not found" {
+		t.Errorf("unknown billing: err = %v, want billing not found", err)
+	}
+}
+
+func TestDiscountRuleService_ValidatesRules(t *testing.T) {
+	svc := NewDiscountRuleService(&memoryDiscountRuleRepository{}, newTestLogger())
+
+	rule, err := svc.CreateDiscountRule(&DiscountRuleRequest{Name: " Rumah kosong ", UserID: 7, DiscountType: models.FeeTypePercentage, Value: 5000, FromMonth: "2026-03"}, 4)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rule.Name != "Rumah kosong" || !rule.IsActive || rule.FromMonth != "2026-03" || rule.ToMonth != "" || rule.CreatedByID != 4 {
+		t.Errorf("rule = %+v, want an active rule from 2026-03 created by 4", rule)
+	}
+
+	for _, req := range []*DiscountRuleRequest{
+		{Name: "", UserID: 7, DiscountType: models.FeeTypeFlat, Value: 5000},
+		{Name: "Diskon", UserID: 7, DiscountType: "daily", Value: 5000},
+		{Name: "Diskon", UserID: 7, DiscountType: models.FeeTypePercentage, Value: 10001},
+		{Name: "Diskon", UserID: 7, DiscountType: models.FeeTypeFlat, Value: 0},
+		{Name: "Diskon", UserID: 7, DiscountType: models.FeeTypeFlat, Value: 5000, FromMonth: "03-2026"},
+		{Name: "Diskon", UserID: 7, DiscountType: models.FeeTypeFlat, Value: 5000, FromMonth: "2026-06", ToMonth: "2026-03"},
+	} {
+		if _, err := svc.CreateDiscountRule(req, 4); !errors.Is(err, ErrInvalidDiscountRule) {
+			t.Errorf("create %+v: err = %v, want ErrInvalidDiscountRule", req, err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+)
+
+// billingBalance is a billing with what has been paid of it and what is still owed
+type billingBalance struct {
+	billing     *models.Billing
+	status      string
+	adjustment  int64 // Approved adjustments, negative for discounts
+	penalty     int64 // Unwaived late fees
+	due         int64 // Nominal plus adjustment and penalty, never below zero
+	paid        int64
+	outstanding int64
+}
+
+// loadBillingBalances loads the billings and their outstanding balance, approved adjustments and
+// late fees included. A billing marked "Sudah Dibayar" owes nothing, even when it was settled
+// outside payment transactions. Unpublished, e.g. cancelled, billings are not found.
+func loadBillingBalances(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, billingIDs []uint) (map[uint]*billingBalance, error) {
+	statuses, err := billingRepo.GetBillingStatusNames(billingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billing status: %w", err)
+	}
+
+	paidAmounts, err := paymentRepo.GetBillingPaidAmounts(billingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billing payments: %w", err)
+	}
+
+	adjustments, penalties, err := loadBillingCharges(billingRepo, billingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[uint]*billingBalance, len(billingIDs))
+	for _, billingID := range billingIDs {
+		billing, err := billingRepo.GetBillingByID(billingID)
+		if err != nil || billing.PublishedAt == nil {
+			return nil, ErrBillingNotFound
+		}
+		if billing.Nominal == nil || *billing.Nominal <= 0 {
+			return nil, ErrInvalidBillingNominal
+		}
+
+		balance := &billingBalance{
+			billing:    billing,
+			status:     statuses[billingID],
+			adjustment: adjustments[billingID],
+			penalty:    penalties[billingID],
+			paid:       paidAmounts[billingID],
+		}
+		balance.due = max(*billing.Nominal+balance.adjustment+balance.penalty, 0)
+		if balance.status != StatusSudahDibayar && balance.paid < balance.due {
+			balance.outstanding = balance.due - balance.paid
+		}
+		balances[billingID] = balance
+	}
+
+	return balances, nil
+}
+
+// billingStatusName returns the status of a billing owing due of which paid has been paid
+func billingStatusName(due, paid int64) string {
+	switch {
+	case paid >= due:
+		return StatusSudahDibayar
+	case paid > 0:
+		return StatusDibayarSebagian
+	default:
+		return StatusBelumDibayar
+	}
+}
+
+// loadBillingCharges loads the approved adjustments and unwaived late fees of each billing, the
+// amounts owed on top of its nominal
+func loadBillingCharges(billingRepo repository.BillingRepository, billingIDs []uint) (map[uint]int64, map[uint]int64, error) {
+	adjustments, err := billingRepo.GetAdjustmentTotals(billingIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get billing adjustments: %w", err)
+	}
+
+	penalties, err := billingRepo.GetPenaltyTotals(billingIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get billing late fees: %w", err)
+	}
+
+	return adjustments, penalties, nil
+}
+
+// billingStatusIDsFor resolves the status each billing moves to once paidAmounts of it are paid,
+// counting its approved adjustments and unwaived late fees
+func billingStatusIDsFor(billingRepo repository.BillingRepository, paidAmounts map[uint]int64) (map[uint]uint, error) {
+	statusIDs := make(map[string]uint)
+	result := make(map[uint]uint, len(paidAmounts))
+
+	billingIDs := make([]uint, 0, len(paidAmounts))
+	for billingID := range paidAmounts {
+		billingIDs = append(billingIDs, billingID)
+	}
+	adjustments, penalties, err := loadBillingCharges(billingRepo, billingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for billingID, paid := range paidAmounts {
+		billing, err := billingRepo.GetBillingByID(billingID)
+		if err != nil {
+			return nil, ErrBillingNotFound
+		}
+		due := adjustments[billingID] + penalties[billingID]
+		if billing.Nominal != nil {
+			due += *billing.Nominal
+		}
+
+		name := billingStatusName(due, paid)
+		if _, ok := statusIDs[name]; !ok {
+			status, err := billingRepo.GetStatusByName(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get billing status %q: %w", name, err)
+			}
+			statusIDs[name] = status.ID
+		}
+		result[billingID] = statusIDs[name]
+	}
+
+	return result, nil
+}
+
+// billingStatusAfterChange resolves the status ID of a billing once its amount due changes by
+// delta. A billing marked paid without payments covering it stays paid.
+func billingStatusAfterChange(billingRepo repository.BillingRepository, balance *billingBalance, delta int64) (uint, error) {
+	name := StatusSudahDibayar
+	if balance.status != StatusSudahDibayar || balance.paid >= balance.due {
+		name = billingStatusName(balance.due+delta, balance.paid)
+	}
+
+	status, err := billingRepo.GetStatusByName(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get billing status %q: %w", name, err)
+	}
+	return status.ID, nil
+}
+
+// billingLockKeys returns the advisory lock keys that serialize balance changes of each billing,
+// in a fixed order so two holders cannot deadlock
+func billingLockKeys(billingIDs []uint) []string {
+	keys := make([]string, 0, len(billingIDs))
+	for _, id := range uniqueSortedIDs(billingIDs) {
+		keys = append(keys, "billing:"+strconv.FormatUint(uint64(id), 10))
+	}
+	return keys
+}
+
+// ensureNoPendingCheckout fails with ErrPendingCheckout while any of the billings has a pending,
+// unexpired checkout. DOKU cannot close a checkout, so one still open would collect the amount it
+// was created for after the billing is cancelled or re-priced. Call it under billingLockKeys.
+func ensureNoPendingCheckout(paymentRepo repository.PaymentRepository, billingIDs []uint) error {
+	if len(billingIDs) == 0 {
+		return nil
+	}
+	pending, err := paymentRepo.GetPendingTransactionsByBillingIDs(billingIDs, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to get pending payment transactions: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: cancel %s or wait for it to expire", ErrPendingCheckout, pending[0].InvoiceNumber)
+	}
+	return nil
+}
+
+// sortedAllocationIDs returns the billing IDs of an allocation in ascending order
+func sortedAllocationIDs(allocations map[uint]int64) []uint {
+	ids := make([]uint, 0, len(allocations))
+	for id := range allocations {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
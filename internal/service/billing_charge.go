@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// ErrInvalidOneOffBilling is returned when a one-off billing request fails validation
+var ErrInvalidOneOffBilling = errors.New("invalid one-off billing request")
+
+// CreateOneOffBillingRequest represents a one-off charge to bill to a set of residents
+type CreateOneOffBillingRequest struct {
+	UserIDs     []uint
+	NamaBilling string
+	Nominal     int64
+	Month       int
+	Year        int
+	DueDate     string // YYYY-MM-DD; empty for the billing due day of the month
+	Keterangan  string
+	CreatedByID uint
+}
+
+// OneOffBillingResponse represents the billings created for a one-off charge
+type OneOffBillingResponse struct {
+	Charge       *models.BillingCharge `json:"charge"`
+	CreatedCount int                   `json:"created_count"`
+	TotalNominal int64                 `json:"total_nominal"`
+	BillingIDs   []uint                `json:"billing_ids"`
+	SkippedUsers []uint                `json:"skipped_user_ids,omitempty"` // Users not found or without a resident profile
+}
+
+// CreateOneOffBillings bills a one-off charge, such as a 17-Agustusan contribution, to the given
+// residents: one unpaid billing each for the period, written with the same links as generated
+// billings and recorded against the charge. Tariffs, proration and discount rules do not apply.
+func (s *billingService) CreateOneOffBillings(req *CreateOneOffBillingRequest) (*OneOffBillingResponse, error) {
+	name := strings.TrimSpace(req.NamaBilling)
+	switch {
+	case name == "":
+		return nil, fmt.Errorf("%w: nama_billing is required", ErrInvalidOneOffBilling)
+	case req.Nominal <= 0:
+		return nil, fmt.Errorf("%w: nominal must be positive", ErrInvalidOneOffBilling)
+	case req.Month < 1 || req.Month > 12:
+		return nil, fmt.Errorf("%w: month must be between 1 and 12", ErrInvalidOneOffBilling)
+	case len(req.UserIDs) == 0:
+		return nil, fmt.Errorf("%w: user_ids is required", ErrInvalidOneOffBilling)
+	}
+
+	dueDate := billingDueDate(req.Month, req.Year, s.policy.DueDay)
+	if req.DueDate != "" {
+		date, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: due_date %q is not YYYY-MM-DD", ErrInvalidOneOffBilling, req.DueDate)
+		}
+		dueDate = date
+	}
+
+	userIDs := uniqueSortedIDs(req.UserIDs)
+	users, err := s.billingRepo.GetUsersWithProfile(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w: none of the users has a resident profile", ErrInvalidOneOffBilling)
+	}
+
+	unpaidStatus, err := s.billingRepo.GetStatusByName(StatusBelumDibayar)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default status: %w", err)
+	}
+
+	charge := &models.BillingCharge{
+		NamaBilling: name,
+		Nominal:     req.Nominal,
+		Bulan:       req.Month,
+		Tahun:       req.Year,
+		DueDate:     dueDate,
+		Keterangan:  strings.TrimSpace(req.Keterangan),
+		CreatedByID: req.CreatedByID,
+	}
+
+	now := time.Now()
+	generated := make([]*models.GeneratedBilling, len(users))
+	for i, user := range users {
+		generated[i] = &models.GeneratedBilling{
+			Billing: newGeneratedBilling(req.Month, req.Year, req.Nominal, now),
+			Source: &models.BillingSource{
+				UserID:      user.ID,
+				Bulan:       req.Month,
+				Tahun:       req.Year,
+				NamaBilling: name,
+				BaseNominal: req.Nominal,
+			},
+			Detail:   &models.BillingDetail{DueDate: &dueDate},
+			StatusID: unpaidStatus.ID,
+		}
+	}
+
+	if err := s.billingRepo.CreateBillingCharge(charge, generated); err != nil {
+		s.logger.WithError(err).WithField("nama_billing", name).Error("Failed to create one-off billings")
+		return nil, fmt.Errorf("failed to create one-off billings: %w", err)
+	}
+
+	response := &OneOffBillingResponse{
+		Charge:       charge,
+		CreatedCount: len(generated),
+		TotalNominal: req.Nominal * int64(len(generated)),
+		BillingIDs:   make([]uint, len(generated)),
+		SkippedUsers: subtractIDs(userIDs, userIDsOf(users)),
+	}
+	for i, g := range generated {
+		response.BillingIDs[i] = g.Billing.ID
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"charge_id":     charge.ID,
+		"nama_billing":  name,
+		"created_count": response.CreatedCount,
+	}).Info("One-off billings created")
+
+	return response, nil
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidBillingUpdate is returned when a billing listing, update or cancellation request
+// fails validation
+var ErrInvalidBillingUpdate = errors.New("invalid billing request")
+
+// BillingManagementService defines the interface for listing, reading, correcting and cancelling
+// individual billings. Cancelling unpublishes a billing, which takes it out of every listing,
+// payment and late fee assessment, and keeps bulk generation from creating it again.
+type BillingManagementService interface {
+	ListBillings(req *ListBillingsRequest) ([]*models.BillingRecord, int64, error)
+	GetBilling(id uint) (*BillingRecordDetail, error)
+	UpdateBilling(id uint, req *UpdateBillingRequest) (*BillingRecordDetail, error)
+	CancelBilling(id uint, req *CancelBillingRequest) (*BillingRecordDetail, error)
+}
+
+// ListBillingsRequest represents the filters, sorting and page of a billing listing. Dates are
+// YYYY-MM-DD and their ranges inclusive.
+type ListBillingsRequest struct {
+	UserID      *uint
+	Month       *int
+	Year        *int
+	Status      string // Status name, e.g. "Belum Dibayar"
+	CategoryID  *uint  // master_kategori_transaksis ID
+	CreatedFrom string
+	CreatedTo   string
+	DueFrom     string
+	DueTo       string
+	Cancelled   bool   // List the cancelled billings instead of the published ones
+	Sort        string // created_at (default), due_date, nominal or period
+	Order       string // desc (default) or asc
+	Limit       int
+	Offset      int
+}
+
+// UpdateBillingRequest represents a correction of a billing; omitted fields are left unchanged
+type UpdateBillingRequest struct {
+	Nominal     *int64
+	DueDate     *string // YYYY-MM-DD
+	Notes       *string
+	UpdatedByID uint
+}
+
+// CancelBillingRequest represents the cancellation of a billing
+type CancelBillingRequest struct {
+	Reason        string
+	CancelledByID uint
+}
+
+// BillingRecordDetail represents a billing with its links and, while it is published, what it owes
+type BillingRecordDetail struct {
+	*models.BillingRecord
+	Balance *BillingRecordBalance `json:"balance,omitempty"`
+}
+
+// BillingRecordBalance represents the amount due of a billing and what of it is paid
+type BillingRecordBalance struct {
+	Adjustment  int64 `json:"adjustment"` // Approved adjustments, negative for discounts
+	LateFee     int64 `json:"late_fee"`   // Unwaived late fees
+	AmountDue   int64 `json:"amount_due"` // Nominal plus adjustment and late fee
+	Paid        int64 `json:"paid"`
+	Outstanding int64 `json:"outstanding"`
+}
+
+// billingSortFields are the fields billings can be listed by
+var billingSortFields = map[string]bool{"created_at": true, "due_date": true, "nominal": true, "period": true}
+
+// billingManagementService implements BillingManagementService
+type billingManagementService struct {
+	billingRepo repository.BillingRepository
+	paymentRepo repository.PaymentRepository
+	dueDay      int
+	logger      *logger.Logger
+}
+
+// NewBillingManagementService creates a new instance of BillingManagementService. Billings
+// without a due date of their own are due on dueDay of their month.
+func NewBillingManagementService(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, dueDay int, logger *logger.Logger) BillingManagementService {
+	return &billingManagementService{
+		billingRepo: billingRepo,
+		paymentRepo: paymentRepo,
+		dueDay:      dueDay,
+		logger:      logger,
+	}
+}
+
+// ListBillings returns a page of the billings matching the request and how many match in total
+func (s *billingManagementService) ListBillings(req *ListBillingsRequest) ([]*models.BillingRecord, int64, error) {
+	filter := &repository.BillingFilter{
+		UserID:     req.UserID,
+		Month:      req.Month,
+		Year:       req.Year,
+		StatusName: strings.TrimSpace(req.Status),
+		CategoryID: req.CategoryID,
+		Cancelled:  req.Cancelled,
+		DueDay:     s.dueDay,
+		Sort:       req.Sort,
+		Descending: true,
+	}
+	if filter.Sort == "" {
+		filter.Sort = "created_at"
+	}
+	if !billingSortFields[filter.Sort] {
+		return nil, 0, fmt.Errorf("%w: sort must be created_at, due_date, nominal or period", ErrInvalidBillingUpdate)
+	}
+	switch strings.ToLower(req.Order) {
+	case "", "desc":
+	case "asc":
+		filter.Descending = false
+	default:
+		return nil, 0, fmt.Errorf("%w: order must be asc or desc", ErrInvalidBillingUpdate)
+	}
+	if req.Month != nil && (*req.Month < 1 || *req.Month > 12) {
+		return nil, 0, fmt.Errorf("%w: month must be between 1 and 12", ErrInvalidBillingUpdate)
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseBillingDate("created_from", req.CreatedFrom); err != nil {
+		return nil, 0, err
+	}
+	if filter.CreatedTo, err = parseBillingDate("created_to", req.CreatedTo); err != nil {
+		return nil, 0, err
+	}
+	if filter.CreatedTo != nil {
+		// created_at is a timestamp; the range takes in the whole last day, in WIB
+		end := time.Date(filter.CreatedTo.Year(), filter.CreatedTo.Month(), filter.CreatedTo.Day()+1, 0, 0, 0, 0, dokuTimezone)
+		filter.CreatedTo = &end
+	}
+	if filter.CreatedFrom != nil {
+		start := time.Date(filter.CreatedFrom.Year(), filter.CreatedFrom.Month(), filter.CreatedFrom.Day(), 0, 0, 0, 0, dokuTimezone)
+		filter.CreatedFrom = &start
+	}
+	if filter.DueFrom, err = parseBillingDate("due_from", req.DueFrom); err != nil {
+		return nil, 0, err
+	}
+	if filter.DueTo, err = parseBillingDate("due_to", req.DueTo); err != nil {
+		return nil, 0, err
+	}
+
+	billings, total, err := s.billingRepo.ListBillings(filter, req.Limit, req.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get billings: %w", err)
+	}
+	return billings, total, nil
+}
+
+// GetBilling returns a billing with its links and, while it is published, its balance
+func (s *billingManagementService) GetBilling(id uint) (*BillingRecordDetail, error) {
+	record, err := s.getRecord(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withBalance(record)
+}
+
+// UpdateBilling corrects the nominal, due date or notes of a published billing. A new nominal
+// must still cover what is paid, and the status follows it. Late fees already assessed are kept.
+// The nominal cannot change while the billing has a pending checkout.
+func (s *billingManagementService) UpdateBilling(id uint, req *UpdateBillingRequest) (*BillingRecordDetail, error) {
+	if req.Nominal == nil && req.DueDate == nil && req.Notes == nil {
+		return nil, fmt.Errorf("%w: nominal, due_date or notes is required", ErrInvalidBillingUpdate)
+	}
+	if req.Nominal != nil && *req.Nominal <= 0 {
+		return nil, fmt.Errorf("%w: nominal must be positive", ErrInvalidBillingUpdate)
+	}
+	var dueDate *time.Time
+	if req.DueDate != nil {
+		date, err := parseBillingDate("due_date", *req.DueDate)
+		if err != nil {
+			return nil, err
+		}
+		if date == nil {
+			return nil, fmt.Errorf("%w: due_date cannot be empty", ErrInvalidBillingUpdate)
+		}
+		dueDate = date
+	}
+
+	err := s.paymentRepo.WithCheckoutLock(billingLockKeys([]uint{id}), func() error {
+		record, err := s.getPublishedRecord(id)
+		if err != nil {
+			return err
+		}
+
+		balances, err := loadBillingBalances(s.billingRepo, s.paymentRepo, []uint{id})
+		if err != nil {
+			return err
+		}
+		balance := balances[id]
+
+		nominal, delta := *balance.billing.Nominal, int64(0)
+		if req.Nominal != nil {
+			nominal, delta = *req.Nominal, *req.Nominal-*balance.billing.Nominal
+		}
+		if delta != 0 {
+			if err := ensureNoPendingCheckout(s.paymentRepo, []uint{id}); err != nil {
+				return err
+			}
+		}
+		if balance.paid > max(balance.due+delta, 0) {
+			return fmt.Errorf("billing payments exceed the updated nominal")
+		}
+		statusID, err := billingStatusAfterChange(s.billingRepo, balance, delta)
+		if err != nil {
+			return err
+		}
+
+		detail := &models.BillingDetail{DueDate: dueDate, Notes: record.Notes}
+		if req.Notes != nil {
+			detail.Notes = strings.TrimSpace(*req.Notes)
+		}
+
+		if err := s.billingRepo.UpdateBilling(id, nominal, detail, statusID); err != nil {
+			s.logger.WithError(err).WithField("billing_id", id).Error("Failed to update billing")
+			return fmt.Errorf("failed to update billing: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"billing_id":    id,
+		"updated_by_id": req.UpdatedByID,
+	}).Info("Billing updated")
+
+	return s.GetBilling(id)
+}
+
+// CancelBilling cancels a published billing without payments or a pending checkout by
+// unpublishing it
+func (s *billingManagementService) CancelBilling(id uint, req *CancelBillingRequest) (*BillingRecordDetail, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidBillingUpdate)
+	}
+
+	err := s.paymentRepo.WithCheckoutLock(billingLockKeys([]uint{id}), func() error {
+		record, err := s.getPublishedRecord(id)
+		if err != nil {
+			return err
+		}
+
+		paidAmounts, err := s.paymentRepo.GetBillingPaidAmounts([]uint{id})
+		if err != nil {
+			return fmt.Errorf("failed to get billing payments: %w", err)
+		}
+		if paidAmounts[id] > 0 || record.StatusName == StatusSudahDibayar {
+			return fmt.Errorf("billing has payments")
+		}
+		if err := ensureNoPendingCheckout(s.paymentRepo, []uint{id}); err != nil {
+			return err
+		}
+
+		cancelledAt, cancelledByID := time.Now(), req.CancelledByID
+		detail := &models.BillingDetail{
+			BillingID:     id,
+			Notes:         record.Notes,
+			CancelledAt:   &cancelledAt,
+			CancelledByID: &cancelledByID,
+			CancelReason:  reason,
+		}
+
+		if err := s.billingRepo.CancelBilling(detail); err != nil {
+			s.logger.WithError(err).WithField("billing_id", id).Error("Failed to cancel billing")
+			return fmt.Errorf("failed to cancel billing: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"billing_id":      id,
+		"cancelled_by_id": req.CancelledByID,
+	}).Info("Billing cancelled")
+
+	return s.GetBilling(id)
+}
+
+// getRecord loads a billing with its links
+func (s *billingManagementService) getRecord(id uint) (*models.BillingRecord, error) {
+	record, err := s.billingRepo.GetBillingRecord(id, s.dueDay)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("billing not found")
+		}
+		return nil, fmt.Errorf("failed to get billing: %w", err)
+	}
+	return record, nil
+}
+
+// getPublishedRecord loads a billing that can still be changed: published, and not cancelled
+func (s *billingManagementService) getPublishedRecord(id uint) (*models.BillingRecord, error) {
+	record, err := s.getRecord(id)
+	if err != nil {
+		return nil, err
+	}
+	if record.PublishedAt == nil {
+		if record.CancelledAt != nil {
+			return nil, fmt.Errorf("billing is cancelled")
+		}
+		return nil, fmt.Errorf("billing not found")
+	}
+	return record, nil
+}
+
+// withBalance adds the balance of a published billing to its record
+func (s *billingManagementService) withBalance(record *models.BillingRecord) (*BillingRecordDetail, error) {
+	detail := &BillingRecordDetail{BillingRecord: record}
+	if record.PublishedAt == nil || record.Nominal <= 0 {
+		return detail, nil
+	}
+
+	balances, err := loadBillingBalances(s.billingRepo, s.paymentRepo, []uint{record.ID})
+	if err != nil {
+		return nil, err
+	}
+	balance := balances[record.ID]
+	detail.Balance = &BillingRecordBalance{
+		Adjustment:  balance.adjustment,
+		LateFee:     balance.penalty,
+		AmountDue:   balance.due,
+		Paid:        balance.paid,
+		Outstanding: balance.outstanding,
+	}
+	return detail, nil
+}
+
+// parseBillingDate parses an optional YYYY-MM-DD field of a billing request
+func parseBillingDate(field, value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %q is not YYYY-MM-DD", ErrInvalidBillingUpdate, field, value)
+	}
+	return &date, nil
+}
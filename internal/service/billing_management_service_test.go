@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestBillingManagementService wires a BillingManagementService to in-memory repositories
+// holding an unpaid 150000 billing for March 2026
+func newTestBillingManagementService(t *testing.T) (BillingManagementService, *memoryBillingRepository, *memoryPaymentRepository) {
+	t.Helper()
+
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	billingRepo.addBilling(1, 150000, 3, 2026, testResident)
+
+	return NewBillingManagementService(billingRepo, paymentRepo, 10, newTestLogger()), billingRepo, paymentRepo
+}
+
+func TestUpdateBilling(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestBillingManagementService(t)
+	manualPayments := NewManualPaymentService(billingRepo, paymentRepo, t.TempDir(), newTestLogger())
+
+	nominal, zero, dueDate, badDate, notes := int64(120000), int64(0), "2026-03-20", "20/03/2026", " Dikoreksi "
+	for _, req := range []UpdateBillingRequest{
+		{},
+		{Nominal: &zero},
+		{DueDate: &badDate},
+	} {
+		if _, err := svc.UpdateBilling(1, &req); !errors.Is(err, ErrInvalidBillingUpdate) {
+			t.Errorf("request %+v: err = %v, want ErrInvalidBillingUpdate", req, err)
+		}
+	}
+	if _, err := svc.UpdateBilling(9, &UpdateBillingRequest{Notes: &notes}); err == nil || err.Error() != "billing not found" {
+		t.Errorf("update an unknown billing: err = %v, want billing not found", err)
+	}
+
+	updated, err := svc.UpdateBilling(1, &UpdateBillingRequest{Nominal: &nominal, DueDate: &dueDate, Notes: &notes, UpdatedByID: 3})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.Nominal != 120000 || !updated.DueDate.Equal(time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)) || updated.Notes != "Dikoreksi" {
+		t.Errorf("updated = %+v, want 120000 due 2026-03-20 with the notes", updated.BillingRecord)
+	}
+	if updated.Balance == nil || updated.Balance.Outstanding != 120000 {
+		t.Errorf("balance = %+v, want 120000 outstanding", updated.Balance)
+	}
+
+	if _, err := manualPayments.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{1}, Amount: 100000, Method: ManualPaymentMethodCash, PaidAt: time.Now()}); err != nil {
+		t.Fatalf("pay part: %v", err)
+	}
+	below := int64(90000)
+	if _, err := svc.UpdateBilling(1, &UpdateBillingRequest{Nominal: &below}); err == nil || err.Error() != "billing payments exceed the updated nominal" {
+		t.Errorf("update below the payments: err = %v, want billing payments exceed the updated nominal", err)
+	}
+
+	paid := int64(100000)
+	if _, err := svc.UpdateBilling(1, &UpdateBillingRequest{Nominal: &paid}); err != nil {
+		t.Fatalf("update to the payments: %v", err)
+	}
+	if status := billingRepo.statusName(1); status != StatusSudahDibayar {
+		t.Errorf("status after lowering the nominal to the payments = %q, want %q", status, StatusSudahDibayar)
+	}
+	if detail := billingRepo.details[1]; detail.Notes != "Dikoreksi" || !detail.DueDate.Equal(time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("detail = %+v, want the notes and due date kept", detail)
+	}
+}
+
+func TestCancelBilling(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestBillingManagementService(t)
+	billingRepo.addBilling(2, 150000, 3, 2026, testResident)
+	manualPayments := NewManualPaymentService(billingRepo, paymentRepo, t.TempDir(), newTestLogger())
+
+	if _, err := svc.CancelBilling(1, &CancelBillingRequest{Reason: " ", CancelledByID: 3}); !errors.Is(err, ErrInvalidBillingUpdate) {
+		t.Errorf("cancel without reason: err = %v, want ErrInvalidBillingUpdate", err)
+	}
+
+	cancelled, err := svc.CancelBilling(1, &CancelBillingRequest{Reason: "Tagihan ganda", CancelledByID: 3})
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if cancelled.PublishedAt != nil || cancelled.CancelledAt == nil || *cancelled.CancelledByID != 3 || cancelled.CancelReason != "Tagihan ganda" || cancelled.Balance != nil {
+		t.Errorf("cancelled = %+v, want unpublished, cancelled by 3 and without a balance", cancelled.BillingRecord)
+	}
+
+	if _, err := svc.CancelBilling(1, &CancelBillingRequest{Reason: "Lagi", CancelledByID: 3}); err == nil || err.Error() != "billing is cancelled" {
+		t.Errorf("cancel twice: err = %v, want billing is cancelled", err)
+	}
+	notes := "Catatan"
+	if _, err := svc.UpdateBilling(1, &UpdateBillingRequest{Notes: &notes}); err == nil || err.Error() != "billing is cancelled" {
+		t.Errorf("update a cancelled billing: err = %v, want billing is cancelled", err)
+	}
+	if _, err := manualPayments.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{1}, Amount: 150000, Method: ManualPaymentMethodCash, PaidAt: time.Now()}); err == nil {
+		t.Error("paying a cancelled billing succeeded, want an error")
+	}
+
+	if _, err := manualPayments.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{2}, Amount: 50000, Method: ManualPaymentMethodCash, PaidAt: time.Now()}); err != nil {
+		t.Fatalf("pay part of billing 2: %v", err)
+	}
+	if _, err := svc.CancelBilling(2, &CancelBillingRequest{Reason: "Tagihan ganda", CancelledByID: 3}); err == nil || err.Error() != "billing has payments" {
+		t.Errorf("cancel a partly paid billing: err = %v, want billing has payments", err)
+	}
+}
+
+func TestCancelBilling_NotGeneratedAgain(t *testing.T) {
+	billingService, billingRepo, _ := newTestBillingService(t)
+	svc := NewBillingManagementService(billingRepo, newMemoryPaymentRepository(billingRepo), 10, newTestLogger())
+
+	if _, err := billingService.CreateBulkMonthlyBillings([]uint{10}, 3, 2026, BulkBillingOptions{}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if _, err := svc.CancelBilling(1, &CancelBillingRequest{Reason: "Dibebaskan", CancelledByID: 3}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	again, err := billingService.CreateBulkMonthlyBillings([]uint{10}, 3, 2026, BulkBillingOptions{Existing: ExistingBillingsReplaceUnpaid})
+	if err != nil {
+		t.Fatalf("generate again: %v", err)
+	}
+	if again.CreatedCount != 0 || again.ReplacedCount != 0 || again.SkippedCount != 2 || len(billingRepo.billings) != 2 {
+		t.Errorf("second run = %+v, want the cancelled billing skipped", again)
+	}
+}
+
+func TestListBillings_RejectsInvalidRequests(t *testing.T) {
+	svc, _, _ := newTestBillingManagementService(t)
+
+	month := 13
+	for _, req := range []ListBillingsRequest{
+		{Sort: "amount"},
+		{Order: "up"},
+		{Month: &month},
+		{CreatedFrom: "2026-13-01"},
+		{DueTo: "yesterday"},
+	} {
+		if _, _, err := svc.ListBillings(&req); !errors.Is(err, ErrInvalidBillingUpdate) {
+			t.Errorf("request %+v: err = %v, want ErrInvalidBillingUpdate", req, err)
+		}
+	}
+}
+
+func TestBillingChanges_RefusedWhileACheckoutIsPending(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestBillingManagementService(t)
+	addPendingTransaction(t, paymentRepo, "INV-PENDING", 1, time.Now().Add(time.Hour))
+
+	nominal, notes := int64(120000), "Catatan"
+	if _, err := svc.UpdateBilling(1, &UpdateBillingRequest{Nominal: &nominal}); !errors.Is(err, ErrPendingCheckout) {
+		t.Errorf("re-price with a pending checkout: err = %v, want ErrPendingCheckout", err)
+	}
+	if _, err := svc.CancelBilling(1, &CancelBillingRequest{Reason: "Tagihan ganda", CancelledByID: 3}); !errors.Is(err, ErrPendingCheckout) {
+		t.Errorf("cancel with a pending checkout: err = %v, want ErrPendingCheckout", err)
+	}
+	if _, err := svc.UpdateBilling(1, &UpdateBillingRequest{Notes: &notes}); err != nil {
+		t.Errorf("update notes with a pending checkout: %v", err)
+	}
+	if nominal := *billingRepo.billings[1].Nominal; nominal != 150000 {
+		t.Errorf("nominal = %d, want 150000 kept", nominal)
+	}
+
+	billingRepo.addBilling(2, 150000, 3, 2026, testResident)
+	addPendingTransaction(t, paymentRepo, "INV-EXPIRED", 2, time.Now().Add(-time.Minute))
+	if _, err := svc.CancelBilling(2, &CancelBillingRequest{Reason: "Tagihan ganda", CancelledByID: 3}); err != nil {
+		t.Errorf("cancel with an expired checkout: %v", err)
+	}
+}
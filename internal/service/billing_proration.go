@@ -0,0 +1,84 @@
+package service
+
+import (
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// How bulk generation charges the first and last month of an occupancy
+const (
+	ProrationNone      = "none"       // Every occupied month is charged in full
+	ProrationDaily     = "daily"      // The nominal is shared out over the days of the month
+	ProrationHalfMonth = "half_month" // Half the nominal for half a month or less, else in full
+)
+
+// BillingProration is how the nominal of a billing was prorated for a partly occupied month
+type BillingProration struct {
+	Policy       string `json:"policy"`
+	BaseNominal  int64  `json:"base_nominal"` // Nominal of a full month
+	OccupiedDays int    `json:"occupied_days"`
+	DaysInMonth  int    `json:"days_in_month"`
+}
+
+// validProration reports whether policy is a known proration policy
+func validProration(policy string) bool {
+	return policy == ProrationNone || policy == ProrationDaily || policy == ProrationHalfMonth
+}
+
+// occupiedDays returns how many days of month and year a unit was occupied, and the days in the
+// month. A resident without a unit or occupancy dates occupies every day.
+func occupiedDays(unit *models.ResidentUnit, month, year int) (int, int) {
+	first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	daysInMonth := last.Day()
+	if unit == nil {
+		return daysInMonth, daysInMonth
+	}
+
+	if unit.OccupiedFrom != nil && dateOfDay(*unit.OccupiedFrom).After(first) {
+		first = dateOfDay(*unit.OccupiedFrom)
+	}
+	if unit.OccupiedUntil != nil && dateOfDay(*unit.OccupiedUntil).Before(last) {
+		last = dateOfDay(*unit.OccupiedUntil)
+	}
+	if last.Before(first) {
+		return 0, daysInMonth
+	}
+	return int(last.Sub(first).Hours()/24) + 1, daysInMonth
+}
+
+// prorate returns what is charged of a full month's nominal for occupying days of a month of
+// daysInMonth days under policy, and the calculation when it is not the full nominal. Daily
+// proration rounds to the nearest rupiah and half a month rounds up.
+func prorate(policy string, nominal int64, days, daysInMonth int) (int64, *BillingProration) {
+	if days >= daysInMonth || policy == ProrationNone || policy == "" {
+		return nominal, nil
+	}
+
+	prorated := nominal
+	switch policy {
+	case ProrationDaily:
+		prorated = (nominal*int64(days) + int64(daysInMonth)/2) / int64(daysInMonth)
+	case ProrationHalfMonth:
+		if days*2 <= daysInMonth {
+			prorated = (nominal + 1) / 2
+		}
+	}
+	if prorated == nominal {
+		return nominal, nil
+	}
+
+	return prorated, &BillingProration{
+		Policy:       policy,
+		BaseNominal:  nominal,
+		OccupiedDays: days,
+		DaysInMonth:  daysInMonth,
+	}
+}
+
+// dateOfDay returns the calendar day of a date read from the database, at midnight UTC
+func dateOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
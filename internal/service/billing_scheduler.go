@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/cron"
+	"ipl-be-svc/pkg/logger"
+)
+
+// billingRunKindMonthly is the kind of the runs generating the monthly (bulanan) billings
+const billingRunKindMonthly = "bulanan"
+
+// BillingScheduler defines a background worker that generates the monthly billings of every
+// penghuni on a schedule, and the history of its runs
+type BillingScheduler interface {
+	Run(ctx context.Context)
+	RunDue(now time.Time) (*models.BillingRun, error)
+	GetRuns(limit, offset int) ([]*models.BillingRun, int64, error)
+}
+
+// billingScheduler implements BillingScheduler
+type billingScheduler struct {
+	billingService BillingService
+	runRepo        repository.BillingRunRepository
+	schedule       *cron.Schedule
+	dayOfMonth     int
+	logger         *logger.Logger
+}
+
+// NewBillingScheduler creates a worker that, on every tick of the cron expression (in WIB)
+// from dayOfMonth onwards, generates the current month's billings unless a run already did
+func NewBillingScheduler(billingService BillingService, runRepo repository.BillingRunRepository, cronExpr string, dayOfMonth int, logger *logger.Logger) (BillingScheduler, error) {
+	schedule, err := cron.Parse(cronExpr)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Next(time.Now().In(dokuTimezone)).IsZero() {
+		return nil, fmt.Errorf("billing scheduler cron expression %q never matches", cronExpr)
+	}
+	if dayOfMonth < 1 || dayOfMonth > 28 {
+		return nil, fmt.Errorf("billing day of month must be between 1 and 28, got %d", dayOfMonth)
+	}
+
+	return &billingScheduler{
+		billingService: billingService,
+		runRepo:        runRepo,
+		schedule:       schedule,
+		dayOfMonth:     dayOfMonth,
+		logger:         logger,
+	}, nil
+}
+
+// Run checks for a due run on every tick of the schedule until ctx is cancelled
+func (s *billingScheduler) Run(ctx context.Context) {
+	s.logger.WithField("day_of_month", s.dayOfMonth).Info("Billing scheduler started")
+
+	for {
+		next := s.schedule.Next(time.Now().In(dokuTimezone))
+		if next.IsZero() {
+			s.logger.Error("Billing scheduler cron expression no longer matches, scheduler stopped")
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.logger.Info("Billing scheduler stopped")
+			return
+		case <-timer.C:
+			if _, err := s.RunDue(time.Now()); err != nil {
+				s.logger.WithError(err).Error("Scheduled billing generation failed")
+			}
+		}
+	}
+}
+
+// RunDue generates the billings of the month of now if the billing day has come and no run has
+// succeeded for the month yet. Only one replica runs at a time; the others return nil, as does
+// a call when nothing is due. A failed run is retried on the next tick.
+func (s *billingScheduler) RunDue(now time.Time) (*models.BillingRun, error) {
+	now = now.In(dokuTimezone)
+	if now.Day() < s.dayOfMonth {
+		return nil, nil
+	}
+	month, year := int(now.Month()), now.Year()
+
+	var run *models.BillingRun
+	acquired, err := s.runRepo.TryLock("billing-scheduler:"+billingRunKindMonthly, func() error {
+		done, err := s.runRepo.HasSucceeded(billingRunKindMonthly, month, year)
+		if err != nil {
+			return fmt.Errorf("failed to get billing runs: %w", err)
+		}
+		if done {
+			return nil
+		}
+
+		run, err = s.generate(month, year)
+		return err
+	})
+	if err != nil {
+		return run, err
+	}
+	if !acquired {
+		s.logger.Debug("Billing scheduler is running on another replica")
+	}
+
+	return run, nil
+}
+
+// generate runs the monthly generation for every penghuni and records it in the run history
+func (s *billingScheduler) generate(month, year int) (*models.BillingRun, error) {
+	run := &models.BillingRun{
+		Kind:      billingRunKindMonthly,
+		Bulan:     month,
+		Tahun:     year,
+		Status:    models.BillingRunRunning,
+		StartedAt: time.Now(),
+	}
+	if err := s.runRepo.Create(run); err != nil {
+		return nil, fmt.Errorf("failed to create billing run: %w", err)
+	}
+
+	response, err := s.billingService.CreateBulkMonthlyBillingsForAllUsers(month, year, BulkBillingOptions{})
+	switch {
+	case err != nil:
+		run.Status = models.BillingRunFailed
+		run.Error = err.Error()
+	case len(response.Errors) > 0:
+		run.Status = models.BillingRunFailed
+		run.Error = response.Errors[0]
+	default:
+		run.Status = models.BillingRunSucceeded
+	}
+	if response != nil {
+		run.CreatedCount = response.CreatedCount
+		run.ReplacedCount = response.ReplacedCount
+		run.SkippedCount = response.SkippedCount
+		run.FailedCount = response.FailedCount
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if err := s.runRepo.Update(run); err != nil {
+		return run, fmt.Errorf("failed to update billing run: %w", err)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"run_id":        run.ID,
+		"month":         month,
+		"year":          year,
+		"status":        run.Status,
+		"created_count": run.CreatedCount,
+		"skipped_count": run.SkippedCount,
+		"failed_count":  run.FailedCount,
+	}).Info("Scheduled billing generation finished")
+
+	if run.Status == models.BillingRunFailed {
+		return run, fmt.Errorf("billing generation failed: %s", run.Error)
+	}
+	return run, nil
+}
+
+// GetRuns returns the billing run history, newest first
+func (s *billingScheduler) GetRuns(limit, offset int) ([]*models.BillingRun, int64, error) {
+	runs, total, err := s.runRepo.GetAll(limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get billing runs: %w", err)
+	}
+	return runs, total, nil
+}
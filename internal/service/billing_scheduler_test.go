@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+func TestBillingScheduler_RunDue(t *testing.T) {
+	billingService, billingRepo, _ := newTestBillingService(t)
+	runRepo := &memoryBillingRunRepository{}
+	scheduler, err := NewBillingScheduler(billingService, runRepo, "0 1 * * *", 5, newTestLogger())
+	if err != nil {
+		t.Fatalf("new scheduler: %v", err)
+	}
+
+	if run, err := scheduler.RunDue(time.Date(2026, 3, 4, 1, 0, 0, 0, dokuTimezone)); run != nil || err != nil {
+		t.Fatalf("run before the billing day = %+v, %v; want nothing", run, err)
+	}
+
+	runRepo.locked = true
+	if run, err := scheduler.RunDue(time.Date(2026, 3, 5, 1, 0, 0, 0, dokuTimezone)); run != nil || err != nil {
+		t.Fatalf("run while another replica holds the lock = %+v, %v; want nothing", run, err)
+	}
+	runRepo.locked = false
+
+	run, err := scheduler.RunDue(time.Date(2026, 3, 5, 1, 0, 0, 0, dokuTimezone))
+	if err != nil {
+		t.Fatalf("run on the billing day: %v", err)
+	}
+	if run.Status != models.BillingRunSucceeded || run.Bulan != 3 || run.Tahun != 2026 || run.CreatedCount != 4 || run.FinishedAt == nil {
+		t.Errorf("run = %+v, want a finished successful run creating 4 billings for 3/2026", run)
+	}
+	if len(billingRepo.billings) != 4 {
+		t.Errorf("%d billings stored, want 4", len(billingRepo.billings))
+	}
+
+	if again, err := scheduler.RunDue(time.Date(2026, 3, 6, 1, 0, 0, 0, dokuTimezone)); again != nil || err != nil {
+		t.Errorf("run after a successful one = %+v, %v; want nothing", again, err)
+	}
+	if len(runRepo.runs) != 1 {
+		t.Errorf("%d runs recorded, want 1", len(runRepo.runs))
+	}
+}
+
+func TestBillingScheduler_RetriesFailedRun(t *testing.T) {
+	billingService, billingRepo, _ := newTestBillingService(t)
+	settings := billingRepo.settings
+	billingRepo.settings = nil
+	runRepo := &memoryBillingRunRepository{}
+	scheduler, err := NewBillingScheduler(billingService, runRepo, "0 1 * * *", 1, newTestLogger())
+	if err != nil {
+		t.Fatalf("new scheduler: %v", err)
+	}
+
+	now := time.Date(2026, 4, 1, 1, 0, 0, 0, dokuTimezone)
+	failed, err := scheduler.RunDue(now)
+	if err == nil || failed.Status != models.BillingRunFailed || failed.Error == "" {
+		t.Fatalf("run without settings = %+v, %v; want a failed run", failed, err)
+	}
+
+	billingRepo.settings = settings
+	retried, err := scheduler.RunDue(now.Add(24 * time.Hour))
+	if err != nil || retried.Status != models.BillingRunSucceeded {
+		t.Errorf("retry = %+v, %v; want a successful run", retried, err)
+	}
+}
+
+func TestNewBillingScheduler_InvalidConfig(t *testing.T) {
+	billingService, _, _ := newTestBillingService(t)
+
+	if _, err := NewBillingScheduler(billingService, &memoryBillingRunRepository{}, "0 1 * *", 1, newTestLogger()); err == nil {
+		t.Error("invalid cron expression accepted")
+	}
+	if _, err := NewBillingScheduler(billingService, &memoryBillingRunRepository{}, "0 1 * * *", 31, newTestLogger()); err == nil {
+		t.Error("day of month 31 accepted")
+	}
+	if _, err := NewBillingScheduler(billingService, &memoryBillingRunRepository{}, "0 0 30 2 *", 1, newTestLogger()); err == nil {
+		t.Error("cron expression that never matches accepted")
+	}
+}
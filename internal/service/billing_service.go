@@ -1,89 +1,208 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"ipl-be-svc/internal/models"
 	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
+// How bulk generation treats a billing that already exists for a resident, period and setting
+const (
+	ExistingBillingsSkip          = "skip"
+	ExistingBillingsReplaceUnpaid = "replace_unpaid"
+)
+
+// What bulk generation does with a setting billing for a resident
+const (
+	BulkBillingActionCreate  = "create"
+	BulkBillingActionReplace = "replace"
+	BulkBillingActionSkip    = "skip"
+)
+
+// ErrInvalidBulkBilling is returned when a bulk billing request fails validation
+var ErrInvalidBulkBilling = errors.New("invalid bulk billing request")
+
 // BillingService defines the interface for billing business operations
 type BillingService interface {
-	CreateBulkMonthlyBillings(userIDs []uint, month int, year int) (*BulkBillingResponse, error)
-	CreateBulkMonthlyBillingsForAllUsers(month int, year int) (*BulkBillingResponse, error)
+	CreateBulkMonthlyBillings(userIDs []uint, month int, year int, opts BulkBillingOptions) (*BulkBillingResponse, error)
+	CreateBulkMonthlyBillingsForAllUsers(month int, year int, opts BulkBillingOptions) (*BulkBillingResponse, error)
+	CreateOneOffBillings(req *CreateOneOffBillingRequest) (*OneOffBillingResponse, error)
 	GetBillingPenghuni() ([]*models.BillingPenghuniResponse, error)
 }
 
+// BulkBillingOptions tunes a bulk billing run
+type BulkBillingOptions struct {
+	Existing string // ExistingBillingsSkip (default) or ExistingBillingsReplaceUnpaid
+	DryRun   bool   // Only report what the run would do, without writing anything
+}
+
 // BulkBillingResponse represents the response for bulk billing creation
 type BulkBillingResponse struct {
-	TotalUsers    int      `json:"total_users"`
-	TotalBillings int      `json:"total_billings"`
-	SuccessCount  int      `json:"success_count"`
-	FailedCount   int      `json:"failed_count"`
-	Errors        []string `json:"errors,omitempty"`
+	TotalUsers    int                      `json:"total_users"`
+	TotalBillings int                      `json:"total_billings"`
+	SuccessCount  int                      `json:"success_count"`
+	FailedCount   int                      `json:"failed_count"`
+	CreatedCount  int                      `json:"created_count"`
+	ReplacedCount int                      `json:"replaced_count"`
+	SkippedCount  int                      `json:"skipped_count"`
+	TotalNominal  int64                    `json:"total_nominal"`  // Sum of the created and replaced billings
+	TotalDiscount int64                    `json:"total_discount"` // Sum of their discount rule adjustments
+	DryRun        bool                     `json:"dry_run"`
+	Users         []*BulkBillingUserResult `json:"users,omitempty"`
+	SkippedUsers  []uint                   `json:"skipped_user_ids,omitempty"` // Users not found or without a resident profile
+	Errors        []string                 `json:"errors,omitempty"`
+}
+
+// BulkBillingUserResult counts what a bulk billing run did for one resident
+type BulkBillingUserResult struct {
+	UserID   uint   `json:"user_id"`
+	Created  int    `json:"created"`
+	Replaced int    `json:"replaced"`
+	Skipped  int    `json:"skipped"`
+	Reason   string `json:"reason,omitempty"` // Why every billing of the resident was skipped
+
+	TotalNominal  int64              `json:"total_nominal"`  // Sum of the created and replaced billings
+	TotalDiscount int64              `json:"total_discount"` // Sum of their discount rule adjustments
+	Items         []*BulkBillingItem `json:"items"`
+}
+
+// BulkBillingItem is the outcome of one setting billing for a resident
+type BulkBillingItem struct {
+	SettingBillingID uint   `json:"setting_billing_id"`
+	NamaBilling      string `json:"nama_billing"`
+	Nominal          int64  `json:"nominal"`
+	TariffID         *uint  `json:"tariff_id,omitempty"`        // Tariff that priced the billing instead of the setting nominal
+	TariffName       string `json:"tariff_name,omitempty"`      // Its name
+	Discount         int64  `json:"discount,omitempty"`         // Given by the resident's discount rules
+	Action           string `json:"action"`                     // create, replace or skip
+	BillingID        *uint  `json:"billing_id,omitempty"`       // Existing billing that is replaced or skipped
+	PreviousNominal  *int64 `json:"previous_nominal,omitempty"` // Nominal of the existing billing
+	Reason           string `json:"reason,omitempty"`           // Why an unpaid billing was not replaced
+
+	Proration *BillingProration `json:"proration,omitempty"` // How Nominal was prorated for a partly occupied month
+}
+
+// BillingPolicy holds how generated billings are dated and charged
+type BillingPolicy struct {
+	DueDay    int    // Day of its month a billing is due, 1 to 28
+	Proration string // How the first and last month of an occupancy are charged
+}
+
+// bulkBillingInput holds what a bulk billing run plans from besides the existing billings
+type bulkBillingInput struct {
+	users          []*models.User
+	settings       []*models.SettingBilling
+	discountRules  []*models.BillingDiscountRule
+	tariffs        []*models.SettingBillingTariff
+	units          map[uint]*models.ResidentUnit // user ID -> unit with its occupancy dates and tariff attributes
+	proration      string
+	month, year    int
+	dueDate        time.Time
+	unpaidStatusID uint
+	paidStatusID   uint // For billings discounted to nothing
+
+	pendingCheckouts map[uint]bool // Existing billings with a pending checkout, never replaced
+}
+
+// bulkBillingPlan holds the writes a bulk billing run will make
+type bulkBillingPlan struct {
+	users        []*BulkBillingUserResult
+	created      []*models.GeneratedBilling
+	replacements []*models.GeneratedBilling // Billing holds the ID and new nominal, Source the calculation
 }
 
 // billingService implements BillingService
 type billingService struct {
-	billingRepo repository.BillingRepository
-	db          *gorm.DB
+	billingRepo      repository.BillingRepository
+	paymentRepo      repository.PaymentRepository
+	discountRuleRepo repository.DiscountRuleRepository
+	tariffRepo       repository.TariffRepository
+	policy           BillingPolicy
+	logger           *logger.Logger
 }
 
-// NewBillingService creates a new instance of BillingService
-func NewBillingService(billingRepo repository.BillingRepository, db *gorm.DB) BillingService {
-	return &billingService{
-		billingRepo: billingRepo,
-		db:          db,
+// NewBillingService creates a new instance of BillingService generating billings under policy
+func NewBillingService(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, discountRuleRepo repository.DiscountRuleRepository, tariffRepo repository.TariffRepository, policy BillingPolicy, logger *logger.Logger) (BillingService, error) {
+	if policy.DueDay < 1 || policy.DueDay > 28 {
+		return nil, fmt.Errorf("billing due day must be between 1 and 28, got %d", policy.DueDay)
 	}
+	if !validProration(policy.Proration) {
+		return nil, fmt.Errorf("billing proration must be %s, %s or %s, got %q", ProrationNone, ProrationDaily, ProrationHalfMonth, policy.Proration)
+	}
+
+	return &billingService{
+		billingRepo:      billingRepo,
+		paymentRepo:      paymentRepo,
+		discountRuleRepo: discountRuleRepo,
+		tariffRepo:       tariffRepo,
+		policy:           policy,
+		logger:           logger,
+	}, nil
 }
 
-// CreateBulkMonthlyBillings creates monthly billings for specified user IDs
-func (s *billingService) CreateBulkMonthlyBillings(userIDs []uint, month int, year int) (*BulkBillingResponse, error) {
-	// Always use admin user (ID 1) as the creator
-	adminID := 1
-	createdByInt := &adminID
+// CreateBulkMonthlyBillings creates the billings of a month for the given users: one per active
+// monthly setting and one per yearly setting scheduled for the month. Each billing is priced by
+// the first tariff of its setting matching the resident's unit, or by the setting nominal when
+// none does, and residents are billed only for months their unit is occupied. The first and last
+// of those months are prorated by the proration policy, except for yearly settings. The active
+// discount rules of each resident are applied as approved adjustments, and a billing discounted
+// to nothing is created paid.
+//
+// A billing that already exists for the same resident, period and setting is skipped. With
+// ExistingBillingsReplaceUnpaid it gets the current nominal instead, as long as it is unpaid and
+// has no pending checkout. Runs for the same period are serialized across replicas, and the
+// billing_sources unique key rejects any duplicate that slips through. A dry run makes the same
+// decisions and returns them without writing.
+func (s *billingService) CreateBulkMonthlyBillings(userIDs []uint, month int, year int, opts BulkBillingOptions) (*BulkBillingResponse, error) {
+	if opts.Existing == "" {
+		opts.Existing = ExistingBillingsSkip
+	}
+	if opts.Existing != ExistingBillingsSkip && opts.Existing != ExistingBillingsReplaceUnpaid {
+		return nil, fmt.Errorf("%w: existing must be %s or %s", ErrInvalidBulkBilling, ExistingBillingsSkip, ExistingBillingsReplaceUnpaid)
+	}
 
 	// Get default status ("Belum Dibayar")
-	var defaultStatus models.MasterGeneralStatus
-	if err := s.db.Table("master_general_statuses").Where("status_name = ? AND published_at IS NOT NULL", "Belum Dibayar").First(&defaultStatus).Error; err != nil {
-		// If no default status found, get first available status
-		if err := s.db.Table("master_general_statuses").Where("published_at IS NOT NULL").First(&defaultStatus).Error; err != nil {
-			return nil, fmt.Errorf("failed to get default status: %w", err)
-		}
+	defaultStatus, err := s.billingRepo.GetStatusByName(StatusBelumDibayar)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default status: %w", err)
+	}
+	paidStatus, err := s.billingRepo.GetStatusByName(StatusSudahDibayar)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get paid status: %w", err)
 	}
 
 	// Get setting billings
-	settings, err := s.billingRepo.GetActiveMonthlySettingBillings()
+	settings, err := s.billingRepo.GetActiveSettingBillingsForMonth(month)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get setting billings: %w", err)
 	}
 
 	if len(settings) == 0 {
-		return nil, fmt.Errorf("no active monthly setting billings found")
+		return nil, fmt.Errorf("no active setting billings found for the month")
 	}
 
-	// Get users with profiles
-	var users []*models.User
-	if len(userIDs) > 0 {
-		// Filter specific users
-		for _, userID := range userIDs {
-			user, err := s.getUserWithProfile(userID)
-			if err != nil {
-				continue // Skip if user not found or no profile
-			}
-			users = append(users, user)
-		}
-	} else {
+	// Get users with profiles, skipping those not found or without a profile
+	if len(userIDs) == 0 {
 		// Get all penghuni users
-		users, err = s.billingRepo.GetUsersWithPenghuniRole()
+		penghuni, err := s.billingRepo.GetUsersWithPenghuniRole()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get penghuni users: %w", err)
 		}
+		userIDs = userIDsOf(penghuni)
 	}
+	userIDs = uniqueSortedIDs(userIDs)
+	users, err := s.billingRepo.GetUsersWithProfile(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	skippedUsers := subtractIDs(userIDs, userIDsOf(users))
 
 	if len(users) == 0 {
 		return &BulkBillingResponse{
@@ -91,158 +210,360 @@ func (s *billingService) CreateBulkMonthlyBillings(userIDs []uint, month int, ye
 			TotalBillings: 0,
 			SuccessCount:  0,
 			FailedCount:   0,
+			DryRun:        opts.DryRun,
+			SkippedUsers:  skippedUsers,
 		}, nil
 	}
 
-	// Prepare billings and links
-	var billings []*models.Billing
-	var links []*models.BillingProfileLink
-	var statusLinks []*models.BillingStatusBillLink
-	var kategoriLinks []*models.BillingKategoriTransaksiLink
-	now := time.Now()
+	discountRules, err := s.discountRuleRepo.GetActiveByUserIDs(userIDsOf(users))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discount rules: %w", err)
+	}
 
-	for _, user := range users {
-		for _, setting := range settings {
-			// Skip settings that are not published
-			if setting.PublishedAt == nil {
-				continue
-			}
+	units, err := s.billingRepo.GetUserUnits(userIDsOf(users))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resident units: %w", err)
+	}
 
-			// Generate document ID
-			docID := uuid.New().String()
+	settingIDs := make([]uint, len(settings))
+	for i, setting := range settings {
+		settingIDs[i] = setting.ID
+	}
+	tariffs, err := s.tariffRepo.GetActiveBySettingIDs(settingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tariffs: %w", err)
+	}
 
-			// Convert nominal from float64 to int64
-			nominal := int64(setting.Nominal)
+	input := &bulkBillingInput{
+		users:          users,
+		settings:       settings,
+		discountRules:  discountRules,
+		tariffs:        tariffs,
+		units:          units,
+		proration:      s.policy.Proration,
+		month:          month,
+		year:           year,
+		dueDate:        billingDueDate(month, year, s.policy.DueDay),
+		unpaidStatusID: defaultStatus.ID,
+		paidStatusID:   paidStatus.ID,
+	}
 
-			// Use provided month and year
-			billingMonth := month
-			billingYear := year
+	response := &BulkBillingResponse{
+		TotalUsers:    len(users),
+		TotalBillings: len(users) * len(settings),
+		DryRun:        opts.DryRun,
+		SkippedUsers:  skippedUsers,
+	}
 
-			// Set PublishedAt based on setting's PublishedAt
-			var billingPublishedAt *time.Time
-			if setting.PublishedAt != nil {
-				billingPublishedAt = &now
-			} else {
-				billingPublishedAt = nil
-			}
+	if opts.DryRun {
+		existing, err := s.billingRepo.GetPeriodBillings(userIDsOf(users), month, year)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get existing billings: %w", err)
+		}
+		input.pendingCheckouts, err = s.pendingCheckouts(existing, opts)
+		if err != nil {
+			return nil, err
+		}
 
-			// Create billing
-			billing := &models.Billing{
-				DocumentID:  &docID,
-				Bulan:       &billingMonth,
-				Tahun:       &billingYear,
-				Nominal:     &nominal,
-				CreatedAt:   &now,
-				UpdatedAt:   &now,
-				PublishedAt: billingPublishedAt,
-				CreatedByID: createdByInt,
-				UpdatedByID: createdByInt,
-			}
-			billings = append(billings, billing)
+		response.addPlan(planBulkBillings(input, existing, opts))
+		return response, nil
+	}
 
-			// Create link
-			link := &models.BillingProfileLink{
-				BillingID: billing.ID, // Will be set after insert
-				ProfileID: user.ID,    // Use user ID directly
-			}
-			links = append(links, link)
+	lockKey := fmt.Sprintf("billing-generation:%04d-%02d", year, month)
+	err = s.billingRepo.WithGenerationLock(lockKey, func() error {
+		existing, err := s.billingRepo.GetPeriodBillings(userIDsOf(users), month, year)
+		if err != nil {
+			return fmt.Errorf("failed to get existing billings: %w", err)
+		}
+		input.pendingCheckouts, err = s.pendingCheckouts(existing, opts)
+		if err != nil {
+			return err
+		}
 
-			// Create status link
-			statusLink := &models.BillingStatusBillLink{
-				BillingID:             billing.ID, // Will be set after insert
-				MasterGeneralStatusID: defaultStatus.ID,
+		plan := planBulkBillings(input, existing, opts)
+		response.addPlan(plan)
+
+		replacedIDs := make([]uint, len(plan.replacements))
+		for i, replacement := range plan.replacements {
+			replacedIDs[i] = replacement.Billing.ID
+		}
+		// A checkout opened since the lookup would still collect the old nominal, so the
+		// replaced billings are locked against checkouts and checked again before writing
+		return s.paymentRepo.WithCheckoutLock(billingLockKeys(replacedIDs), func() error {
+			if err := ensureNoPendingCheckout(s.paymentRepo, replacedIDs); err != nil {
+				return err
 			}
-			statusLinks = append(statusLinks, statusLink)
 
-			// Create kategori transaksi link
-			kategoriLink := &models.BillingKategoriTransaksiLink{
-				BillingID:                 billing.ID, // Will be set after insert
-				MasterKategoriTransaksiID: 1,
+			if err := s.billingRepo.CreateGeneratedBillings(plan.created); err != nil {
+				return err
+			}
+			if len(plan.replacements) > 0 {
+				if err := s.billingRepo.UpdateGeneratedBillings(plan.replacements); err != nil {
+					return fmt.Errorf("failed to replace billings: %w", err)
+				}
 			}
-			kategoriLinks = append(kategoriLinks, kategoriLink)
+
+			response.SuccessCount = response.CreatedCount + response.ReplacedCount
+			return nil
+		})
+	})
+
+	if err != nil {
+		s.logger.WithError(err).WithFields(map[string]interface{}{"month": month, "year": year}).Error("Failed to create bulk billings")
+		response.FailedCount = response.TotalBillings - response.SkippedCount
+		response.CreatedCount, response.ReplacedCount, response.TotalNominal, response.TotalDiscount = 0, 0, 0, 0
+		for _, user := range response.Users {
+			user.Created, user.Replaced, user.TotalNominal, user.TotalDiscount = 0, 0, 0, 0
 		}
+		response.Errors = []string{err.Error()}
 	}
 
-	// Execute in transaction
-	response := &BulkBillingResponse{
-		TotalUsers:    len(users),
-		TotalBillings: len(billings),
+	return response, nil
+}
+
+// CreateBulkMonthlyBillingsForAllUsers creates monthly billings for all penghuni users
+func (s *billingService) CreateBulkMonthlyBillingsForAllUsers(month int, year int, opts BulkBillingOptions) (*BulkBillingResponse, error) {
+	return s.CreateBulkMonthlyBillings([]uint{}, month, year, opts)
+}
+
+// pendingCheckouts returns the unpaid existing billings that have a pending, unexpired checkout.
+// Only ExistingBillingsReplaceUnpaid changes existing billings, so other runs skip the lookup.
+func (s *billingService) pendingCheckouts(existing []*models.PeriodBilling, opts BulkBillingOptions) (map[uint]bool, error) {
+	if opts.Existing != ExistingBillingsReplaceUnpaid {
+		return nil, nil
 	}
 
-	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Create billings
-		if err := tx.CreateInBatches(billings, 100).Error; err != nil {
-			return fmt.Errorf("failed to create billings: %w", err)
+	var unpaidIDs []uint
+	unpaid := make(map[uint]bool)
+	for _, billing := range existing {
+		if !billing.Cancelled && billing.StatusName == StatusBelumDibayar {
+			unpaidIDs = append(unpaidIDs, billing.BillingID)
+			unpaid[billing.BillingID] = true
 		}
+	}
+	if len(unpaidIDs) == 0 {
+		return nil, nil
+	}
 
-		// Update links with billing IDs
-		for i, billing := range billings {
-			if i < len(links) {
-				links[i].BillingID = billing.ID
-			}
-			if i < len(statusLinks) {
-				statusLinks[i].BillingID = billing.ID
-			}
-			if i < len(kategoriLinks) {
-				kategoriLinks[i].BillingID = billing.ID
+	transactions, err := s.paymentRepo.GetPendingTransactionsByBillingIDs(unpaidIDs, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending payment transactions: %w", err)
+	}
+	pending := make(map[uint]bool)
+	for _, transaction := range transactions {
+		billingIDs, err := s.paymentRepo.GetBillingIDsByTransactionID(transaction.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get payment transaction billings: %w", err)
+		}
+		for _, billingID := range billingIDs {
+			if unpaid[billingID] {
+				pending[billingID] = true
 			}
 		}
+	}
+	return pending, nil
+}
 
-		// Create profile links
-		if err := tx.CreateInBatches(links, 100).Error; err != nil {
-			return fmt.Errorf("failed to create billing profile links: %w", err)
-		}
+// planBulkBillings decides, for every user and setting, whether a billing is created, replaced
+// or skipped given the billings the users already have in the period. A user with a billing in
+// the period that predates setting tracking is skipped entirely, as it cannot be told which
+// setting that billing covers, and so is a user whose unit is not occupied in the period. A
+// cancelled billing is never created again or replaced, nor is one with a pending checkout.
+func planBulkBillings(in *bulkBillingInput, existing []*models.PeriodBilling, opts BulkBillingOptions) *bulkBillingPlan {
+	now := time.Now()
 
-		// Create status bill links
-		if err := tx.CreateInBatches(statusLinks, 100).Error; err != nil {
-			return fmt.Errorf("failed to create billing status bill links: %w", err)
+	existingBySetting := make(map[uint]map[uint]*models.PeriodBilling)
+	untracked := make(map[uint]bool)
+	for _, billing := range existing {
+		if billing.SettingBillingID == nil {
+			if !billing.Cancelled {
+				untracked[billing.UserID] = true
+			}
+			continue
 		}
+		if existingBySetting[billing.UserID] == nil {
+			existingBySetting[billing.UserID] = make(map[uint]*models.PeriodBilling)
+		}
+		existingBySetting[billing.UserID][*billing.SettingBillingID] = billing
+	}
 
-		// Create kategori transaksi links
-		if err := tx.CreateInBatches(kategoriLinks, 100).Error; err != nil {
-			return fmt.Errorf("failed to create billing kategori transaksi links: %w", err)
+	plan := &bulkBillingPlan{}
+	for _, user := range in.users {
+		result := &BulkBillingUserResult{UserID: user.ID}
+		plan.users = append(plan.users, result)
+
+		unit := in.units[user.ID]
+		days, daysInMonth := occupiedDays(unit, in.month, in.year)
+		switch {
+		case untracked[user.ID]:
+			result.Reason = "billings without a setting reference already exist for the period"
+		case days == 0:
+			result.Reason = "unit is not occupied in the period"
 		}
 
-		response.SuccessCount = len(billings)
-		return nil
-	})
+		for _, setting := range in.settings {
+			baseNominal := int64(setting.Nominal)
+			tariff := matchTariff(in.tariffs, setting.ID, unit)
+			if tariff != nil {
+				baseNominal = calculateTariff(tariff, unit.LandArea)
+			}
+			nominal, proration := baseNominal, (*BillingProration)(nil)
+			if !strings.EqualFold(setting.JenisBilling, models.JenisBillingTahunan) {
+				nominal, proration = prorate(in.proration, baseNominal, days, daysInMonth)
+			}
+			item := &BulkBillingItem{SettingBillingID: setting.ID, NamaBilling: setting.NamaBilling, Nominal: nominal, Action: BulkBillingActionSkip, Proration: proration}
+			if tariff != nil {
+				tariffID := tariff.ID
+				item.TariffID, item.TariffName = &tariffID, tariff.Name
+			}
+			result.Items = append(result.Items, item)
 
-	if err != nil {
-		response.FailedCount = len(billings)
-		response.Errors = []string{err.Error()}
+			if result.Reason != "" || nominal <= 0 {
+				result.Skipped++
+				continue
+			}
+			source := &models.BillingSource{
+				UserID:           user.ID,
+				Bulan:            in.month,
+				Tahun:            in.year,
+				SettingBillingID: setting.ID,
+				NamaBilling:      setting.NamaBilling,
+				TariffID:         item.TariffID,
+				TariffName:       item.TariffName,
+				BaseNominal:      baseNominal,
+			}
+			if proration != nil {
+				source.Proration, source.OccupiedDays, source.DaysInMonth = proration.Policy, proration.OccupiedDays, proration.DaysInMonth
+			}
+
+			adjustments := discountAdjustments(in.discountRules, user.ID, setting.ID, in.month, in.year, nominal, now)
+			statusID := in.unpaidStatusID
+			for _, adjustment := range adjustments {
+				item.Discount -= adjustment.Amount
+			}
+			if item.Discount >= nominal {
+				statusID = in.paidStatusID
+			}
+
+			if billing, ok := existingBySetting[user.ID][setting.ID]; ok {
+				billingID, previousNominal := billing.BillingID, billing.Nominal
+				item.BillingID, item.PreviousNominal = &billingID, &previousNominal
+
+				replace := opts.Existing == ExistingBillingsReplaceUnpaid && !billing.Cancelled && billing.StatusName == StatusBelumDibayar && billing.Nominal != nominal
+				if replace && in.pendingCheckouts[billing.BillingID] {
+					replace, item.Reason = false, "billing has a pending checkout"
+				}
+				if replace {
+					plan.replacements = append(plan.replacements, &models.GeneratedBilling{
+						Billing:     &models.Billing{ID: billing.BillingID, Nominal: &nominal},
+						Source:      source,
+						Adjustments: adjustments,
+						StatusID:    statusID,
+					})
+					item.Action = BulkBillingActionReplace
+					result.Replaced++
+					result.TotalNominal += nominal
+					result.TotalDiscount += item.Discount
+				} else {
+					item.Discount = 0
+					result.Skipped++
+				}
+				continue
+			}
+
+			plan.created = append(plan.created, &models.GeneratedBilling{
+				Billing:     newGeneratedBilling(in.month, in.year, nominal, now),
+				Source:      source,
+				Detail:      &models.BillingDetail{DueDate: &in.dueDate},
+				Adjustments: adjustments,
+				StatusID:    statusID,
+			})
+			item.Action = BulkBillingActionCreate
+			result.Created++
+			result.TotalNominal += nominal
+			result.TotalDiscount += item.Discount
+		}
 	}
 
-	return response, nil
+	return plan
 }
 
-// CreateBulkMonthlyBillingsForAllUsers creates monthly billings for all penghuni users
-func (s *billingService) CreateBulkMonthlyBillingsForAllUsers(month int, year int) (*BulkBillingResponse, error) {
-	return s.CreateBulkMonthlyBillings([]uint{}, month, year)
+// newGeneratedBilling returns a published billing of nominal for a billing period, created now
+func newGeneratedBilling(month, year int, nominal int64, now time.Time) *models.Billing {
+	// Always use admin user (ID 1) as the creator
+	adminID := 1
+	docID := uuid.New().String()
+
+	return &models.Billing{
+		DocumentID:  &docID,
+		Bulan:       &month,
+		Tahun:       &year,
+		Nominal:     &nominal,
+		CreatedAt:   &now,
+		UpdatedAt:   &now,
+		PublishedAt: &now,
+		CreatedByID: &adminID,
+		UpdatedByID: &adminID,
+	}
 }
 
-// getUserWithProfile gets user with profile information
-func (s *billingService) getUserWithProfile(userID uint) (*models.User, error) {
-	var user models.User
-	err := s.db.Where("id = ?", userID).First(&user).Error
-	if err != nil {
-		return nil, err
-	}
+// discountAdjustments returns the approved adjustments the active discount rules of a user give
+// a billing of nominal for setting in month and year. Rules apply in order and never discount
+// more than the nominal in total.
+func discountAdjustments(rules []*models.BillingDiscountRule, userID, settingID uint, month, year int, nominal int64, now time.Time) []*models.BillingAdjustment {
+	billingMonth := formatBillingMonth(&BillingMonth{Year: year, Month: month})
+
+	var adjustments []*models.BillingAdjustment
+	remaining := nominal
+	for _, rule := range rules {
+		if rule.UserID != userID || !rule.IsActive || (rule.SettingBillingID != nil && *rule.SettingBillingID != settingID) {
+			continue
+		}
+		if (rule.FromMonth != "" && billingMonth < rule.FromMonth) || (rule.ToMonth != "" && billingMonth > rule.ToMonth) {
+			continue
+		}
 
-	// Check if user has profile via join
-	var count int64
-	err = s.db.Table("profiles").
-		Joins("JOIN profiles_user_lnk pul ON profiles.id = pul.profile_id").
-		Where("pul.user_id = ?", userID).
-		Count(&count).Error
-	if err != nil {
-		return nil, err
+		discount := min(calculateDiscount(rule, nominal), remaining)
+		if discount <= 0 {
+			continue
+		}
+		remaining -= discount
+
+		ruleID, approverID, reviewedAt := rule.ID, rule.CreatedByID, now
+		adjustments = append(adjustments, &models.BillingAdjustment{
+			Type:           models.AdjustmentTypeDiscount,
+			Amount:         -discount,
+			Reason:         "Diskon otomatis: " + rule.Name,
+			Status:         models.AdjustmentStatusApproved,
+			DiscountRuleID: &ruleID,
+			CreatedByID:    rule.CreatedByID,
+			ReviewedByID:   &approverID,
+			ReviewedAt:     &reviewedAt,
+		})
 	}
 
-	if count == 0 {
-		return nil, fmt.Errorf("user has no profile")
+	return adjustments
+}
+
+// addPlan copies the per-user outcomes of a plan into the response and totals them
+func (r *BulkBillingResponse) addPlan(plan *bulkBillingPlan) {
+	r.Users = plan.users
+	for _, user := range plan.users {
+		r.CreatedCount += user.Created
+		r.ReplacedCount += user.Replaced
+		r.SkippedCount += user.Skipped
+		r.TotalNominal += user.TotalNominal
+		r.TotalDiscount += user.TotalDiscount
 	}
+}
 
-	return &user, nil
+// userIDsOf returns the IDs of users
+func userIDsOf(users []*models.User) []uint {
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
 }
 
 // GetBillingPenghuni retrieves all billing data for penghuni users
@@ -0,0 +1,477 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// newTestBillingService wires a BillingService to in-memory repositories with three penghuni,
+// user 12 without a profile, two monthly settings and no discount rules
+func newTestBillingService(t *testing.T) (BillingService, *memoryBillingRepository, *memoryDiscountRuleRepository) {
+	t.Helper()
+
+	billingRepo := newMemoryBillingRepository()
+	billingRepo.addPenghuni(10, true)
+	billingRepo.addPenghuni(11, true)
+	billingRepo.addPenghuni(12, false)
+	billingRepo.addSetting(1, "Keamanan", 100000)
+	billingRepo.addSetting(2, "Kebersihan", 50000)
+
+	discountRuleRepo := &memoryDiscountRuleRepository{}
+	svc, err := NewBillingService(billingRepo, newMemoryPaymentRepository(billingRepo), discountRuleRepo, &memoryTariffRepository{}, BillingPolicy{DueDay: 10, Proration: ProrationNone}, newTestLogger())
+	if err != nil {
+		t.Fatalf("new billing service: %v", err)
+	}
+	return svc, billingRepo, discountRuleRepo
+}
+
+func TestCreateBulkMonthlyBillings_SkipsExistingBillings(t *testing.T) {
+	svc, billingRepo, _ := newTestBillingService(t)
+
+	first, err := svc.CreateBulkMonthlyBillings([]uint{10, 11, 12}, 3, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if first.TotalUsers != 2 || first.CreatedCount != 4 || first.SuccessCount != 4 || first.SkippedCount != 0 {
+		t.Errorf("first run = %+v, want 2 users and 4 created billings", first)
+	}
+
+	if due := billingRepo.billings[1].DueDate; due == nil || !due.Equal(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("due date = %v, want 2026-03-10", due)
+	}
+
+	second, err := svc.CreateBulkMonthlyBillings([]uint{10, 11}, 3, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if second.CreatedCount != 0 || second.SkippedCount != 4 || len(billingRepo.billings) != 4 {
+		t.Errorf("second run = %+v with %d billings stored, want everything skipped", second, len(billingRepo.billings))
+	}
+	for _, user := range second.Users {
+		if user.Created != 0 || user.Skipped != 2 {
+			t.Errorf("user %d = %+v, want 2 skipped", user.UserID, user)
+		}
+	}
+
+	// A new setting only adds its own billings to the period
+	billingRepo.addSetting(3, "Sampah", 20000)
+	third, err := svc.CreateBulkMonthlyBillingsForAllUsers(3, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("third run: %v", err)
+	}
+	if third.TotalUsers != 2 || third.CreatedCount != 2 || third.SkippedCount != 4 {
+		t.Errorf("third run = %+v, want 2 users with a profile, 2 created and 4 skipped", third)
+	}
+}
+
+func TestCreateBulkMonthlyBillings_ReplaceUnpaid(t *testing.T) {
+	svc, billingRepo, _ := newTestBillingService(t)
+
+	if _, err := svc.CreateBulkMonthlyBillings([]uint{10, 11}, 3, 2026, BulkBillingOptions{}); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	// User 11 has paid Keamanan; both settings then get more expensive
+	billingRepo.setStatuses(map[uint]uint{3: testStatusIDs[StatusSudahDibayar]})
+	billingRepo.settings[0].Nominal = 120000
+	billingRepo.settings[1].Nominal = 60000
+
+	response, err := svc.CreateBulkMonthlyBillings([]uint{10, 11}, 3, 2026, BulkBillingOptions{Existing: ExistingBillingsReplaceUnpaid})
+	if err != nil {
+		t.Fatalf("replace run: %v", err)
+	}
+	if response.ReplacedCount != 3 || response.SkippedCount != 1 || response.CreatedCount != 0 {
+		t.Errorf("replace run = %+v, want 3 replaced and 1 skipped", response)
+	}
+	if nominal := *billingRepo.billings[1].Nominal; nominal != 120000 {
+		t.Errorf("unpaid billing nominal = %d, want 120000", nominal)
+	}
+	if nominal := *billingRepo.billings[3].Nominal; nominal != 100000 {
+		t.Errorf("paid billing nominal = %d, want it kept at 100000", nominal)
+	}
+}
+
+func TestCreateBulkMonthlyBillings_SkipsUsersWithUntrackedBillings(t *testing.T) {
+	svc, billingRepo, _ := newTestBillingService(t)
+	billingRepo.addBilling(100, 150000, 3, 2026, &models.UserDetail{UserID: 10})
+
+	response, err := svc.CreateBulkMonthlyBillings([]uint{10, 11}, 3, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if response.Users[0].Created != 0 || response.Users[0].Skipped != 2 || response.Users[0].Reason == "" {
+		t.Errorf("user 10 = %+v, want every billing skipped with a reason", response.Users[0])
+	}
+	if response.Users[1].Created != 2 {
+		t.Errorf("user 11 = %+v, want 2 created", response.Users[1])
+	}
+}
+
+func TestCreateBulkMonthlyBillings_InvalidExistingOption(t *testing.T) {
+	svc, _, _ := newTestBillingService(t)
+
+	if _, err := svc.CreateBulkMonthlyBillings(nil, 3, 2026, BulkBillingOptions{Existing: "overwrite"}); !errors.Is(err, ErrInvalidBulkBilling) {
+		t.Errorf("err = %v, want ErrInvalidBulkBilling", err)
+	}
+}
+
+func TestCreateBulkMonthlyBillings_DryRun(t *testing.T) {
+	svc, billingRepo, _ := newTestBillingService(t)
+
+	if _, err := svc.CreateBulkMonthlyBillings([]uint{10}, 3, 2026, BulkBillingOptions{}); err != nil {
+		t.Fatalf("generate for user 10: %v", err)
+	}
+	billingRepo.settings[0].Nominal = 120000
+
+	preview, err := svc.CreateBulkMonthlyBillings([]uint{10, 11, 12, 99}, 3, 2026, BulkBillingOptions{Existing: ExistingBillingsReplaceUnpaid, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(billingRepo.billings) != 2 || *billingRepo.billings[1].Nominal != 100000 {
+		t.Fatalf("dry run wrote billings: %d stored", len(billingRepo.billings))
+	}
+
+	if !preview.DryRun || preview.CreatedCount != 2 || preview.ReplacedCount != 1 || preview.SkippedCount != 1 || preview.TotalNominal != 290000 {
+		t.Errorf("preview = %+v, want 2 created, 1 replaced, 1 skipped for 290000", preview)
+	}
+	if len(preview.SkippedUsers) != 2 || preview.SkippedUsers[0] != 12 || preview.SkippedUsers[1] != 99 {
+		t.Errorf("skipped users = %v, want [12 99]", preview.SkippedUsers)
+	}
+
+	user10 := preview.Users[0]
+	keamanan, kebersihan := user10.Items[0], user10.Items[1]
+	if keamanan.NamaBilling != "Keamanan" || keamanan.Action != BulkBillingActionReplace || keamanan.Nominal != 120000 || *keamanan.PreviousNominal != 100000 {
+		t.Errorf("user 10 Keamanan = %+v, want replace 100000 with 120000", keamanan)
+	}
+	if kebersihan.Action != BulkBillingActionSkip || kebersihan.BillingID == nil || user10.TotalNominal != 120000 {
+		t.Errorf("user 10 Kebersihan = %+v (total %d), want skip of the existing billing", kebersihan, user10.TotalNominal)
+	}
+	if user11 := preview.Users[1]; user11.Created != 2 || user11.TotalNominal != 170000 {
+		t.Errorf("user 11 = %+v, want 2 created for 170000", user11)
+	}
+}
+
+func TestCreateBulkMonthlyBillings_KeepsBillingsWithAPendingCheckout(t *testing.T) {
+	_, billingRepo, _ := newTestBillingService(t)
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	svc, err := NewBillingService(billingRepo, paymentRepo, &memoryDiscountRuleRepository{}, &memoryTariffRepository{}, BillingPolicy{DueDay: 10, Proration: ProrationNone}, newTestLogger())
+	if err != nil {
+		t.Fatalf("new billing service: %v", err)
+	}
+
+	if _, err := svc.CreateBulkMonthlyBillings([]uint{10, 11}, 3, 2026, BulkBillingOptions{}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	addPendingTransaction(t, paymentRepo, "INV-PENDING", 1, time.Now().Add(time.Hour))
+	billingRepo.settings[0].Nominal = 120000
+
+	opts := BulkBillingOptions{Existing: ExistingBillingsReplaceUnpaid, DryRun: true}
+	preview, err := svc.CreateBulkMonthlyBillings([]uint{10, 11}, 3, 2026, opts)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if item := preview.Users[0].Items[0]; item.Action != BulkBillingActionSkip || item.Reason != "billing has a pending checkout" {
+		t.Errorf("user 10 Keamanan = %+v, want skipped for its pending checkout", item)
+	}
+
+	opts.DryRun = false
+	result, err := svc.CreateBulkMonthlyBillings([]uint{10, 11}, 3, 2026, opts)
+	if err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if result.ReplacedCount != 1 || len(result.Errors) != 0 {
+		t.Errorf("result = %+v, want only user 11 replaced", result)
+	}
+	if *billingRepo.billings[1].Nominal != 100000 || *billingRepo.billings[3].Nominal != 120000 {
+		t.Errorf("nominals = %d and %d, want 100000 kept under the checkout and 120000", *billingRepo.billings[1].Nominal, *billingRepo.billings[3].Nominal)
+	}
+}
+
+func TestCreateBulkMonthlyBillings_RecordsSettingOfEachBilling(t *testing.T) {
+	svc, billingRepo, _ := newTestBillingService(t)
+
+	if _, err := svc.CreateBulkMonthlyBillings([]uint{10}, 3, 2026, BulkBillingOptions{}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	// A later rename of the setting does not change the name of billings already generated
+	billingRepo.settings[0].NamaBilling = "Satpam"
+
+	for id, want := range map[uint]string{1: "Keamanan Maret 2026", 2: "Kebersihan Maret 2026"} {
+		billing, err := billingRepo.GetBillingByID(id)
+		if err != nil {
+			t.Fatalf("get billing %d: %v", id, err)
+		}
+		if billing.SettingBillingID == nil || *billing.SettingBillingID != id {
+			t.Errorf("billing %d setting = %v, want %d", id, billing.SettingBillingID, id)
+		}
+		if name := billingLineItemName(billing); name != want {
+			t.Errorf("billing %d name = %q, want %q", id, name, want)
+		}
+	}
+}
+
+func TestCreateBulkMonthlyBillings_AppliesDiscountRules(t *testing.T) {
+	svc, billingRepo, discountRuleRepo := newTestBillingService(t)
+	keamanan := uint(1)
+	rules := []*models.BillingDiscountRule{
+		{Name: "Rumah kosong", UserID: 10, SettingBillingID: &keamanan, DiscountType: models.FeeTypePercentage, Value: 5000, FromMonth: "2026-03", IsActive: true, CreatedByID: 4},
+		{Name: "Pengurus RT", UserID: 11, DiscountType: models.FeeTypePercentage, Value: 10000, IsActive: true, CreatedByID: 4},
+		{Name: "Berakhir", UserID: 10, DiscountType: models.FeeTypeFlat, Value: 5000, ToMonth: "2026-02", IsActive: true, CreatedByID: 4},
+	}
+	for _, rule := range rules {
+		if err := discountRuleRepo.Create(rule); err != nil {
+			t.Fatalf("create rule: %v", err)
+		}
+	}
+
+	response, err := svc.CreateBulkMonthlyBillings([]uint{10, 11}, 3, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if response.TotalNominal != 300000 || response.TotalDiscount != 200000 {
+		t.Errorf("response = %d nominal, %d discount; want 300000 and 200000", response.TotalNominal, response.TotalDiscount)
+	}
+	if item := response.Users[0].Items[0]; item.Discount != 50000 {
+		t.Errorf("user 10 Keamanan discount = %d, want 50000", item.Discount)
+	}
+
+	// Billings keep their nominal; the discount is an approved adjustment
+	totals, _ := billingRepo.GetAdjustmentTotals([]uint{1, 2, 3, 4})
+	if *billingRepo.billings[1].Nominal != 100000 || totals[1] != -50000 || totals[2] != 0 || totals[3] != -100000 || totals[4] != -50000 {
+		t.Errorf("adjustment totals = %v, want 50%% off user 10 Keamanan and all of user 11", totals)
+	}
+	adjustment := billingRepo.adjustments[0]
+	if adjustment.Type != models.AdjustmentTypeDiscount || adjustment.Status != models.AdjustmentStatusApproved || adjustment.DiscountRuleID == nil || *adjustment.DiscountRuleID != 1 || *adjustment.ReviewedByID != 4 {
+		t.Errorf("adjustment = %+v, want a discount approved by the rule's creator", adjustment)
+	}
+
+	if status := billingRepo.statusName(1); status != StatusBelumDibayar {
+		t.Errorf("discounted billing status = %q, want %q", status, StatusBelumDibayar)
+	}
+	if status := billingRepo.statusName(3); status != StatusSudahDibayar {
+		t.Errorf("exempt billing status = %q, want %q", status, StatusSudahDibayar)
+	}
+}
+
+func TestCreateBulkMonthlyBillings_ProratesOccupancy(t *testing.T) {
+	billingRepo := newMemoryBillingRepository()
+	for _, userID := range []uint{10, 11, 13} {
+		billingRepo.addPenghuni(userID, true)
+	}
+	billingRepo.addSetting(1, "Keamanan", 100000)
+	movedIn, movedOut, later := time.Date(2026, 4, 21, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	billingRepo.units = map[uint]*models.ResidentUnit{
+		10: {OccupiedFrom: &movedIn},
+		11: {OccupiedUntil: &movedOut},
+		13: {OccupiedFrom: &later},
+	}
+
+	for policy, want := range map[string][]int64{
+		ProrationNone:      {100000, 100000},
+		ProrationDaily:     {33333, 33333},
+		ProrationHalfMonth: {50000, 50000},
+	} {
+		svc, err := NewBillingService(billingRepo, newMemoryPaymentRepository(billingRepo), &memoryDiscountRuleRepository{}, &memoryTariffRepository{}, BillingPolicy{DueDay: 10, Proration: policy}, newTestLogger())
+		if err != nil {
+			t.Fatalf("new billing service: %v", err)
+		}
+
+		preview, err := svc.CreateBulkMonthlyBillings(nil, 4, 2026, BulkBillingOptions{DryRun: true})
+		if err != nil {
+			t.Fatalf("%s: preview: %v", policy, err)
+		}
+		moveIn, moveOut := preview.Users[0].Items[0], preview.Users[1].Items[0]
+		if moveIn.Nominal != want[0] || moveOut.Nominal != want[1] {
+			t.Errorf("%s: nominals = %d and %d, want %v", policy, moveIn.Nominal, moveOut.Nominal, want)
+		}
+		if policy != ProrationNone && (moveIn.Proration == nil || moveIn.Proration.OccupiedDays != 10 || moveIn.Proration.DaysInMonth != 30 || moveIn.Proration.BaseNominal != 100000) {
+			t.Errorf("%s: proration = %+v, want 10 of 30 days of 100000", policy, moveIn.Proration)
+		}
+		if user := preview.Users[2]; user.Created != 0 || user.Reason == "" {
+			t.Errorf("%s: user 13 = %+v, want skipped before moving in", policy, user)
+		}
+	}
+
+	svc, _ := NewBillingService(billingRepo, newMemoryPaymentRepository(billingRepo), &memoryDiscountRuleRepository{}, &memoryTariffRepository{}, BillingPolicy{DueDay: 10, Proration: ProrationDaily}, newTestLogger())
+	if _, err := svc.CreateBulkMonthlyBillings(nil, 4, 2026, BulkBillingOptions{}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if source := billingRepo.sources[1]; source.BaseNominal != 100000 || source.Proration != ProrationDaily || source.OccupiedDays != 10 || source.DaysInMonth != 30 {
+		t.Errorf("source = %+v, want the daily calculation recorded", source)
+	}
+
+	// The month after moving out is not billed
+	next, err := svc.CreateBulkMonthlyBillings(nil, 5, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("generate May: %v", err)
+	}
+	if next.CreatedCount != 2 || next.Users[1].Reason == "" || next.Users[2].Items[0].Proration != nil {
+		t.Errorf("May = %+v, want users 10 and 13 billed in full and user 11 skipped", next)
+	}
+}
+
+func TestCreateBulkMonthlyBillings_PricesByTariff(t *testing.T) {
+	billingRepo := newMemoryBillingRepository()
+	for _, userID := range []uint{10, 11, 13, 14} {
+		billingRepo.addPenghuni(userID, true)
+	}
+	billingRepo.addSetting(1, "Keamanan", 100000)
+	billingRepo.addSetting(2, "Kebersihan", 50000)
+	movedIn := time.Date(2026, 4, 16, 0, 0, 0, 0, time.UTC)
+	billingRepo.units = map[uint]*models.ResidentUnit{
+		10: {HouseType: "72", Cluster: "Melati", LandArea: 150},
+		11: {HouseType: "36", Cluster: "melati", LandArea: 90},
+		13: {HouseType: "36", Cluster: "Mawar", LandArea: 250, OccupiedFrom: &movedIn},
+	}
+
+	tariffRepo := &memoryTariffRepository{}
+	for _, req := range []TariffRequest{
+		{SettingBillingID: 1, Name: "Tipe 72", Priority: 1, HouseType: "72", PricingType: models.TariffPricingFlat, Amount: 150000},
+		{SettingBillingID: 1, Name: "Per m²", Priority: 2, PricingType: models.TariffPricingPerM2, Amount: 1000},
+		{SettingBillingID: 2, Name: "Cluster Melati", Cluster: "Melati", PricingType: models.TariffPricingTiered, Tiers: []models.TariffTier{{UpToArea: 100, Amount: 40000}, {UpToArea: 0, Amount: 60000}}},
+	} {
+		tariff := &models.SettingBillingTariff{IsActive: true}
+		if err := applyTariffRequest(tariff, &req); err != nil {
+			t.Fatalf("tariff %q: %v", req.Name, err)
+		}
+		tariffRepo.Create(tariff)
+	}
+
+	svc, err := NewBillingService(billingRepo, newMemoryPaymentRepository(billingRepo), &memoryDiscountRuleRepository{}, tariffRepo, BillingPolicy{DueDay: 10, Proration: ProrationDaily}, newTestLogger())
+	if err != nil {
+		t.Fatalf("new billing service: %v", err)
+	}
+	response, err := svc.CreateBulkMonthlyBillings(nil, 4, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	want := map[uint][2]int64{
+		10: {150000, 60000}, // flat by house type; tiered above 100 m²
+		11: {90000, 40000},  // per m²; tiered up to 100 m², clusters matched case-insensitively
+		13: {125000, 25000}, // per m² and the setting nominal, no tariff in its cluster, prorated from the 16th
+		14: {100000, 50000}, // no unit, so only the setting nominals
+	}
+	for _, user := range response.Users {
+		if len(user.Items) != 2 || user.Items[0].Nominal != want[user.UserID][0] || user.Items[1].Nominal != want[user.UserID][1] {
+			t.Errorf("user %d items = %+v, want nominals %v", user.UserID, user.Items, want[user.UserID])
+		}
+	}
+
+	if item := response.Users[0].Items[0]; item.TariffID == nil || *item.TariffID != 1 || item.TariffName != "Tipe 72" {
+		t.Errorf("user 10 Keamanan item = %+v, want priced by tariff 1", item)
+	}
+	if item := response.Users[3].Items[0]; item.TariffID != nil {
+		t.Errorf("user 14 Keamanan item = %+v, want no tariff", item)
+	}
+	for billingID, source := range billingRepo.sources {
+		if source.UserID == 13 && source.SettingBillingID == 1 && (source.TariffID == nil || *source.TariffID != 2 || source.BaseNominal != 250000) {
+			t.Errorf("billing %d source = %+v, want tariff 2 with a base nominal of 250000", billingID, source)
+		}
+	}
+}
+
+func TestApplyTariffRequest_RejectsInvalidTariffs(t *testing.T) {
+	minArea, maxArea := 200, 100
+	for _, req := range []TariffRequest{
+		{SettingBillingID: 1, Name: " ", PricingType: models.TariffPricingFlat, Amount: 1},
+		{SettingBillingID: 1, Name: "A", PricingType: "monthly", Amount: 1},
+		{SettingBillingID: 1, Name: "A", PricingType: models.TariffPricingPerM2},
+		{SettingBillingID: 1, Name: "A", PricingType: models.TariffPricingFlat, Amount: 1, MinLandArea: &minArea, MaxLandArea: &maxArea},
+		{SettingBillingID: 1, Name: "A", PricingType: models.TariffPricingTiered},
+		{SettingBillingID: 1, Name: "A", PricingType: models.TariffPricingTiered, Tiers: []models.TariffTier{{UpToArea: 100, Amount: 1}}},
+		{SettingBillingID: 1, Name: "A", PricingType: models.TariffPricingTiered, Tiers: []models.TariffTier{{UpToArea: 200, Amount: 1}, {UpToArea: 100, Amount: 1}, {Amount: 1}}},
+		{SettingBillingID: 1, Name: "A", PricingType: models.TariffPricingTiered, Tiers: []models.TariffTier{{UpToArea: 100, Amount: 1}, {Amount: 0}}},
+	} {
+		if err := applyTariffRequest(&models.SettingBillingTariff{}, &req); !errors.Is(err, ErrInvalidTariff) {
+			t.Errorf("request %+v: err = %v, want ErrInvalidTariff", req, err)
+		}
+	}
+}
+
+func TestCreateBulkMonthlyBillings_BillsYearlySettingsInTheirMonth(t *testing.T) {
+	billingRepo := newMemoryBillingRepository()
+	billingRepo.addPenghuni(10, true)
+	billingRepo.addSetting(1, "Keamanan", 100000)
+	billingRepo.addYearlySetting(2, "Iuran 17 Agustus", 200000, 8)
+	movedIn := time.Date(2026, 8, 17, 0, 0, 0, 0, time.UTC)
+	billingRepo.units = map[uint]*models.ResidentUnit{10: {OccupiedFrom: &movedIn}}
+
+	svc, err := NewBillingService(billingRepo, newMemoryPaymentRepository(billingRepo), &memoryDiscountRuleRepository{}, &memoryTariffRepository{}, BillingPolicy{DueDay: 10, Proration: ProrationHalfMonth}, newTestLogger())
+	if err != nil {
+		t.Fatalf("new billing service: %v", err)
+	}
+
+	august, err := svc.CreateBulkMonthlyBillings(nil, 8, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("generate August: %v", err)
+	}
+	items := august.Users[0].Items
+	if august.CreatedCount != 2 || len(items) != 2 || items[0].Nominal != 50000 || items[1].Nominal != 200000 || items[1].Proration != nil {
+		t.Errorf("August = %+v, want Keamanan prorated and the yearly setting billed in full", august)
+	}
+
+	september, err := svc.CreateBulkMonthlyBillings(nil, 9, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("generate September: %v", err)
+	}
+	if september.CreatedCount != 1 || september.Users[0].Items[0].SettingBillingID != 1 {
+		t.Errorf("September = %+v, want only the monthly setting", september)
+	}
+}
+
+func TestCreateOneOffBillings(t *testing.T) {
+	svc, billingRepo, _ := newTestBillingService(t)
+
+	for _, req := range []CreateOneOffBillingRequest{
+		{UserIDs: []uint{10}, NamaBilling: " ", Nominal: 75000, Month: 8, Year: 2026},
+		{UserIDs: []uint{10}, NamaBilling: "Iuran 17 Agustus", Month: 8, Year: 2026},
+		{UserIDs: []uint{10}, NamaBilling: "Iuran 17 Agustus", Nominal: 75000, Month: 8, Year: 2026, DueDate: "17-08-2026"},
+		{UserIDs: []uint{12}, NamaBilling: "Iuran 17 Agustus", Nominal: 75000, Month: 8, Year: 2026},
+	} {
+		if _, err := svc.CreateOneOffBillings(&req); !errors.Is(err, ErrInvalidOneOffBilling) {
+			t.Errorf("request %+v: err = %v, want ErrInvalidOneOffBilling", req, err)
+		}
+	}
+
+	response, err := svc.CreateOneOffBillings(&CreateOneOffBillingRequest{UserIDs: []uint{11, 10, 12, 10}, NamaBilling: "Iuran 17 Agustus", Nominal: 75000, Month: 8, Year: 2026, DueDate: "2026-08-15", CreatedByID: 4})
+	if err != nil {
+		t.Fatalf("create one-off billings: %v", err)
+	}
+	if response.CreatedCount != 2 || response.TotalNominal != 150000 || len(response.SkippedUsers) != 1 || response.SkippedUsers[0] != 12 || response.Charge.ID != 1 {
+		t.Errorf("response = %+v, want users 10 and 11 billed and user 12 skipped", response)
+	}
+	source, billing := billingRepo.sources[response.BillingIDs[0]], billingRepo.billings[response.BillingIDs[0]]
+	if source.ChargeID != 1 || source.SettingBillingID != 0 || source.UserID != 10 || !billing.DueDate.Equal(time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("source = %+v, due %v; want user 10 charged by charge 1, due 2026-08-15", source, billing.DueDate)
+	}
+	if status := billingRepo.statusName(response.BillingIDs[0]); status != StatusBelumDibayar {
+		t.Errorf("status = %q, want %q", status, StatusBelumDibayar)
+	}
+
+	// A second charge in the period and the monthly generation both bill alongside it
+	if _, err := svc.CreateOneOffBillings(&CreateOneOffBillingRequest{UserIDs: []uint{10}, NamaBilling: "Perbaikan jalan", Nominal: 50000, Month: 8, Year: 2026}); err != nil {
+		t.Fatalf("second charge: %v", err)
+	}
+	monthly, err := svc.CreateBulkMonthlyBillings([]uint{10, 11}, 8, 2026, BulkBillingOptions{})
+	if err != nil {
+		t.Fatalf("monthly generation: %v", err)
+	}
+	if monthly.CreatedCount != 4 || monthly.SkippedCount != 0 {
+		t.Errorf("monthly generation = %+v, want 4 billings created next to the charges", monthly)
+	}
+}
+
+func TestNewBillingService_RejectsInvalidPolicies(t *testing.T) {
+	for _, policy := range []BillingPolicy{
+		{DueDay: 0, Proration: ProrationNone},
+		{DueDay: 29, Proration: ProrationNone},
+		{DueDay: 10, Proration: "weekly"},
+	} {
+		if _, err := NewBillingService(newMemoryBillingRepository(), nil, nil, nil, policy, newTestLogger()); err == nil {
+			t.Errorf("policy %+v accepted, want an error", policy)
+		}
+	}
+}
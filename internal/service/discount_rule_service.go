@@ -0,0 +1,195 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidDiscountRule is returned when a discount rule request fails validation
+var ErrInvalidDiscountRule = errors.New("invalid discount rule")
+
+// DiscountRuleService defines the interface for managing the recurring discounts of residents
+type DiscountRuleService interface {
+	CreateDiscountRule(req *DiscountRuleRequest, createdByID uint) (*models.BillingDiscountRule, error)
+	GetDiscountRules(userID *uint) ([]*models.BillingDiscountRule, error)
+	UpdateDiscountRule(id uint, req *DiscountRuleRequest) (*models.BillingDiscountRule, error)
+	DeleteDiscountRule(id uint) error
+}
+
+// DiscountRuleRequest represents the request to create or replace a discount rule
+type DiscountRuleRequest struct {
+	Name             string `json:"name" binding:"required" example:"Rumah kosong"`
+	UserID           uint   `json:"user_id" binding:"required" example:"7"`
+	SettingBillingID *uint  `json:"setting_billing_id" example:"1"`                        // Omitted applies to every setting billing
+	DiscountType     string `json:"discount_type" binding:"required" example:"percentage"` // flat or percentage
+	Value            int64  `json:"value" binding:"required" example:"5000"`               // Rupiah for flat discounts, basis points for percentage discounts
+	FromMonth        string `json:"from_month" example:"2026-01"`                          // First billing month, YYYY-MM; omitted is open
+	ToMonth          string `json:"to_month" example:"2026-12"`                            // Last billing month, YYYY-MM; omitted is open
+	IsActive         *bool  `json:"is_active" example:"true"`                              // Defaults to true
+}
+
+// discountRuleService implements DiscountRuleService
+type discountRuleService struct {
+	discountRuleRepo repository.DiscountRuleRepository
+	logger           *logger.Logger
+}
+
+// NewDiscountRuleService creates a new instance of DiscountRuleService
+func NewDiscountRuleService(discountRuleRepo repository.DiscountRuleRepository, logger *logger.Logger) DiscountRuleService {
+	return &discountRuleService{
+		discountRuleRepo: discountRuleRepo,
+		logger:           logger,
+	}
+}
+
+// CreateDiscountRule creates a recurring discount of a resident. Billings generated from then on
+// in its periods get it as an approved adjustment; the admin creating it is its approver.
+func (s *discountRuleService) CreateDiscountRule(req *DiscountRuleRequest, createdByID uint) (*models.BillingDiscountRule, error) {
+	rule := &models.BillingDiscountRule{IsActive: true, CreatedByID: createdByID}
+	if err := applyDiscountRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.discountRuleRepo.Create(rule); err != nil {
+		s.logger.WithError(err).WithField("user_id", rule.UserID).Error("Failed to create discount rule")
+		return nil, fmt.Errorf("failed to create discount rule: %w", err)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"id":            rule.ID,
+		"user_id":       rule.UserID,
+		"discount_type": rule.DiscountType,
+		"value":         rule.Value,
+		"created_by_id": createdByID,
+	}).Info("Discount rule created")
+
+	return rule, nil
+}
+
+// GetDiscountRules returns the discount rules, of one resident when userID is set
+func (s *discountRuleService) GetDiscountRules(userID *uint) ([]*models.BillingDiscountRule, error) {
+	rules, err := s.discountRuleRepo.GetAll(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discount rules: %w", err)
+	}
+	return rules, nil
+}
+
+// UpdateDiscountRule replaces the settings of a discount rule. Billings already generated keep
+// the discount they were given.
+func (s *discountRuleService) UpdateDiscountRule(id uint, req *DiscountRuleRequest) (*models.BillingDiscountRule, error) {
+	rule, err := s.getDiscountRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyDiscountRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.discountRuleRepo.Update(rule); err != nil {
+		s.logger.WithError(err).WithField("id", id).Error("Failed to update discount rule")
+		return nil, fmt.Errorf("failed to update discount rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// DeleteDiscountRule deletes a discount rule
+func (s *discountRuleService) DeleteDiscountRule(id uint) error {
+	if _, err := s.getDiscountRule(id); err != nil {
+		return err
+	}
+
+	if err := s.discountRuleRepo.Delete(id); err != nil {
+		s.logger.WithError(err).WithField("id", id).Error("Failed to delete discount rule")
+		return fmt.Errorf("failed to delete discount rule: %w", err)
+	}
+
+	return nil
+}
+
+// getDiscountRule loads a discount rule by ID
+func (s *discountRuleService) getDiscountRule(id uint) (*models.BillingDiscountRule, error) {
+	rule, err := s.discountRuleRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("discount rule not found")
+		}
+		return nil, fmt.Errorf("failed to get discount rule: %w", err)
+	}
+	return rule, nil
+}
+
+// applyDiscountRuleRequest validates a discount rule request and copies it onto rule
+func applyDiscountRuleRequest(rule *models.BillingDiscountRule, req *DiscountRuleRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidDiscountRule)
+	}
+	if req.UserID == 0 {
+		return fmt.Errorf("%w: user_id is required", ErrInvalidDiscountRule)
+	}
+
+	switch req.DiscountType {
+	case models.FeeTypeFlat:
+	case models.FeeTypePercentage:
+		if req.Value > 10000 {
+			return fmt.Errorf("%w: a percentage discount cannot exceed 10000 basis points", ErrInvalidDiscountRule)
+		}
+	default:
+		return fmt.Errorf("%w: discount type must be %s or %s", ErrInvalidDiscountRule, models.FeeTypeFlat, models.FeeTypePercentage)
+	}
+	if req.Value <= 0 {
+		return fmt.Errorf("%w: value must be positive", ErrInvalidDiscountRule)
+	}
+
+	from, err := ParseBillingMonth(req.FromMonth)
+	if err != nil {
+		return fmt.Errorf("%w: from_month %q is not YYYY-MM", ErrInvalidDiscountRule, req.FromMonth)
+	}
+	to, err := ParseBillingMonth(req.ToMonth)
+	if err != nil {
+		return fmt.Errorf("%w: to_month %q is not YYYY-MM", ErrInvalidDiscountRule, req.ToMonth)
+	}
+	if from != nil && to != nil && from.period() > to.period() {
+		return fmt.Errorf("%w: from_month is after to_month", ErrInvalidDiscountRule)
+	}
+
+	rule.Name = name
+	rule.UserID = req.UserID
+	rule.SettingBillingID = req.SettingBillingID
+	rule.DiscountType = req.DiscountType
+	rule.Value = req.Value
+	rule.FromMonth = formatBillingMonth(from)
+	rule.ToMonth = formatBillingMonth(to)
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+// formatBillingMonth returns a month as YYYY-MM, or an empty string when it is nil
+func formatBillingMonth(m *BillingMonth) string {
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d", m.Year, m.Month)
+}
+
+// calculateDiscount returns the discount a rule gives on nominal. Percentage discounts are
+// rounded down to the rupiah.
+func calculateDiscount(rule *models.BillingDiscountRule, nominal int64) int64 {
+	if rule.DiscountType == models.FeeTypeFlat {
+		return rule.Value
+	}
+	return nominal * rule.Value / 10000
+}
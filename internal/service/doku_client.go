@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"ipl-be-svc/internal/config"
+	"ipl-be-svc/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// Defaults used when the DOKU client settings are not configured
+const (
+	dokuDefaultRequestTimeout  = 10 * time.Second
+	dokuDefaultBreakerFailures = 5
+	dokuDefaultBreakerCooldown = 30 * time.Second
+	dokuRetryBackoff           = 200 * time.Millisecond
+)
+
+// dokuClient sends signed requests to the DOKU API. It is created once per gateway so
+// connections are reused, retries idempotent requests that fail with a timeout, network
+// error or 5xx response, and fails fast while DOKU keeps failing.
+type dokuClient struct {
+	config       config.DokuConfig
+	httpClient   *http.Client
+	breaker      *circuitBreaker
+	maxRetries   int
+	retryBackoff time.Duration
+	logger       *logger.Logger
+}
+
+// newDokuClient creates a DOKU API client from configuration
+func newDokuClient(cfg config.DokuConfig, logger *logger.Logger) *dokuClient {
+	timeout := time.Duration(cfg.RequestTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = dokuDefaultRequestTimeout
+	}
+	breakerFailures := cfg.BreakerFailures
+	if breakerFailures <= 0 {
+		breakerFailures = dokuDefaultBreakerFailures
+	}
+	breakerCooldown := time.Duration(cfg.BreakerCooldownSeconds) * time.Second
+	if breakerCooldown <= 0 {
+		breakerCooldown = dokuDefaultBreakerCooldown
+	}
+
+	return &dokuClient{
+		config:       cfg,
+		httpClient:   &http.Client{Timeout: timeout},
+		breaker:      newCircuitBreaker(breakerFailures, breakerCooldown),
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: dokuRetryBackoff,
+		logger:       logger,
+	}
+}
+
+// generateDokuSignature creates the HMACSHA256 signature DOKU expects exactly like Python code.
+// The Digest component is only part of the signature for requests with a body.
+func generateDokuSignature(clientID, secretKey, requestID, requestTimestamp, requestTarget, body string) string {
+	// Step 1: Gabungkan semua komponen signature
+	signatureComponents := fmt.Sprintf("Client-Id:%s\nRequest-Id:%s\nRequest-Timestamp:%s\nRequest-Target:%s",
+		clientID, requestID, requestTimestamp, requestTarget)
+
+	// Step 2: Digest body menggunakan SHA256 lalu encode base64
+	if body != "" {
+		bodyHash := sha256.Sum256([]byte(body))
+		digestBase64 := base64.StdEncoding.EncodeToString(bodyHash[:])
+		signatureComponents += fmt.Sprintf("\nDigest:%s", digestBase64)
+	}
+
+	// Step 3: Buat HMAC-SHA256
+	h := hmac.New(sha256.New, []byte(secretKey))
+	h.Write([]byte(signatureComponents))
+	signatureHMAC := h.Sum(nil)
+	signatureBase64 := base64.StdEncoding.EncodeToString(signatureHMAC)
+
+	return fmt.Sprintf("HMACSHA256=%s", signatureBase64)
+}
+
+// do sends a signed request to the DOKU API and returns the response body and status code.
+// Only GET requests are retried: repeating a checkout POST could create a second checkout.
+// Failures that outlast the retries, and calls made while the circuit breaker is open, return
+// an error wrapping ErrGatewayUnavailable.
+func (c *dokuClient) do(ctx context.Context, method, requestTarget string, body []byte) ([]byte, int, error) {
+	if !c.breaker.allow() {
+		return nil, 0, fmt.Errorf("%w: DOKU circuit breaker is open", ErrGatewayUnavailable)
+	}
+
+	attempts := 1
+	if method == http.MethodGet {
+		attempts += c.maxRetries
+	}
+
+	for attempt := 1; ; attempt++ {
+		respBody, statusCode, err := c.send(ctx, method, requestTarget, body, attempt)
+		if ctx.Err() != nil {
+			c.breaker.abandon()
+			return nil, 0, ctx.Err()
+		}
+		if err == nil && statusCode < http.StatusInternalServerError {
+			c.breaker.success()
+			return respBody, statusCode, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("DOKU responded with status %d", statusCode)
+		}
+
+		if attempt >= attempts {
+			c.breaker.failure()
+			return nil, statusCode, fmt.Errorf("%w: %v", ErrGatewayUnavailable, err)
+		}
+
+		c.logger.WithError(err).WithFields(map[string]interface{}{
+			"method":         method,
+			"request_target": requestTarget,
+			"attempt":        attempt,
+		}).Warn("DOKU request failed, retrying")
+
+		// Back off 1x, 2x, 4x... the base delay between attempts
+		select {
+		case <-ctx.Done():
+			c.breaker.abandon()
+			return nil, 0, ctx.Err()
+		case <-time.After(c.retryBackoff << (attempt - 1)):
+		}
+	}
+}
+
+// send makes a single signed request. The signature and response body are not logged because
+// they carry credentials and payment tokens.
+func (c *dokuClient) send(ctx context.Context, method, requestTarget string, body []byte, attempt int) ([]byte, int, error) {
+	url := c.config.BaseURL + requestTarget
+
+	// A fresh Request-Id and timestamp per attempt, so DOKU never sees a replayed signature
+	requestID := uuid.New().String()
+	requestTimestamp := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	signature := generateDokuSignature(c.config.ClientID, c.config.SecretKey, requestID, requestTimestamp, requestTarget, string(body))
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Client-Id", c.config.ClientID)
+	req.Header.Set("Request-Id", requestID)
+	req.Header.Set("Request-Timestamp", requestTimestamp)
+	req.Header.Set("Signature", signature)
+
+	started := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	c.logger.WithFields(map[string]interface{}{
+		"method":         method,
+		"request_target": requestTarget,
+		"request_id":     requestID,
+		"attempt":        attempt,
+		"status_code":    resp.StatusCode,
+		"response_bytes": len(respBody),
+		"duration_ms":    time.Since(started).Milliseconds(),
+	}).Info("DOKU API response")
+
+	return respBody, resp.StatusCode, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/config"
+)
+
+// newTestDokuClient creates a DOKU client for a test server that retries without waiting
+func newTestDokuClient(t *testing.T, handler http.HandlerFunc, cfg config.DokuConfig) *dokuClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg.ClientID = "BRN-TEST"
+	cfg.SecretKey = "SK-TEST"
+	cfg.BaseURL = server.URL
+	client := newDokuClient(cfg, newTestLogger())
+	client.retryBackoff = time.Millisecond
+	return client
+}
+
+func TestDokuClient_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestDokuClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}, config.DokuConfig{MaxRetries: 2})
+
+	_, statusCode, err := client.do(context.Background(), http.MethodGet, "/orders/v1/status/INV-1", nil)
+	if err != nil || statusCode != http.StatusOK {
+		t.Fatalf("do = %d, %v; want 200 after retries", statusCode, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestDokuClient_DoesNotRetryCheckout(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestDokuClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, config.DokuConfig{MaxRetries: 2})
+
+	_, _, err := client.do(context.Background(), http.MethodPost, "/checkout/v1/payment", []byte(`{}`))
+	if !errors.Is(err, ErrGatewayUnavailable) {
+		t.Errorf("error = %v, want ErrGatewayUnavailable", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestDokuClient_CircuitBreakerFailsFast(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestDokuClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}, config.DokuConfig{BreakerFailures: 2, BreakerCooldownSeconds: 60})
+
+	for i := 0; i < 4; i++ {
+		if _, _, err := client.do(context.Background(), http.MethodGet, "/orders/v1/status/INV-1", nil); !errors.Is(err, ErrGatewayUnavailable) {
+			t.Errorf("call %d error = %v, want ErrGatewayUnavailable", i, err)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls reaching DOKU = %d, want 2 before the breaker opened", calls.Load())
+	}
+
+	// After the cooldown a single probe goes through and a success closes the breaker again
+	client.breaker.openedAt = time.Now().Add(-time.Hour)
+	client.breaker.failures = client.breaker.threshold
+	if !client.breaker.allow() || client.breaker.allow() {
+		t.Errorf("breaker should allow exactly one probe after the cooldown")
+	}
+	client.breaker.success()
+	if !client.breaker.allow() {
+		t.Errorf("breaker still open after a successful probe")
+	}
+}
+
+func TestDokuClient_StopsWhenContextCancelled(t *testing.T) {
+	client := newTestDokuClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}, config.DokuConfig{MaxRetries: 2})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, _, err := client.do(ctx, http.MethodGet, "/orders/v1/status/INV-1", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if client.breaker.failures != 0 {
+		t.Errorf("breaker counted %d failures for a cancelled call", client.breaker.failures)
+	}
+}
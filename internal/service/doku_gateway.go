@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/config"
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// ErrPaymentMethodTypesNotConfigured is returned for a checkout that has to leave out the payment
+// methods with a fee while DOKU_PAYMENT_METHOD_TYPES lists no other method to offer
+var ErrPaymentMethodTypesNotConfigured = errors.New("DOKU_PAYMENT_METHOD_TYPES lists no payment method without a fee")
+
+// DokuOrder represents order details for DOKU checkout
+type DokuOrder struct {
+	Amount            int64          `json:"amount"`
+	InvoiceNumber     string         `json:"invoice_number"`
+	Currency          string         `json:"currency"`
+	SessionID         string         `json:"session_id"`
+	CallbackURL       string         `json:"callback_url"`
+	CallbackURLCancel string         `json:"callback_url_cancel,omitempty"`
+	LineItems         []DokuLineItem `json:"line_items"`
+}
+
+// DokuLineItem represents a line item in the order
+type DokuLineItem struct {
+	Name     string `json:"name"`
+	Price    int64  `json:"price"`
+	Quantity int    `json:"quantity"`
+}
+
+// DokuLineItemResponse represents a line item in the response (with string price)
+type DokuLineItemResponse struct {
+	Name     string `json:"name"`
+	Price    string `json:"price"`
+	Quantity int    `json:"quantity"`
+}
+
+// DokuPayment represents payment configuration
+type DokuPayment struct {
+	PaymentDueDate     int      `json:"payment_due_date"`
+	PaymentMethodTypes []string `json:"payment_method_types,omitempty"` // All methods enabled for the merchant when empty
+}
+
+// DokuCustomer represents customer information
+type DokuCustomer struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+	Country string `json:"country"`
+}
+
+// DokuCheckoutRequest represents the complete DOKU checkout request
+type DokuCheckoutRequest struct {
+	Order    DokuOrder    `json:"order"`
+	Payment  DokuPayment  `json:"payment"`
+	Customer DokuCustomer `json:"customer"`
+}
+
+// DokuCheckoutResponse represents the actual DOKU API response structure
+type DokuCheckoutResponse struct {
+	Message  []string `json:"message"`
+	Response struct {
+		Order struct {
+			Amount        string                 `json:"amount"`
+			InvoiceNumber string                 `json:"invoice_number"`
+			Currency      string                 `json:"currency"`
+			SessionID     string                 `json:"session_id"`
+			CallbackURL   string                 `json:"callback_url"`
+			LineItems     []DokuLineItemResponse `json:"line_items"`
+		} `json:"order"`
+		Payment struct {
+			PaymentMethodTypes []string `json:"payment_method_types"`
+			PaymentDueDate     int      `json:"payment_due_date"`
+			TokenID            string   `json:"token_id"`
+			URL                string   `json:"url"`
+			ExpiredDate        string   `json:"expired_date"`
+			ExpiredDatetime    string   `json:"expired_datetime"`
+		} `json:"payment"`
+		Customer struct {
+			Email   string `json:"email"`
+			Phone   string `json:"phone"`
+			Name    string `json:"name"`
+			Address string `json:"address"`
+			Country string `json:"country"`
+		} `json:"customer"`
+		AdditionalInfo struct {
+			Origin struct {
+				Product   string `json:"product"`
+				System    string `json:"system"`
+				APIFormat string `json:"apiFormat"`
+				Source    string `json:"source"`
+			} `json:"origin"`
+			LineItems []DokuLineItemResponse `json:"line_items"`
+		} `json:"additional_info"`
+		UUID    interface{} `json:"uuid"` // Can be int64 or float64 depending on size
+		Headers struct {
+			RequestID string `json:"request_id"`
+			Signature string `json:"signature"`
+			Date      string `json:"date"`
+			ClientID  string `json:"client_id"`
+		} `json:"headers"`
+	} `json:"response"`
+	RawResponse string `json:"-"` // Raw response body as received from DOKU
+}
+
+// dokuTimezone is the timezone DOKU uses for expired_date (WIB)
+var dokuTimezone = time.FixedZone("WIB", 7*60*60)
+
+// ExpiredAt returns the expiry time of the checkout link, or nil if DOKU did not send one
+func (r *DokuCheckoutResponse) ExpiredAt() *time.Time {
+	payment := r.Response.Payment
+	if t, err := time.Parse(time.RFC3339, payment.ExpiredDatetime); err == nil {
+		return &t
+	}
+	if t, err := time.ParseInLocation("20060102150405", payment.ExpiredDate, dokuTimezone); err == nil {
+		return &t
+	}
+	if payment.PaymentDueDate > 0 {
+		t := time.Now().Add(time.Duration(payment.PaymentDueDate) * time.Minute)
+		return &t
+	}
+	return nil
+}
+
+// DokuNotification represents the body of a DOKU HTTP notification
+type DokuNotification struct {
+	Order struct {
+		InvoiceNumber string `json:"invoice_number"`
+		Amount        int64  `json:"amount"`
+	} `json:"order"`
+	Transaction struct {
+		Status            string `json:"status"`
+		Date              string `json:"date"`
+		OriginalRequestID string `json:"original_request_id"`
+	} `json:"transaction"`
+	Service struct {
+		ID string `json:"id"`
+	} `json:"service"`
+	Acquirer struct {
+		ID string `json:"id"`
+	} `json:"acquirer"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+}
+
+// DokuStatusResponse represents the DOKU order status API response
+type DokuStatusResponse struct {
+	Order struct {
+		InvoiceNumber string `json:"invoice_number"`
+		Amount        int64  `json:"amount"`
+	} `json:"order"`
+	Transaction struct {
+		Status            string `json:"status"`
+		Date              string `json:"date"`
+		OriginalRequestID string `json:"original_request_id"`
+	} `json:"transaction"`
+}
+
+// DOKU transaction statuses sent in notifications and status responses
+const (
+	DokuTransactionSuccess = "SUCCESS"
+	DokuTransactionFailed  = "FAILED"
+	DokuTransactionExpired = "EXPIRED"
+	DokuTransactionPending = "PENDING"
+)
+
+// dokuNotificationMaxAge is how far a notification's Request-Timestamp may be from our clock
+const dokuNotificationMaxAge = 5 * time.Minute
+
+// dokuPaymentStatus maps a DOKU transaction status to a payment transaction status
+func dokuPaymentStatus(transactionStatus string) string {
+	switch transactionStatus {
+	case DokuTransactionSuccess:
+		return models.PaymentStatusPaid
+	case DokuTransactionFailed:
+		return models.PaymentStatusFailed
+	case DokuTransactionExpired:
+		return models.PaymentStatusExpired
+	default:
+		return models.PaymentStatusPending
+	}
+}
+
+// dokuGateway implements PaymentGateway on top of the DOKU Checkout API
+type dokuGateway struct {
+	logger *logger.Logger
+	config config.DokuConfig
+	client *dokuClient
+}
+
+// NewDokuGateway creates a new DOKU PaymentGateway
+func NewDokuGateway(cfg config.DokuConfig, logger *logger.Logger) PaymentGateway {
+	return &dokuGateway{
+		logger: logger,
+		config: cfg,
+		client: newDokuClient(cfg, logger),
+	}
+}
+
+// Name returns the gateway name stored on payment transactions
+func (d *dokuGateway) Name() string {
+	return PaymentGatewayDoku
+}
+
+// InitiateDokuCheckout initiates DOKU checkout payment exactly like Python code
+func (d *dokuGateway) InitiateDokuCheckout(ctx context.Context, invoiceNumber string, amount int64, lineItems []DokuLineItem, customer DokuCustomer, paymentMethodTypes []string) (*DokuCheckoutResponse, error) {
+	// --- Payload body ---
+	payload := DokuCheckoutRequest{
+		Order: DokuOrder{
+			Amount:            amount,
+			InvoiceNumber:     invoiceNumber,
+			Currency:          "IDR",
+			SessionID:         strings.ReplaceAll(uuid.New().String(), "-", ""),
+			CallbackURL:       d.config.CallbackURL,
+			CallbackURLCancel: d.config.CallbackURLCancel,
+			LineItems:         lineItems,
+		},
+		Payment:  DokuPayment{PaymentDueDate: d.config.PaymentDueMinutes, PaymentMethodTypes: paymentMethodTypes},
+		Customer: customer,
+	}
+
+	// Konversi ke string JSON (compact, separators=(',', ':'))
+	bodyJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	body, _, err := d.client.do(ctx, http.MethodPost, "/checkout/v1/payment", bodyJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse response
+	var dokuResp DokuCheckoutResponse
+	if err := json.Unmarshal(body, &dokuResp); err != nil {
+		// If we can't parse as expected structure, try generic
+		var genericResp map[string]interface{}
+		if err := json.Unmarshal(body, &genericResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		d.logger.WithField("message", genericResp["message"]).Error("Unexpected response structure")
+		return nil, fmt.Errorf("unexpected response structure: %v", genericResp)
+	}
+	dokuResp.RawResponse = string(body)
+
+	// Debug log the parsed response
+	d.logger.WithFields(map[string]interface{}{
+		"message":     dokuResp.Message,
+		"payment_url": dokuResp.Response.Payment.URL,
+	}).Info("Successfully parsed DOKU response")
+
+	return &dokuResp, nil
+}
+
+// CreateCheckout creates a DOKU checkout payment link
+func (d *dokuGateway) CreateCheckout(ctx context.Context, req *CheckoutRequest) (*CheckoutResult, error) {
+	d.logger.WithFields(map[string]interface{}{
+		"invoice_number": req.InvoiceNumber,
+		"amount":         req.Amount,
+		"description":    req.Description,
+	}).Info("Creating DOKU payment link")
+
+	if d.config.ClientID == "" || d.config.SecretKey == "" {
+		return nil, fmt.Errorf("DOKU credentials not configured")
+	}
+
+	customer := DokuCustomer{
+		Name:    req.Customer.Name,
+		Email:   req.Customer.Email,
+		Phone:   req.Customer.Phone,
+		Country: "ID",
+	}
+
+	lineItems := make([]DokuLineItem, 0, len(req.LineItems))
+	var lineItemsTotal int64
+	for _, item := range req.LineItems {
+		lineItems = append(lineItems, DokuLineItem{Name: item.Name, Price: item.Price, Quantity: item.Quantity})
+		lineItemsTotal += item.Price * int64(item.Quantity)
+	}
+	if len(lineItems) == 0 {
+		lineItems = append(lineItems, DokuLineItem{Name: req.Description, Price: req.Amount, Quantity: 1})
+	} else if lineItemsTotal != req.Amount {
+		return nil, fmt.Errorf("line items total %d does not match amount %d", lineItemsTotal, req.Amount)
+	}
+
+	paymentMethodTypes, err := d.paymentMethodTypes(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initiate DOKU checkout
+	result, err := d.InitiateDokuCheckout(ctx, req.InvoiceNumber, req.Amount, lineItems, customer, paymentMethodTypes)
+	if err != nil {
+		d.logger.WithError(err).Error("Failed to initiate DOKU checkout")
+		return nil, err
+	}
+
+	// Validate payment URL in response
+	if result.Response.Payment.URL == "" {
+		d.logger.Error("Payment URL not found in response")
+		return nil, fmt.Errorf("payment URL not found in response")
+	}
+
+	d.logger.WithFields(map[string]interface{}{
+		"amount":      req.Amount,
+		"description": req.Description,
+		"payment_url": result.Response.Payment.URL,
+	}).Info("DOKU payment link created successfully")
+
+	return &CheckoutResult{
+		InvoiceNumber: req.InvoiceNumber,
+		RequestID:     result.Response.Headers.RequestID,
+		TokenID:       result.Response.Payment.TokenID,
+		SessionID:     result.Response.Order.SessionID,
+		PaymentURL:    result.Response.Payment.URL,
+		ExpiredAt:     result.ExpiredAt(),
+		RawResponse:   result.RawResponse,
+	}, nil
+}
+
+// paymentMethodTypes picks the payment method types offered by a checkout. An empty list lets DOKU
+// offer every method enabled for the merchant, so a checkout that has to leave out excluded types
+// fails unless DOKU_PAYMENT_METHOD_TYPES lists another method.
+func (d *dokuGateway) paymentMethodTypes(req *CheckoutRequest) ([]string, error) {
+	if len(req.PaymentMethodTypes) > 0 {
+		return req.PaymentMethodTypes, nil
+	}
+
+	excluded := make(map[string]bool, len(req.ExcludedPaymentMethodTypes))
+	for _, methodType := range req.ExcludedPaymentMethodTypes {
+		excluded[methodType] = true
+	}
+	var types []string
+	for _, methodType := range d.config.PaymentMethodTypes {
+		if !excluded[methodType] {
+			types = append(types, methodType)
+		}
+	}
+	if len(types) == 0 && len(excluded) > 0 {
+		return nil, ErrPaymentMethodTypesNotConfigured
+	}
+	return types, nil
+}
+
+// GetStatus queries the DOKU order status API for an invoice
+func (d *dokuGateway) GetStatus(ctx context.Context, invoiceNumber string) (*GatewayPaymentStatus, error) {
+	body, statusCode, err := d.client.do(ctx, http.MethodGet, fmt.Sprintf("/orders/v1/status/%s", invoiceNumber), nil)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected DOKU status response code %d", statusCode)
+	}
+
+	var statusResp DokuStatusResponse
+	if err := json.Unmarshal(body, &statusResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &GatewayPaymentStatus{
+		InvoiceNumber: invoiceNumber,
+		Status:        dokuPaymentStatus(statusResp.Transaction.Status),
+		Amount:        statusResp.Order.Amount,
+		RawResponse:   string(body),
+	}, nil
+}
+
+// VerifyNotification checks the Signature header of a DOKU notification against the
+// signature computed from our own credentials and parses its body
+func (d *dokuGateway) VerifyNotification(req *GatewayNotificationRequest) (*GatewayNotification, error) {
+	signature := req.Headers.Get("Signature")
+	if signature == "" || req.Headers.Get("Client-Id") != d.config.ClientID {
+		return nil, fmt.Errorf("invalid notification signature")
+	}
+
+	requestTimestamp := req.Headers.Get("Request-Timestamp")
+	expected := generateDokuSignature(d.config.ClientID, d.config.SecretKey, req.Headers.Get("Request-Id"), requestTimestamp, req.RequestTarget, string(req.Body))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, fmt.Errorf("invalid notification signature")
+	}
+
+	// A signed notification stays valid forever, so old ones are rejected to stop replays
+	sentAt, err := time.Parse(time.RFC3339, requestTimestamp)
+	if err != nil || time.Since(sentAt).Abs() > dokuNotificationMaxAge {
+		return nil, fmt.Errorf("notification timestamp out of range")
+	}
+
+	var notification DokuNotification
+	if err := json.Unmarshal(req.Body, &notification); err != nil {
+		return nil, fmt.Errorf("invalid notification body: %w", err)
+	}
+
+	result := &GatewayNotification{
+		InvoiceNumber: notification.Order.InvoiceNumber,
+		GatewayStatus: notification.Transaction.Status,
+		Amount:        notification.Order.Amount,
+	}
+	switch notification.Transaction.Status {
+	case DokuTransactionSuccess, DokuTransactionFailed, DokuTransactionExpired:
+		result.Status = dokuPaymentStatus(notification.Transaction.Status)
+	}
+
+	return result, nil
+}
+
+// CancelCheckout is not available for DOKU Checkout; links stop working when they expire
+func (d *dokuGateway) CancelCheckout(ctx context.Context, invoiceNumber string) error {
+	return ErrCancelNotSupported
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"ipl-be-svc/internal/config"
+	"ipl-be-svc/internal/models"
+)
+
+// dokuStandIn is a local HTTP stand-in for the DOKU order status API
+type dokuStandIn struct {
+	server   *httptest.Server
+	gateway  *dokuGateway
+	mu       sync.Mutex
+	statuses map[string]*DokuStatusResponse // invoice number -> response; missing invoices get 404
+}
+
+// newDokuStandIn starts a stand-in server and a DOKU gateway pointed at it
+func newDokuStandIn(t *testing.T) *dokuStandIn {
+	t.Helper()
+
+	standIn := &dokuStandIn{statuses: make(map[string]*DokuStatusResponse)}
+	standIn.server = httptest.NewServer(http.HandlerFunc(standIn.serveStatus))
+	t.Cleanup(standIn.server.Close)
+
+	standIn.gateway = NewDokuGateway(config.DokuConfig{
+		ClientID:  "BRN-TEST",
+		SecretKey: "SK-TEST",
+		BaseURL:   standIn.server.URL,
+	}, newTestLogger()).(*dokuGateway)
+
+	return standIn
+}
+
+// setStatus makes the stand-in report a DOKU transaction status for an invoice
+func (d *dokuStandIn) setStatus(invoiceNumber, transactionStatus string, amount int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	response := &DokuStatusResponse{}
+	response.Order.InvoiceNumber = invoiceNumber
+	response.Order.Amount = amount
+	response.Transaction.Status = transactionStatus
+	d.statuses[invoiceNumber] = response
+}
+
+func (d *dokuStandIn) serveStatus(w http.ResponseWriter, r *http.Request) {
+	expected := generateDokuSignature("BRN-TEST", "SK-TEST", r.Header.Get("Request-Id"), r.Header.Get("Request-Timestamp"), r.URL.Path, "")
+	if r.Header.Get("Client-Id") != "BRN-TEST" || r.Header.Get("Signature") != expected {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	invoiceNumber := strings.TrimPrefix(r.URL.Path, "/orders/v1/status/")
+
+	d.mu.Lock()
+	response, ok := d.statuses[invoiceNumber]
+	d.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func TestDokuGateway_GetStatus(t *testing.T) {
+	standIn := newDokuStandIn(t)
+	standIn.setStatus("INV-1", DokuTransactionSuccess, 150000)
+	standIn.setStatus("INV-2", DokuTransactionPending, 150000)
+	standIn.setStatus("INV-3", DokuTransactionExpired, 150000)
+
+	tests := []struct {
+		invoice string
+		want    string
+	}{
+		{"INV-1", models.PaymentStatusPaid},
+		{"INV-2", models.PaymentStatusPending},
+		{"INV-3", models.PaymentStatusExpired},
+	}
+	for _, tt := range tests {
+		status, err := standIn.gateway.GetStatus(context.Background(), tt.invoice)
+		if err != nil {
+			t.Fatalf("GetStatus(%s): %v", tt.invoice, err)
+		}
+		if status.Status != tt.want || status.Amount != 150000 {
+			t.Errorf("GetStatus(%s) = %s/%d, want %s/150000", tt.invoice, status.Status, status.Amount, tt.want)
+		}
+	}
+
+	if _, err := standIn.gateway.GetStatus(context.Background(), "INV-UNKNOWN"); err == nil {
+		t.Errorf("GetStatus for unknown invoice succeeded")
+	}
+}
+
+func TestDokuGateway_PaymentMethodTypes(t *testing.T) {
+	gateway := &dokuGateway{
+		logger: newTestLogger(),
+		config: config.DokuConfig{PaymentMethodTypes: []string{"VIRTUAL_ACCOUNT_BCA", "QRIS", "CREDIT_CARD"}},
+	}
+
+	tests := []struct {
+		name string
+		req  *CheckoutRequest
+		want string
+	}{
+		{"limited", &CheckoutRequest{PaymentMethodTypes: []string{"CREDIT_CARD"}, ExcludedPaymentMethodTypes: []string{"CREDIT_CARD"}}, "CREDIT_CARD"},
+		{"excluded", &CheckoutRequest{ExcludedPaymentMethodTypes: []string{"CREDIT_CARD"}}, "VIRTUAL_ACCOUNT_BCA,QRIS"},
+		{"defaults", &CheckoutRequest{}, "VIRTUAL_ACCOUNT_BCA,QRIS,CREDIT_CARD"},
+	}
+	for _, tt := range tests {
+		types, err := gateway.paymentMethodTypes(tt.req)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := strings.Join(types, ","); got != tt.want {
+			t.Errorf("%s: payment method types = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	gateway.config.PaymentMethodTypes = []string{"CREDIT_CARD"}
+	if _, err := gateway.paymentMethodTypes(&CheckoutRequest{ExcludedPaymentMethodTypes: []string{"CREDIT_CARD"}}); !errors.Is(err, ErrPaymentMethodTypesNotConfigured) {
+		t.Errorf("every configured method excluded: err = %v, want ErrPaymentMethodTypesNotConfigured", err)
+	}
+	gateway.config.PaymentMethodTypes = nil
+	if _, err := gateway.paymentMethodTypes(&CheckoutRequest{ExcludedPaymentMethodTypes: []string{"CREDIT_CARD"}}); !errors.Is(err, ErrPaymentMethodTypesNotConfigured) {
+		t.Errorf("without configured defaults: err = %v, want ErrPaymentMethodTypesNotConfigured", err)
+	}
+	if types, err := gateway.paymentMethodTypes(&CheckoutRequest{}); err != nil || len(types) != 0 {
+		t.Errorf("without fee rules or configured defaults = %v, %v; want every enabled method", types, err)
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/pkg/logger"
+)
+
+// fakeCheckoutTTL is how long a fake checkout stays payable
+const fakeCheckoutTTL = 60 * time.Minute
+
+// FakeNotification represents the body accepted by the fake gateway's notification endpoint
+type FakeNotification struct {
+	InvoiceNumber string `json:"invoice_number"`
+	Status        string `json:"status"` // One of the payment transaction statuses
+	Amount        int64  `json:"amount"`
+}
+
+// fakeGateway implements PaymentGateway in memory for local development and tests.
+// Checkouts get deterministic identifiers derived from the invoice number and stay pending
+// until a notification marks them otherwise.
+type fakeGateway struct {
+	logger    *logger.Logger
+	mu        sync.Mutex
+	checkouts map[string]*GatewayPaymentStatus
+}
+
+// NewFakeGateway creates a new in-memory PaymentGateway
+func NewFakeGateway(logger *logger.Logger) PaymentGateway {
+	return &fakeGateway{
+		logger:    logger,
+		checkouts: make(map[string]*GatewayPaymentStatus),
+	}
+}
+
+// Name returns the gateway name stored on payment transactions
+func (f *fakeGateway) Name() string {
+	return PaymentGatewayFake
+}
+
+// CreateCheckout records a pending checkout and returns a fake payment link
+func (f *fakeGateway) CreateCheckout(ctx context.Context, req *CheckoutRequest) (*CheckoutResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.checkouts[req.InvoiceNumber]; exists {
+		return nil, fmt.Errorf("invoice %s already exists", req.InvoiceNumber)
+	}
+
+	f.checkouts[req.InvoiceNumber] = &GatewayPaymentStatus{
+		InvoiceNumber: req.InvoiceNumber,
+		Status:        models.PaymentStatusPending,
+		Amount:        req.Amount,
+	}
+
+	expiredAt := time.Now().Add(fakeCheckoutTTL)
+
+	f.logger.WithFields(map[string]interface{}{
+		"invoice_number": req.InvoiceNumber,
+		"amount":         req.Amount,
+	}).Info("Fake gateway checkout created")
+
+	return &CheckoutResult{
+		InvoiceNumber: req.InvoiceNumber,
+		RequestID:     "fake-request-" + req.InvoiceNumber,
+		TokenID:       "fake-token-" + req.InvoiceNumber,
+		SessionID:     "fake-session-" + req.InvoiceNumber,
+		PaymentURL:    "https://fake-gateway.local/checkout/" + req.InvoiceNumber,
+		ExpiredAt:     &expiredAt,
+	}, nil
+}
+
+// GetStatus returns the recorded status of a fake checkout
+func (f *fakeGateway) GetStatus(ctx context.Context, invoiceNumber string) (*GatewayPaymentStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	checkout, exists := f.checkouts[invoiceNumber]
+	if !exists {
+		return nil, fmt.Errorf("invoice %s not found", invoiceNumber)
+	}
+
+	status := *checkout
+	return &status, nil
+}
+
+// VerifyNotification accepts a FakeNotification for a known invoice and records its status
+func (f *fakeGateway) VerifyNotification(req *GatewayNotificationRequest) (*GatewayNotification, error) {
+	var notification FakeNotification
+	if err := json.Unmarshal(req.Body, &notification); err != nil {
+		return nil, fmt.Errorf("invalid notification body: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	checkout, exists := f.checkouts[notification.InvoiceNumber]
+	if !exists {
+		return nil, ErrPaymentTransactionNotFound
+	}
+	checkout.Status = notification.Status
+
+	return &GatewayNotification{
+		InvoiceNumber: notification.InvoiceNumber,
+		Status:        notification.Status,
+		GatewayStatus: notification.Status,
+		Amount:        notification.Amount,
+	}, nil
+}
+
+// CancelCheckout marks a pending fake checkout as failed
+func (f *fakeGateway) CancelCheckout(ctx context.Context, invoiceNumber string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	checkout, exists := f.checkouts[invoiceNumber]
+	if !exists {
+		return fmt.Errorf("invoice %s not found", invoiceNumber)
+	}
+	if checkout.Status != models.PaymentStatusPending {
+		return fmt.Errorf("invoice %s is not pending", invoiceNumber)
+	}
+	checkout.Status = models.PaymentStatusFailed
+
+	return nil
+}
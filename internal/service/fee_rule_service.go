@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// feeLineItemName is the checkout line item carrying a convenience fee
+const feeLineItemName = "Biaya Layanan"
+
+// ErrInvalidFeeRule is returned when a fee rule request fails validation
+var ErrInvalidFeeRule = errors.New("invalid fee rule")
+
+// FeeRuleService defines the interface for managing payment fee rules
+type FeeRuleService interface {
+	CreateFeeRule(req *FeeRuleRequest) (*models.PaymentFeeRule, error)
+	GetFeeRules() ([]*models.PaymentFeeRule, error)
+	UpdateFeeRule(id uint, req *FeeRuleRequest) (*models.PaymentFeeRule, error)
+	DeleteFeeRule(id uint) error
+}
+
+// FeeRuleRequest represents the request to create or replace a fee rule
+type FeeRuleRequest struct {
+	PaymentMethodType string `json:"payment_method_type" binding:"required" example:"CREDIT_CARD"`
+	FeeType           string `json:"fee_type" binding:"required" example:"percentage"` // flat or percentage
+	Value             int64  `json:"value" binding:"required" example:"250"`           // Rupiah for flat fees, basis points for percentage fees
+	MaxFee            *int64 `json:"max_fee" example:"10000"`                          // Optional cap of a percentage fee
+	IsActive          *bool  `json:"is_active" example:"true"`                         // Defaults to true
+	Description       string `json:"description" example:"Biaya kartu kredit 2,5%"`
+}
+
+// feeRuleService implements FeeRuleService
+type feeRuleService struct {
+	feeRuleRepo repository.FeeRuleRepository
+	logger      *logger.Logger
+}
+
+// NewFeeRuleService creates a new instance of FeeRuleService
+func NewFeeRuleService(feeRuleRepo repository.FeeRuleRepository, logger *logger.Logger) FeeRuleService {
+	return &feeRuleService{
+		feeRuleRepo: feeRuleRepo,
+		logger:      logger,
+	}
+}
+
+// CreateFeeRule creates the fee rule of a payment method type
+func (s *feeRuleService) CreateFeeRule(req *FeeRuleRequest) (*models.PaymentFeeRule, error) {
+	rule := &models.PaymentFeeRule{IsActive: true}
+	if err := applyFeeRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.feeRuleRepo.GetByPaymentMethodType(rule.PaymentMethodType)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get fee rule: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("fee rule already exists for payment method type")
+	}
+
+	if err := s.feeRuleRepo.Create(rule); err != nil {
+		s.logger.WithError(err).WithField("payment_method_type", rule.PaymentMethodType).Error("Failed to create fee rule")
+		return nil, fmt.Errorf("failed to create fee rule: %w", err)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"id":                  rule.ID,
+		"payment_method_type": rule.PaymentMethodType,
+		"fee_type":            rule.FeeType,
+		"value":               rule.Value,
+	}).Info("Fee rule created")
+
+	return rule, nil
+}
+
+// GetFeeRules returns every fee rule
+func (s *feeRuleService) GetFeeRules() ([]*models.PaymentFeeRule, error) {
+	rules, err := s.feeRuleRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee rules: %w", err)
+	}
+	return rules, nil
+}
+
+// UpdateFeeRule replaces the settings of a fee rule
+func (s *feeRuleService) UpdateFeeRule(id uint, req *FeeRuleRequest) (*models.PaymentFeeRule, error) {
+	rule, err := s.getFeeRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyFeeRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.feeRuleRepo.GetByPaymentMethodType(rule.PaymentMethodType)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get fee rule: %w", err)
+	}
+	if existing != nil && existing.ID != rule.ID {
+		return nil, fmt.Errorf("fee rule already exists for payment method type")
+	}
+
+	if err := s.feeRuleRepo.Update(rule); err != nil {
+		s.logger.WithError(err).WithField("id", id).Error("Failed to update fee rule")
+		return nil, fmt.Errorf("failed to update fee rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// DeleteFeeRule deletes a fee rule
+func (s *feeRuleService) DeleteFeeRule(id uint) error {
+	if _, err := s.getFeeRule(id); err != nil {
+		return err
+	}
+
+	if err := s.feeRuleRepo.Delete(id); err != nil {
+		s.logger.WithError(err).WithField("id", id).Error("Failed to delete fee rule")
+		return fmt.Errorf("failed to delete fee rule: %w", err)
+	}
+
+	return nil
+}
+
+// getFeeRule loads a fee rule by ID
+func (s *feeRuleService) getFeeRule(id uint) (*models.PaymentFeeRule, error) {
+	rule, err := s.feeRuleRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("fee rule not found")
+		}
+		return nil, fmt.Errorf("failed to get fee rule: %w", err)
+	}
+	return rule, nil
+}
+
+// applyFeeRuleRequest validates a fee rule request and copies it onto rule
+func applyFeeRuleRequest(rule *models.PaymentFeeRule, req *FeeRuleRequest) error {
+	methodType := normalizePaymentMethodType(req.PaymentMethodType)
+	if methodType == "" {
+		return fmt.Errorf("%w: payment method type is required", ErrInvalidFeeRule)
+	}
+
+	switch req.FeeType {
+	case models.FeeTypeFlat:
+		if req.MaxFee != nil {
+			return fmt.Errorf("%w: a flat fee cannot have a cap", ErrInvalidFeeRule)
+		}
+	case models.FeeTypePercentage:
+		if req.Value > 10000 {
+			return fmt.Errorf("%w: a percentage fee cannot exceed 10000 basis points", ErrInvalidFeeRule)
+		}
+	default:
+		return fmt.Errorf("%w: fee type must be %s or %s", ErrInvalidFeeRule, models.FeeTypeFlat, models.FeeTypePercentage)
+	}
+
+	if req.Value <= 0 {
+		return fmt.Errorf("%w: value must be positive", ErrInvalidFeeRule)
+	}
+	if req.MaxFee != nil && *req.MaxFee <= 0 {
+		return fmt.Errorf("%w: max fee must be positive", ErrInvalidFeeRule)
+	}
+
+	rule.PaymentMethodType = methodType
+	rule.FeeType = req.FeeType
+	rule.Value = req.Value
+	rule.MaxFee = req.MaxFee
+	rule.Description = strings.TrimSpace(req.Description)
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+// normalizePaymentMethodType formats a payment method type the way DOKU spells them
+func normalizePaymentMethodType(paymentMethodType string) string {
+	return strings.ToUpper(strings.TrimSpace(paymentMethodType))
+}
+
+// calculateFee returns the fee a rule charges on amount. Percentage fees are rounded up to the
+// next rupiah and capped at MaxFee.
+func calculateFee(rule *models.PaymentFeeRule, amount int64) int64 {
+	if rule.FeeType == models.FeeTypeFlat {
+		return rule.Value
+	}
+
+	fee := (amount*rule.Value + 9999) / 10000
+	if rule.MaxFee != nil && fee > *rule.MaxFee {
+		fee = *rule.MaxFee
+	}
+	return fee
+}
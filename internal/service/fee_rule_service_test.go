@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ipl-be-svc/internal/models"
+)
+
+func TestCalculateFee(t *testing.T) {
+	maxFee := int64(3000)
+
+	tests := []struct {
+		name   string
+		rule   *models.PaymentFeeRule
+		amount int64
+		want   int64
+	}{
+		{"flat", &models.PaymentFeeRule{FeeType: models.FeeTypeFlat, Value: 4000}, 150000, 4000},
+		{"percentage rounds up", &models.PaymentFeeRule{FeeType: models.FeeTypePercentage, Value: 250}, 100001, 2501},
+		{"percentage under cap", &models.PaymentFeeRule{FeeType: models.FeeTypePercentage, Value: 150, MaxFee: &maxFee}, 150000, 2250},
+		{"percentage capped", &models.PaymentFeeRule{FeeType: models.FeeTypePercentage, Value: 250, MaxFee: &maxFee}, 150000, 3000},
+	}
+	for _, tt := range tests {
+		if got := calculateFee(tt.rule, tt.amount); got != tt.want {
+			t.Errorf("%s: fee = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCreateFeeRule_Validation(t *testing.T) {
+	svc := NewFeeRuleService(newMemoryFeeRuleRepository(), newTestLogger())
+	maxFee := int64(1000)
+
+	invalid := []*FeeRuleRequest{
+		{PaymentMethodType: " ", FeeType: models.FeeTypeFlat, Value: 1000},
+		{PaymentMethodType: "CREDIT_CARD", FeeType: "tiered", Value: 1000},
+		{PaymentMethodType: "CREDIT_CARD", FeeType: models.FeeTypeFlat, Value: 0},
+		{PaymentMethodType: "CREDIT_CARD", FeeType: models.FeeTypeFlat, Value: 1000, MaxFee: &maxFee},
+		{PaymentMethodType: "CREDIT_CARD", FeeType: models.FeeTypePercentage, Value: 10001},
+	}
+	for _, req := range invalid {
+		if _, err := svc.CreateFeeRule(req); !errors.Is(err, ErrInvalidFeeRule) {
+			t.Errorf("CreateFeeRule(%+v) err = %v, want ErrInvalidFeeRule", req, err)
+		}
+	}
+
+	rule, err := svc.CreateFeeRule(&FeeRuleRequest{PaymentMethodType: " credit_card", FeeType: models.FeeTypePercentage, Value: 250})
+	if err != nil {
+		t.Fatalf("create fee rule: %v", err)
+	}
+	if rule.PaymentMethodType != "CREDIT_CARD" || !rule.IsActive {
+		t.Errorf("rule = %+v, want active CREDIT_CARD", rule)
+	}
+
+	if _, err := svc.CreateFeeRule(&FeeRuleRequest{PaymentMethodType: "CREDIT_CARD", FeeType: models.FeeTypeFlat, Value: 1000}); err == nil || err.Error() != "fee rule already exists for payment method type" {
+		t.Errorf("duplicate rule: err = %v", err)
+	}
+}
+
+func TestCreatePaymentLink_AddsPaymentMethodFee(t *testing.T) {
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	feeRuleRepo := newMemoryFeeRuleRepository()
+	billingRepo.addBilling(1, 150000, 11, 2025, testResident)
+	svc := NewPaymentService(billingRepo, paymentRepo, newMemoryInstallmentRepository(), feeRuleRepo, NewFakeGateway(newTestLogger()), newTestLogger())
+
+	maxFee := int64(3000)
+	feeRules := NewFeeRuleService(feeRuleRepo, newTestLogger())
+	if _, err := feeRules.CreateFeeRule(&FeeRuleRequest{PaymentMethodType: "CREDIT_CARD", FeeType: models.FeeTypePercentage, Value: 250, MaxFee: &maxFee}); err != nil {
+		t.Fatalf("create fee rule: %v", err)
+	}
+
+	plain, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create link without method: %v", err)
+	}
+	if plain.Amount != 150000 || plain.FeeAmount != 0 {
+		t.Errorf("link without method = %d with fee %d, want 150000 without fee", plain.Amount, plain.FeeAmount)
+	}
+
+	if _, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{PaymentMethodType: "credit_card"}); !errors.Is(err, ErrPendingCheckout) {
+		t.Errorf("card link while the plain one is pending: err = %v, want ErrPendingCheckout", err)
+	}
+	if _, err := svc.CancelPaymentLink(context.Background(), plain.InvoiceNumber); err != nil {
+		t.Fatalf("cancel link without method: %v", err)
+	}
+
+	card, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{PaymentMethodType: "credit_card"})
+	if err != nil {
+		t.Fatalf("create card link: %v", err)
+	}
+	if card.Reused || card.Amount != 153000 || card.FeeAmount != 3000 {
+		t.Errorf("card link = %+v, want a new checkout of 153000 with fee 3000", card)
+	}
+
+	transaction := paymentRepo.transaction(card.InvoiceNumber)
+	if transaction.PaymentMethod != "CREDIT_CARD" || transaction.FeeAmount != 3000 {
+		t.Errorf("transaction method %q fee %d, want CREDIT_CARD and 3000", transaction.PaymentMethod, transaction.FeeAmount)
+	}
+
+	// The fee is not part of what the billing is paid
+	if _, err := svc.HandleNotification(fakeNotification(t, card.InvoiceNumber, models.PaymentStatusPaid, card.Amount)); err != nil {
+		t.Fatalf("handle notification: %v", err)
+	}
+	balance, err := svc.GetBillingBalance(1)
+	if err != nil {
+		t.Fatalf("get balance: %v", err)
+	}
+	if balance.PaidAmount != 150000 || balance.Status != StatusSudahDibayar {
+		t.Errorf("balance = %+v, want 150000 paid", balance)
+	}
+}
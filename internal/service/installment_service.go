@@ -0,0 +1,207 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// Limits on the number of parts of an installment plan
+const (
+	minInstallmentParts = 2
+	maxInstallmentParts = 24
+)
+
+// ErrInvalidInstallmentPlan is returned when an installment plan request fails validation
+var ErrInvalidInstallmentPlan = errors.New("invalid installment plan")
+
+// InstallmentService defines the interface for installment plan business logic
+type InstallmentService interface {
+	CreateInstallmentPlan(req *InstallmentPlanRequest) (*InstallmentPlanResponse, error)
+	GetInstallmentPlan(id uint) (*InstallmentPlanResponse, error)
+}
+
+// InstallmentPlanRequest represents an admin request to split overdue billings into parts
+type InstallmentPlanRequest struct {
+	BillingIDs   []uint
+	Parts        int
+	FirstDueDate time.Time
+	Notes        string
+	CreatedByID  uint
+}
+
+// InstallmentPlanResponse represents an installment plan with its parts and billings
+type InstallmentPlanResponse struct {
+	*models.InstallmentPlan
+	BillingIDs []uint `json:"billing_ids"`
+}
+
+// installmentService implements InstallmentService
+type installmentService struct {
+	billingRepo     repository.BillingRepository
+	paymentRepo     repository.PaymentRepository
+	installmentRepo repository.InstallmentRepository
+	logger          *logger.Logger
+}
+
+// NewInstallmentService creates a new instance of InstallmentService
+func NewInstallmentService(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, installmentRepo repository.InstallmentRepository, logger *logger.Logger) InstallmentService {
+	return &installmentService{
+		billingRepo:     billingRepo,
+		paymentRepo:     paymentRepo,
+		installmentRepo: installmentRepo,
+		logger:          logger,
+	}
+}
+
+// CreateInstallmentPlan splits the outstanding balance of a resident's overdue billings into
+// equal monthly parts, the remainder going to the last part. A billing can only be in one
+// active plan at a time.
+func (s *installmentService) CreateInstallmentPlan(req *InstallmentPlanRequest) (*InstallmentPlanResponse, error) {
+	if len(req.BillingIDs) == 0 {
+		return nil, fmt.Errorf("%w: billing IDs cannot be empty", ErrInvalidInstallmentPlan)
+	}
+	if req.Parts < minInstallmentParts || req.Parts > maxInstallmentParts {
+		return nil, fmt.Errorf("%w: parts must be between %d and %d", ErrInvalidInstallmentPlan, minInstallmentParts, maxInstallmentParts)
+	}
+	if req.FirstDueDate.IsZero() {
+		return nil, fmt.Errorf("%w: first due date is required", ErrInvalidInstallmentPlan)
+	}
+	billingIDs := uniqueSortedIDs(req.BillingIDs)
+
+	var response *InstallmentPlanResponse
+	err := s.paymentRepo.WithCheckoutLock(billingLockKeys(billingIDs), func() error {
+		var err error
+		response, err = s.createInstallmentPlanLocked(req, billingIDs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// createInstallmentPlanLocked does the work of CreateInstallmentPlan while the billing locks are held
+func (s *installmentService) createInstallmentPlanLocked(req *InstallmentPlanRequest, billingIDs []uint) (*InstallmentPlanResponse, error) {
+	planned, err := s.installmentRepo.GetActivePlanBillingIDs(billingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installment plans: %w", err)
+	}
+	if len(planned) > 0 {
+		return nil, fmt.Errorf("billing already in an installment plan")
+	}
+
+	balances, err := loadBillingBalances(s.billingRepo, s.paymentRepo, billingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(dokuTimezone)
+	currentPeriod := now.Year()*12 + int(now.Month())
+
+	var userID uint
+	var total int64
+	for _, billingID := range billingIDs {
+		balance := balances[billingID]
+		if balance.outstanding <= 0 {
+			return nil, fmt.Errorf("billing already paid")
+		}
+
+		billing := balance.billing
+		if billing.Bulan == nil || billing.Tahun == nil || *billing.Tahun*12+*billing.Bulan >= currentPeriod {
+			return nil, fmt.Errorf("%w: billing %d is not overdue", ErrInvalidInstallmentPlan, billingID)
+		}
+
+		owner, err := s.billingRepo.GetBillingOwner(billingID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrBillingOwnerNotFound
+			}
+			return nil, fmt.Errorf("failed to get billing owner: %w", err)
+		}
+		if userID != 0 && owner.UserID != userID {
+			return nil, fmt.Errorf("%w: billings belong to different residents", ErrInvalidInstallmentPlan)
+		}
+		userID = owner.UserID
+
+		total += balance.outstanding
+	}
+
+	if total < int64(req.Parts) {
+		return nil, fmt.Errorf("%w: outstanding balance is too small for %d parts", ErrInvalidInstallmentPlan, req.Parts)
+	}
+
+	plan := &models.InstallmentPlan{
+		UserID:      userID,
+		BillingKey:  buildBillingKey(billingIDs),
+		TotalAmount: total,
+		Status:      models.InstallmentPlanActive,
+		Notes:       strings.TrimSpace(req.Notes),
+		CreatedByID: req.CreatedByID,
+		Parts:       splitInstallments(total, req.Parts, req.FirstDueDate),
+	}
+
+	if err := s.installmentRepo.CreatePlan(plan, billingIDs); err != nil {
+		s.logger.WithError(err).WithField("billing_ids", billingIDs).Error("Failed to create installment plan")
+		return nil, fmt.Errorf("failed to create installment plan: %w", err)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"plan_id":       plan.ID,
+		"user_id":       userID,
+		"billing_ids":   billingIDs,
+		"total_amount":  total,
+		"parts":         req.Parts,
+		"created_by_id": req.CreatedByID,
+	}).Info("Installment plan created")
+
+	return &InstallmentPlanResponse{InstallmentPlan: plan, BillingIDs: billingIDs}, nil
+}
+
+// GetInstallmentPlan returns an installment plan with its parts and billings
+func (s *installmentService) GetInstallmentPlan(id uint) (*InstallmentPlanResponse, error) {
+	plan, err := s.installmentRepo.GetPlanByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("installment plan not found")
+		}
+		return nil, fmt.Errorf("failed to get installment plan: %w", err)
+	}
+
+	billingIDs, err := s.installmentRepo.GetPlanBillingIDs(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installment plan billings: %w", err)
+	}
+
+	return &InstallmentPlanResponse{InstallmentPlan: plan, BillingIDs: billingIDs}, nil
+}
+
+// splitInstallments splits total into parts equal parts due a month apart from firstDueDate,
+// adding the remainder of the division to the last part
+func splitInstallments(total int64, parts int, firstDueDate time.Time) []*models.InstallmentPart {
+	amount := total / int64(parts)
+
+	result := make([]*models.InstallmentPart, 0, parts)
+	for i := 0; i < parts; i++ {
+		part := &models.InstallmentPart{
+			Sequence: i + 1,
+			Amount:   amount,
+			DueDate:  firstDueDate.AddDate(0, i, 0),
+			Status:   models.InstallmentPartUnpaid,
+		}
+		if i == parts-1 {
+			part.Amount = total - amount*int64(parts-1)
+		}
+		result = append(result, part)
+	}
+
+	return result
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// newTestInstallmentServices wires the installment and payment services to shared in-memory
+// repositories holding two overdue billings of 150000
+func newTestInstallmentServices(t *testing.T) (InstallmentService, PaymentService, *memoryBillingRepository) {
+	t.Helper()
+
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	installmentRepo := newMemoryInstallmentRepository()
+	paymentRepo.installmentRepo = installmentRepo
+
+	billingRepo.addBilling(1, 150000, 11, 2025, testResident)
+	billingRepo.addBilling(2, 150000, 12, 2025, testResident)
+
+	installmentService := NewInstallmentService(billingRepo, paymentRepo, installmentRepo, newTestLogger())
+	paymentService := NewPaymentService(billingRepo, paymentRepo, installmentRepo, newMemoryFeeRuleRepository(), NewFakeGateway(newTestLogger()), newTestLogger())
+	return installmentService, paymentService, billingRepo
+}
+
+func TestCreateInstallmentPlan_SplitsOutstandingBalance(t *testing.T) {
+	svc, _, _ := newTestInstallmentServices(t)
+	firstDue := time.Date(2026, 11, 10, 0, 0, 0, 0, dokuTimezone)
+
+	plan, err := svc.CreateInstallmentPlan(&InstallmentPlanRequest{BillingIDs: []uint{2, 1}, Parts: 7, FirstDueDate: firstDue, CreatedByID: 3})
+	if err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+
+	if plan.TotalAmount != 300000 || plan.UserID != testResident.UserID || len(plan.Parts) != 7 {
+		t.Fatalf("plan = %+v, want 7 parts of 300000 for the resident", plan.InstallmentPlan)
+	}
+	var total int64
+	for i, part := range plan.Parts {
+		total += part.Amount
+		if want := firstDue.AddDate(0, i, 0); !part.DueDate.Equal(want) {
+			t.Errorf("part %d due %v, want %v", part.Sequence, part.DueDate, want)
+		}
+	}
+	if total != 300000 || plan.Parts[0].Amount != 42857 || plan.Parts[6].Amount != 42858 {
+		t.Errorf("parts total %d, first %d, last %d; want 300000, 42857, 42858", total, plan.Parts[0].Amount, plan.Parts[6].Amount)
+	}
+
+	if _, err := svc.CreateInstallmentPlan(&InstallmentPlanRequest{BillingIDs: []uint{1}, Parts: 2, FirstDueDate: firstDue}); err == nil || err.Error() != "billing already in an installment plan" {
+		t.Errorf("second plan for billing 1: err = %v", err)
+	}
+}
+
+func TestCreateInstallmentPlan_Validation(t *testing.T) {
+	svc, _, billingRepo := newTestInstallmentServices(t)
+	now := time.Now().In(dokuTimezone)
+	billingRepo.addBilling(3, 150000, int(now.Month()), now.Year(), testResident)
+	billingRepo.addBilling(4, 150000, 10, 2025, &models.UserDetail{UserID: 8})
+
+	tests := []struct {
+		name string
+		req  *InstallmentPlanRequest
+	}{
+		{"too few parts", &InstallmentPlanRequest{BillingIDs: []uint{1}, Parts: 1, FirstDueDate: now}},
+		{"no due date", &InstallmentPlanRequest{BillingIDs: []uint{1}, Parts: 2}},
+		{"not overdue", &InstallmentPlanRequest{BillingIDs: []uint{3}, Parts: 2, FirstDueDate: now}},
+		{"different residents", &InstallmentPlanRequest{BillingIDs: []uint{1, 4}, Parts: 2, FirstDueDate: now}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateInstallmentPlan(tt.req); !errors.Is(err, ErrInvalidInstallmentPlan) {
+				t.Errorf("err = %v, want ErrInvalidInstallmentPlan", err)
+			}
+		})
+	}
+}
+
+func TestCreateInstallmentPaymentLink_PaysPartsInSequence(t *testing.T) {
+	svc, payments, billingRepo := newTestInstallmentServices(t)
+	ctx := context.Background()
+
+	plan, err := svc.CreateInstallmentPlan(&InstallmentPlanRequest{BillingIDs: []uint{1, 2}, Parts: 2, FirstDueDate: time.Now()})
+	if err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	first, second := plan.Parts[0], plan.Parts[1]
+
+	if _, err := payments.CreateInstallmentPaymentLink(ctx, second.ID, CheckoutOptions{}); err == nil || err.Error() != "previous installment part not paid" {
+		t.Fatalf("second part before first: err = %v", err)
+	}
+
+	// Each part is 150000 and pays the oldest billing first
+	for _, part := range []*models.InstallmentPart{first, second} {
+		link, err := payments.CreateInstallmentPaymentLink(ctx, part.ID, CheckoutOptions{})
+		if err != nil {
+			t.Fatalf("create link for part %d: %v", part.Sequence, err)
+		}
+		if link.Amount != part.Amount {
+			t.Errorf("part %d link amount = %d, want %d", part.Sequence, link.Amount, part.Amount)
+		}
+		if _, err := payments.HandleNotification(fakeNotification(t, link.InvoiceNumber, models.PaymentStatusPaid, link.Amount)); err != nil {
+			t.Fatalf("pay part %d: %v", part.Sequence, err)
+		}
+
+		if part == first {
+			if status := billingRepo.statusName(1); status != StatusSudahDibayar {
+				t.Errorf("billing 1 status after part 1 = %s, want %s", status, StatusSudahDibayar)
+			}
+			if status := billingRepo.statusName(2); status != StatusBelumDibayar {
+				t.Errorf("billing 2 status after part 1 = %s, want %s", status, StatusBelumDibayar)
+			}
+		}
+	}
+
+	if status := billingRepo.statusName(2); status != StatusSudahDibayar {
+		t.Errorf("billing 2 status = %s, want %s", status, StatusSudahDibayar)
+	}
+	completed, err := svc.GetInstallmentPlan(plan.ID)
+	if err != nil {
+		t.Fatalf("get plan: %v", err)
+	}
+	if completed.Status != models.InstallmentPlanCompleted {
+		t.Errorf("plan status = %s, want %s", completed.Status, models.InstallmentPlanCompleted)
+	}
+	if _, err := payments.CreateInstallmentPaymentLink(ctx, first.ID, CheckoutOptions{}); err == nil || err.Error() != "installment plan is not active" {
+		t.Errorf("link for completed plan: err = %v", err)
+	}
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/cron"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// Late fee policy types
+const (
+	LateFeeTypeFlat       = "flat"       // A fixed amount for every month a billing is overdue
+	LateFeeTypePercentage = "percentage" // A share of the nominal for every month a billing is overdue
+)
+
+// billingRunKindLateFee is the kind of the runs assessing late fees (denda)
+const billingRunKindLateFee = "denda"
+
+// ErrInvalidLateFeeWaiver is returned when a late fee waiver request fails validation
+var ErrInvalidLateFeeWaiver = errors.New("invalid late fee waiver")
+
+// LateFeePolicy sets when billings are due and the denda charged once they are overdue
+type LateFeePolicy struct {
+	DueDay int    // Day of its month a billing without its own due date is shown due, 1-28; it is never charged
+	Type   string // LateFeeTypeFlat or LateFeeTypePercentage
+	Amount int64  // Rupiah per month for flat, basis points of the nominal per month for percentage
+	Cap    int64  // Most denda a billing is ever charged, waived penalties included; 0 for no cap
+}
+
+// LateFeeService defines the interface for late fee (denda) assessment and waivers
+type LateFeeService interface {
+	Run(ctx context.Context)
+	AssessLateFees(now time.Time) (*models.BillingRun, error)
+	GetBillingLateFees(billingID uint) (*BillingLateFees, error)
+	WaiveLateFees(req *WaiveLateFeesRequest) (*BillingLateFees, error)
+}
+
+// BillingLateFees represents the due date of a billing and the penalties assessed on it
+type BillingLateFees struct {
+	BillingID uint                     `json:"billing_id"`
+	DueDate   *time.Time               `json:"due_date"`
+	LateFee   int64                    `json:"late_fee"` // Sum of the unwaived penalties
+	Penalties []*models.BillingPenalty `json:"penalties"`
+}
+
+// WaiveLateFeesRequest represents the data needed to waive penalties of a billing
+type WaiveLateFeesRequest struct {
+	BillingID  uint
+	PenaltyIDs []uint // Empty waives every unwaived penalty
+	Reason     string
+	WaivedByID uint
+}
+
+// lateFeeService implements LateFeeService
+type lateFeeService struct {
+	billingRepo repository.BillingRepository
+	paymentRepo repository.PaymentRepository
+	lateFeeRepo repository.LateFeeRepository
+	runRepo     repository.BillingRunRepository
+	schedule    *cron.Schedule
+	policy      LateFeePolicy
+	logger      *logger.Logger
+}
+
+// NewLateFeeService creates a late fee service that assesses denda on every tick of the cron
+// expression (in WIB) once Run is started
+func NewLateFeeService(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, lateFeeRepo repository.LateFeeRepository, runRepo repository.BillingRunRepository, cronExpr string, policy LateFeePolicy, logger *logger.Logger) (LateFeeService, error) {
+	schedule, err := cron.Parse(cronExpr)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Next(time.Now().In(dokuTimezone)).IsZero() {
+		return nil, fmt.Errorf("late fee cron expression %q never matches", cronExpr)
+	}
+	if policy.DueDay < 1 || policy.DueDay > 28 {
+		return nil, fmt.Errorf("billing due day must be between 1 and 28, got %d", policy.DueDay)
+	}
+	switch policy.Type {
+	case LateFeeTypeFlat:
+	case LateFeeTypePercentage:
+		if policy.Amount > 10000 {
+			return nil, fmt.Errorf("late fee percentage must be at most 10000 basis points, got %d", policy.Amount)
+		}
+	default:
+		return nil, fmt.Errorf("late fee type must be %s or %s, got %q", LateFeeTypeFlat, LateFeeTypePercentage, policy.Type)
+	}
+	if policy.Amount < 0 || policy.Cap < 0 {
+		return nil, fmt.Errorf("late fee amount and cap must not be negative")
+	}
+
+	return &lateFeeService{
+		billingRepo: billingRepo,
+		paymentRepo: paymentRepo,
+		lateFeeRepo: lateFeeRepo,
+		runRepo:     runRepo,
+		schedule:    schedule,
+		policy:      policy,
+		logger:      logger,
+	}, nil
+}
+
+// Run assesses late fees on every tick of the schedule until ctx is cancelled
+func (s *lateFeeService) Run(ctx context.Context) {
+	s.logger.WithFields(map[string]interface{}{
+		"due_day": s.policy.DueDay,
+		"type":    s.policy.Type,
+		"amount":  s.policy.Amount,
+		"cap":     s.policy.Cap,
+	}).Info("Late fee worker started")
+
+	for {
+		next := s.schedule.Next(time.Now().In(dokuTimezone))
+		if next.IsZero() {
+			s.logger.Error("Late fee cron expression no longer matches, worker stopped")
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.logger.Info("Late fee worker stopped")
+			return
+		case <-timer.C:
+			if _, err := s.AssessLateFees(time.Now()); err != nil {
+				s.logger.WithError(err).Error("Scheduled late fee assessment failed")
+			}
+		}
+	}
+}
+
+// AssessLateFees adds the penalties every overdue billing is owed as of now and records the run
+// in the billing run history. Penalties are unique per billing and month overdue, so running it
+// again the same day adds nothing. Only one replica runs at a time; the others return nil.
+func (s *lateFeeService) AssessLateFees(now time.Time) (*models.BillingRun, error) {
+	today := dateOf(now)
+
+	var run *models.BillingRun
+	acquired, err := s.runRepo.TryLock("billing-scheduler:"+billingRunKindLateFee, func() error {
+		var err error
+		run, err = s.assess(today)
+		return err
+	})
+	if err != nil {
+		return run, err
+	}
+	if !acquired {
+		s.logger.Debug("Late fee assessment is running on another replica")
+	}
+
+	return run, nil
+}
+
+// assess does the work of AssessLateFees while the lock is held
+func (s *lateFeeService) assess(today time.Time) (*models.BillingRun, error) {
+	run := &models.BillingRun{
+		Kind:      billingRunKindLateFee,
+		Bulan:     int(today.Month()),
+		Tahun:     today.Year(),
+		Status:    models.BillingRunRunning,
+		StartedAt: time.Now(),
+	}
+	if err := s.runRepo.Create(run); err != nil {
+		return nil, fmt.Errorf("failed to create billing run: %w", err)
+	}
+
+	err := func() error {
+		overdue, err := s.lateFeeRepo.GetOverdueBillings(today, StatusSudahDibayar)
+		if err != nil {
+			return fmt.Errorf("failed to get overdue billings: %w", err)
+		}
+
+		var penalties []*models.BillingPenalty
+		for _, billing := range overdue {
+			owed := s.policy.penaltiesOwed(billing, today)
+			if len(owed) == 0 {
+				run.SkippedCount++
+			}
+			penalties = append(penalties, owed...)
+		}
+
+		created, err := s.lateFeeRepo.CreatePenalties(penalties)
+		if err != nil {
+			return fmt.Errorf("failed to create late fees: %w", err)
+		}
+		run.CreatedCount = int(created)
+		return nil
+	}()
+
+	run.Status = models.BillingRunSucceeded
+	if err != nil {
+		run.Status = models.BillingRunFailed
+		run.Error = err.Error()
+	}
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if updateErr := s.runRepo.Update(run); updateErr != nil {
+		return run, fmt.Errorf("failed to update billing run: %w", updateErr)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"run_id":        run.ID,
+		"date":          today.Format("2006-01-02"),
+		"status":        run.Status,
+		"created_count": run.CreatedCount,
+		"skipped_count": run.SkippedCount,
+	}).Info("Late fee assessment finished")
+
+	return run, err
+}
+
+// penaltiesOwed returns the penalties an overdue billing is owed as of today and does not have
+// yet. Month k overdue starts the day after the due date moved k-1 months on, so with billings
+// due on the 10th the first denda is charged on the 11th and another on every 11th after.
+func (p LateFeePolicy) penaltiesOwed(billing *models.OverdueBilling, today time.Time) []*models.BillingPenalty {
+	var penalties []*models.BillingPenalty
+	total := billing.PenaltyTotal
+
+	for monthsLate := billing.MonthsCharged + 1; billing.DueDate.AddDate(0, monthsLate-1, 0).Before(today); monthsLate++ {
+		amount := p.monthlyAmount(billing.Nominal)
+		if p.Cap > 0 {
+			amount = min(amount, p.Cap-total)
+		}
+		if amount <= 0 {
+			break
+		}
+
+		penalties = append(penalties, &models.BillingPenalty{
+			BillingID:  billing.BillingID,
+			MonthsLate: monthsLate,
+			Amount:     amount,
+			DueDate:    billing.DueDate,
+		})
+		total += amount
+	}
+
+	return penalties
+}
+
+// monthlyAmount returns the denda for one month overdue of a billing of nominal
+func (p LateFeePolicy) monthlyAmount(nominal int64) int64 {
+	if p.Type == LateFeeTypePercentage {
+		return (nominal*p.Amount + 5000) / 10000
+	}
+	return p.Amount
+}
+
+// GetBillingLateFees returns the due date of a billing and the penalties assessed on it
+func (s *lateFeeService) GetBillingLateFees(billingID uint) (*BillingLateFees, error) {
+	billing, err := s.billingRepo.GetBillingByID(billingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("billing not found")
+		}
+		return nil, fmt.Errorf("failed to get billing: %w", err)
+	}
+
+	penalties, err := s.lateFeeRepo.GetPenaltiesByBillingID(billingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get late fees: %w", err)
+	}
+
+	response := &BillingLateFees{BillingID: billingID, DueDate: billing.DueDate, Penalties: penalties}
+	if response.DueDate == nil && billing.Bulan != nil && billing.Tahun != nil {
+		dueDate := billingDueDate(*billing.Bulan, *billing.Tahun, s.policy.DueDay)
+		response.DueDate = &dueDate
+	}
+	for _, penalty := range penalties {
+		if penalty.WaivedAt == nil {
+			response.LateFee += penalty.Amount
+		}
+	}
+
+	return response, nil
+}
+
+// WaiveLateFees waives penalties of a billing and moves the billing to the status its payments
+// now earn, so a billing whose payments covered everything but the denda becomes paid. It is
+// refused while the billing has a pending checkout, which would still collect the denda.
+func (s *lateFeeService) WaiveLateFees(req *WaiveLateFeesRequest) (*BillingLateFees, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidLateFeeWaiver)
+	}
+
+	err := s.paymentRepo.WithCheckoutLock(billingLockKeys([]uint{req.BillingID}), func() error {
+		current, err := s.GetBillingLateFees(req.BillingID)
+		if err != nil {
+			return err
+		}
+
+		unwaived := make(map[uint]int64)
+		for _, penalty := range current.Penalties {
+			if penalty.WaivedAt == nil {
+				unwaived[penalty.ID] = penalty.Amount
+			}
+		}
+		if len(unwaived) == 0 {
+			return fmt.Errorf("billing has no late fees to waive")
+		}
+
+		waivedAmount := current.LateFee
+		if len(req.PenaltyIDs) > 0 {
+			waivedAmount = 0
+			for _, penaltyID := range uniqueSortedIDs(req.PenaltyIDs) {
+				amount, ok := unwaived[penaltyID]
+				if !ok {
+					return fmt.Errorf("%w: penalty %d is not an unwaived late fee of the billing", ErrInvalidLateFeeWaiver, penaltyID)
+				}
+				waivedAmount += amount
+			}
+		}
+
+		if err := ensureNoPendingCheckout(s.paymentRepo, []uint{req.BillingID}); err != nil {
+			return err
+		}
+
+		balances, err := loadBillingBalances(s.billingRepo, s.paymentRepo, []uint{req.BillingID})
+		if err != nil {
+			return err
+		}
+		statusID, err := billingStatusAfterChange(s.billingRepo, balances[req.BillingID], -waivedAmount)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.lateFeeRepo.WaivePenalties(req.BillingID, req.PenaltyIDs, req.WaivedByID, req.Reason, time.Now(), statusID); err != nil {
+			return fmt.Errorf("failed to waive late fees: %w", err)
+		}
+
+		s.logger.WithFields(map[string]interface{}{
+			"billing_id":    req.BillingID,
+			"waived_amount": waivedAmount,
+			"waived_by_id":  req.WaivedByID,
+		}).Info("Late fees waived")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetBillingLateFees(req.BillingID)
+}
+
+// billingDueDate returns the due date of a billing of month and year due on dueDay
+func billingDueDate(month, year, dueDay int) time.Time {
+	return time.Date(year, time.Month(month), dueDay, 0, 0, 0, 0, time.UTC)
+}
+
+// dateOf returns the calendar day of t in WIB, at midnight UTC like the dates read from the database
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.In(dokuTimezone).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// newTestLateFeeService wires a LateFeeService to in-memory repositories holding an unpaid
+// 150000 billing for March 2026, due on the 10th
+func newTestLateFeeService(t *testing.T, policy LateFeePolicy) (LateFeeService, *memoryBillingRepository, *memoryPaymentRepository, *memoryBillingRunRepository) {
+	t.Helper()
+
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	runRepo := &memoryBillingRunRepository{}
+	billingRepo.addBilling(1, 150000, 3, 2026, testResident)
+	dueDate := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	billingRepo.billings[1].DueDate = &dueDate
+
+	svc, err := NewLateFeeService(billingRepo, paymentRepo, &memoryLateFeeRepository{billingRepo: billingRepo}, runRepo, "0 2 * * *", policy, newTestLogger())
+	if err != nil {
+		t.Fatalf("new late fee service: %v", err)
+	}
+	return svc, billingRepo, paymentRepo, runRepo
+}
+
+// penaltyAmounts returns the amounts of the penalties of a billing, oldest first
+func penaltyAmounts(t *testing.T, svc LateFeeService, billingID uint) []int64 {
+	t.Helper()
+
+	lateFees, err := svc.GetBillingLateFees(billingID)
+	if err != nil {
+		t.Fatalf("get late fees: %v", err)
+	}
+	amounts := make([]int64, len(lateFees.Penalties))
+	for i, penalty := range lateFees.Penalties {
+		amounts[i] = penalty.Amount
+	}
+	return amounts
+}
+
+func TestAssessLateFees_FlatPerMonthCapped(t *testing.T) {
+	svc, billingRepo, paymentRepo, runRepo := newTestLateFeeService(t, LateFeePolicy{DueDay: 10, Type: LateFeeTypeFlat, Amount: 10000, Cap: 25000})
+
+	if _, err := svc.AssessLateFees(time.Date(2026, 3, 10, 23, 0, 0, 0, dokuTimezone)); err != nil {
+		t.Fatalf("assess on the due date: %v", err)
+	}
+	if amounts := penaltyAmounts(t, svc, 1); len(amounts) != 0 {
+		t.Fatalf("penalties on the due date = %v, want none", amounts)
+	}
+
+	// 17:30 UTC on the 10th is already the 11th in WIB
+	run, err := svc.AssessLateFees(time.Date(2026, 3, 10, 17, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("assess the day after the due date: %v", err)
+	}
+	if run.Kind != billingRunKindLateFee || run.Status != models.BillingRunSucceeded || run.CreatedCount != 1 {
+		t.Errorf("run = %+v, want a successful denda run creating 1 penalty", run)
+	}
+	if again, err := svc.AssessLateFees(time.Date(2026, 3, 11, 9, 0, 0, 0, dokuTimezone)); err != nil || again.CreatedCount != 0 {
+		t.Errorf("second run the same day = %+v, %v; want nothing created", again, err)
+	}
+
+	if _, err := svc.AssessLateFees(time.Date(2026, 7, 1, 2, 0, 0, 0, dokuTimezone)); err != nil {
+		t.Fatalf("assess months later: %v", err)
+	}
+	if amounts := penaltyAmounts(t, svc, 1); len(amounts) != 3 || amounts[0] != 10000 || amounts[1] != 10000 || amounts[2] != 5000 {
+		t.Errorf("penalties = %v, want [10000 10000 5000] up to the cap", amounts)
+	}
+
+	balances, err := loadBillingBalances(billingRepo, paymentRepo, []uint{1})
+	if err != nil {
+		t.Fatalf("load balance: %v", err)
+	}
+	if balance := balances[1]; balance.penalty != 25000 || balance.outstanding != 175000 {
+		t.Errorf("balance = %d late fee, %d outstanding; want 25000 and 175000", balance.penalty, balance.outstanding)
+	}
+	if len(runRepo.runs) != 4 {
+		t.Errorf("%d runs recorded, want 4", len(runRepo.runs))
+	}
+}
+
+func TestAssessLateFees_PercentageSkipsPaidBillings(t *testing.T) {
+	svc, billingRepo, _, _ := newTestLateFeeService(t, LateFeePolicy{DueDay: 10, Type: LateFeeTypePercentage, Amount: 200})
+	billingRepo.addBilling(2, 150000, 3, 2026, testResident)
+	billingRepo.setStatuses(map[uint]uint{2: testStatusIDs[StatusSudahDibayar]})
+
+	if _, err := svc.AssessLateFees(time.Date(2026, 4, 11, 2, 0, 0, 0, dokuTimezone)); err != nil {
+		t.Fatalf("assess: %v", err)
+	}
+	if amounts := penaltyAmounts(t, svc, 1); len(amounts) != 2 || amounts[0] != 3000 || amounts[1] != 3000 {
+		t.Errorf("penalties = %v, want two of 2%% of 150000", amounts)
+	}
+	if amounts := penaltyAmounts(t, svc, 2); len(amounts) != 0 {
+		t.Errorf("penalties of the paid billing = %v, want none", amounts)
+	}
+}
+
+func TestAssessLateFees_SkipsBillingsWithoutADueDate(t *testing.T) {
+	svc, billingRepo, _, _ := newTestLateFeeService(t, LateFeePolicy{DueDay: 10, Type: LateFeeTypeFlat, Amount: 10000})
+	billingRepo.addBilling(2, 150000, 1, 2025, testResident)
+
+	run, err := svc.AssessLateFees(time.Date(2026, 4, 11, 2, 0, 0, 0, dokuTimezone))
+	if err != nil {
+		t.Fatalf("assess: %v", err)
+	}
+	if amounts := penaltyAmounts(t, svc, 2); len(amounts) != 0 {
+		t.Errorf("penalties of the billing without a due date = %v, want none", amounts)
+	}
+	if run.CreatedCount != 2 {
+		t.Errorf("run = %+v, want only the 2 penalties of the billing with a due date", run)
+	}
+	lateFees, err := svc.GetBillingLateFees(2)
+	if err != nil {
+		t.Fatalf("get late fees: %v", err)
+	}
+	if lateFees.DueDate == nil || !lateFees.DueDate.Equal(time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("due date = %v, want the configured due day shown", lateFees.DueDate)
+	}
+}
+
+func TestWaiveLateFees(t *testing.T) {
+	svc, billingRepo, paymentRepo, _ := newTestLateFeeService(t, LateFeePolicy{DueDay: 10, Type: LateFeeTypeFlat, Amount: 10000})
+	manualPayments := NewManualPaymentService(billingRepo, paymentRepo, t.TempDir(), newTestLogger())
+
+	if _, err := svc.WaiveLateFees(&WaiveLateFeesRequest{BillingID: 1, Reason: "Bencana", WaivedByID: 3}); err == nil || err.Error() != "billing has no late fees to waive" {
+		t.Errorf("waive without late fees: err = %v, want billing has no late fees to waive", err)
+	}
+
+	if _, err := svc.AssessLateFees(time.Date(2026, 4, 11, 2, 0, 0, 0, dokuTimezone)); err != nil {
+		t.Fatalf("assess: %v", err)
+	}
+	_, err := manualPayments.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{1}, Amount: 150000, Method: ManualPaymentMethodCash, PaidAt: time.Now()})
+	if err != nil {
+		t.Fatalf("pay the nominal: %v", err)
+	}
+	if status := billingRepo.statusName(1); status != StatusDibayarSebagian {
+		t.Fatalf("status after paying only the nominal = %q, want %q", status, StatusDibayarSebagian)
+	}
+
+	if _, err := svc.WaiveLateFees(&WaiveLateFeesRequest{BillingID: 1, Reason: " ", WaivedByID: 3}); !errors.Is(err, ErrInvalidLateFeeWaiver) {
+		t.Errorf("waive without reason: err = %v, want ErrInvalidLateFeeWaiver", err)
+	}
+	if _, err := svc.WaiveLateFees(&WaiveLateFeesRequest{BillingID: 1, PenaltyIDs: []uint{9}, Reason: "Bencana", WaivedByID: 3}); !errors.Is(err, ErrInvalidLateFeeWaiver) {
+		t.Errorf("waive an unknown penalty: err = %v, want ErrInvalidLateFeeWaiver", err)
+	}
+
+	addPendingTransaction(t, paymentRepo, "INV-PENDING", 1, time.Now().Add(time.Hour))
+	if _, err := svc.WaiveLateFees(&WaiveLateFeesRequest{BillingID: 1, Reason: "Bencana", WaivedByID: 3}); !errors.Is(err, ErrPendingCheckout) {
+		t.Errorf("waive with a pending checkout: err = %v, want ErrPendingCheckout", err)
+	}
+	if _, err := paymentRepo.UpdatePendingTransactionStatus(paymentRepo.transaction("INV-PENDING").ID, models.PaymentStatusCancelled); err != nil {
+		t.Fatalf("cancel checkout: %v", err)
+	}
+
+	partly, err := svc.WaiveLateFees(&WaiveLateFeesRequest{BillingID: 1, PenaltyIDs: []uint{1}, Reason: "Bencana", WaivedByID: 3})
+	if err != nil {
+		t.Fatalf("waive one penalty: %v", err)
+	}
+	if partly.LateFee != 10000 || billingRepo.statusName(1) != StatusDibayarSebagian {
+		t.Errorf("after waiving one = %d late fee, %q; want 10000 and %q", partly.LateFee, billingRepo.statusName(1), StatusDibayarSebagian)
+	}
+
+	waived, err := svc.WaiveLateFees(&WaiveLateFeesRequest{BillingID: 1, Reason: "Bencana", WaivedByID: 3})
+	if err != nil {
+		t.Fatalf("waive the rest: %v", err)
+	}
+	if waived.LateFee != 0 || billingRepo.statusName(1) != StatusSudahDibayar {
+		t.Errorf("after waiving all = %d late fee, %q; want 0 and %q", waived.LateFee, billingRepo.statusName(1), StatusSudahDibayar)
+	}
+	penalty := waived.Penalties[1]
+	if penalty.WaivedAt == nil || penalty.WaivedByID == nil || *penalty.WaivedByID != 3 || penalty.WaiveReason != "Bencana" {
+		t.Errorf("waived penalty = %+v, want waived by 3 for Bencana", penalty)
+	}
+}
+
+func TestNewLateFeeService_RejectsInvalidPolicies(t *testing.T) {
+	billingRepo := newMemoryBillingRepository()
+	for _, policy := range []LateFeePolicy{
+		{DueDay: 0, Type: LateFeeTypeFlat},
+		{DueDay: 29, Type: LateFeeTypeFlat},
+		{DueDay: 10, Type: "daily"},
+		{DueDay: 10, Type: LateFeeTypePercentage, Amount: 10001},
+		{DueDay: 10, Type: LateFeeTypeFlat, Amount: -1},
+	} {
+		if _, err := NewLateFeeService(billingRepo, nil, nil, nil, "0 2 * * *", policy, newTestLogger()); err == nil {
+			t.Errorf("policy %+v accepted, want an error", policy)
+		}
+	}
+
+	if _, err := NewLateFeeService(billingRepo, nil, nil, nil, "0 0 30 2 *", LateFeePolicy{DueDay: 10, Type: LateFeeTypeFlat}, newTestLogger()); err == nil {
+		t.Error("cron expression that never matches accepted")
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+)
+
+// Offline payment methods accepted when admins record a payment
+const (
+	ManualPaymentMethodCash     = "cash"
+	ManualPaymentMethodTransfer = "transfer"
+)
+
+// ErrInvalidManualPayment is returned when a manual payment request fails validation
+var ErrInvalidManualPayment = errors.New("invalid manual payment")
+
+// receiptExtensions are the receipt file types accepted with a manual payment
+var receiptExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".pdf": true}
+
+// ManualPaymentService defines the interface for recording payments made outside the gateway
+type ManualPaymentService interface {
+	RecordManualPayment(req *ManualPaymentRequest) (*ManualPaymentResponse, error)
+}
+
+// ManualPaymentReceipt holds a receipt file uploaded with a manual payment
+type ManualPaymentReceipt struct {
+	FileName string
+	Content  io.Reader
+}
+
+// ManualPaymentRequest represents a cash or transfer payment collected by the RT treasurer
+type ManualPaymentRequest struct {
+	BillingIDs      []uint
+	Amount          int64 // optional partial amount, only for a single billing
+	Method          string
+	ReferenceNumber string
+	PaidAt          time.Time
+	Notes           string
+	Receipt         *ManualPaymentReceipt
+	RecordedByID    uint
+}
+
+// ManualPaymentResponse represents a recorded manual payment
+type ManualPaymentResponse struct {
+	TransactionID   uint      `json:"transaction_id"`
+	InvoiceNumber   string    `json:"invoice_number"`
+	BillingIDs      []uint    `json:"billing_ids"`
+	Amount          int64     `json:"amount"`
+	Method          string    `json:"method"`
+	ReferenceNumber string    `json:"reference_number,omitempty"`
+	PaidAt          time.Time `json:"paid_at"`
+	ReceiptPath     string    `json:"receipt_path,omitempty"`
+}
+
+// manualPaymentService implements ManualPaymentService
+type manualPaymentService struct {
+	billingRepo repository.BillingRepository
+	paymentRepo repository.PaymentRepository
+	receiptDir  string
+	logger      *logger.Logger
+}
+
+// NewManualPaymentService creates a new instance of ManualPaymentService storing receipts in receiptDir
+func NewManualPaymentService(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, receiptDir string, logger *logger.Logger) ManualPaymentService {
+	return &manualPaymentService{
+		billingRepo: billingRepo,
+		paymentRepo: paymentRepo,
+		receiptDir:  receiptDir,
+		logger:      logger,
+	}
+}
+
+// RecordManualPayment records an offline payment against one or more unpaid billings. It writes
+// the same paid payment transaction and billing links as an online payment and updates the
+// billing statuses in one database transaction. Without an amount it settles the outstanding
+// balance of every billing; with one it records a partial payment of a single billing.
+func (s *manualPaymentService) RecordManualPayment(req *ManualPaymentRequest) (*ManualPaymentResponse, error) {
+	if err := validateManualPayment(req); err != nil {
+		return nil, err
+	}
+	billingIDs := uniqueSortedIDs(req.BillingIDs)
+	billingKey := buildBillingKey(billingIDs)
+
+	var response *ManualPaymentResponse
+	err := s.paymentRepo.WithCheckoutLock(billingLockKeys(billingIDs), func() error {
+		var err error
+		response, err = s.recordManualPaymentLocked(req, billingIDs, billingKey)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// recordManualPaymentLocked does the work of RecordManualPayment while the billings lock is held
+func (s *manualPaymentService) recordManualPaymentLocked(req *ManualPaymentRequest, billingIDs []uint, billingKey string) (*ManualPaymentResponse, error) {
+	balances, err := loadBillingBalances(s.billingRepo, s.paymentRepo, billingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var amount int64
+	var descriptions []string
+	allocations := make(map[uint]int64, len(billingIDs))
+	paidAmounts := make(map[uint]int64, len(billingIDs))
+	for _, billingID := range billingIDs {
+		balance := balances[billingID]
+		if balance.outstanding <= 0 {
+			return nil, fmt.Errorf("billing already paid")
+		}
+
+		allocations[billingID] = balance.outstanding
+		if req.Amount > 0 {
+			if req.Amount > balance.outstanding {
+				return nil, fmt.Errorf("%w: amount %d exceeds the outstanding balance %d", ErrInvalidManualPayment, req.Amount, balance.outstanding)
+			}
+			allocations[billingID] = req.Amount
+		}
+
+		amount += allocations[billingID]
+		paidAmounts[billingID] = balance.due - balance.outstanding + allocations[billingID]
+		descriptions = append(descriptions, billingLineItemName(balance.billing))
+	}
+
+	billingStatusIDs, err := billingStatusIDsFor(s.billingRepo, paidAmounts)
+	if err != nil {
+		return nil, err
+	}
+
+	invoiceNumber := generateInvoiceNumber()
+
+	receiptPath := ""
+	if req.Receipt != nil {
+		receiptPath, err = s.saveReceipt(invoiceNumber, req.Receipt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paidAt := req.PaidAt
+	recordedByID := req.RecordedByID
+	transaction := &models.PaymentTransaction{
+		InvoiceNumber:   invoiceNumber,
+		BillingKey:      billingKey,
+		Description:     fmt.Sprintf("Manual %s payment: %s", req.Method, strings.Join(descriptions, ", ")),
+		Gateway:         PaymentGatewayManual,
+		PaymentMethod:   req.Method,
+		ReferenceNumber: req.ReferenceNumber,
+		ReceiptPath:     receiptPath,
+		Notes:           req.Notes,
+		RecordedByID:    &recordedByID,
+		Status:          models.PaymentStatusPaid,
+		Amount:          amount,
+		PaidAt:          &paidAt,
+	}
+
+	if err := s.paymentRepo.CreatePaidTransaction(transaction, allocations, billingStatusIDs); err != nil {
+		if receiptPath != "" {
+			os.Remove(receiptPath)
+		}
+		s.logger.WithError(err).WithField("billing_ids", billingIDs).Error("Failed to record manual payment")
+		return nil, fmt.Errorf("failed to record manual payment: %w", err)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"invoice_number": invoiceNumber,
+		"billing_ids":    billingIDs,
+		"amount":         amount,
+		"method":         req.Method,
+		"recorded_by_id": req.RecordedByID,
+	}).Info("Manual payment recorded")
+
+	return &ManualPaymentResponse{
+		TransactionID:   transaction.ID,
+		InvoiceNumber:   invoiceNumber,
+		BillingIDs:      billingIDs,
+		Amount:          amount,
+		Method:          req.Method,
+		ReferenceNumber: req.ReferenceNumber,
+		PaidAt:          paidAt,
+		ReceiptPath:     receiptPath,
+	}, nil
+}
+
+// ParseWIBDate parses a YYYY-MM-DD date as midnight WIB, the timezone residents and admins work in
+func ParseWIBDate(value string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", value, dokuTimezone)
+}
+
+// validateManualPayment checks the fields of a manual payment request
+func validateManualPayment(req *ManualPaymentRequest) error {
+	if len(req.BillingIDs) == 0 {
+		return fmt.Errorf("%w: billing IDs cannot be empty", ErrInvalidManualPayment)
+	}
+
+	if req.Amount < 0 || (req.Amount > 0 && len(uniqueSortedIDs(req.BillingIDs)) > 1) {
+		return fmt.Errorf("%w: a partial amount must be positive and for a single billing", ErrInvalidManualPayment)
+	}
+
+	switch req.Method {
+	case ManualPaymentMethodCash:
+	case ManualPaymentMethodTransfer:
+		if strings.TrimSpace(req.ReferenceNumber) == "" {
+			return fmt.Errorf("%w: reference number is required for transfers", ErrInvalidManualPayment)
+		}
+	default:
+		return fmt.Errorf("%w: method must be %s or %s", ErrInvalidManualPayment, ManualPaymentMethodCash, ManualPaymentMethodTransfer)
+	}
+
+	if req.PaidAt.IsZero() || req.PaidAt.After(time.Now()) {
+		return fmt.Errorf("%w: paid date cannot be empty or in the future", ErrInvalidManualPayment)
+	}
+
+	if req.Receipt != nil && !receiptExtensions[strings.ToLower(filepath.Ext(req.Receipt.FileName))] {
+		return fmt.Errorf("%w: receipt must be a JPG, PNG or PDF file", ErrInvalidManualPayment)
+	}
+
+	return nil
+}
+
+// saveReceipt stores a receipt file as <invoice number><ext> in the receipt directory
+func (s *manualPaymentService) saveReceipt(invoiceNumber string, receipt *ManualPaymentReceipt) (string, error) {
+	if err := os.MkdirAll(s.receiptDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create receipt directory: %w", err)
+	}
+
+	path := filepath.Join(s.receiptDir, invoiceNumber+strings.ToLower(filepath.Ext(receipt.FileName)))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to store receipt: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, receipt.Content); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to store receipt: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// newTestManualPaymentService wires a ManualPaymentService to in-memory repositories
+func newTestManualPaymentService(t *testing.T) (ManualPaymentService, *memoryBillingRepository, *memoryPaymentRepository, string) {
+	t.Helper()
+
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	receiptDir := filepath.Join(t.TempDir(), "receipts")
+
+	billingRepo.addBilling(1, 150000, 11, 2025, testResident)
+	billingRepo.addBilling(2, 150000, 12, 2025, testResident)
+
+	return NewManualPaymentService(billingRepo, paymentRepo, receiptDir, newTestLogger()), billingRepo, paymentRepo, receiptDir
+}
+
+func TestRecordManualPayment_Cash(t *testing.T) {
+	svc, billingRepo, paymentRepo, receiptDir := newTestManualPaymentService(t)
+	paidAt := time.Now().Add(-time.Hour)
+
+	response, err := svc.RecordManualPayment(&ManualPaymentRequest{
+		BillingIDs:   []uint{2, 1},
+		Method:       ManualPaymentMethodCash,
+		PaidAt:       paidAt,
+		Receipt:      &ManualPaymentReceipt{FileName: "kwitansi.JPG", Content: strings.NewReader("receipt")},
+		RecordedByID: 3,
+	})
+	if err != nil {
+		t.Fatalf("record manual payment: %v", err)
+	}
+	if response.Amount != 300000 {
+		t.Errorf("amount = %d, want 300000", response.Amount)
+	}
+
+	transaction := paymentRepo.transaction(response.InvoiceNumber)
+	if transaction.Status != models.PaymentStatusPaid || transaction.Gateway != PaymentGatewayManual {
+		t.Errorf("transaction = %s via %s, want paid via manual", transaction.Status, transaction.Gateway)
+	}
+	if transaction.RecordedByID == nil || *transaction.RecordedByID != 3 {
+		t.Errorf("recorded by = %v, want 3", transaction.RecordedByID)
+	}
+	for _, billingID := range []uint{1, 2} {
+		if status := billingRepo.statusName(billingID); status != StatusSudahDibayar {
+			t.Errorf("billing %d status = %q, want %q", billingID, status, StatusSudahDibayar)
+		}
+	}
+
+	wantPath := filepath.Join(receiptDir, response.InvoiceNumber+".jpg")
+	if response.ReceiptPath != wantPath {
+		t.Errorf("receipt path = %q, want %q", response.ReceiptPath, wantPath)
+	}
+	if content, err := os.ReadFile(wantPath); err != nil || string(content) != "receipt" {
+		t.Errorf("stored receipt = %q (%v), want %q", content, err, "receipt")
+	}
+}
+
+func TestRecordManualPayment_RejectsInvalidRequests(t *testing.T) {
+	svc, _, paymentRepo, _ := newTestManualPaymentService(t)
+
+	requests := map[string]*ManualPaymentRequest{
+		"transfer without reference": {BillingIDs: []uint{1}, Method: ManualPaymentMethodTransfer, PaidAt: time.Now()},
+		"unknown method":             {BillingIDs: []uint{1}, Method: "cheque", PaidAt: time.Now()},
+		"future paid date":           {BillingIDs: []uint{1}, Method: ManualPaymentMethodCash, PaidAt: time.Now().Add(24 * time.Hour)},
+		"unsupported receipt": {BillingIDs: []uint{1}, Method: ManualPaymentMethodCash, PaidAt: time.Now(),
+			Receipt: &ManualPaymentReceipt{FileName: "receipt.exe", Content: strings.NewReader("x")}},
+	}
+
+	for name, req := range requests {
+		if _, err := svc.RecordManualPayment(req); !errors.Is(err, ErrInvalidManualPayment) {
+			t.Errorf("%s: error = %v, want ErrInvalidManualPayment", name, err)
+		}
+	}
+	if paymentRepo.count() != 0 {
+		t.Errorf("created %d transactions for invalid requests", paymentRepo.count())
+	}
+}
+
+func TestRecordManualPayment_AlreadyPaid(t *testing.T) {
+	svc, _, _, _ := newTestManualPaymentService(t)
+	req := &ManualPaymentRequest{BillingIDs: []uint{1}, Method: ManualPaymentMethodTransfer, ReferenceNumber: "TRF-1", PaidAt: time.Now()}
+
+	if _, err := svc.RecordManualPayment(req); err != nil {
+		t.Fatalf("record manual payment: %v", err)
+	}
+	if _, err := svc.RecordManualPayment(req); err == nil || err.Error() != "billing already paid" {
+		t.Errorf("second payment error = %v, want billing already paid", err)
+	}
+}
+
+func TestRecordManualPayment_PartialAmount(t *testing.T) {
+	svc, billingRepo, _, _ := newTestManualPaymentService(t)
+	paidAt := time.Now().Add(-time.Hour)
+
+	if _, err := svc.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{1, 2}, Amount: 1000, Method: ManualPaymentMethodCash, PaidAt: paidAt}); !errors.Is(err, ErrInvalidManualPayment) {
+		t.Fatalf("partial amount for two billings: err = %v, want ErrInvalidManualPayment", err)
+	}
+
+	response, err := svc.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{1}, Amount: 100000, Method: ManualPaymentMethodCash, PaidAt: paidAt})
+	if err != nil {
+		t.Fatalf("record partial payment: %v", err)
+	}
+	if response.Amount != 100000 {
+		t.Errorf("amount = %d, want 100000", response.Amount)
+	}
+	if status := billingRepo.statusName(1); status != StatusDibayarSebagian {
+		t.Errorf("billing status = %q, want %q", status, StatusDibayarSebagian)
+	}
+
+	if _, err := svc.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{1}, Amount: 60000, Method: ManualPaymentMethodCash, PaidAt: paidAt}); !errors.Is(err, ErrInvalidManualPayment) {
+		t.Errorf("amount above the outstanding balance: err = %v, want ErrInvalidManualPayment", err)
+	}
+
+	rest, err := svc.RecordManualPayment(&ManualPaymentRequest{BillingIDs: []uint{1}, Method: ManualPaymentMethodCash, PaidAt: paidAt})
+	if err != nil {
+		t.Fatalf("record the rest: %v", err)
+	}
+	if rest.Amount != 50000 {
+		t.Errorf("rest amount = %d, want 50000", rest.Amount)
+	}
+	if status := billingRepo.statusName(1); status != StatusSudahDibayar {
+		t.Errorf("billing status = %q, want %q", status, StatusSudahDibayar)
+	}
+}
@@ -0,0 +1,1226 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// testStatusIDs are the master_general_statuses IDs used by the in-memory billing repository
+var testStatusIDs = map[string]uint{
+	StatusBelumDibayar:    1,
+	StatusSudahDibayar:    2,
+	StatusDibayarSebagian: 3,
+}
+
+// memoryBillingRepository is an in-memory BillingRepository for service tests.
+// Methods the tests do not need fall through to the nil embedded interface and panic.
+type memoryBillingRepository struct {
+	repository.BillingRepository
+
+	mu       sync.Mutex
+	billings map[uint]*models.Billing
+	owners   map[uint]*models.UserDetail
+	statuses map[uint]uint // billing ID -> master_general_statuses ID
+
+	users    []*models.User                 // penghuni users
+	profiles map[uint]bool                  // user IDs with a resident profile
+	settings []*models.SettingBilling       // active monthly and yearly settings
+	schedule map[uint]int                   // yearly setting ID -> month it is billed in
+	charges  []*models.BillingCharge        // one-off charges
+	details  map[uint]*models.BillingDetail // billing ID -> notes and cancellation set through the billing API
+	sources  map[uint]*models.BillingSource // billing ID -> source of a generated billing
+	units    map[uint]*models.ResidentUnit  // user ID -> unit with its occupancy dates and tariff attributes
+
+	penalties   []*models.BillingPenalty    // late fees, shared with memoryLateFeeRepository
+	adjustments []*models.BillingAdjustment // shared with memoryAdjustmentRepository
+}
+
+func newMemoryBillingRepository() *memoryBillingRepository {
+	return &memoryBillingRepository{
+		billings: make(map[uint]*models.Billing),
+		owners:   make(map[uint]*models.UserDetail),
+		statuses: make(map[uint]uint),
+		profiles: make(map[uint]bool),
+		sources:  make(map[uint]*models.BillingSource),
+		schedule: make(map[uint]int),
+		details:  make(map[uint]*models.BillingDetail),
+	}
+}
+
+// addBilling stores an unpaid billing owned by owner
+func (r *memoryBillingRepository) addBilling(id uint, nominal int64, month, year int, owner *models.UserDetail) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	publishedAt := time.Now()
+	r.billings[id] = &models.Billing{ID: id, Nominal: &nominal, Bulan: &month, Tahun: &year, PublishedAt: &publishedAt}
+	r.owners[id] = owner
+	r.statuses[id] = testStatusIDs[StatusBelumDibayar]
+}
+
+// statusName returns the current status name of a billing
+func (r *memoryBillingRepository) statusName(billingID uint) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, id := range testStatusIDs {
+		if r.statuses[billingID] == id {
+			return name
+		}
+	}
+	return ""
+}
+
+func (r *memoryBillingRepository) GetBillingByID(id uint) (*models.Billing, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	billing, ok := r.billings[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *billing
+	return &copied, nil
+}
+
+func (r *memoryBillingRepository) GetBillingOwner(billingID uint) (*models.UserDetail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner, ok := r.owners[billingID]
+	if !ok || owner == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return owner, nil
+}
+
+func (r *memoryBillingRepository) GetStatusByName(statusName string) (*models.MasterGeneralStatus, error) {
+	id, ok := testStatusIDs[statusName]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	name := statusName
+	return &models.MasterGeneralStatus{ID: id, Status: &name}, nil
+}
+
+func (r *memoryBillingRepository) GetBillingStatusNames(billingIDs []uint) (map[uint]string, error) {
+	result := make(map[uint]string, len(billingIDs))
+	for _, billingID := range billingIDs {
+		if name := r.statusName(billingID); name != "" {
+			result[billingID] = name
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryBillingRepository) UpdateBillingsStatus(billingIDs []uint, statusID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, billingID := range billingIDs {
+		r.statuses[billingID] = statusID
+	}
+	return nil
+}
+
+func (r *memoryBillingRepository) GetUnpaidBillingIDsByUser(userID uint, paidStatusName string, fromPeriod, toPeriod int) ([]uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var billingIDs []uint
+	for id, billing := range r.billings {
+		owner := r.owners[id]
+		period := *billing.Tahun*12 + *billing.Bulan
+		if owner == nil || owner.UserID != userID || r.statuses[id] == testStatusIDs[paidStatusName] ||
+			(fromPeriod > 0 && period < fromPeriod) || (toPeriod > 0 && period > toPeriod) {
+			continue
+		}
+		billingIDs = append(billingIDs, id)
+	}
+	return uniqueSortedIDs(billingIDs), nil
+}
+
+// addPenghuni stores a penghuni user, with a resident profile if hasProfile is set
+func (r *memoryBillingRepository) addPenghuni(userID uint, hasProfile bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users = append(r.users, &models.User{ID: userID})
+	r.profiles[userID] = hasProfile
+}
+
+// addSetting stores an active monthly setting billing
+func (r *memoryBillingRepository) addSetting(id uint, name string, nominal float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings = append(r.settings, &models.SettingBilling{ID: id, NamaBilling: name, Nominal: nominal, JenisBilling: models.JenisBillingBulanan})
+}
+
+// addYearlySetting stores an active yearly setting billing scheduled for month
+func (r *memoryBillingRepository) addYearlySetting(id uint, name string, nominal float64, month int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings = append(r.settings, &models.SettingBilling{ID: id, NamaBilling: name, Nominal: nominal, JenisBilling: models.JenisBillingTahunan})
+	r.schedule[id] = month
+}
+
+func (r *memoryBillingRepository) GetUsersWithPenghuniRole() ([]*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*models.User(nil), r.users...), nil
+}
+
+func (r *memoryBillingRepository) GetActiveSettingBillingsForMonth(month int) ([]*models.SettingBilling, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var settings []*models.SettingBilling
+	for _, setting := range r.settings {
+		if setting.JenisBilling == models.JenisBillingBulanan || r.schedule[setting.ID] == month {
+			settings = append(settings, setting)
+		}
+	}
+	return settings, nil
+}
+
+func (r *memoryBillingRepository) WithGenerationLock(lockKey string, fn func() error) error {
+	return fn()
+}
+
+func (r *memoryBillingRepository) GetUsersWithProfile(userIDs []uint) ([]*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var users []*models.User
+	for _, userID := range userIDs {
+		if r.profiles[userID] {
+			users = append(users, &models.User{ID: userID})
+		}
+	}
+	return users, nil
+}
+
+func (r *memoryBillingRepository) GetUserUnits(userIDs []uint) (map[uint]*models.ResidentUnit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	units := make(map[uint]*models.ResidentUnit)
+	for _, userID := range userIDs {
+		if unit, ok := r.units[userID]; ok {
+			units[userID] = unit
+		}
+	}
+	return units, nil
+}
+
+func (r *memoryBillingRepository) GetPeriodBillings(userIDs []uint, month, year int) ([]*models.PeriodBilling, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wanted := make(map[uint]bool, len(userIDs))
+	for _, userID := range userIDs {
+		wanted[userID] = true
+	}
+
+	var billings []*models.PeriodBilling
+	for _, id := range r.sortedBillingIDs() {
+		billing, owner := r.billings[id], r.owners[id]
+		if owner == nil || !wanted[owner.UserID] || *billing.Bulan != month || *billing.Tahun != year {
+			continue
+		}
+		cancelled := r.details[id] != nil && r.details[id].CancelledAt != nil
+		if billing.PublishedAt == nil && !cancelled {
+			continue
+		}
+
+		periodBilling := &models.PeriodBilling{BillingID: id, UserID: owner.UserID, Nominal: *billing.Nominal, Cancelled: cancelled}
+		if source, ok := r.sources[id]; ok {
+			if source.ChargeID != 0 {
+				continue
+			}
+			settingID := source.SettingBillingID
+			periodBilling.SettingBillingID = &settingID
+		}
+		for name, statusID := range testStatusIDs {
+			if r.statuses[id] == statusID {
+				periodBilling.StatusName = name
+			}
+		}
+		billings = append(billings, periodBilling)
+	}
+	return billings, nil
+}
+
+func (r *memoryBillingRepository) CreateGeneratedBillings(generated []*models.GeneratedBilling) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.createGeneratedBillings(generated)
+}
+
+func (r *memoryBillingRepository) CreateBillingCharge(charge *models.BillingCharge, generated []*models.GeneratedBilling) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	charge.ID = uint(len(r.charges) + 1)
+	copied := *charge
+	r.charges = append(r.charges, &copied)
+	for _, g := range generated {
+		g.Source.ChargeID = charge.ID
+	}
+	return r.createGeneratedBillings(generated)
+}
+
+// createGeneratedBillings stores generated billings; the caller holds r.mu
+func (r *memoryBillingRepository) createGeneratedBillings(generated []*models.GeneratedBilling) error {
+	for _, g := range generated {
+		source := g.Source
+		for _, existing := range r.sources {
+			if existing.UserID == source.UserID && existing.Bulan == source.Bulan && existing.Tahun == source.Tahun && existing.SettingBillingID == source.SettingBillingID && existing.ChargeID == source.ChargeID {
+				return fmt.Errorf("duplicate billing source for user %d", source.UserID)
+			}
+		}
+
+		billing := g.Billing
+		billing.ID = uint(len(r.billings) + 1)
+		source.BillingID = billing.ID
+		settingID, name, dueDate := source.SettingBillingID, source.NamaBilling, *g.Detail.DueDate
+		copied, copiedSource := *billing, *source
+		copied.SettingBillingID, copied.NamaBilling, copied.DueDate = &settingID, &name, &dueDate
+		r.billings[billing.ID] = &copied
+		r.owners[billing.ID] = &models.UserDetail{UserID: source.UserID}
+		r.statuses[billing.ID] = g.StatusID
+		r.sources[billing.ID] = &copiedSource
+		r.addAdjustments(billing.ID, g.Adjustments)
+	}
+	return nil
+}
+
+func (r *memoryBillingRepository) UpdateGeneratedBillings(replacements []*models.GeneratedBilling) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, g := range replacements {
+		billingID, nominal := g.Billing.ID, *g.Billing.Nominal
+		r.billings[billingID].Nominal = &nominal
+		if source, ok := r.sources[billingID]; ok && g.Source != nil {
+			source.BaseNominal, source.Proration, source.OccupiedDays, source.DaysInMonth = g.Source.BaseNominal, g.Source.Proration, g.Source.OccupiedDays, g.Source.DaysInMonth
+			source.TariffID, source.TariffName = g.Source.TariffID, g.Source.TariffName
+		}
+
+		kept := r.adjustments[:0]
+		for _, adjustment := range r.adjustments {
+			if adjustment.BillingID != billingID || adjustment.DiscountRuleID == nil {
+				kept = append(kept, adjustment)
+			}
+		}
+		r.adjustments = kept
+		r.addAdjustments(billingID, g.Adjustments)
+		r.statuses[billingID] = g.StatusID
+	}
+	return nil
+}
+
+func (r *memoryBillingRepository) GetBillingRecord(id uint, dueDay int) (*models.BillingRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	billing, ok := r.billings[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	record := &models.BillingRecord{ID: id, Bulan: billing.Bulan, Tahun: billing.Tahun, Nominal: *billing.Nominal, DueDate: billing.DueDate, PublishedAt: billing.PublishedAt}
+	if record.DueDate == nil {
+		dueDate := billingDueDate(*billing.Bulan, *billing.Tahun, dueDay)
+		record.DueDate = &dueDate
+	}
+	if owner := r.owners[id]; owner != nil {
+		userID := owner.UserID
+		record.UserID = &userID
+	}
+	statusID := r.statuses[id]
+	record.StatusID = &statusID
+	for name, testID := range testStatusIDs {
+		if statusID == testID {
+			record.StatusName = name
+		}
+	}
+	if source, ok := r.sources[id]; ok {
+		settingID := source.SettingBillingID
+		record.SettingBillingID, record.NamaBilling = &settingID, source.NamaBilling
+	}
+	if detail, ok := r.details[id]; ok {
+		record.Notes, record.CancelledAt, record.CancelledByID, record.CancelReason = detail.Notes, detail.CancelledAt, detail.CancelledByID, detail.CancelReason
+	}
+	return record, nil
+}
+
+func (r *memoryBillingRepository) UpdateBilling(billingID uint, nominal int64, detail *models.BillingDetail, statusID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.billings[billingID].Nominal = &nominal
+	r.statuses[billingID] = statusID
+	copied := *detail
+	copied.BillingID = billingID
+	if detail.DueDate != nil {
+		dueDate := *detail.DueDate
+		r.billings[billingID].DueDate = &dueDate
+	} else if existing, ok := r.details[billingID]; ok {
+		copied.DueDate = existing.DueDate
+	}
+	r.details[billingID] = &copied
+	return nil
+}
+
+func (r *memoryBillingRepository) CancelBilling(detail *models.BillingDetail) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.billings[detail.BillingID].PublishedAt = nil
+	copied := *detail
+	r.details[detail.BillingID] = &copied
+	return nil
+}
+
+// addAdjustments stores adjustments of a billing; the caller holds r.mu
+func (r *memoryBillingRepository) addAdjustments(billingID uint, adjustments []*models.BillingAdjustment) {
+	for _, adjustment := range adjustments {
+		adjustment.BillingID = billingID
+		adjustment.ID = uint(len(r.adjustments) + 1)
+		copied := *adjustment
+		r.adjustments = append(r.adjustments, &copied)
+	}
+}
+
+func (r *memoryBillingRepository) GetAdjustmentTotals(billingIDs []uint) (map[uint]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	totals := make(map[uint]int64)
+	for _, billingID := range billingIDs {
+		for _, adjustment := range r.adjustments {
+			if adjustment.BillingID == billingID && adjustment.Status == models.AdjustmentStatusApproved {
+				totals[billingID] += adjustment.Amount
+			}
+		}
+	}
+	return totals, nil
+}
+
+func (r *memoryBillingRepository) GetPenaltyTotals(billingIDs []uint) (map[uint]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	totals := make(map[uint]int64)
+	for _, billingID := range billingIDs {
+		for _, penalty := range r.penalties {
+			if penalty.BillingID == billingID && penalty.WaivedAt == nil {
+				totals[billingID] += penalty.Amount
+			}
+		}
+	}
+	return totals, nil
+}
+
+// sortedBillingIDs returns the stored billing IDs in ascending order; the caller holds r.mu
+func (r *memoryBillingRepository) sortedBillingIDs() []uint {
+	ids := make([]uint, 0, len(r.billings))
+	for id := range r.billings {
+		ids = append(ids, id)
+	}
+	return uniqueSortedIDs(ids)
+}
+
+// setStatuses moves each billing to its entry in billingStatusIDs
+func (r *memoryBillingRepository) setStatuses(billingStatusIDs map[uint]uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for billingID, statusID := range billingStatusIDs {
+		r.statuses[billingID] = statusID
+	}
+}
+
+// memoryPaymentRepository is an in-memory PaymentRepository for service tests
+type memoryPaymentRepository struct {
+	repository.PaymentRepository
+
+	checkoutMu      sync.Mutex
+	mu              sync.Mutex
+	billingRepo     *memoryBillingRepository
+	installmentRepo *memoryInstallmentRepository // optional, marks paid installment parts
+	transactions    []*models.PaymentTransaction
+	links           map[uint]map[uint]int64 // transaction ID -> billing ID -> amount paid
+	refunds         []*models.PaymentRefund
+}
+
+func newMemoryPaymentRepository(billingRepo *memoryBillingRepository) *memoryPaymentRepository {
+	return &memoryPaymentRepository{
+		billingRepo: billingRepo,
+		links:       make(map[uint]map[uint]int64),
+	}
+}
+
+// transaction returns a copy of the stored transaction with the given invoice number
+func (r *memoryPaymentRepository) transaction(invoiceNumber string) *models.PaymentTransaction {
+	transaction, err := r.GetTransactionByInvoiceNumber(invoiceNumber)
+	if err != nil {
+		return nil
+	}
+	return transaction
+}
+
+// WithCheckoutLock serializes every checkout, which is stricter than the per-key database locks
+func (r *memoryPaymentRepository) WithCheckoutLock(lockKeys []string, fn func() error) error {
+	r.checkoutMu.Lock()
+	defer r.checkoutMu.Unlock()
+
+	return fn()
+}
+
+// count returns the number of stored transactions
+func (r *memoryPaymentRepository) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.transactions)
+}
+
+func (r *memoryPaymentRepository) CreateTransaction(transaction *models.PaymentTransaction, allocations map[uint]int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transaction.ID = uint(len(r.transactions) + 1)
+	if transaction.CreatedAt.IsZero() {
+		transaction.CreatedAt = time.Now()
+	}
+	copied := *transaction
+	r.transactions = append(r.transactions, &copied)
+	r.links[transaction.ID] = make(map[uint]int64, len(allocations))
+	for billingID, amount := range allocations {
+		r.links[transaction.ID][billingID] = amount
+	}
+	return nil
+}
+
+func (r *memoryPaymentRepository) CreatePaidTransaction(transaction *models.PaymentTransaction, allocations map[uint]int64, billingStatusIDs map[uint]uint) error {
+	if err := r.CreateTransaction(transaction, allocations); err != nil {
+		return err
+	}
+	r.billingRepo.setStatuses(billingStatusIDs)
+	return nil
+}
+
+// find returns a copy of the last stored transaction matching the predicate
+func (r *memoryPaymentRepository) find(match func(*models.PaymentTransaction) bool) (*models.PaymentTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.transactions) - 1; i >= 0; i-- {
+		if match(r.transactions[i]) {
+			copied := *r.transactions[i]
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryPaymentRepository) GetTransactionByInvoiceNumber(invoiceNumber string) (*models.PaymentTransaction, error) {
+	return r.find(func(t *models.PaymentTransaction) bool { return t.InvoiceNumber == invoiceNumber })
+}
+
+func (r *memoryPaymentRepository) GetTransactionByIdempotencyKey(idempotencyKey string) (*models.PaymentTransaction, error) {
+	return r.find(func(t *models.PaymentTransaction) bool {
+		return t.IdempotencyKey != nil && *t.IdempotencyKey == idempotencyKey
+	})
+}
+
+func (r *memoryPaymentRepository) GetPendingTransactionsByBillingIDs(billingIDs []uint, now time.Time) ([]*models.PaymentTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*models.PaymentTransaction
+	for i := len(r.transactions) - 1; i >= 0; i-- {
+		t := r.transactions[i]
+		if t.Status != models.PaymentStatusPending || (t.ExpiredAt != nil && !t.ExpiredAt.After(now)) {
+			continue
+		}
+		for _, billingID := range billingIDs {
+			if _, ok := r.links[t.ID][billingID]; ok {
+				copied := *t
+				result = append(result, &copied)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryPaymentRepository) GetPendingTransactionsCreatedBefore(before time.Time, afterID uint, limit int) ([]*models.PaymentTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*models.PaymentTransaction
+	for _, t := range r.transactions {
+		if t.Status == models.PaymentStatusPending && t.CreatedAt.Before(before) && t.ID > afterID {
+			copied := *t
+			result = append(result, &copied)
+		}
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryPaymentRepository) GetTransactionsByInvoiceNumbers(invoiceNumbers []string) ([]*models.PaymentTransaction, error) {
+	var result []*models.PaymentTransaction
+	for _, invoiceNumber := range invoiceNumbers {
+		if transaction := r.transaction(invoiceNumber); transaction != nil {
+			result = append(result, transaction)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryPaymentRepository) GetPaidTransactionsBetween(gateway string, from, to time.Time) ([]*models.PaymentTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*models.PaymentTransaction
+	for _, t := range r.transactions {
+		if t.Gateway == gateway && t.Status == models.PaymentStatusPaid && t.PaidAt != nil &&
+			!t.PaidAt.Before(from) && t.PaidAt.Before(to) {
+			copied := *t
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryPaymentRepository) GetBillingIDsByTransactionID(transactionID uint) ([]uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return sortedAllocationIDs(r.links[transactionID]), nil
+}
+
+func (r *memoryPaymentRepository) GetTransactionAllocations(transactionID uint) (map[uint]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	allocations := make(map[uint]int64, len(r.links[transactionID]))
+	for billingID, amount := range r.links[transactionID] {
+		allocations[billingID] = amount
+	}
+	return allocations, nil
+}
+
+func (r *memoryPaymentRepository) GetBillingPaidAmounts(billingIDs []uint) (map[uint]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	paidAmounts := make(map[uint]int64)
+	for _, transaction := range r.transactions {
+		if transaction.Status != models.PaymentStatusPaid && transaction.Status != models.PaymentStatusRefunded {
+			continue
+		}
+		reversed := r.reversals(transaction.ID)
+		for _, billingID := range billingIDs {
+			if amount, ok := r.links[transaction.ID][billingID]; ok && amount > reversed[billingID] {
+				paidAmounts[billingID] += amount - reversed[billingID]
+			}
+		}
+	}
+	return paidAmounts, nil
+}
+
+func (r *memoryPaymentRepository) GetTransactionReversals(transactionID uint) (map[uint]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reversals(transactionID), nil
+}
+
+// reversals sums what the refunds of a transaction took back from each billing; r.mu must be held
+func (r *memoryPaymentRepository) reversals(transactionID uint) map[uint]int64 {
+	reversed := make(map[uint]int64)
+	for _, refund := range r.refunds {
+		for _, billing := range refund.Billings {
+			if billing.PaymentTransactionID == transactionID {
+				reversed[billing.BillingID] += billing.Amount
+			}
+		}
+	}
+	return reversed
+}
+
+func (r *memoryPaymentRepository) UpdatePendingTransactionStatus(transactionID uint, status string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transaction := r.transactions[transactionID-1]
+	if transaction.Status != models.PaymentStatusPending {
+		return false, nil
+	}
+	transaction.Status = status
+	return true, nil
+}
+
+func (r *memoryPaymentRepository) MarkTransactionPaid(transactionID uint, billingStatusIDs map[uint]uint, paidAt time.Time) (bool, error) {
+	r.mu.Lock()
+	transaction := r.transactions[transactionID-1]
+	if transaction.Status == models.PaymentStatusPaid || transaction.Status == models.PaymentStatusRefunded {
+		r.mu.Unlock()
+		return false, nil
+	}
+	transaction.Status = models.PaymentStatusPaid
+	transaction.PaidAt = &paidAt
+	partID := transaction.InstallmentPartID
+	r.mu.Unlock()
+
+	r.billingRepo.setStatuses(billingStatusIDs)
+	if partID != nil && r.installmentRepo != nil {
+		r.installmentRepo.markPartPaid(*partID, paidAt)
+	}
+	return true, nil
+}
+
+func (r *memoryPaymentRepository) CreateRefund(refund *models.PaymentRefund, status string, reopenedStatusIDs map[uint]uint) error {
+	r.mu.Lock()
+	transaction := r.transactions[refund.PaymentTransactionID-1]
+	if transaction.Status != models.PaymentStatusPaid || transaction.RefundedAmount+refund.Amount > transaction.Amount {
+		r.mu.Unlock()
+		return fmt.Errorf("payment transaction is no longer refundable")
+	}
+	transaction.RefundedAmount += refund.Amount
+	transaction.Status = status
+	refund.ID = uint(len(r.refunds) + 1)
+	copied := *refund
+	r.refunds = append(r.refunds, &copied)
+	r.mu.Unlock()
+
+	r.billingRepo.setStatuses(reopenedStatusIDs)
+	return nil
+}
+
+func (r *memoryPaymentRepository) GetRefundsByTransactionID(transactionID uint) ([]*models.PaymentRefund, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var refunds []*models.PaymentRefund
+	for _, refund := range r.refunds {
+		if refund.PaymentTransactionID == transactionID {
+			copied := *refund
+			refunds = append(refunds, &copied)
+		}
+	}
+	return refunds, nil
+}
+
+// memoryInstallmentRepository is an in-memory InstallmentRepository for service tests
+type memoryInstallmentRepository struct {
+	mu    sync.Mutex
+	plans map[uint]*models.InstallmentPlan
+	links map[uint][]uint // plan ID -> billing IDs
+	parts map[uint]*models.InstallmentPart
+}
+
+func newMemoryInstallmentRepository() *memoryInstallmentRepository {
+	return &memoryInstallmentRepository{
+		plans: make(map[uint]*models.InstallmentPlan),
+		links: make(map[uint][]uint),
+		parts: make(map[uint]*models.InstallmentPart),
+	}
+}
+
+func (r *memoryInstallmentRepository) CreatePlan(plan *models.InstallmentPlan, billingIDs []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	plan.ID = uint(len(r.plans) + 1)
+	for _, part := range plan.Parts {
+		part.ID = uint(len(r.parts) + 1)
+		part.PlanID = plan.ID
+		copied := *part
+		r.parts[part.ID] = &copied
+	}
+	copied := *plan
+	copied.Parts = nil
+	r.plans[plan.ID] = &copied
+	r.links[plan.ID] = append([]uint(nil), billingIDs...)
+	return nil
+}
+
+func (r *memoryInstallmentRepository) GetPlanByID(id uint) (*models.InstallmentPlan, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	plan, ok := r.plans[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *plan
+	for partID := uint(1); partID <= uint(len(r.parts)); partID++ {
+		if part := r.parts[partID]; part.PlanID == id {
+			copiedPart := *part
+			copied.Parts = append(copied.Parts, &copiedPart)
+		}
+	}
+	return &copied, nil
+}
+
+func (r *memoryInstallmentRepository) GetPlanBillingIDs(planID uint) ([]uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]uint(nil), r.links[planID]...), nil
+}
+
+func (r *memoryInstallmentRepository) GetPartByID(id uint) (*models.InstallmentPart, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	part, ok := r.parts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *part
+	return &copied, nil
+}
+
+func (r *memoryInstallmentRepository) GetActivePlanBillingIDs(billingIDs []uint) ([]uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var planned []uint
+	for planID, linked := range r.links {
+		if r.plans[planID].Status != models.InstallmentPlanActive {
+			continue
+		}
+		for _, id := range linked {
+			for _, wanted := range billingIDs {
+				if id == wanted {
+					planned = append(planned, id)
+				}
+			}
+		}
+	}
+	return planned, nil
+}
+
+// markPartPaid marks a part paid and completes its plan once every part is paid
+func (r *memoryInstallmentRepository) markPartPaid(partID uint, paidAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	part := r.parts[partID]
+	part.Status = models.InstallmentPartPaid
+	part.PaidAt = &paidAt
+
+	for _, other := range r.parts {
+		if other.PlanID == part.PlanID && other.Status != models.InstallmentPartPaid {
+			return
+		}
+	}
+	r.plans[part.PlanID].Status = models.InstallmentPlanCompleted
+}
+
+// memoryFeeRuleRepository is an in-memory FeeRuleRepository for service tests
+type memoryFeeRuleRepository struct {
+	repository.FeeRuleRepository
+
+	mu    sync.Mutex
+	rules []*models.PaymentFeeRule
+}
+
+func newMemoryFeeRuleRepository() *memoryFeeRuleRepository {
+	return &memoryFeeRuleRepository{}
+}
+
+func (r *memoryFeeRuleRepository) Create(rule *models.PaymentFeeRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rule.ID = uint(len(r.rules) + 1)
+	copied := *rule
+	r.rules = append(r.rules, &copied)
+	return nil
+}
+
+func (r *memoryFeeRuleRepository) GetByPaymentMethodType(paymentMethodType string) (*models.PaymentFeeRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rule := range r.rules {
+		if rule.PaymentMethodType == paymentMethodType {
+			copied := *rule
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryFeeRuleRepository) GetActiveByPaymentMethodType(paymentMethodType string) (*models.PaymentFeeRule, error) {
+	rule, err := r.GetByPaymentMethodType(paymentMethodType)
+	if err != nil || !rule.IsActive {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return rule, nil
+}
+
+func (r *memoryFeeRuleRepository) GetActivePaymentMethodTypes() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var types []string
+	for _, rule := range r.rules {
+		if rule.IsActive {
+			types = append(types, rule.PaymentMethodType)
+		}
+	}
+	return types, nil
+}
+
+// newTestLogger returns a logger that discards its output
+func newTestLogger() *logger.Logger {
+	log := logger.NewLogger("error", "text")
+	log.SetOutput(io.Discard)
+	return log
+}
+
+// memoryResidentUnitRepository is an in-memory ResidentUnitRepository
+type memoryResidentUnitRepository struct {
+	repository.ResidentUnitRepository
+
+	mu    sync.Mutex
+	units map[uint]*models.ResidentUnit // profile ID -> unit
+}
+
+func newMemoryResidentUnitRepository() *memoryResidentUnitRepository {
+	return &memoryResidentUnitRepository{units: make(map[uint]*models.ResidentUnit)}
+}
+
+func (r *memoryResidentUnitRepository) GetByProfileID(profileID uint) (*models.ResidentUnit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unit, ok := r.units[profileID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *unit
+	return &copied, nil
+}
+
+func (r *memoryResidentUnitRepository) Save(unit *models.ResidentUnit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *unit
+	r.units[unit.ProfileID] = &copied
+	return nil
+}
+
+// memoryBillingRunRepository is an in-memory BillingRunRepository
+type memoryBillingRunRepository struct {
+	repository.BillingRunRepository
+
+	mu     sync.Mutex
+	locked bool // simulates another replica holding the scheduler lock
+	runs   []*models.BillingRun
+}
+
+func (r *memoryBillingRunRepository) TryLock(lockKey string, fn func() error) (bool, error) {
+	if r.locked {
+		return false, nil
+	}
+	return true, fn()
+}
+
+func (r *memoryBillingRunRepository) Create(run *models.BillingRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	run.ID = uint(len(r.runs) + 1)
+	copied := *run
+	r.runs = append(r.runs, &copied)
+	return nil
+}
+
+func (r *memoryBillingRunRepository) Update(run *models.BillingRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *run
+	r.runs[run.ID-1] = &copied
+	return nil
+}
+
+func (r *memoryBillingRunRepository) HasSucceeded(kind string, month, year int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, run := range r.runs {
+		if run.Kind == kind && run.Bulan == month && run.Tahun == year && run.Status == models.BillingRunSucceeded {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// memoryLateFeeRepository is an in-memory LateFeeRepository keeping its penalties in the billing repository
+type memoryLateFeeRepository struct {
+	repository.LateFeeRepository
+
+	billingRepo *memoryBillingRepository
+}
+
+func (r *memoryLateFeeRepository) GetOverdueBillings(today time.Time, paidStatusName string) ([]*models.OverdueBilling, error) {
+	billingRepo := r.billingRepo
+	billingRepo.mu.Lock()
+	defer billingRepo.mu.Unlock()
+
+	var overdue []*models.OverdueBilling
+	for _, billingID := range billingRepo.sortedBillingIDs() {
+		billing := billingRepo.billings[billingID]
+		if billingRepo.statuses[billingID] == testStatusIDs[paidStatusName] || *billing.Nominal <= 0 {
+			continue
+		}
+		if billing.DueDate == nil || !billing.DueDate.Before(today) {
+			continue
+		}
+
+		row := &models.OverdueBilling{BillingID: billingID, Nominal: *billing.Nominal, DueDate: *billing.DueDate}
+		for _, adjustment := range billingRepo.adjustments {
+			if adjustment.BillingID == billingID && adjustment.Status == models.AdjustmentStatusApproved {
+				row.Nominal += adjustment.Amount
+			}
+		}
+		if row.Nominal <= 0 {
+			continue
+		}
+		for _, penalty := range billingRepo.penalties {
+			if penalty.BillingID == billingID {
+				row.MonthsCharged = max(row.MonthsCharged, penalty.MonthsLate)
+				row.PenaltyTotal += penalty.Amount
+			}
+		}
+		overdue = append(overdue, row)
+	}
+	return overdue, nil
+}
+
+func (r *memoryLateFeeRepository) CreatePenalties(penalties []*models.BillingPenalty) (int64, error) {
+	billingRepo := r.billingRepo
+	billingRepo.mu.Lock()
+	defer billingRepo.mu.Unlock()
+
+	var created int64
+	for _, penalty := range penalties {
+		duplicate := false
+		for _, existing := range billingRepo.penalties {
+			duplicate = duplicate || (existing.BillingID == penalty.BillingID && existing.MonthsLate == penalty.MonthsLate)
+		}
+		if duplicate {
+			continue
+		}
+
+		copied := *penalty
+		copied.ID = uint(len(billingRepo.penalties) + 1)
+		billingRepo.penalties = append(billingRepo.penalties, &copied)
+		created++
+	}
+	return created, nil
+}
+
+func (r *memoryLateFeeRepository) GetPenaltiesByBillingID(billingID uint) ([]*models.BillingPenalty, error) {
+	billingRepo := r.billingRepo
+	billingRepo.mu.Lock()
+	defer billingRepo.mu.Unlock()
+
+	var penalties []*models.BillingPenalty
+	for _, penalty := range billingRepo.penalties {
+		if penalty.BillingID == billingID {
+			copied := *penalty
+			penalties = append(penalties, &copied)
+		}
+	}
+	return penalties, nil
+}
+
+func (r *memoryLateFeeRepository) WaivePenalties(billingID uint, penaltyIDs []uint, waivedByID uint, reason string, waivedAt time.Time, billingStatusID uint) (int64, error) {
+	billingRepo := r.billingRepo
+	billingRepo.mu.Lock()
+	defer billingRepo.mu.Unlock()
+
+	selected := make(map[uint]bool)
+	for _, id := range penaltyIDs {
+		selected[id] = true
+	}
+
+	var waived int64
+	for _, penalty := range billingRepo.penalties {
+		if penalty.BillingID != billingID || penalty.WaivedAt != nil || (len(penaltyIDs) > 0 && !selected[penalty.ID]) {
+			continue
+		}
+		at, by := waivedAt, waivedByID
+		penalty.WaivedAt, penalty.WaivedByID, penalty.WaiveReason = &at, &by, reason
+		waived++
+	}
+	billingRepo.statuses[billingID] = billingStatusID
+	return waived, nil
+}
+
+// memoryAdjustmentRepository is an in-memory AdjustmentRepository keeping its adjustments in the billing repository
+type memoryAdjustmentRepository struct {
+	billingRepo *memoryBillingRepository
+}
+
+func (r *memoryAdjustmentRepository) Create(adjustment *models.BillingAdjustment) error {
+	billingRepo := r.billingRepo
+	billingRepo.mu.Lock()
+	defer billingRepo.mu.Unlock()
+
+	billingRepo.addAdjustments(adjustment.BillingID, []*models.BillingAdjustment{adjustment})
+	return nil
+}
+
+func (r *memoryAdjustmentRepository) GetByID(id uint) (*models.BillingAdjustment, error) {
+	billingRepo := r.billingRepo
+	billingRepo.mu.Lock()
+	defer billingRepo.mu.Unlock()
+
+	for _, adjustment := range billingRepo.adjustments {
+		if adjustment.ID == id {
+			copied := *adjustment
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryAdjustmentRepository) GetByBillingID(billingID uint) ([]*models.BillingAdjustment, error) {
+	billingRepo := r.billingRepo
+	billingRepo.mu.Lock()
+	defer billingRepo.mu.Unlock()
+
+	var adjustments []*models.BillingAdjustment
+	for _, adjustment := range billingRepo.adjustments {
+		if adjustment.BillingID == billingID {
+			copied := *adjustment
+			adjustments = append(adjustments, &copied)
+		}
+	}
+	return adjustments, nil
+}
+
+func (r *memoryAdjustmentRepository) Review(adjustment *models.BillingAdjustment, billingStatusID uint) (bool, error) {
+	billingRepo := r.billingRepo
+	billingRepo.mu.Lock()
+	defer billingRepo.mu.Unlock()
+
+	for _, stored := range billingRepo.adjustments {
+		if stored.ID != adjustment.ID {
+			continue
+		}
+		if stored.Status != models.AdjustmentStatusPending {
+			return false, nil
+		}
+		stored.Status, stored.ReviewedByID, stored.ReviewedAt, stored.ReviewNote = adjustment.Status, adjustment.ReviewedByID, adjustment.ReviewedAt, adjustment.ReviewNote
+		if adjustment.Status == models.AdjustmentStatusApproved {
+			billingRepo.statuses[adjustment.BillingID] = billingStatusID
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// memoryDiscountRuleRepository is an in-memory DiscountRuleRepository for service tests
+type memoryDiscountRuleRepository struct {
+	repository.DiscountRuleRepository
+
+	mu    sync.Mutex
+	rules []*models.BillingDiscountRule
+}
+
+func (r *memoryDiscountRuleRepository) Create(rule *models.BillingDiscountRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rule.ID = uint(len(r.rules) + 1)
+	copied := *rule
+	r.rules = append(r.rules, &copied)
+	return nil
+}
+
+func (r *memoryDiscountRuleRepository) GetActiveByUserIDs(userIDs []uint) ([]*models.BillingDiscountRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wanted := make(map[uint]bool, len(userIDs))
+	for _, userID := range userIDs {
+		wanted[userID] = true
+	}
+
+	var rules []*models.BillingDiscountRule
+	for _, rule := range r.rules {
+		if wanted[rule.UserID] && rule.IsActive {
+			copied := *rule
+			rules = append(rules, &copied)
+		}
+	}
+	return rules, nil
+}
+
+// memoryTariffRepository is an in-memory TariffRepository for service tests
+type memoryTariffRepository struct {
+	repository.TariffRepository
+
+	mu      sync.Mutex
+	tariffs []*models.SettingBillingTariff
+}
+
+func (r *memoryTariffRepository) Create(tariff *models.SettingBillingTariff) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tariff.ID = uint(len(r.tariffs) + 1)
+	copied := *tariff
+	r.tariffs = append(r.tariffs, &copied)
+	return nil
+}
+
+func (r *memoryTariffRepository) GetActiveBySettingIDs(settingIDs []uint) ([]*models.SettingBillingTariff, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wanted := make(map[uint]bool, len(settingIDs))
+	for _, settingID := range settingIDs {
+		wanted[settingID] = true
+	}
+
+	var tariffs []*models.SettingBillingTariff
+	for _, tariff := range r.tariffs {
+		if wanted[tariff.SettingBillingID] && tariff.IsActive {
+			copied := *tariff
+			tariffs = append(tariffs, &copied)
+		}
+	}
+	sort.SliceStable(tariffs, func(i, j int) bool { return tariffs[i].Priority < tariffs[j].Priority })
+	return tariffs, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ipl-be-svc/internal/config"
+	"ipl-be-svc/pkg/logger"
+)
+
+// Payment gateway names stored on payment transactions and used in PAYMENT_GATEWAY
+const (
+	PaymentGatewayDoku = "doku"
+	PaymentGatewayFake = "fake"
+
+	// PaymentGatewayManual marks offline payments recorded by admins; it has no PaymentGateway
+	PaymentGatewayManual = "manual"
+)
+
+// ErrGatewayUnavailable is returned when the payment gateway cannot be reached or keeps failing
+var ErrGatewayUnavailable = errors.New("payment gateway unavailable")
+
+// ErrCancelNotSupported is returned by gateways whose checkouts cannot be cancelled
+var ErrCancelNotSupported = errors.New("cancel is not supported by the payment gateway")
+
+// PaymentGateway defines the gateway-neutral operations PaymentService relies on.
+// Calls that reach the gateway stop when ctx is cancelled.
+type PaymentGateway interface {
+	Name() string
+	CreateCheckout(ctx context.Context, req *CheckoutRequest) (*CheckoutResult, error)
+	GetStatus(ctx context.Context, invoiceNumber string) (*GatewayPaymentStatus, error)
+	VerifyNotification(req *GatewayNotificationRequest) (*GatewayNotification, error)
+	CancelCheckout(ctx context.Context, invoiceNumber string) error
+}
+
+// CheckoutCustomer represents the payer of a checkout
+type CheckoutCustomer struct {
+	Name  string
+	Email string
+	Phone string
+}
+
+// CheckoutLineItem represents one item shown on the checkout page and receipt
+type CheckoutLineItem struct {
+	Name     string
+	Price    int64
+	Quantity int
+}
+
+// CheckoutRequest represents a gateway-neutral checkout request.
+// Amount must equal the sum of the line items when line items are given.
+type CheckoutRequest struct {
+	InvoiceNumber string
+	Amount        int64
+	Description   string
+	LineItems     []CheckoutLineItem
+	Customer      CheckoutCustomer
+
+	// PaymentMethodTypes limits the checkout to these payment method types; when empty the
+	// gateway offers its default methods except ExcludedPaymentMethodTypes
+	PaymentMethodTypes         []string
+	ExcludedPaymentMethodTypes []string
+}
+
+// CheckoutResult represents a checkout created by a gateway
+type CheckoutResult struct {
+	InvoiceNumber string
+	RequestID     string
+	TokenID       string
+	SessionID     string
+	PaymentURL    string
+	ExpiredAt     *time.Time
+	RawResponse   string
+}
+
+// GatewayPaymentStatus represents the status of an invoice as reported by a gateway.
+// Status uses the payment transaction statuses (models.PaymentStatus*).
+type GatewayPaymentStatus struct {
+	InvoiceNumber string
+	Status        string
+	Amount        int64
+	RawResponse   string
+}
+
+// GatewayNotificationRequest holds an incoming HTTP notification as received
+type GatewayNotificationRequest struct {
+	Headers       http.Header
+	RequestTarget string
+	Body          []byte
+}
+
+// GatewayNotification represents a verified notification. Status uses the payment transaction
+// statuses (models.PaymentStatus*) and is empty for non-final gateway statuses.
+type GatewayNotification struct {
+	InvoiceNumber string
+	Status        string
+	GatewayStatus string
+	Amount        int64
+}
+
+// NewPaymentGateway creates the payment gateway selected by configuration
+func NewPaymentGateway(cfg *config.Config, logger *logger.Logger) (PaymentGateway, error) {
+	switch cfg.Payment.Gateway {
+	case PaymentGatewayDoku:
+		return NewDokuGateway(cfg.Doku, logger), nil
+	case PaymentGatewayFake:
+		return NewFakeGateway(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway: %s", cfg.Payment.Gateway)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ipl-be-svc/pkg/logger"
+)
+
+// PaymentReconciler defines a background worker that reconciles pending payments with the gateway
+type PaymentReconciler interface {
+	Run(ctx context.Context)
+}
+
+// paymentReconciler implements PaymentReconciler
+type paymentReconciler struct {
+	paymentService PaymentService
+	interval       time.Duration
+	minAge         time.Duration
+	logger         *logger.Logger
+}
+
+// NewPaymentReconciler creates a worker that runs a reconciliation pass every interval for
+// pending transactions older than minAge
+func NewPaymentReconciler(paymentService PaymentService, interval, minAge time.Duration, logger *logger.Logger) PaymentReconciler {
+	return &paymentReconciler{
+		paymentService: paymentService,
+		interval:       interval,
+		minAge:         minAge,
+		logger:         logger,
+	}
+}
+
+// Run reconciles on every tick until ctx is cancelled
+func (r *paymentReconciler) Run(ctx context.Context) {
+	r.logger.WithFields(map[string]interface{}{
+		"interval": r.interval.String(),
+		"min_age":  r.minAge.String(),
+	}).Info("Payment reconciler started")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Payment reconciler stopped")
+			return
+		case <-ticker.C:
+			r.reconcile(ctx)
+		}
+	}
+}
+
+// reconcile runs a single reconciliation pass and logs its outcome
+func (r *paymentReconciler) reconcile(ctx context.Context) {
+	result, err := r.paymentService.ReconcilePendingTransactions(ctx, r.minAge)
+	if err != nil {
+		r.logger.WithError(err).Error("Payment reconciliation failed")
+		return
+	}
+
+	if result.Checked == 0 {
+		return
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"checked":   result.Checked,
+		"paid":      result.Paid,
+		"expired":   result.Expired,
+		"failed":    result.Failed,
+		"unchanged": result.Unchanged,
+		"errors":    len(result.Errors),
+	}).Info("Payment reconciliation completed")
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// addPendingTransaction stores a pending transaction for billingID created an hour ago
+func addPendingTransaction(t *testing.T, paymentRepo *memoryPaymentRepository, invoiceNumber string, billingID uint, expiredAt time.Time) {
+	t.Helper()
+
+	transaction := &models.PaymentTransaction{
+		InvoiceNumber: invoiceNumber,
+		BillingKey:    buildBillingKey([]uint{billingID}),
+		Gateway:       PaymentGatewayDoku,
+		Status:        models.PaymentStatusPending,
+		Amount:        150000,
+		ExpiredAt:     &expiredAt,
+		CreatedAt:     time.Now().Add(-time.Hour),
+	}
+	if err := paymentRepo.CreateTransaction(transaction, map[uint]int64{billingID: 150000}); err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+}
+
+func TestReconcilePendingTransactions(t *testing.T) {
+	standIn := newDokuStandIn(t)
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	svc := NewPaymentService(billingRepo, paymentRepo, newMemoryInstallmentRepository(), newMemoryFeeRuleRepository(), standIn.gateway, newTestLogger())
+
+	for id := uint(1); id <= 6; id++ {
+		billingRepo.addBilling(id, 150000, 11, 2025, testResident)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	addPendingTransaction(t, paymentRepo, "INV-PAID", 1, future)
+	addPendingTransaction(t, paymentRepo, "INV-FAILED", 2, future)
+	addPendingTransaction(t, paymentRepo, "INV-PENDING", 3, future)
+	addPendingTransaction(t, paymentRepo, "INV-PENDING-EXPIRED", 4, past)
+	addPendingTransaction(t, paymentRepo, "INV-UNKNOWN-EXPIRED", 5, past)
+	addPendingTransaction(t, paymentRepo, "INV-UNKNOWN", 6, future)
+
+	standIn.setStatus("INV-PAID", DokuTransactionSuccess, 150000)
+	standIn.setStatus("INV-FAILED", DokuTransactionFailed, 150000)
+	standIn.setStatus("INV-PENDING", DokuTransactionPending, 150000)
+	standIn.setStatus("INV-PENDING-EXPIRED", DokuTransactionPending, 150000)
+
+	result, err := svc.ReconcilePendingTransactions(context.Background(), 15*time.Minute)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if result.Checked != 6 || result.Paid != 1 || result.Failed != 1 || result.Expired != 2 || result.Unchanged != 1 || len(result.Errors) != 1 {
+		t.Errorf("result = %+v, want checked 6, paid 1, failed 1, expired 2, unchanged 1, 1 error", result)
+	}
+
+	wantStatuses := map[string]string{
+		"INV-PAID":            models.PaymentStatusPaid,
+		"INV-FAILED":          models.PaymentStatusFailed,
+		"INV-PENDING":         models.PaymentStatusPending,
+		"INV-PENDING-EXPIRED": models.PaymentStatusExpired,
+		"INV-UNKNOWN-EXPIRED": models.PaymentStatusExpired,
+		"INV-UNKNOWN":         models.PaymentStatusPending,
+	}
+	for invoice, want := range wantStatuses {
+		if got := paymentRepo.transaction(invoice).Status; got != want {
+			t.Errorf("%s status = %s, want %s", invoice, got, want)
+		}
+	}
+
+	if status := billingRepo.statusName(1); status != StatusSudahDibayar {
+		t.Errorf("billing 1 status = %s, want %s", status, StatusSudahDibayar)
+	}
+	for id := uint(2); id <= 6; id++ {
+		if status := billingRepo.statusName(id); status != StatusBelumDibayar {
+			t.Errorf("billing %d status = %s, want %s", id, status, StatusBelumDibayar)
+		}
+	}
+}
+
+func TestReconcilePendingTransactions_PagesPastUnreportedTransactions(t *testing.T) {
+	standIn := newDokuStandIn(t)
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	svc := NewPaymentService(billingRepo, paymentRepo, newMemoryInstallmentRepository(), newMemoryFeeRuleRepository(), standIn.gateway, newTestLogger())
+
+	// A full batch the gateway cannot report on must not hide the transactions behind it
+	future := time.Now().Add(time.Hour)
+	for i := 0; i < reconcileBatchSize; i++ {
+		addPendingTransaction(t, paymentRepo, generateInvoiceNumber(), 1, future)
+	}
+	billingRepo.addBilling(2, 150000, 11, 2025, testResident)
+	addPendingTransaction(t, paymentRepo, "INV-PAID", 2, future)
+	standIn.setStatus("INV-PAID", DokuTransactionSuccess, 150000)
+
+	result, err := svc.ReconcilePendingTransactions(context.Background(), 15*time.Minute)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if result.Checked != reconcileBatchSize+1 || result.Paid != 1 {
+		t.Errorf("result checked %d paid %d, want %d and 1", result.Checked, result.Paid, reconcileBatchSize+1)
+	}
+}
+
+func TestApplyPaymentStatus_DoesNotOverwritePaid(t *testing.T) {
+	svc, _, paymentRepo, _ := newTestPaymentService(t)
+
+	link, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create link: %v", err)
+	}
+
+	// The reconciler read the transaction as pending before the webhook marked it paid
+	stale := paymentRepo.transaction(link.InvoiceNumber)
+	if _, err := svc.HandleNotification(fakeNotification(t, link.InvoiceNumber, models.PaymentStatusPaid, link.Amount)); err != nil {
+		t.Fatalf("handle notification: %v", err)
+	}
+
+	updated, err := svc.(*paymentService).applyPaymentStatus(stale, []uint{1}, models.PaymentStatusExpired, 0)
+	if err != nil {
+		t.Fatalf("apply status: %v", err)
+	}
+	if updated || paymentRepo.transaction(link.InvoiceNumber).Status != models.PaymentStatusPaid {
+		t.Errorf("stale expired status overwrote paid transaction")
+	}
+}
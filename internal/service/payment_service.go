@@ -1,202 +1,353 @@
 package service
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"ipl-be-svc/internal/models"
 	"ipl-be-svc/internal/repository"
 	"ipl-be-svc/pkg/logger"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-// DokuConfig holds DOKU API configuration
-type DokuConfig struct {
-	ClientID  string
-	SecretKey string
-	BaseURL   string
-}
-
-// DokuOrder represents order details for DOKU checkout
-type DokuOrder struct {
-	Amount        int64          `json:"amount"`
-	InvoiceNumber string         `json:"invoice_number"`
-	Currency      string         `json:"currency"`
-	SessionID     string         `json:"session_id"`
-	CallbackURL   string         `json:"callback_url"`
-	LineItems     []DokuLineItem `json:"line_items"`
-}
-
-// DokuLineItem represents a line item in the order
-type DokuLineItem struct {
-	Name     string `json:"name"`
-	Price    int64  `json:"price"`
-	Quantity int    `json:"quantity"`
-}
-
-// DokuLineItemResponse represents a line item in the response (with string price)
-type DokuLineItemResponse struct {
-	Name     string `json:"name"`
-	Price    string `json:"price"`
-	Quantity int    `json:"quantity"`
-}
-
-// DokuPayment represents payment configuration
-type DokuPayment struct {
-	PaymentDueDate int `json:"payment_due_date"`
-}
-
-// DokuCustomer represents customer information
-type DokuCustomer struct {
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Phone   string `json:"phone"`
-	Address string `json:"address"`
-	Country string `json:"country"`
-}
-
-// DokuCheckoutRequest represents the complete DOKU checkout request
-type DokuCheckoutRequest struct {
-	Order    DokuOrder    `json:"order"`
-	Payment  DokuPayment  `json:"payment"`
-	Customer DokuCustomer `json:"customer"`
-}
-
-// DokuCheckoutResponse represents the actual DOKU API response structure
-type DokuCheckoutResponse struct {
-	Message  []string `json:"message"`
-	Response struct {
-		Order struct {
-			Amount        string                 `json:"amount"`
-			InvoiceNumber string                 `json:"invoice_number"`
-			Currency      string                 `json:"currency"`
-			SessionID     string                 `json:"session_id"`
-			CallbackURL   string                 `json:"callback_url"`
-			LineItems     []DokuLineItemResponse `json:"line_items"`
-		} `json:"order"`
-		Payment struct {
-			PaymentMethodTypes []string `json:"payment_method_types"`
-			PaymentDueDate     int      `json:"payment_due_date"`
-			TokenID            string   `json:"token_id"`
-			URL                string   `json:"url"`
-			ExpiredDate        string   `json:"expired_date"`
-			ExpiredDatetime    string   `json:"expired_datetime"`
-		} `json:"payment"`
-		Customer struct {
-			Email   string `json:"email"`
-			Phone   string `json:"phone"`
-			Name    string `json:"name"`
-			Address string `json:"address"`
-			Country string `json:"country"`
-		} `json:"customer"`
-		AdditionalInfo struct {
-			Origin struct {
-				Product   string `json:"product"`
-				System    string `json:"system"`
-				APIFormat string `json:"apiFormat"`
-				Source    string `json:"source"`
-			} `json:"origin"`
-			LineItems []DokuLineItemResponse `json:"line_items"`
-		} `json:"additional_info"`
-		UUID    interface{} `json:"uuid"` // Can be int64 or float64 depending on size
-		Headers struct {
-			RequestID string `json:"request_id"`
-			Signature string `json:"signature"`
-			Date      string `json:"date"`
-			ClientID  string `json:"client_id"`
-		} `json:"headers"`
-	} `json:"response"`
-}
-
-// DokuService defines the interface for DOKU payment operations
-type DokuService interface {
-	CreatePaymentLink(amount int64, description string) (string, error)
-	InitiateDokuCheckout(clientID, secretKey string, amount int64, description string) (*DokuCheckoutResponse, error)
-}
+// Billing status names stored in master_general_statuses
+const (
+	StatusBelumDibayar = "Belum Dibayar"
+	StatusSudahDibayar = "Sudah Dibayar"
+
+	// StatusDibayarSebagian marks a billing with payments that do not cover its nominal yet
+	StatusDibayarSebagian = "Dibayar Sebagian"
+)
+
+// ErrBillingOwnerNotFound is returned when a billing has no resident with a published profile
+var ErrBillingOwnerNotFound = errors.New("billing owner not found")
+
+// ErrInvalidPaymentAmount is returned when a partial payment amount is not payable
+var ErrInvalidPaymentAmount = errors.New("invalid payment amount")
+
+// ErrNoOutstandingBillings is returned when a resident has nothing left to pay
+var ErrNoOutstandingBillings = errors.New("no outstanding billings")
+
+// ErrBillingNotFound is returned when a billing to pay does not exist or is not published
+var ErrBillingNotFound = errors.New("billing record not found")
+
+// ErrInvalidBillingNominal is returned when a billing to pay has no positive nominal
+var ErrInvalidBillingNominal = errors.New("invalid billing nominal")
+
+// ErrPaymentTransactionNotFound is returned when no payment transaction has the invoice number
+var ErrPaymentTransactionNotFound = errors.New("payment transaction not found")
+
+// ErrPaymentTransactionNotPaid is returned when a payment transaction has not been paid
+var ErrPaymentTransactionNotPaid = errors.New("payment transaction is not paid")
+
+// ErrPendingCheckout is returned when a billing already has a pending, unexpired checkout that
+// the request cannot reuse
+var ErrPendingCheckout = errors.New("billing has a pending checkout")
 
 // PaymentService defines the interface for payment operations
 type PaymentService interface {
-	CreatePaymentLink(billingID uint) (*PaymentLinkResponse, error)
-	CreatePaymentLinkMultiple(billingIDs []uint) (*PaymentLinkResponse, error)
+	CreatePaymentLink(ctx context.Context, billingID uint, opts CheckoutOptions) (*PaymentLinkResponse, error)
+	CreatePaymentLinkMultiple(ctx context.Context, billingIDs []uint, opts CheckoutOptions) (*PaymentLinkResponse, error)
+	CreatePartialPaymentLink(ctx context.Context, billingID uint, amount int64, opts CheckoutOptions) (*PaymentLinkResponse, error)
+	CreateInstallmentPaymentLink(ctx context.Context, partID uint, opts CheckoutOptions) (*PaymentLinkResponse, error)
+	CreateOutstandingPaymentLink(ctx context.Context, userID uint, months BillingMonthRange, opts CheckoutOptions) (*PaymentLinkResponse, error)
+	GetBillingBalance(billingID uint) (*BillingBalance, error)
+	HandleNotification(req *GatewayNotificationRequest) (*PaymentNotificationResult, error)
+	ReconcilePendingTransactions(ctx context.Context, minAge time.Duration) (*PaymentReconcileResult, error)
+	CancelPaymentLink(ctx context.Context, invoiceNumber string) (*PaymentCancelResult, error)
+	GatewayName() string
+}
+
+// BillingMonth is the month and year of a billing period
+type BillingMonth struct {
+	Year  int
+	Month int
+}
+
+// BillingMonthRange limits billings to the periods from From to To, both included. A nil end
+// leaves that side of the range open.
+type BillingMonthRange struct {
+	From *BillingMonth
+	To   *BillingMonth
+}
+
+// CheckoutOptions holds the optional settings of a payment link request
+type CheckoutOptions struct {
+	// PaymentMethodType limits the checkout to one gateway payment method type, e.g. CREDIT_CARD,
+	// and adds the fee of its fee rule
+	PaymentMethodType string
+	// IdempotencyKey returns the checkout created earlier with the same key
+	IdempotencyKey string
+}
+
+// PaymentReconcileResult represents the outcome of one reconciliation pass
+type PaymentReconcileResult struct {
+	Checked   int      `json:"checked"`
+	Paid      int      `json:"paid"`
+	Expired   int      `json:"expired"`
+	Failed    int      `json:"failed"`
+	Unchanged int      `json:"unchanged"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// BillingBalance represents what has been paid of a billing and what is still outstanding
+type BillingBalance struct {
+	BillingID   uint   `json:"billing_id"`
+	Nominal     int64  `json:"nominal"`
+	Adjustment  int64  `json:"adjustment"` // Approved discounts, waivers, surcharges and corrections
+	LateFee     int64  `json:"late_fee"`   // Unwaived denda
+	AmountDue   int64  `json:"amount_due"` // Nominal plus adjustment and late fee
+	PaidAmount  int64  `json:"paid_amount"`
+	Outstanding int64  `json:"outstanding"`
+	Status      string `json:"status"`
+}
+
+// PaymentCancelResult represents the outcome of cancelling a pending checkout
+type PaymentCancelResult struct {
+	InvoiceNumber    string `json:"invoice_number"`
+	PaymentStatus    string `json:"payment_status"`
+	BillingIDs       []uint `json:"billing_ids"`
+	GatewayCancelled bool   `json:"gateway_cancelled"` // False when the gateway cannot cancel and the link stays open until it expires
+}
+
+// PaymentNotificationResult represents the outcome of processing a payment notification
+type PaymentNotificationResult struct {
+	InvoiceNumber     string `json:"invoice_number"`
+	TransactionStatus string `json:"transaction_status"`
+	PaymentStatus     string `json:"payment_status"`
+	BillingIDs        []uint `json:"billing_ids"`
+	Updated           bool   `json:"updated"`
 }
 
 // PaymentLinkResponse represents the response for payment link creation
 type PaymentLinkResponse struct {
-	BillingID   uint   `json:"billing_id,omitempty"`
-	BillingIDs  []uint `json:"billing_ids,omitempty"`
-	Amount      int64  `json:"amount"`
-	PaymentURL  string `json:"payment_url"`
-	Description string `json:"description"`
+	TransactionID uint       `json:"transaction_id"`
+	InvoiceNumber string     `json:"invoice_number"`
+	BillingID     uint       `json:"billing_id,omitempty"`
+	BillingIDs    []uint     `json:"billing_ids,omitempty"`
+	Amount        int64      `json:"amount"`     // Billing amounts plus the fee
+	FeeAmount     int64      `json:"fee_amount"` // Convenience fee of the payment method type
+	PaymentURL    string     `json:"payment_url"`
+	Description   string     `json:"description"`
+	ExpiredAt     *time.Time `json:"expired_at,omitempty"`
+	Reused        bool       `json:"reused"` // True when an existing pending checkout was returned
 }
 
 // paymentService implements PaymentService
 type paymentService struct {
-	billingRepo repository.BillingRepository
-	dokuService DokuService
-	logger      *logger.Logger
+	billingRepo     repository.BillingRepository
+	paymentRepo     repository.PaymentRepository
+	installmentRepo repository.InstallmentRepository
+	feeRuleRepo     repository.FeeRuleRepository
+	gateway         PaymentGateway
+	logger          *logger.Logger
 }
 
 // NewPaymentService creates a new instance of PaymentService
-func NewPaymentService(billingRepo repository.BillingRepository, dokuService DokuService, logger *logger.Logger) PaymentService {
+func NewPaymentService(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, installmentRepo repository.InstallmentRepository, feeRuleRepo repository.FeeRuleRepository, gateway PaymentGateway, logger *logger.Logger) PaymentService {
 	return &paymentService{
-		billingRepo: billingRepo,
-		dokuService: dokuService,
-		logger:      logger,
+		billingRepo:     billingRepo,
+		paymentRepo:     paymentRepo,
+		installmentRepo: installmentRepo,
+		feeRuleRepo:     feeRuleRepo,
+		gateway:         gateway,
+		logger:          logger,
 	}
 }
 
-// CreatePaymentLink creates a DOKU payment link for a billing record
-func (s *paymentService) CreatePaymentLink(billingID uint) (*PaymentLinkResponse, error) {
+// GatewayName returns the name of the payment gateway the service creates checkouts with
+func (s *paymentService) GatewayName() string {
+	return s.gateway.Name()
+}
+
+// CreatePaymentLink creates a payment link for a billing record
+func (s *paymentService) CreatePaymentLink(ctx context.Context, billingID uint, opts CheckoutOptions) (*PaymentLinkResponse, error) {
 	// Get billing record
 	billing, err := s.billingRepo.GetBillingByID(billingID)
 	if err != nil {
 		s.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to get billing record")
-		return nil, fmt.Errorf("billing record not found: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrBillingNotFound, err)
 	}
 	s.logger.WithField("billing", billing).Info("Retrieved billing record")
 
 	// Validate nominal exists
 	if billing.Nominal == nil || *billing.Nominal <= 0 {
 		s.logger.WithField("billing_id", billingID).Error("Invalid billing nominal")
-		return nil, fmt.Errorf("invalid billing nominal")
+		return nil, ErrInvalidBillingNominal
 	}
 
 	// Create description
-	description := fmt.Sprintf("Payment for Billing ID %d", billingID)
-	if billing.Bulan != nil && billing.Tahun != nil {
-		description = fmt.Sprintf("Payment for %d/%d - Billing ID %d", *billing.Bulan, *billing.Tahun, billingID)
+	description := fmt.Sprintf("Payment for %s - Billing ID %d", billingLineItemName(billing), billingID)
+
+	response, err := s.createCheckout(ctx, []uint{billingID}, description, opts, nil, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create DOKU payment link
-	paymentURL, err := s.dokuService.CreatePaymentLink(*billing.Nominal, description)
+	response.BillingID = billingID
+	return response, nil
+}
+
+// CreatePartialPaymentLink creates a payment link for part of the outstanding balance of a billing
+func (s *paymentService) CreatePartialPaymentLink(ctx context.Context, billingID uint, amount int64, opts CheckoutOptions) (*PaymentLinkResponse, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidPaymentAmount)
+	}
+
+	billing, err := s.billingRepo.GetBillingByID(billingID)
 	if err != nil {
-		s.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to create DOKU payment link")
-		return nil, fmt.Errorf("failed to create payment link: %w", err)
+		s.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to get billing record")
+		return nil, ErrBillingNotFound
 	}
 
-	return &PaymentLinkResponse{
+	description := fmt.Sprintf("Partial payment for %s - Billing ID %d", billingLineItemName(billing), billingID)
+
+	allocate := func(outstanding map[uint]int64) (map[uint]int64, error) {
+		if outstanding[billingID] <= 0 {
+			return nil, fmt.Errorf("billing already paid")
+		}
+		if amount > outstanding[billingID] {
+			return nil, fmt.Errorf("%w: amount %d exceeds the outstanding balance %d", ErrInvalidPaymentAmount, amount, outstanding[billingID])
+		}
+		return map[uint]int64{billingID: amount}, nil
+	}
+
+	response, err := s.createCheckout(ctx, []uint{billingID}, description, opts, allocate, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response.BillingID = billingID
+	return response, nil
+}
+
+// CreateInstallmentPaymentLink creates a payment link for one part of an installment plan. Parts
+// are paid in sequence; a part pays the plan's billings oldest first.
+func (s *paymentService) CreateInstallmentPaymentLink(ctx context.Context, partID uint, opts CheckoutOptions) (*PaymentLinkResponse, error) {
+	part, err := s.installmentRepo.GetPartByID(partID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("installment part not found")
+		}
+		return nil, fmt.Errorf("failed to get installment part: %w", err)
+	}
+
+	plan, err := s.installmentRepo.GetPlanByID(part.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installment plan: %w", err)
+	}
+	if plan.Status != models.InstallmentPlanActive {
+		return nil, fmt.Errorf("installment plan is not active")
+	}
+	if part.Status == models.InstallmentPartPaid {
+		return nil, fmt.Errorf("installment part already paid")
+	}
+	for _, other := range plan.Parts {
+		if other.Sequence < part.Sequence && other.Status != models.InstallmentPartPaid {
+			return nil, fmt.Errorf("previous installment part not paid")
+		}
+	}
+
+	billingIDs, err := s.installmentRepo.GetPlanBillingIDs(plan.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installment plan billings: %w", err)
+	}
+
+	description := fmt.Sprintf("Installment %d/%d for billings %s", part.Sequence, len(plan.Parts), plan.BillingKey)
+
+	allocate := func(outstanding map[uint]int64) (map[uint]int64, error) {
+		allocations := make(map[uint]int64)
+		remaining := part.Amount
+		for _, billingID := range billingIDs {
+			if remaining == 0 {
+				break
+			}
+			if amount := min(outstanding[billingID], remaining); amount > 0 {
+				allocations[billingID] = amount
+				remaining -= amount
+			}
+		}
+		if len(allocations) == 0 {
+			return nil, fmt.Errorf("billing already paid")
+		}
+		return allocations, nil
+	}
+
+	response, err := s.createCheckout(ctx, billingIDs, description, opts, allocate, &part.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response.BillingIDs = billingIDs
+	return response, nil
+}
+
+// GetBillingBalance returns what has been paid of a billing and what is still outstanding
+func (s *paymentService) GetBillingBalance(billingID uint) (*BillingBalance, error) {
+	balances, err := loadBillingBalances(s.billingRepo, s.paymentRepo, []uint{billingID})
+	if err != nil {
+		return nil, err
+	}
+
+	balance := balances[billingID]
+	return &BillingBalance{
 		BillingID:   billingID,
-		Amount:      *billing.Nominal,
-		PaymentURL:  paymentURL,
-		Description: description,
+		Nominal:     *balance.billing.Nominal,
+		Adjustment:  balance.adjustment,
+		LateFee:     balance.penalty,
+		AmountDue:   balance.due,
+		PaidAmount:  balance.due - balance.outstanding,
+		Outstanding: balance.outstanding,
+		Status:      balance.status,
 	}, nil
 }
 
-// CreatePaymentLinkMultiple creates a DOKU payment link for multiple billing records
-func (s *paymentService) CreatePaymentLinkMultiple(billingIDs []uint) (*PaymentLinkResponse, error) {
+// CreateOutstandingPaymentLink creates one payment link for every unpaid published billing of a
+// resident, optionally limited to a range of months. Billings in an active installment plan are
+// left out because they are paid through the plan's parts.
+func (s *paymentService) CreateOutstandingPaymentLink(ctx context.Context, userID uint, months BillingMonthRange, opts CheckoutOptions) (*PaymentLinkResponse, error) {
+	fromPeriod, toPeriod := months.From.period(), months.To.period()
+	if fromPeriod > 0 && toPeriod > 0 && fromPeriod > toPeriod {
+		return nil, fmt.Errorf("%w: from month is after to month", ErrInvalidBillingMonth)
+	}
+
+	billingIDs, err := s.billingRepo.GetUnpaidBillingIDsByUser(userID, StatusSudahDibayar, fromPeriod, toPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unpaid billings: %w", err)
+	}
+
+	planned, err := s.installmentRepo.GetActivePlanBillingIDs(billingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installment plans: %w", err)
+	}
+	billingIDs = subtractIDs(billingIDs, planned)
+
+	if len(billingIDs) == 0 {
+		return nil, ErrNoOutstandingBillings
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"user_id":     userID,
+		"billing_ids": billingIDs,
+	}).Info("Creating payment link for outstanding billings")
+
+	return s.CreatePaymentLinkMultiple(ctx, billingIDs, opts)
+}
+
+// CreatePaymentLinkMultiple creates a payment link for multiple billing records
+func (s *paymentService) CreatePaymentLinkMultiple(ctx context.Context, billingIDs []uint, opts CheckoutOptions) (*PaymentLinkResponse, error) {
 	if len(billingIDs) == 0 {
 		return nil, fmt.Errorf("billing IDs cannot be empty")
 	}
+	billingIDs = uniqueSortedIDs(billingIDs)
 
-	var totalAmount int64 = 0
 	var descriptions []string
 
 	for _, billingID := range billingIDs {
@@ -204,226 +355,647 @@ func (s *paymentService) CreatePaymentLinkMultiple(billingIDs []uint) (*PaymentL
 		billing, err := s.billingRepo.GetBillingByID(billingID)
 		if err != nil {
 			s.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to get billing record")
-			return nil, fmt.Errorf("billing record not found for ID %d: %w", billingID, err)
+			return nil, fmt.Errorf("%w for ID %d: %w", ErrBillingNotFound, billingID, err)
 		}
 
 		// Validate nominal exists
 		if billing.Nominal == nil || *billing.Nominal <= 0 {
 			s.logger.WithField("billing_id", billingID).Error("Invalid billing nominal")
-			return nil, fmt.Errorf("invalid billing nominal for ID %d", billingID)
+			return nil, fmt.Errorf("%w for ID %d", ErrInvalidBillingNominal, billingID)
 		}
 
-		totalAmount += *billing.Nominal
-
 		// Create description part
-		desc := fmt.Sprintf("Billing ID %d", billingID)
-		if billing.Bulan != nil && billing.Tahun != nil {
-			desc = fmt.Sprintf("%d/%d - Billing ID %d", *billing.Bulan, *billing.Tahun, billingID)
-		}
-		descriptions = append(descriptions, desc)
+		descriptions = append(descriptions, fmt.Sprintf("%s - Billing ID %d", billingLineItemName(billing), billingID))
 	}
 
 	// Create combined description
-	description := fmt.Sprintf("Payment for multiple billings: %v", descriptions)
+	description := fmt.Sprintf("Payment for %d billings: %s", len(billingIDs), strings.Join(descriptions, ", "))
+
+	response, err := s.createCheckout(ctx, billingIDs, description, opts, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response.BillingIDs = billingIDs
+	return response, nil
+}
+
+// allocationFunc picks how much of each billing a checkout pays, given their outstanding balances
+type allocationFunc func(outstanding map[uint]int64) (map[uint]int64, error)
+
+// createCheckout creates a gateway checkout and stores it as a pending payment transaction. By
+// default it pays the whole outstanding balance of every billing; allocate picks other amounts.
+// The fee of the payment method type's fee rule is added on top as its own line item.
+// A request repeated with the same Idempotency-Key, or for the same billings, amount and payment
+// method type while an earlier checkout is still pending and unexpired, gets the existing
+// checkout back. Any other request for a billing with a pending, unexpired checkout fails with
+// ErrPendingCheckout, so a billing is never payable through two live checkouts. Concurrent
+// requests sharing a key or any billing are serialized so only one of them reaches the gateway.
+func (s *paymentService) createCheckout(ctx context.Context, billingIDs []uint, description string, opts CheckoutOptions, allocate allocationFunc, installmentPartID *uint) (*PaymentLinkResponse, error) {
+	billingKey := buildBillingKey(billingIDs)
+	opts.PaymentMethodType = normalizePaymentMethodType(opts.PaymentMethodType)
+
+	lockKeys := billingLockKeys(billingIDs)
+	if opts.IdempotencyKey != "" {
+		lockKeys = append([]string{"idempotency:" + opts.IdempotencyKey}, lockKeys...)
+	}
+
+	var response *PaymentLinkResponse
+	err := s.paymentRepo.WithCheckoutLock(lockKeys, func() error {
+		var err error
+		response, err = s.createCheckoutLocked(ctx, billingIDs, billingKey, description, opts, allocate, installmentPartID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// createCheckoutLocked does the work of createCheckout while its checkout locks are held
+func (s *paymentService) createCheckoutLocked(ctx context.Context, billingIDs []uint, billingKey, description string, opts CheckoutOptions, allocate allocationFunc, installmentPartID *uint) (*PaymentLinkResponse, error) {
+	if opts.IdempotencyKey != "" {
+		existing, err := s.paymentRepo.GetTransactionByIdempotencyKey(opts.IdempotencyKey)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get payment transaction: %w", err)
+		}
+		if existing != nil {
+			if existing.BillingKey != billingKey {
+				return nil, fmt.Errorf("idempotency key reused with different billings")
+			}
+			return newReusedPaymentLinkResponse(existing), nil
+		}
+	}
+
+	balances, err := loadBillingBalances(s.billingRepo, s.paymentRepo, billingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	outstanding := make(map[uint]int64, len(balances))
+	for billingID, balance := range balances {
+		outstanding[billingID] = balance.outstanding
+	}
+
+	allocations := outstanding
+	if allocate != nil {
+		allocations, err = allocate(outstanding)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for _, billingID := range billingIDs {
+			if outstanding[billingID] <= 0 {
+				s.logger.WithField("billing_id", billingID).Warn("Refused to create payment link for paid billing")
+				return nil, fmt.Errorf("billing already paid")
+			}
+		}
+	}
+
+	var amount int64
+	lineItems := make([]CheckoutLineItem, 0, len(allocations))
+	for _, billingID := range sortedAllocationIDs(allocations) {
+		amount += allocations[billingID]
+		lineItems = append(lineItems, CheckoutLineItem{
+			Name:     billingLineItemName(balances[billingID].billing),
+			Price:    allocations[billingID],
+			Quantity: 1,
+		})
+	}
+
+	fee, err := s.checkoutFee(opts.PaymentMethodType, amount)
+	if err != nil {
+		return nil, err
+	}
+	if fee > 0 {
+		amount += fee
+		lineItems = append(lineItems, CheckoutLineItem{Name: feeLineItemName, Price: fee, Quantity: 1})
+	}
+
+	pending, err := s.paymentRepo.GetPendingTransactionsByBillingIDs(billingIDs, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending payment transactions: %w", err)
+	}
+	for _, existing := range pending {
+		if existing.BillingKey == billingKey && existing.Amount == amount && existing.PaymentMethod == opts.PaymentMethodType &&
+			equalOptionalIDs(existing.InstallmentPartID, installmentPartID) {
+			s.logger.WithFields(map[string]interface{}{
+				"invoice_number": existing.InvoiceNumber,
+				"billing_ids":    billingIDs,
+			}).Info("Reusing pending payment transaction")
+			return newReusedPaymentLinkResponse(existing), nil
+		}
+	}
+	if len(pending) > 0 {
+		s.logger.WithFields(map[string]interface{}{
+			"invoice_number": pending[0].InvoiceNumber,
+			"billing_ids":    billingIDs,
+		}).Warn("Refused to create payment link for billings with a pending checkout")
+		return nil, fmt.Errorf("%w: cancel %s or wait for it to expire", ErrPendingCheckout, pending[0].InvoiceNumber)
+	}
+
+	customer, err := s.resolveCustomer(billingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	invoiceNumber := generateInvoiceNumber()
+
+	checkoutRequest := &CheckoutRequest{
+		InvoiceNumber: invoiceNumber,
+		Amount:        amount,
+		Description:   description,
+		LineItems:     lineItems,
+		Customer:      customer,
+	}
+	if opts.PaymentMethodType != "" {
+		checkoutRequest.PaymentMethodTypes = []string{opts.PaymentMethodType}
+	} else {
+		// Methods with a fee are only offered by checkouts limited to them, which charge the fee
+		checkoutRequest.ExcludedPaymentMethodTypes, err = s.feeRuleRepo.GetActivePaymentMethodTypes()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fee rules: %w", err)
+		}
+	}
 
-	// Create DOKU payment link
-	paymentURL, err := s.dokuService.CreatePaymentLink(totalAmount, description)
+	// Create gateway payment link
+	checkout, err := s.gateway.CreateCheckout(ctx, checkoutRequest)
 	if err != nil {
-		s.logger.WithError(err).WithField("billing_ids", billingIDs).Error("Failed to create DOKU payment link")
+		s.logger.WithError(err).WithField("billing_ids", billingIDs).Error("Failed to create payment link")
 		return nil, fmt.Errorf("failed to create payment link: %w", err)
 	}
 
+	transaction := &models.PaymentTransaction{
+		InvoiceNumber: invoiceNumber,
+		BillingKey:    billingKey,
+		Description:   description,
+		RequestID:     checkout.RequestID,
+		TokenID:       checkout.TokenID,
+		SessionID:     checkout.SessionID,
+		PaymentURL:    checkout.PaymentURL,
+		Gateway:       s.gateway.Name(),
+		PaymentMethod: opts.PaymentMethodType,
+		Status:        models.PaymentStatusPending,
+		Amount:        amount,
+		FeeAmount:     fee,
+		ExpiredAt:     checkout.ExpiredAt,
+		RawResponse:   checkout.RawResponse,
+
+		InstallmentPartID: installmentPartID,
+	}
+	if opts.IdempotencyKey != "" {
+		transaction.IdempotencyKey = &opts.IdempotencyKey
+	}
+
+	if err := s.paymentRepo.CreateTransaction(transaction, allocations); err != nil {
+		s.logger.WithError(err).WithFields(map[string]interface{}{
+			"invoice_number": invoiceNumber,
+			"billing_ids":    billingIDs,
+		}).Error("Failed to store payment transaction")
+		return nil, fmt.Errorf("failed to store payment transaction: %w", err)
+	}
+
 	return &PaymentLinkResponse{
-		BillingIDs:  billingIDs,
-		Amount:      totalAmount,
-		PaymentURL:  paymentURL,
-		Description: description,
+		TransactionID: transaction.ID,
+		InvoiceNumber: invoiceNumber,
+		Amount:        amount,
+		FeeAmount:     fee,
+		PaymentURL:    transaction.PaymentURL,
+		Description:   description,
+		ExpiredAt:     transaction.ExpiredAt,
 	}, nil
 }
 
-// dokuService implements DokuService
-type dokuService struct {
-	logger *logger.Logger
-	config DokuConfig
+// checkoutFee returns the fee the active fee rule of a payment method type charges on amount,
+// or zero when the checkout is not limited to a method type or the type has no active rule
+func (s *paymentService) checkoutFee(paymentMethodType string, amount int64) (int64, error) {
+	if paymentMethodType == "" {
+		return 0, nil
+	}
+
+	rule, err := s.feeRuleRepo.GetActiveByPaymentMethodType(paymentMethodType)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get fee rule: %w", err)
+	}
+
+	return calculateFee(rule, amount), nil
 }
 
-// NewDokuService creates a new instance of DokuService
-func NewDokuService(logger *logger.Logger) DokuService {
-	config := DokuConfig{
-		ClientID:  os.Getenv("DOKU_CLIENT_ID"),
-		SecretKey: os.Getenv("DOKU_SECRET_KEY"),
-		BaseURL:   "https://api-sandbox.doku.com",
+// ErrInvalidBillingMonth is returned when a billing month or month range is malformed
+var ErrInvalidBillingMonth = errors.New("invalid billing month")
+
+// ParseBillingMonth parses a YYYY-MM billing month; an empty value returns nil
+func ParseBillingMonth(value string) (*BillingMonth, error) {
+	if value == "" {
+		return nil, nil
 	}
 
-	if config.ClientID == "" {
-		config.ClientID = "BRN-0241-1762176502792" // Default dari Python code
+	t, err := time.Parse("2006-01", value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not YYYY-MM", ErrInvalidBillingMonth, value)
 	}
-	if config.SecretKey == "" {
-		config.SecretKey = "SK-PaILsZudZTytTSTNCmUV" // Default dari Python code
+	return &BillingMonth{Year: t.Year(), Month: int(t.Month())}, nil
+}
+
+// period returns the month as year*12+month, or zero when it is nil
+func (m *BillingMonth) period() int {
+	if m == nil {
+		return 0
 	}
+	return m.Year*12 + m.Month
+}
+
+// monthNames maps billing months to the Indonesian names shown to residents
+var monthNames = map[int]string{
+	1: "Januari", 2: "Februari", 3: "Maret", 4: "April",
+	5: "Mei", 6: "Juni", 7: "Juli", 8: "Agustus",
+	9: "September", 10: "Oktober", 11: "November", 12: "Desember",
+}
 
-	return &dokuService{
-		logger: logger,
-		config: config,
+// billingLineItemName builds the checkout line item name of a billing from the nama_billing of
+// the setting it was generated from, e.g. "Keamanan Desember 2025". Billings generated before
+// settings were tracked are named "IPL".
+func billingLineItemName(billing *models.Billing) string {
+	name := "IPL"
+	if billing.NamaBilling != nil && *billing.NamaBilling != "" {
+		name = *billing.NamaBilling
+	}
+	if billing.Bulan != nil && billing.Tahun != nil {
+		if month, ok := monthNames[*billing.Bulan]; ok {
+			return fmt.Sprintf("%s %s %d", name, month, *billing.Tahun)
+		}
+		return fmt.Sprintf("%s %d/%d", name, *billing.Bulan, *billing.Tahun)
 	}
+	return fmt.Sprintf("%s #%d", name, billing.ID)
 }
 
-// generateSignature creates HMACSHA256 signature exactly like Python code
-func (d *dokuService) generateSignature(clientID, secretKey, requestID, requestTimestamp, requestTarget, body string) string {
-	// Step 1: Digest body menggunakan SHA256 lalu encode base64
-	bodyHash := sha256.Sum256([]byte(body))
-	digestBase64 := base64.StdEncoding.EncodeToString(bodyHash[:])
+// equalOptionalIDs reports whether two optional IDs are both unset or equal
+func equalOptionalIDs(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
 
-	// Step 2: Gabungkan semua komponen signature
-	signatureComponents := fmt.Sprintf("Client-Id:%s\nRequest-Id:%s\nRequest-Timestamp:%s\nRequest-Target:%s\nDigest:%s",
-		clientID, requestID, requestTimestamp, requestTarget, digestBase64)
+// newReusedPaymentLinkResponse builds a payment link response from an existing transaction
+func newReusedPaymentLinkResponse(transaction *models.PaymentTransaction) *PaymentLinkResponse {
+	return &PaymentLinkResponse{
+		TransactionID: transaction.ID,
+		InvoiceNumber: transaction.InvoiceNumber,
+		Amount:        transaction.Amount,
+		FeeAmount:     transaction.FeeAmount,
+		PaymentURL:    transaction.PaymentURL,
+		Description:   transaction.Description,
+		ExpiredAt:     transaction.ExpiredAt,
+		Reused:        true,
+	}
+}
 
-	// Step 3: Buat HMAC-SHA256
-	h := hmac.New(sha256.New, []byte(secretKey))
-	h.Write([]byte(signatureComponents))
-	signatureHMAC := h.Sum(nil)
-	signatureBase64 := base64.StdEncoding.EncodeToString(signatureHMAC)
+// buildBillingKey builds the billing_key of a payment transaction from its billing IDs
+func buildBillingKey(billingIDs []uint) string {
+	parts := make([]string, 0, len(billingIDs))
+	for _, id := range uniqueSortedIDs(billingIDs) {
+		parts = append(parts, strconv.FormatUint(uint64(id), 10))
+	}
+	return strings.Join(parts, ",")
+}
 
-	return fmt.Sprintf("HMACSHA256=%s", signatureBase64)
+// uniqueSortedIDs returns the IDs sorted ascending with duplicates removed
+func uniqueSortedIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
 }
 
-// InitiateDokuCheckout initiates DOKU checkout payment exactly like Python code
-func (d *dokuService) InitiateDokuCheckout(clientID, secretKey string, amount int64, description string) (*DokuCheckoutResponse, error) {
-	// --- Konfigurasi dasar ---
-	url := fmt.Sprintf("%s/checkout/v1/payment", d.config.BaseURL)
-	requestTarget := "/checkout/v1/payment"
+// resolveCustomer builds the checkout customer from the resident the billings belong to
+func (s *paymentService) resolveCustomer(billingIDs []uint) (CheckoutCustomer, error) {
+	var owner *models.UserDetail
+	for _, billingID := range billingIDs {
+		billingOwner, err := s.billingRepo.GetBillingOwner(billingID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.WithField("billing_id", billingID).Warn("Billing has no resident with a published profile")
+			return CheckoutCustomer{}, fmt.Errorf("%w for ID %d", ErrBillingOwnerNotFound, billingID)
+		}
+		if err != nil {
+			s.logger.WithError(err).WithField("billing_id", billingID).Error("Failed to get billing owner")
+			return CheckoutCustomer{}, fmt.Errorf("failed to get billing owner for ID %d: %w", billingID, err)
+		}
 
-	// --- Generate ID & Timestamp ---
-	requestID := uuid.New().String()
-	requestTimestamp := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+		if owner != nil && owner.UserID != billingOwner.UserID {
+			return CheckoutCustomer{}, fmt.Errorf("billings belong to different residents")
+		}
+		owner = billingOwner
+	}
 
-	// --- Payload body ---
-	payload := DokuCheckoutRequest{
-		Order: DokuOrder{
-			Amount:        amount,
-			InvoiceNumber: fmt.Sprintf("INV-%d", time.Now().Unix()),
-			Currency:      "IDR",
-			SessionID:     "SU5WFDferd561dfasfasdfae123c",
-			CallbackURL:   "https://doku.com/",
-			LineItems: []DokuLineItem{
-				{Name: description, Price: amount, Quantity: 1},
-			},
-		},
-		Payment: DokuPayment{PaymentDueDate: 60},
-		Customer: DokuCustomer{
-			Name:    "Anton Budiman",
-			Email:   "anton@doku.com",
-			Phone:   "+6285694566147",
-			Address: "Plaza Asia Office Park Unit 3",
-			Country: "ID",
-		},
+	return CheckoutCustomer{
+		Name:  owner.NamaPenghuni,
+		Email: owner.Email,
+		Phone: owner.NoHP,
+	}, nil
+}
+
+// HandleNotification verifies a gateway notification and marks the invoice's billings as paid
+func (s *paymentService) HandleNotification(req *GatewayNotificationRequest) (*PaymentNotificationResult, error) {
+	notification, err := s.gateway.VerifyNotification(req)
+	if err != nil {
+		s.logger.WithError(err).WithField("request_target", req.RequestTarget).Warn("Rejected payment notification")
+		return nil, err
 	}
 
-	// Konversi ke string JSON (compact, separators=(',', ':'))
-	bodyJSON, err := json.Marshal(payload)
+	invoiceNumber := notification.InvoiceNumber
+	transaction, err := s.paymentRepo.GetTransactionByInvoiceNumber(invoiceNumber)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		s.logger.WithError(err).WithField("invoice_number", invoiceNumber).Error("Failed to get payment transaction for invoice")
+		return nil, ErrPaymentTransactionNotFound
 	}
 
-	// --- Generate Signature ---
-	signature := d.generateSignature(clientID, secretKey, requestID, requestTimestamp, requestTarget, string(bodyJSON))
+	billingIDs, err := s.paymentRepo.GetBillingIDsByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction billings: %w", err)
+	}
 
-	// --- Header HTTP ---
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyJSON))
+	result := &PaymentNotificationResult{
+		InvoiceNumber:     invoiceNumber,
+		TransactionStatus: notification.GatewayStatus,
+		PaymentStatus:     transaction.Status,
+		BillingIDs:        billingIDs,
+	}
+
+	// Gateways retry notifications until they get a 2xx, so a paid transaction is acknowledged as-is
+	if transaction.Status == models.PaymentStatusPaid || transaction.Status == models.PaymentStatusRefunded {
+		s.logger.WithField("invoice_number", invoiceNumber).Info("Payment transaction already paid, ignoring notification")
+		return result, nil
+	}
+
+	if notification.Status == "" {
+		s.logger.WithFields(map[string]interface{}{
+			"invoice_number":     invoiceNumber,
+			"transaction_status": notification.GatewayStatus,
+		}).Info("Notification is not a final payment status, billings left unchanged")
+		return result, nil
+	}
+
+	updated, err := s.applyPaymentStatus(transaction, billingIDs, notification.Status, notification.Amount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
+	}
+
+	if updated {
+		result.PaymentStatus = notification.Status
+	}
+	result.Updated = updated
+
+	return result, nil
+}
+
+// reconcileBatchSize is how many pending transactions a reconciliation pass loads at a time
+const reconcileBatchSize = 100
+
+// ReconcilePendingTransactions queries the gateway for every pending transaction older than minAge
+// and applies the reported status. A transaction whose checkout has expired is marked expired when
+// the gateway still reports it as pending or cannot report on it at all, e.g. an unopened DOKU link
+// or a fake checkout lost on restart.
+func (s *paymentService) ReconcilePendingTransactions(ctx context.Context, minAge time.Duration) (*PaymentReconcileResult, error) {
+	now := time.Now()
+	result := &PaymentReconcileResult{}
+
+	var lastID uint
+	for {
+		transactions, err := s.paymentRepo.GetPendingTransactionsCreatedBefore(now.Add(-minAge), lastID, reconcileBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pending payment transactions: %w", err)
+		}
+
+		for _, transaction := range transactions {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			lastID = transaction.ID
+			s.reconcileTransaction(ctx, transaction, now, result)
+		}
+
+		if len(transactions) < reconcileBatchSize {
+			return result, nil
+		}
 	}
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Client-Id", clientID)
-	req.Header.Set("Request-Id", requestID)
-	req.Header.Set("Request-Timestamp", requestTimestamp)
-	req.Header.Set("Signature", signature)
+// reconcileTransaction reconciles one pending transaction and counts the outcome in result
+func (s *paymentService) reconcileTransaction(ctx context.Context, transaction *models.PaymentTransaction, now time.Time, result *PaymentReconcileResult) {
+	result.Checked++
+	expired := transaction.ExpiredAt != nil && now.After(*transaction.ExpiredAt)
 
-	// --- Eksekusi request ---
-	d.logger.Info("📡 Sending request to DOKU Sandbox...")
-	d.logger.WithFields(map[string]interface{}{
-		"url":               url,
-		"request_id":        requestID,
-		"request_timestamp": requestTimestamp,
-		"signature":         signature,
-	}).Info("Request Info")
+	status := models.PaymentStatusExpired
+	var paidAmount int64
+	gatewayStatus, err := s.gateway.GetStatus(ctx, transaction.InvoiceNumber)
+	if err != nil {
+		s.logger.WithError(err).WithField("invoice_number", transaction.InvoiceNumber).Error("Failed to get payment status from gateway")
+		if !expired {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", transaction.InvoiceNumber, err.Error()))
+			return
+		}
+	} else {
+		status = gatewayStatus.Status
+		paidAmount = gatewayStatus.Amount
+		if status == models.PaymentStatusPending && expired {
+			status = models.PaymentStatusExpired
+		}
+	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	billingIDs, err := s.paymentRepo.GetBillingIDsByTransactionID(transaction.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", transaction.InvoiceNumber, err.Error()))
+		return
 	}
-	defer resp.Body.Close()
 
-	// --- Baca response ---
-	body, err := io.ReadAll(resp.Body)
+	updated, err := s.applyPaymentStatus(transaction, billingIDs, status, paidAmount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", transaction.InvoiceNumber, err.Error()))
+		return
+	}
+
+	switch {
+	case !updated:
+		result.Unchanged++
+	case status == models.PaymentStatusPaid:
+		result.Paid++
+	case status == models.PaymentStatusExpired:
+		result.Expired++
+	case status == models.PaymentStatusFailed:
+		result.Failed++
+	}
+}
+
+// applyPaymentStatus moves a payment transaction to a final status reported by the gateway.
+// A paid status also marks the transaction's billings as paid, after checking the paid amount.
+// It reports false when the stored transaction already moved on, e.g. when the webhook and the
+// reconciler handle the same invoice at once.
+func (s *paymentService) applyPaymentStatus(transaction *models.PaymentTransaction, billingIDs []uint, status string, paidAmount int64) (bool, error) {
+	if transaction.Status == models.PaymentStatusPaid || transaction.Status == models.PaymentStatusRefunded || transaction.Status == status {
+		return false, nil
+	}
+
+	switch status {
+	case models.PaymentStatusPaid:
+		if transaction.Amount != paidAmount {
+			s.logger.WithFields(map[string]interface{}{
+				"invoice_number":  transaction.InvoiceNumber,
+				"expected_amount": transaction.Amount,
+				"paid_amount":     paidAmount,
+			}).Error("Paid amount does not match payment transaction")
+			return false, fmt.Errorf("amount mismatch")
+		}
+
+		// Billings are locked so payments of the same billing settle one after the other
+		var marked bool
+		err := s.paymentRepo.WithCheckoutLock(billingLockKeys(billingIDs), func() error {
+			billingStatusIDs, err := s.billingStatusesAfterPayment(transaction.ID)
+			if err != nil {
+				return err
+			}
+			marked, err = s.paymentRepo.MarkTransactionPaid(transaction.ID, billingStatusIDs, time.Now())
+			return err
+		})
+		if err != nil {
+			s.logger.WithError(err).WithField("billing_ids", billingIDs).Error("Failed to mark billings as paid")
+			return false, fmt.Errorf("failed to update billing status: %w", err)
+		}
+		if !marked {
+			s.logger.WithField("invoice_number", transaction.InvoiceNumber).Info("Payment transaction already paid")
+			return false, nil
+		}
+
+		s.logger.WithFields(map[string]interface{}{
+			"invoice_number": transaction.InvoiceNumber,
+			"billing_ids":    billingIDs,
+			"amount":         paidAmount,
+		}).Info("Billings marked as paid")
+	case models.PaymentStatusFailed, models.PaymentStatusExpired:
+		changed, err := s.paymentRepo.UpdatePendingTransactionStatus(transaction.ID, status)
+		if err != nil {
+			return false, fmt.Errorf("failed to update payment transaction: %w", err)
+		}
+		if !changed {
+			s.logger.WithField("invoice_number", transaction.InvoiceNumber).Info("Payment transaction no longer pending, status left unchanged")
+			return false, nil
+		}
+
+		s.logger.WithFields(map[string]interface{}{
+			"invoice_number": transaction.InvoiceNumber,
+			"status":         status,
+		}).Info("Payment transaction status updated")
+	default:
+		return false, nil
 	}
 
-	// Log response for debugging
-	d.logger.WithFields(map[string]interface{}{
-		"status_code": resp.StatusCode,
-		"response":    string(body),
-	}).Info("DOKU API Response")
+	transaction.Status = status
+	return true, nil
+}
 
-	// Parse response
-	var dokuResp DokuCheckoutResponse
-	if err := json.Unmarshal(body, &dokuResp); err != nil {
-		// If we can't parse as expected structure, try generic
-		var genericResp map[string]interface{}
-		if err := json.Unmarshal(body, &genericResp); err != nil {
-			return nil, fmt.Errorf("failed to parse response: %w", err)
+// CancelPaymentLink cancels a pending checkout so it is no longer reused or reconciled. The gateway
+// is asked to close the link as well; gateways that cannot do so (DOKU Checkout) leave it open until
+// it expires, and a payment made through it is still applied when notified.
+func (s *paymentService) CancelPaymentLink(ctx context.Context, invoiceNumber string) (*PaymentCancelResult, error) {
+	transaction, err := s.paymentRepo.GetTransactionByInvoiceNumber(invoiceNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentTransactionNotFound
 		}
+		return nil, fmt.Errorf("failed to get payment transaction: %w", err)
+	}
 
-		d.logger.WithField("response", genericResp).Error("Unexpected response structure")
-		return nil, fmt.Errorf("unexpected response structure: %v", genericResp)
+	billingIDs, err := s.paymentRepo.GetBillingIDsByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction billings: %w", err)
 	}
 
-	// Debug log the parsed response
-	d.logger.WithFields(map[string]interface{}{
-		"message":     dokuResp.Message,
-		"payment_url": dokuResp.Response.Payment.URL,
-		"token_id":    dokuResp.Response.Payment.TokenID,
-	}).Info("Successfully parsed DOKU response")
+	var result *PaymentCancelResult
+	err = s.paymentRepo.WithCheckoutLock(billingLockKeys(billingIDs), func() error {
+		var err error
+		result, err = s.cancelPaymentLinkLocked(ctx, transaction)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return &dokuResp, nil
+	return result, nil
 }
 
-// CreatePaymentLink creates a payment link using DOKU service
-func (d *dokuService) CreatePaymentLink(amount int64, description string) (string, error) {
-	d.logger.WithFields(map[string]interface{}{
-		"amount":      amount,
-		"description": description,
-	}).Info("Creating DOKU payment link")
+// cancelPaymentLinkLocked does the work of CancelPaymentLink while the billings lock is held
+func (s *paymentService) cancelPaymentLinkLocked(ctx context.Context, transaction *models.PaymentTransaction) (*PaymentCancelResult, error) {
+	if transaction.Status != models.PaymentStatusPending {
+		return nil, fmt.Errorf("payment transaction is not pending")
+	}
 
-	// Use configured credentials
-	clientID := d.config.ClientID
-	secretKey := d.config.SecretKey
+	gatewayCancelled := true
+	if err := s.gateway.CancelCheckout(ctx, transaction.InvoiceNumber); err != nil {
+		if !errors.Is(err, ErrCancelNotSupported) {
+			s.logger.WithError(err).WithField("invoice_number", transaction.InvoiceNumber).Error("Failed to cancel checkout at gateway")
+			return nil, fmt.Errorf("failed to cancel checkout: %w", err)
+		}
+		s.logger.WithField("invoice_number", transaction.InvoiceNumber).Warn("Gateway cannot cancel checkouts, link stays open until it expires")
+		gatewayCancelled = false
+	}
 
-	if clientID == "" || secretKey == "" {
-		return "", fmt.Errorf("DOKU credentials not configured")
+	changed, err := s.paymentRepo.UpdatePendingTransactionStatus(transaction.ID, models.PaymentStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update payment transaction: %w", err)
+	}
+	if !changed {
+		return nil, fmt.Errorf("payment transaction is not pending")
 	}
 
-	// Initiate DOKU checkout
-	result, err := d.InitiateDokuCheckout(clientID, secretKey, amount, description)
+	billingIDs, err := s.paymentRepo.GetBillingIDsByTransactionID(transaction.ID)
 	if err != nil {
-		d.logger.WithError(err).Error("Failed to initiate DOKU checkout")
-		return "", err
+		return nil, fmt.Errorf("failed to get transaction billings: %w", err)
 	}
 
-	// Extract payment URL from response
-	if result.Response.Payment.URL == "" {
-		d.logger.Error("Payment URL not found in response")
-		return "", fmt.Errorf("payment URL not found in response")
+	s.logger.WithFields(map[string]interface{}{
+		"invoice_number":    transaction.InvoiceNumber,
+		"gateway_cancelled": gatewayCancelled,
+	}).Info("Payment link cancelled")
+
+	return &PaymentCancelResult{
+		InvoiceNumber:    transaction.InvoiceNumber,
+		PaymentStatus:    models.PaymentStatusCancelled,
+		BillingIDs:       billingIDs,
+		GatewayCancelled: gatewayCancelled,
+	}, nil
+}
+
+// billingStatusesAfterPayment resolves the status each billing of a transaction moves to once the
+// transaction is paid: paid when its balance is covered, partially paid otherwise
+func (s *paymentService) billingStatusesAfterPayment(transactionID uint) (map[uint]uint, error) {
+	allocations, err := s.paymentRepo.GetTransactionAllocations(transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction billings: %w", err)
 	}
 
-	d.logger.WithFields(map[string]interface{}{
-		"amount":      amount,
-		"description": description,
-		"payment_url": result.Response.Payment.URL,
-	}).Info("DOKU payment link created successfully")
+	paidAmounts, err := s.paymentRepo.GetBillingPaidAmounts(sortedAllocationIDs(allocations))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billing payments: %w", err)
+	}
+	for billingID, amount := range allocations {
+		paidAmounts[billingID] += amount
+	}
+
+	return billingStatusIDsFor(s.billingRepo, paidAmounts)
+}
 
-	return result.Response.Payment.URL, nil
+// generateInvoiceNumber builds a unique invoice number, e.g. INV-20251103153000-1A2B3C
+func generateInvoiceNumber() string {
+	suffix := strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", "")[:6])
+	return fmt.Sprintf("INV-%s-%s", time.Now().Format("20060102150405"), suffix)
 }
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"ipl-be-svc/internal/models"
+)
+
+// testResident is the owner of the billings created by newTestPaymentService
+var testResident = &models.UserDetail{UserID: 7, NamaPenghuni: "Budi Santoso", Email: "budi@example.com", NoHP: "081234567890"}
+
+// newTestPaymentService wires a PaymentService to in-memory repositories and the fake gateway
+func newTestPaymentService(t *testing.T) (PaymentService, *memoryBillingRepository, *memoryPaymentRepository, PaymentGateway) {
+	t.Helper()
+
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	gateway := NewFakeGateway(newTestLogger())
+
+	billingRepo.addBilling(1, 150000, 11, 2025, testResident)
+	billingRepo.addBilling(2, 150000, 12, 2025, testResident)
+
+	return NewPaymentService(billingRepo, paymentRepo, newMemoryInstallmentRepository(), newMemoryFeeRuleRepository(), gateway, newTestLogger()), billingRepo, paymentRepo, gateway
+}
+
+// fakeNotification builds a fake gateway notification request
+func fakeNotification(t *testing.T, invoiceNumber, status string, amount int64) *GatewayNotificationRequest {
+	t.Helper()
+
+	body, err := json.Marshal(FakeNotification{InvoiceNumber: invoiceNumber, Status: status, Amount: amount})
+	if err != nil {
+		t.Fatalf("marshal notification: %v", err)
+	}
+	return &GatewayNotificationRequest{RequestTarget: "/api/v1/payments/notification", Body: body}
+}
+
+func TestCreatePaymentLinkMultiple_ReusesPendingCheckout(t *testing.T) {
+	svc, _, _, _ := newTestPaymentService(t)
+
+	first, err := svc.CreatePaymentLinkMultiple(context.Background(), []uint{2, 1}, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create first link: %v", err)
+	}
+	if first.Reused {
+		t.Errorf("first link reported as reused")
+	}
+	if first.Amount != 300000 {
+		t.Errorf("amount = %d, want 300000", first.Amount)
+	}
+
+	second, err := svc.CreatePaymentLinkMultiple(context.Background(), []uint{1, 2, 2}, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create second link: %v", err)
+	}
+	if !second.Reused || second.InvoiceNumber != first.InvoiceNumber {
+		t.Errorf("second link = %s (reused %v), want reuse of %s", second.InvoiceNumber, second.Reused, first.InvoiceNumber)
+	}
+
+	// An overlapping billing set would make billing 1 payable through two live checkouts
+	if _, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{}); !errors.Is(err, ErrPendingCheckout) {
+		t.Errorf("link for an overlapping billing set: err = %v, want ErrPendingCheckout", err)
+	}
+	if _, err := svc.CancelPaymentLink(context.Background(), first.InvoiceNumber); err != nil {
+		t.Fatalf("cancel first link: %v", err)
+	}
+
+	other, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create single link: %v", err)
+	}
+	if other.Reused || other.InvoiceNumber == first.InvoiceNumber {
+		t.Errorf("link for a different billing set reused %s", first.InvoiceNumber)
+	}
+}
+
+func TestCreatePaymentLink_IdempotencyKey(t *testing.T) {
+	svc, _, _, _ := newTestPaymentService(t)
+
+	first, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{IdempotencyKey: "key-1"})
+	if err != nil {
+		t.Fatalf("create link: %v", err)
+	}
+
+	again, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{IdempotencyKey: "key-1"})
+	if err != nil {
+		t.Fatalf("repeat link: %v", err)
+	}
+	if !again.Reused || again.InvoiceNumber != first.InvoiceNumber {
+		t.Errorf("repeated key returned %s, want %s", again.InvoiceNumber, first.InvoiceNumber)
+	}
+
+	if _, err := svc.CreatePaymentLink(context.Background(), 2, CheckoutOptions{IdempotencyKey: "key-1"}); err == nil || err.Error() != "idempotency key reused with different billings" {
+		t.Errorf("key reuse for other billings: err = %v", err)
+	}
+}
+
+func TestCreatePaymentLink_ConcurrentRequestsCreateOneCheckout(t *testing.T) {
+	svc, _, paymentRepo, _ := newTestPaymentService(t)
+
+	const requests = 10
+	invoices := make(chan string, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			link, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{IdempotencyKey: "double-tap"})
+			if err != nil {
+				t.Errorf("create link: %v", err)
+				return
+			}
+			invoices <- link.InvoiceNumber
+		}()
+	}
+	wg.Wait()
+	close(invoices)
+
+	seen := make(map[string]bool)
+	for invoice := range invoices {
+		seen[invoice] = true
+	}
+	if len(seen) != 1 || paymentRepo.count() != 1 {
+		t.Errorf("got %d invoices and %d transactions, want 1 of each", len(seen), paymentRepo.count())
+	}
+}
+
+func TestCreatePaymentLink_BillingWithoutOwner(t *testing.T) {
+	svc, billingRepo, _, _ := newTestPaymentService(t)
+	billingRepo.addBilling(3, 150000, 1, 2026, nil)
+
+	_, err := svc.CreatePaymentLink(context.Background(), 3, CheckoutOptions{})
+	if !errors.Is(err, ErrBillingOwnerNotFound) {
+		t.Errorf("err = %v, want ErrBillingOwnerNotFound", err)
+	}
+}
+
+func TestCreatePaymentLink_UnknownBilling(t *testing.T) {
+	svc, _, _, _ := newTestPaymentService(t)
+
+	if _, err := svc.CreatePaymentLink(context.Background(), 99, CheckoutOptions{}); !errors.Is(err, ErrBillingNotFound) {
+		t.Errorf("single: err = %v, want ErrBillingNotFound", err)
+	}
+	if _, err := svc.CreatePaymentLinkMultiple(context.Background(), []uint{1, 99}, CheckoutOptions{}); !errors.Is(err, ErrBillingNotFound) {
+		t.Errorf("multiple: err = %v, want ErrBillingNotFound", err)
+	}
+}
+
+func TestHandleNotification_MarksBillingsPaid(t *testing.T) {
+	svc, billingRepo, paymentRepo, _ := newTestPaymentService(t)
+
+	link, err := svc.CreatePaymentLinkMultiple(context.Background(), []uint{1, 2}, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create link: %v", err)
+	}
+
+	result, err := svc.HandleNotification(fakeNotification(t, link.InvoiceNumber, models.PaymentStatusPaid, link.Amount))
+	if err != nil {
+		t.Fatalf("handle notification: %v", err)
+	}
+	if !result.Updated || result.PaymentStatus != models.PaymentStatusPaid {
+		t.Errorf("result = %+v, want updated paid", result)
+	}
+
+	if status := paymentRepo.transaction(link.InvoiceNumber).Status; status != models.PaymentStatusPaid {
+		t.Errorf("transaction status = %s, want paid", status)
+	}
+	for _, billingID := range []uint{1, 2} {
+		if status := billingRepo.statusName(billingID); status != StatusSudahDibayar {
+			t.Errorf("billing %d status = %s, want %s", billingID, status, StatusSudahDibayar)
+		}
+	}
+
+	// Gateways retry notifications, so a repeat is acknowledged without changes
+	repeat, err := svc.HandleNotification(fakeNotification(t, link.InvoiceNumber, models.PaymentStatusPaid, link.Amount))
+	if err != nil {
+		t.Fatalf("repeat notification: %v", err)
+	}
+	if repeat.Updated {
+		t.Errorf("repeated notification updated the transaction again")
+	}
+
+	if _, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{}); err == nil || err.Error() != "billing already paid" {
+		t.Errorf("link for paid billing: err = %v", err)
+	}
+}
+
+func TestHandleNotification_AmountMismatch(t *testing.T) {
+	svc, billingRepo, _, _ := newTestPaymentService(t)
+
+	link, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create link: %v", err)
+	}
+
+	if _, err := svc.HandleNotification(fakeNotification(t, link.InvoiceNumber, models.PaymentStatusPaid, link.Amount-1)); err == nil || err.Error() != "amount mismatch" {
+		t.Errorf("err = %v, want amount mismatch", err)
+	}
+	if status := billingRepo.statusName(1); status != StatusBelumDibayar {
+		t.Errorf("billing status = %s, want %s", status, StatusBelumDibayar)
+	}
+}
+
+func TestHandleNotification_UnknownInvoice(t *testing.T) {
+	svc, _, _, _ := newTestPaymentService(t)
+
+	_, err := svc.HandleNotification(fakeNotification(t, "INV-UNKNOWN", models.PaymentStatusPaid, 1000))
+	if !errors.Is(err, ErrPaymentTransactionNotFound) {
+		t.Errorf("err = %v, want ErrPaymentTransactionNotFound", err)
+	}
+}
+
+func TestBillingLineItemName(t *testing.T) {
+	month, year := 12, 2025
+	if name := billingLineItemName(&models.Billing{ID: 1, Bulan: &month, Tahun: &year}); name != "IPL Desember 2025" {
+		t.Errorf("name = %q, want %q", name, "IPL Desember 2025")
+	}
+	if name := billingLineItemName(&models.Billing{ID: 9}); name != "IPL #9" {
+		t.Errorf("name = %q, want %q", name, "IPL #9")
+	}
+	keamanan := "Keamanan"
+	if name := billingLineItemName(&models.Billing{ID: 1, Bulan: &month, Tahun: &year, NamaBilling: &keamanan}); name != "Keamanan Desember 2025" {
+		t.Errorf("name = %q, want %q", name, "Keamanan Desember 2025")
+	}
+}
+
+// nonCancellableGateway is a fake gateway that cannot cancel checkouts, like DOKU Checkout
+type nonCancellableGateway struct {
+	PaymentGateway
+}
+
+func (g nonCancellableGateway) CancelCheckout(ctx context.Context, invoiceNumber string) error {
+	return ErrCancelNotSupported
+}
+
+func TestCancelPaymentLink(t *testing.T) {
+	svc, _, paymentRepo, _ := newTestPaymentService(t)
+
+	link, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create link: %v", err)
+	}
+
+	result, err := svc.CancelPaymentLink(context.Background(), link.InvoiceNumber)
+	if err != nil {
+		t.Fatalf("cancel link: %v", err)
+	}
+	if !result.GatewayCancelled || result.PaymentStatus != models.PaymentStatusCancelled {
+		t.Errorf("cancel = %+v, want cancelled at the gateway", result)
+	}
+	if status := paymentRepo.transaction(link.InvoiceNumber).Status; status != models.PaymentStatusCancelled {
+		t.Errorf("transaction status = %s, want cancelled", status)
+	}
+
+	if _, err := svc.CancelPaymentLink(context.Background(), link.InvoiceNumber); err == nil || err.Error() != "payment transaction is not pending" {
+		t.Errorf("second cancel error = %v, want payment transaction is not pending", err)
+	}
+
+	next, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create link after cancel: %v", err)
+	}
+	if next.Reused {
+		t.Errorf("cancelled checkout %s was reused", link.InvoiceNumber)
+	}
+}
+
+func TestCancelPaymentLink_GatewayWithoutCancel(t *testing.T) {
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	billingRepo.addBilling(1, 150000, 11, 2025, testResident)
+	svc := NewPaymentService(billingRepo, paymentRepo, newMemoryInstallmentRepository(), newMemoryFeeRuleRepository(), nonCancellableGateway{NewFakeGateway(newTestLogger())}, newTestLogger())
+
+	link, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create link: %v", err)
+	}
+
+	result, err := svc.CancelPaymentLink(context.Background(), link.InvoiceNumber)
+	if err != nil {
+		t.Fatalf("cancel link: %v", err)
+	}
+	if result.GatewayCancelled || result.PaymentStatus != models.PaymentStatusCancelled {
+		t.Errorf("cancel = %+v, want cancelled locally only", result)
+	}
+}
+
+func TestCreatePartialPaymentLink_PaysBalanceInParts(t *testing.T) {
+	svc, billingRepo, _, _ := newTestPaymentService(t)
+
+	if _, err := svc.CreatePartialPaymentLink(context.Background(), 1, 150001, CheckoutOptions{}); !errors.Is(err, ErrInvalidPaymentAmount) {
+		t.Fatalf("amount above nominal: err = %v, want ErrInvalidPaymentAmount", err)
+	}
+
+	first, err := svc.CreatePartialPaymentLink(context.Background(), 1, 50000, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create partial link: %v", err)
+	}
+	if first.Amount != 50000 {
+		t.Errorf("amount = %d, want 50000", first.Amount)
+	}
+	if _, err := svc.HandleNotification(fakeNotification(t, first.InvoiceNumber, models.PaymentStatusPaid, first.Amount)); err != nil {
+		t.Fatalf("handle notification: %v", err)
+	}
+	if status := billingRepo.statusName(1); status != StatusDibayarSebagian {
+		t.Errorf("billing status = %s, want %s", status, StatusDibayarSebagian)
+	}
+
+	balance, err := svc.GetBillingBalance(1)
+	if err != nil {
+		t.Fatalf("get balance: %v", err)
+	}
+	if balance.PaidAmount != 50000 || balance.Outstanding != 100000 || balance.Status != StatusDibayarSebagian {
+		t.Errorf("balance = %+v, want 50000 paid and 100000 outstanding", balance)
+	}
+
+	// A full payment link now only asks for the rest
+	rest, err := svc.CreatePaymentLink(context.Background(), 1, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create link for the rest: %v", err)
+	}
+	if rest.Amount != 100000 {
+		t.Errorf("rest amount = %d, want 100000", rest.Amount)
+	}
+	if _, err := svc.HandleNotification(fakeNotification(t, rest.InvoiceNumber, models.PaymentStatusPaid, rest.Amount)); err != nil {
+		t.Fatalf("handle notification: %v", err)
+	}
+	if status := billingRepo.statusName(1); status != StatusSudahDibayar {
+		t.Errorf("billing status = %s, want %s", status, StatusSudahDibayar)
+	}
+
+	if _, err := svc.CreatePartialPaymentLink(context.Background(), 1, 1000, CheckoutOptions{}); err == nil || err.Error() != "billing already paid" {
+		t.Errorf("partial link for paid billing: err = %v", err)
+	}
+}
+
+func TestCreateOutstandingPaymentLink(t *testing.T) {
+	svc, billingRepo, _, _ := newTestPaymentService(t)
+	billingRepo.addBilling(3, 150000, 1, 2026, testResident)
+	billingRepo.addBilling(4, 150000, 1, 2026, &models.UserDetail{UserID: 8})
+	ctx := context.Background()
+
+	all, err := svc.CreateOutstandingPaymentLink(ctx, testResident.UserID, BillingMonthRange{}, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create outstanding link: %v", err)
+	}
+	if buildBillingKey(all.BillingIDs) != "1,2,3" || all.Amount != 450000 {
+		t.Errorf("outstanding link = %v for %d, want billings 1,2,3 for 450000", all.BillingIDs, all.Amount)
+	}
+
+	if _, err := svc.CancelPaymentLink(ctx, all.InvoiceNumber); err != nil {
+		t.Fatalf("cancel outstanding link: %v", err)
+	}
+
+	from, _ := ParseBillingMonth("2025-12")
+	to, _ := ParseBillingMonth("2025-12")
+	december, err := svc.CreateOutstandingPaymentLink(ctx, testResident.UserID, BillingMonthRange{From: from, To: to}, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("create link for December: %v", err)
+	}
+	if buildBillingKey(december.BillingIDs) != "2" {
+		t.Errorf("December link billings = %v, want [2]", december.BillingIDs)
+	}
+
+	if _, err := svc.CreateOutstandingPaymentLink(ctx, testResident.UserID, BillingMonthRange{From: to, To: &BillingMonth{Year: 2025, Month: 1}}, CheckoutOptions{}); !errors.Is(err, ErrInvalidBillingMonth) {
+		t.Errorf("reversed range: err = %v, want ErrInvalidBillingMonth", err)
+	}
+	if _, err := svc.CreateOutstandingPaymentLink(ctx, 99, BillingMonthRange{}, CheckoutOptions{}); !errors.Is(err, ErrNoOutstandingBillings) {
+		t.Errorf("user without billings: err = %v, want ErrNoOutstandingBillings", err)
+	}
+}
+
+func TestParseBillingMonth(t *testing.T) {
+	if month, err := ParseBillingMonth(""); month != nil || err != nil {
+		t.Errorf("empty month = %v, %v; want nil, nil", month, err)
+	}
+	if month, err := ParseBillingMonth("2025-11"); err != nil || *month != (BillingMonth{Year: 2025, Month: 11}) {
+		t.Errorf("2025-11 = %v, %v", month, err)
+	}
+	if _, err := ParseBillingMonth("11/2025"); !errors.Is(err, ErrInvalidBillingMonth) {
+		t.Errorf("11/2025 err = %v, want ErrInvalidBillingMonth", err)
+	}
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+	"ipl-be-svc/pkg/pdf"
+
+	"gorm.io/gorm"
+)
+
+// receiptSigningKeyPlaceholder is the signing key .env.example used to ship with
+const receiptSigningKeyPlaceholder = "your-receipt-signing-key"
+
+// Layout of the receipt PDF, in points
+const (
+	receiptMargin     = 56.0
+	receiptLineHeight = 18.0
+	receiptFooterY    = 120.0
+)
+
+// ReceiptService defines the interface for payment receipts (kwitansi)
+type ReceiptService interface {
+	GetReceipt(invoiceNumber string) (*Receipt, error)
+	VerifyReceipt(invoiceNumber, code string) (*ReceiptVerification, error)
+}
+
+// Receipt is a rendered PDF receipt of a payment transaction
+type Receipt struct {
+	FileName string
+	Content  []byte
+}
+
+// ReceiptVerification reports whether a verification code printed on a receipt is genuine
+type ReceiptVerification struct {
+	InvoiceNumber string     `json:"invoice_number"`
+	Valid         bool       `json:"valid"`
+	ResidentName  string     `json:"resident_name,omitempty"`
+	Amount        int64      `json:"amount,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+}
+
+// receiptLine is a row of the receipt table
+type receiptLine struct {
+	description string
+	amount      int64
+}
+
+// receiptData holds everything printed on a receipt
+type receiptData struct {
+	transaction      *models.PaymentTransaction
+	residentName     string
+	houseNumber      string
+	lines            []receiptLine
+	verificationCode string
+}
+
+// receiptService implements ReceiptService
+type receiptService struct {
+	billingRepo repository.BillingRepository
+	paymentRepo repository.PaymentRepository
+	unitRepo    repository.ResidentUnitRepository
+	signingKey  []byte
+	logger      *logger.Logger
+}
+
+// NewReceiptService creates a new instance of ReceiptService signing verification codes with
+// signingKey. Anyone knowing the key can forge receipts, so an empty or placeholder key is refused.
+func NewReceiptService(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, unitRepo repository.ResidentUnitRepository, signingKey string, logger *logger.Logger) (ReceiptService, error) {
+	if strings.TrimSpace(signingKey) == "" || signingKey == receiptSigningKeyPlaceholder {
+		return nil, fmt.Errorf("receipt signing key must be set to a secret value")
+	}
+
+	return &receiptService{
+		billingRepo: billingRepo,
+		paymentRepo: paymentRepo,
+		unitRepo:    unitRepo,
+		signingKey:  []byte(signingKey),
+		logger:      logger,
+	}, nil
+}
+
+// GetReceipt renders the receipt of a paid payment transaction. The receipt is built only from
+// stored data, so it can be downloaded again at any time and comes out byte for byte the same
+// unless the payment has been refunded since.
+func (s *receiptService) GetReceipt(invoiceNumber string) (*Receipt, error) {
+	data, err := s.loadReceiptData(invoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Receipt{
+		FileName: fmt.Sprintf("kwitansi-%s.pdf", invoiceNumber),
+		Content:  renderReceipt(data),
+	}, nil
+}
+
+// VerifyReceipt checks a verification code against the one of the payment transaction
+func (s *receiptService) VerifyReceipt(invoiceNumber, code string) (*ReceiptVerification, error) {
+	data, err := s.loadReceiptData(invoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &ReceiptVerification{InvoiceNumber: invoiceNumber}
+	if !hmac.Equal([]byte(normalizeVerificationCode(code)), []byte(normalizeVerificationCode(data.verificationCode))) {
+		return verification, nil
+	}
+
+	verification.Valid = true
+	verification.ResidentName = data.residentName
+	verification.Amount = data.transaction.Amount
+	verification.PaidAt = data.transaction.PaidAt
+	return verification, nil
+}
+
+// loadReceiptData gathers the resident, billings and amounts of a paid payment transaction
+func (s *receiptService) loadReceiptData(invoiceNumber string) (*receiptData, error) {
+	transaction, err := s.paymentRepo.GetTransactionByInvoiceNumber(invoiceNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get payment transaction: %w", err)
+	}
+	if transaction.PaidAt == nil || (transaction.Status != models.PaymentStatusPaid && transaction.Status != models.PaymentStatusRefunded) {
+		return nil, ErrPaymentTransactionNotPaid
+	}
+
+	allocations, err := s.paymentRepo.GetTransactionAllocations(transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment transaction billings: %w", err)
+	}
+	billingIDs := make([]uint, 0, len(allocations))
+	for billingID := range allocations {
+		billingIDs = append(billingIDs, billingID)
+	}
+	billingIDs = uniqueSortedIDs(billingIDs)
+
+	data := &receiptData{transaction: transaction, residentName: "-", houseNumber: "-"}
+	for _, billingID := range billingIDs {
+		billing, err := s.billingRepo.GetBillingByID(billingID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get billing: %w", err)
+		}
+		data.lines = append(data.lines, receiptLine{description: billingLineItemName(billing), amount: allocations[billingID]})
+	}
+	if transaction.FeeAmount > 0 {
+		data.lines = append(data.lines, receiptLine{description: feeLineItemName, amount: transaction.FeeAmount})
+	}
+
+	var ownerUserID uint
+	if len(billingIDs) > 0 {
+		owner, err := s.billingRepo.GetBillingOwner(billingIDs[0])
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get billing owner: %w", err)
+		}
+		if owner != nil {
+			ownerUserID = owner.UserID
+			data.residentName = owner.NamaPenghuni
+
+			unit, err := s.unitRepo.GetByProfileID(owner.ID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("failed to get resident unit: %w", err)
+			}
+			if unit != nil {
+				data.houseNumber = unit.HouseNumber
+			}
+		}
+	}
+
+	data.verificationCode = s.verificationCode(transaction, ownerUserID)
+	return data, nil
+}
+
+// verificationCode signs the fields of a payment that never change once it is paid, formatted
+// as four groups of four hex digits
+func (s *receiptService) verificationCode(transaction *models.PaymentTransaction, ownerUserID uint) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%s|%s|%d|%d|%d|%d", transaction.InvoiceNumber, transaction.BillingKey, transaction.Amount,
+		transaction.FeeAmount, transaction.PaidAt.Unix(), ownerUserID)
+	sum := strings.ToUpper(hex.EncodeToString(mac.Sum(nil))[:16])
+	return sum[0:4] + "-" + sum[4:8] + "-" + sum[8:12] + "-" + sum[12:16]
+}
+
+// normalizeVerificationCode drops the separators and case of a typed verification code
+func normalizeVerificationCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
+
+// renderReceipt lays out a receipt on as many A4 pages as its lines need
+func renderReceipt(data *receiptData) []byte {
+	transaction := data.transaction
+	paidAt := transaction.PaidAt.In(dokuTimezone)
+	doc := pdf.New("Kwitansi "+transaction.InvoiceNumber, *transaction.PaidAt)
+	right := pdf.PageWidth - receiptMargin
+
+	page := doc.AddPage()
+	y := pdf.PageHeight - receiptMargin
+	page.Text(receiptMargin, y, pdf.HelveticaBold, 18, "KWITANSI PEMBAYARAN IPL")
+	y -= 2 * receiptLineHeight
+
+	for _, field := range [][2]string{
+		{"No. Invoice", transaction.InvoiceNumber},
+		{"Tanggal Bayar", formatWIBTime(paidAt)},
+		{"Diterima dari", data.residentName},
+		{"Rumah", data.houseNumber},
+		{"Metode Pembayaran", paymentMethodLabel(transaction)},
+	} {
+		page.Text(receiptMargin, y, pdf.Helvetica, 11, field[0])
+		page.Text(receiptMargin+120, y, pdf.Helvetica, 11, ": "+field[1])
+		y -= receiptLineHeight
+	}
+
+	tableHeader := func() {
+		y -= receiptLineHeight / 2
+		page.Text(receiptMargin, y, pdf.HelveticaBold, 11, "Keterangan")
+		page.TextRight(right, y, pdf.HelveticaBold, 11, "Jumlah")
+		page.Line(receiptMargin, y-6, right, y-6, 0.75)
+		y -= receiptLineHeight + 4
+	}
+	tableHeader()
+
+	lines := data.lines
+	if transaction.RefundedAmount > 0 {
+		lines = append(lines, receiptLine{description: "Dikembalikan (refund)", amount: -transaction.RefundedAmount})
+	}
+	for _, line := range lines {
+		if y < receiptFooterY {
+			page = doc.AddPage()
+			y = pdf.PageHeight - receiptMargin
+			tableHeader()
+		}
+		page.Text(receiptMargin, y, pdf.Helvetica, 11, line.description)
+		page.TextRight(right, y, pdf.Helvetica, 11, formatRupiah(line.amount))
+		y -= receiptLineHeight
+	}
+
+	page.Line(receiptMargin, y+receiptLineHeight-6, right, y+receiptLineHeight-6, 0.75)
+	y -= 4
+	page.Text(receiptMargin, y, pdf.HelveticaBold, 12, "Total")
+	page.TextRight(right, y, pdf.HelveticaBold, 12, formatRupiah(transaction.Amount-transaction.RefundedAmount))
+
+	page.Text(receiptMargin, receiptFooterY-2*receiptLineHeight, pdf.HelveticaBold, 11, "Kode Verifikasi: "+data.verificationCode)
+	page.Text(receiptMargin, receiptFooterY-3*receiptLineHeight, pdf.Helvetica, 9,
+		"Keaslian kwitansi ini dapat diperiksa dengan nomor invoice dan kode verifikasi di atas.")
+
+	return doc.Bytes()
+}
+
+// paymentMethodLabel describes how a payment transaction was paid
+func paymentMethodLabel(transaction *models.PaymentTransaction) string {
+	switch transaction.PaymentMethod {
+	case ManualPaymentMethodCash:
+		return "Tunai"
+	case ManualPaymentMethodTransfer:
+		if transaction.ReferenceNumber != "" {
+			return "Transfer Bank (Ref. " + transaction.ReferenceNumber + ")"
+		}
+		return "Transfer Bank"
+	}
+
+	label := strings.ToUpper(transaction.Gateway)
+	if transaction.PaymentMethod != "" {
+		label += " - " + strings.ReplaceAll(transaction.PaymentMethod, "_", " ")
+	}
+	return label
+}
+
+// formatWIBTime formats a time as e.g. "2 Januari 2026 10:15 WIB"
+func formatWIBTime(t time.Time) string {
+	return fmt.Sprintf("%d %s %d %s WIB", t.Day(), monthNames[int(t.Month())], t.Year(), t.Format("15:04"))
+}
+
+// formatRupiah formats an amount as e.g. "Rp 150.000"
+func formatRupiah(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + "Rp " + grouped.String()
+}
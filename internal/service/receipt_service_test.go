@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// newTestReceiptService wires a ReceiptService to in-memory repositories holding a cash payment
+// of billings 1 and 2
+func newTestReceiptService(t *testing.T) (ReceiptService, *memoryPaymentRepository, string) {
+	t.Helper()
+
+	manualPayments, billingRepo, paymentRepo, _ := newTestManualPaymentService(t)
+	unitRepo := newMemoryResidentUnitRepository()
+	unitRepo.Save(&models.ResidentUnit{ProfileID: testResident.ID, HouseNumber: "A-12"})
+
+	payment, err := manualPayments.RecordManualPayment(&ManualPaymentRequest{
+		BillingIDs:   []uint{1, 2},
+		Method:       ManualPaymentMethodCash,
+		PaidAt:       time.Date(2026, 1, 2, 3, 15, 0, 0, time.UTC),
+		RecordedByID: 3,
+	})
+	if err != nil {
+		t.Fatalf("record manual payment: %v", err)
+	}
+
+	svc, err := NewReceiptService(billingRepo, paymentRepo, unitRepo, "test-signing-key", newTestLogger())
+	if err != nil {
+		t.Fatalf("new receipt service: %v", err)
+	}
+	return svc, paymentRepo, payment.InvoiceNumber
+}
+
+func TestNewReceiptService_RejectsMissingSigningKey(t *testing.T) {
+	for _, key := range []string{"", " ", receiptSigningKeyPlaceholder} {
+		if _, err := NewReceiptService(nil, nil, nil, key, newTestLogger()); err == nil {
+			t.Errorf("signing key %q accepted, want an error", key)
+		}
+	}
+}
+
+func TestGetReceipt(t *testing.T) {
+	svc, _, invoiceNumber := newTestReceiptService(t)
+
+	receipt, err := svc.GetReceipt(invoiceNumber)
+	if err != nil {
+		t.Fatalf("get receipt: %v", err)
+	}
+	if receipt.FileName != "kwitansi-"+invoiceNumber+".pdf" {
+		t.Errorf("file name = %q", receipt.FileName)
+	}
+	if !bytes.HasPrefix(receipt.Content, []byte("%PDF-1.4")) {
+		t.Fatalf("receipt is not a PDF: %q", receipt.Content[:16])
+	}
+	for _, want := range []string{invoiceNumber, "Budi Santoso", "A-12", "Tunai", "IPL November 2025", "IPL Desember 2025", "Rp 300.000", "2 Januari 2026 10:15 WIB", "Kode Verifikasi"} {
+		if !bytes.Contains(receipt.Content, []byte(want)) {
+			t.Errorf("receipt does not contain %q", want)
+		}
+	}
+
+	again, err := svc.GetReceipt(invoiceNumber)
+	if err != nil {
+		t.Fatalf("get receipt again: %v", err)
+	}
+	if !bytes.Equal(receipt.Content, again.Content) {
+		t.Error("receipt rendered twice differs")
+	}
+}
+
+func TestGetReceipt_UnpaidTransaction(t *testing.T) {
+	svc, paymentRepo, _ := newTestReceiptService(t)
+	pending := &models.PaymentTransaction{InvoiceNumber: "INV-PENDING", Status: models.PaymentStatusPending, Amount: 150000}
+	paymentRepo.CreateTransaction(pending, map[uint]int64{1: 150000})
+
+	if _, err := svc.GetReceipt("INV-PENDING"); !errors.Is(err, ErrPaymentTransactionNotPaid) {
+		t.Errorf("pending receipt err = %v, want ErrPaymentTransactionNotPaid", err)
+	}
+	if _, err := svc.GetReceipt("INV-MISSING"); !errors.Is(err, ErrPaymentTransactionNotFound) {
+		t.Errorf("missing receipt err = %v, want ErrPaymentTransactionNotFound", err)
+	}
+}
+
+func TestVerifyReceipt(t *testing.T) {
+	svc, _, invoiceNumber := newTestReceiptService(t)
+	data, err := svc.(*receiptService).loadReceiptData(invoiceNumber)
+	if err != nil {
+		t.Fatalf("load receipt data: %v", err)
+	}
+
+	verification, err := svc.VerifyReceipt(invoiceNumber, " "+strings.ToLower(data.verificationCode))
+	if err != nil {
+		t.Fatalf("verify receipt: %v", err)
+	}
+	if !verification.Valid || verification.ResidentName != "Budi Santoso" || verification.Amount != 300000 {
+		t.Errorf("verification = %+v, want valid for Budi Santoso, 300000", verification)
+	}
+
+	forged, err := svc.VerifyReceipt(invoiceNumber, "0000-0000-0000-0000")
+	if err != nil {
+		t.Fatalf("verify forged code: %v", err)
+	}
+	if forged.Valid || forged.ResidentName != "" {
+		t.Errorf("forged verification = %+v, want invalid without details", forged)
+	}
+}
+
+func TestFormatRupiah(t *testing.T) {
+	tests := map[int64]string{0: "Rp 0", 999: "Rp 999", 150000: "Rp 150.000", 1250000: "Rp 1.250.000", -50000: "-Rp 50.000"}
+	for amount, want := range tests {
+		if got := formatRupiah(amount); got != want {
+			t.Errorf("formatRupiah(%d) = %q, want %q", amount, got, want)
+		}
+	}
+}
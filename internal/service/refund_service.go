@@ -0,0 +1,306 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidRefund is returned when a refund request fails validation
+var ErrInvalidRefund = errors.New("invalid refund")
+
+// RefundService defines the interface for refunding paid payment transactions
+type RefundService interface {
+	RefundTransaction(req *RefundRequest) (*RefundResponse, error)
+	GetRefunds(invoiceNumber string) ([]*models.PaymentRefund, error)
+}
+
+// RefundRequest represents a full or partial refund of a paid payment transaction.
+// Amount 0 refunds everything not refunded yet. BillingIDs are the linked billings to set back
+// to unpaid; a full refund reopens all of them when none are given. Billings a refund does not
+// reopen stay paid by the transaction, even once all of its money is returned.
+type RefundRequest struct {
+	InvoiceNumber string
+	Amount        int64
+	Reason        string
+	BillingIDs    []uint
+	RefundedByID  uint
+}
+
+// RefundResponse represents a recorded refund
+type RefundResponse struct {
+	RefundID           uint   `json:"refund_id"`
+	InvoiceNumber      string `json:"invoice_number"`
+	Amount             int64  `json:"amount"`
+	RefundedAmount     int64  `json:"refunded_amount"` // Total refunded on the transaction so far
+	PaymentStatus      string `json:"payment_status"`
+	ReopenedBillingIDs []uint `json:"reopened_billing_ids"`
+}
+
+// refundService implements RefundService
+type refundService struct {
+	billingRepo repository.BillingRepository
+	paymentRepo repository.PaymentRepository
+	logger      *logger.Logger
+}
+
+// NewRefundService creates a new instance of RefundService
+func NewRefundService(billingRepo repository.BillingRepository, paymentRepo repository.PaymentRepository, logger *logger.Logger) RefundService {
+	return &refundService{
+		billingRepo: billingRepo,
+		paymentRepo: paymentRepo,
+		logger:      logger,
+	}
+}
+
+// RefundTransaction records money returned on a paid transaction. Reopened billings go back to
+// unpaid unless another paid transaction still covers them, e.g. when refunding a duplicate payment.
+func (s *refundService) RefundTransaction(req *RefundRequest) (*RefundResponse, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidRefund)
+	}
+	if req.Amount < 0 {
+		return nil, fmt.Errorf("%w: amount cannot be negative", ErrInvalidRefund)
+	}
+
+	transaction, err := s.getTransaction(req.InvoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	linkedIDs, err := s.paymentRepo.GetBillingIDsByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction billings: %w", err)
+	}
+
+	var response *RefundResponse
+	err = s.paymentRepo.WithCheckoutLock(billingLockKeys(linkedIDs), func() error {
+		// Reload under the lock so concurrent refunds see each other's amounts
+		transaction, err := s.getTransaction(req.InvoiceNumber)
+		if err != nil {
+			return err
+		}
+		response, err = s.refundTransactionLocked(req, transaction)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// refundTransactionLocked does the work of RefundTransaction while the billings lock is held
+func (s *refundService) refundTransactionLocked(req *RefundRequest, transaction *models.PaymentTransaction) (*RefundResponse, error) {
+	if transaction.Status != models.PaymentStatusPaid {
+		return nil, ErrPaymentTransactionNotPaid
+	}
+
+	remaining := transaction.Amount - transaction.RefundedAmount
+	amount := req.Amount
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return nil, fmt.Errorf("%w: amount %d exceeds the refundable %d", ErrInvalidRefund, amount, remaining)
+	}
+	fullRefund := amount == remaining
+
+	linkedIDs, err := s.paymentRepo.GetBillingIDsByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction billings: %w", err)
+	}
+
+	reopenIDs, err := refundBillingIDs(req.BillingIDs, linkedIDs, fullRefund)
+	if err != nil {
+		return nil, err
+	}
+
+	// A reopened billing takes back what the transaction still pays of it. Its allocation stays as
+	// payment history; the reversal is recorded with the refund instead.
+	reversals, err := s.unreversedAllocations(transaction.ID, reopenIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reopened billings keep what other transactions paid of them, so a billing paid again by
+	// another transaction stays paid and one paid in part becomes partially paid
+	reopenedStatusIDs, stillPaid, err := s.reopenedBillingStatuses(reopenIDs, reversals)
+	if err != nil {
+		return nil, err
+	}
+	reopenIDs = subtractIDs(reopenIDs, stillPaid)
+
+	status := models.PaymentStatusPaid
+	if fullRefund {
+		status = models.PaymentStatusRefunded
+	}
+
+	refund := &models.PaymentRefund{
+		PaymentTransactionID: transaction.ID,
+		Amount:               amount,
+		Reason:               strings.TrimSpace(req.Reason),
+		ReopenedBillingKey:   buildBillingKey(reopenIDs),
+		RefundedByID:         req.RefundedByID,
+	}
+	for _, billingID := range sortedAllocationIDs(reversals) {
+		refund.Billings = append(refund.Billings, &models.PaymentRefundBilling{
+			PaymentTransactionID: transaction.ID,
+			BillingID:            billingID,
+			Amount:               reversals[billingID],
+		})
+	}
+	if err := s.paymentRepo.CreateRefund(refund, status, reopenedStatusIDs); err != nil {
+		s.logger.WithError(err).WithField("invoice_number", transaction.InvoiceNumber).Error("Failed to record refund")
+		return nil, fmt.Errorf("failed to record refund: %w", err)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"invoice_number":       transaction.InvoiceNumber,
+		"amount":               amount,
+		"reopened_billing_ids": reopenIDs,
+		"refunded_by_id":       req.RefundedByID,
+	}).Info("Payment refunded")
+
+	return &RefundResponse{
+		RefundID:           refund.ID,
+		InvoiceNumber:      transaction.InvoiceNumber,
+		Amount:             amount,
+		RefundedAmount:     transaction.RefundedAmount + amount,
+		PaymentStatus:      status,
+		ReopenedBillingIDs: reopenIDs,
+	}, nil
+}
+
+// unreversedAllocations returns what transactionID still pays of each of billingIDs: its
+// allocation minus what earlier refunds took back. Billings it no longer pays are left out.
+func (s *refundService) unreversedAllocations(transactionID uint, billingIDs []uint) (map[uint]int64, error) {
+	if len(billingIDs) == 0 {
+		return nil, nil
+	}
+
+	allocations, err := s.paymentRepo.GetTransactionAllocations(transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction billings: %w", err)
+	}
+	reversed, err := s.paymentRepo.GetTransactionReversals(transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction refunds: %w", err)
+	}
+
+	unreversed := make(map[uint]int64, len(billingIDs))
+	for _, billingID := range billingIDs {
+		if amount := allocations[billingID] - reversed[billingID]; amount > 0 {
+			unreversed[billingID] = amount
+		}
+	}
+	return unreversed, nil
+}
+
+// reopenedBillingStatuses resolves the status of each reopened billing once reversals are taken
+// back from it, and returns the billings other transactions still fully pay
+func (s *refundService) reopenedBillingStatuses(reopenIDs []uint, reversals map[uint]int64) (map[uint]uint, []uint, error) {
+	if len(reopenIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	paidAmounts, err := s.paymentRepo.GetBillingPaidAmounts(reopenIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get billing payments: %w", err)
+	}
+
+	otherPaid := make(map[uint]int64, len(reopenIDs))
+	for _, billingID := range reopenIDs {
+		otherPaid[billingID] = max(paidAmounts[billingID]-reversals[billingID], 0)
+	}
+
+	statusIDs, err := billingStatusIDsFor(s.billingRepo, otherPaid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paidStatus, err := s.billingRepo.GetStatusByName(StatusSudahDibayar)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get paid status: %w", err)
+	}
+
+	var stillPaid []uint
+	for _, billingID := range reopenIDs {
+		if statusIDs[billingID] == paidStatus.ID {
+			stillPaid = append(stillPaid, billingID)
+		}
+	}
+
+	return statusIDs, stillPaid, nil
+}
+
+// GetRefunds returns the refund history of a payment transaction
+func (s *refundService) GetRefunds(invoiceNumber string) ([]*models.PaymentRefund, error) {
+	transaction, err := s.getTransaction(invoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := s.paymentRepo.GetRefundsByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunds: %w", err)
+	}
+
+	return refunds, nil
+}
+
+// getTransaction loads a payment transaction by invoice number
+func (s *refundService) getTransaction(invoiceNumber string) (*models.PaymentTransaction, error) {
+	transaction, err := s.paymentRepo.GetTransactionByInvoiceNumber(invoiceNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get payment transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// refundBillingIDs picks the billings a refund reopens. Requested billings must belong to the
+// transaction; without any, a full refund reopens every linked billing and a partial one none.
+func refundBillingIDs(requested, linked []uint, fullRefund bool) ([]uint, error) {
+	if len(requested) == 0 {
+		if fullRefund {
+			return linked, nil
+		}
+		return nil, nil
+	}
+
+	isLinked := make(map[uint]bool, len(linked))
+	for _, id := range linked {
+		isLinked[id] = true
+	}
+	requested = uniqueSortedIDs(requested)
+	for _, id := range requested {
+		if !isLinked[id] {
+			return nil, fmt.Errorf("%w: billing %d is not part of the payment", ErrInvalidRefund, id)
+		}
+	}
+	return requested, nil
+}
+
+// subtractIDs returns the IDs in ids that are not in remove
+func subtractIDs(ids, remove []uint) []uint {
+	removed := make(map[uint]bool, len(remove))
+	for _, id := range remove {
+		removed[id] = true
+	}
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !removed[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+// newTestRefundService wires a RefundService to in-memory repositories with billings 1 and 2
+func newTestRefundService(t *testing.T) (RefundService, *memoryBillingRepository, *memoryPaymentRepository) {
+	t.Helper()
+
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+
+	billingRepo.addBilling(1, 150000, 11, 2025, testResident)
+	billingRepo.addBilling(2, 150000, 12, 2025, testResident)
+
+	return NewRefundService(billingRepo, paymentRepo, newTestLogger()), billingRepo, paymentRepo
+}
+
+// addPaidTransaction stores a paid transaction splitting amount evenly over the billings and marks them paid
+func addPaidTransaction(t *testing.T, paymentRepo *memoryPaymentRepository, invoiceNumber string, amount int64, billingIDs ...uint) {
+	t.Helper()
+
+	paidAt := time.Now()
+	transaction := &models.PaymentTransaction{
+		InvoiceNumber: invoiceNumber,
+		BillingKey:    buildBillingKey(billingIDs),
+		Status:        models.PaymentStatusPaid,
+		Amount:        amount,
+		PaidAt:        &paidAt,
+	}
+	allocations := make(map[uint]int64, len(billingIDs))
+	billingStatusIDs := make(map[uint]uint, len(billingIDs))
+	for _, billingID := range billingIDs {
+		allocations[billingID] = amount / int64(len(billingIDs))
+		billingStatusIDs[billingID] = testStatusIDs[StatusSudahDibayar]
+	}
+	if err := paymentRepo.CreatePaidTransaction(transaction, allocations, billingStatusIDs); err != nil {
+		t.Fatalf("create paid transaction: %v", err)
+	}
+}
+
+func TestRefundTransaction_FullRefundReopensBillings(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestRefundService(t)
+	addPaidTransaction(t, paymentRepo, "INV-1", 300000, 1, 2)
+
+	response, err := svc.RefundTransaction(&RefundRequest{InvoiceNumber: "INV-1", Reason: "Salah transfer", RefundedByID: 3})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if response.Amount != 300000 || response.PaymentStatus != models.PaymentStatusRefunded {
+		t.Errorf("refund = %d/%s, want 300000/refunded", response.Amount, response.PaymentStatus)
+	}
+	for _, billingID := range []uint{1, 2} {
+		if status := billingRepo.statusName(billingID); status != StatusBelumDibayar {
+			t.Errorf("billing %d status = %q, want %q", billingID, status, StatusBelumDibayar)
+		}
+	}
+
+	refunds, err := svc.GetRefunds("INV-1")
+	if err != nil || len(refunds) != 1 || refunds[0].ReopenedBillingKey != "1,2" || refunds[0].RefundedByID != 3 {
+		t.Errorf("refund history = %+v (%v), want one refund reopening 1,2", refunds, err)
+	}
+
+	if _, err := svc.RefundTransaction(&RefundRequest{InvoiceNumber: "INV-1", Reason: "again"}); !errors.Is(err, ErrPaymentTransactionNotPaid) {
+		t.Errorf("second refund error = %v, want ErrPaymentTransactionNotPaid", err)
+	}
+}
+
+func TestRefundTransaction_PartialRefunds(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestRefundService(t)
+	addPaidTransaction(t, paymentRepo, "INV-1", 300000, 1, 2)
+
+	response, err := svc.RefundTransaction(&RefundRequest{InvoiceNumber: "INV-1", Amount: 150000, Reason: "Desember dibatalkan", BillingIDs: []uint{2}})
+	if err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	if response.PaymentStatus != models.PaymentStatusPaid || response.RefundedAmount != 150000 {
+		t.Errorf("refund = %s/%d, want paid/150000", response.PaymentStatus, response.RefundedAmount)
+	}
+	if billingRepo.statusName(1) != StatusSudahDibayar || billingRepo.statusName(2) != StatusBelumDibayar {
+		t.Errorf("statuses = %q/%q, want billing 1 paid and 2 reopened", billingRepo.statusName(1), billingRepo.statusName(2))
+	}
+
+	invalid := []*RefundRequest{
+		{InvoiceNumber: "INV-1", Amount: 200000, Reason: "too much"},
+		{InvoiceNumber: "INV-1", Amount: 1000, Reason: "foreign billing", BillingIDs: []uint{9}},
+		{InvoiceNumber: "INV-1", Amount: 1000},
+	}
+	for _, req := range invalid {
+		if _, err := svc.RefundTransaction(req); !errors.Is(err, ErrInvalidRefund) {
+			t.Errorf("refund %+v error = %v, want ErrInvalidRefund", req, err)
+		}
+	}
+
+	if _, err := svc.RefundTransaction(&RefundRequest{InvoiceNumber: "INV-1", Reason: "sisa"}); err != nil {
+		t.Fatalf("refund remaining: %v", err)
+	}
+	if transaction := paymentRepo.transaction("INV-1"); transaction.Status != models.PaymentStatusRefunded || transaction.RefundedAmount != 300000 {
+		t.Errorf("transaction = %s/%d, want refunded/300000", transaction.Status, transaction.RefundedAmount)
+	}
+}
+
+func TestRefundTransaction_DuplicatePaymentKeepsBillingPaid(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestRefundService(t)
+	addPaidTransaction(t, paymentRepo, "INV-1", 150000, 1)
+	addPaidTransaction(t, paymentRepo, "INV-2", 150000, 1)
+
+	response, err := svc.RefundTransaction(&RefundRequest{InvoiceNumber: "INV-2", Reason: "Pembayaran ganda"})
+	if err != nil {
+		t.Fatalf("refund duplicate: %v", err)
+	}
+	if len(response.ReopenedBillingIDs) != 0 {
+		t.Errorf("reopened %v, want none", response.ReopenedBillingIDs)
+	}
+	if status := billingRepo.statusName(1); status != StatusSudahDibayar {
+		t.Errorf("billing 1 status = %q, want %q", status, StatusSudahDibayar)
+	}
+}
+
+func TestRefundTransaction_FullRefundOfSomeBillingsKeepsTheOthersPaid(t *testing.T) {
+	svc, billingRepo, paymentRepo := newTestRefundService(t)
+	addPaidTransaction(t, paymentRepo, "INV-1", 300000, 1, 2)
+
+	response, err := svc.RefundTransaction(&RefundRequest{InvoiceNumber: "INV-1", Reason: "Salah transfer", BillingIDs: []uint{1}})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if response.PaymentStatus != models.PaymentStatusRefunded || len(response.ReopenedBillingIDs) != 1 {
+		t.Errorf("refund = %s reopening %v, want refunded reopening billing 1", response.PaymentStatus, response.ReopenedBillingIDs)
+	}
+
+	balances, err := loadBillingBalances(billingRepo, paymentRepo, []uint{1, 2})
+	if err != nil {
+		t.Fatalf("load balances: %v", err)
+	}
+	if balances[1].outstanding != 150000 || balances[2].outstanding != 0 {
+		t.Errorf("outstanding = %d/%d, want billing 1 reopened and billing 2 still paid", balances[1].outstanding, balances[2].outstanding)
+	}
+	if billingRepo.statusName(1) != StatusBelumDibayar || billingRepo.statusName(2) != StatusSudahDibayar {
+		t.Errorf("statuses = %q/%q, want billing 1 reopened and 2 paid", billingRepo.statusName(1), billingRepo.statusName(2))
+	}
+
+	allocations, _ := paymentRepo.GetTransactionAllocations(1)
+	if allocations[1] != 150000 || allocations[2] != 150000 {
+		t.Errorf("allocations = %v, want the payment history kept", allocations)
+	}
+	refunds, _ := svc.GetRefunds("INV-1")
+	if len(refunds) != 1 || len(refunds[0].Billings) != 1 || refunds[0].Billings[0].Amount != 150000 {
+		t.Errorf("refunds = %+v, want the reversal of billing 1 recorded", refunds)
+	}
+}
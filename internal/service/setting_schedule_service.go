@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidSettingSchedule is returned when a yearly setting schedule request fails validation
+var ErrInvalidSettingSchedule = errors.New("invalid setting billing schedule")
+
+// SettingScheduleService defines the interface for managing the months yearly (tahunan) setting
+// billings are generated in
+type SettingScheduleService interface {
+	SetYearlySchedule(settingBillingID uint, month int) (*models.SettingBillingSchedule, error)
+	GetYearlySchedules() ([]*models.SettingBillingSchedule, error)
+	DeleteYearlySchedule(settingBillingID uint) error
+}
+
+// settingScheduleService implements SettingScheduleService
+type settingScheduleService struct {
+	billingRepo  repository.BillingRepository
+	scheduleRepo repository.SettingScheduleRepository
+	logger       *logger.Logger
+}
+
+// NewSettingScheduleService creates a new instance of SettingScheduleService
+func NewSettingScheduleService(billingRepo repository.BillingRepository, scheduleRepo repository.SettingScheduleRepository, logger *logger.Logger) SettingScheduleService {
+	return &settingScheduleService{
+		billingRepo:  billingRepo,
+		scheduleRepo: scheduleRepo,
+		logger:       logger,
+	}
+}
+
+// SetYearlySchedule sets the month a yearly setting billing is generated in. The monthly
+// generation of that month, scheduled or bulk, bills it alongside the monthly settings.
+func (s *settingScheduleService) SetYearlySchedule(settingBillingID uint, month int) (*models.SettingBillingSchedule, error) {
+	if month < 1 || month > 12 {
+		return nil, fmt.Errorf("%w: month must be between 1 and 12", ErrInvalidSettingSchedule)
+	}
+
+	setting, err := s.billingRepo.GetSettingBillingByID(settingBillingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("setting billing not found")
+		}
+		return nil, fmt.Errorf("failed to get setting billing: %w", err)
+	}
+	if !strings.EqualFold(setting.JenisBilling, models.JenisBillingTahunan) {
+		return nil, fmt.Errorf("%w: setting billing %d is %q, not %s", ErrInvalidSettingSchedule, setting.ID, setting.JenisBilling, models.JenisBillingTahunan)
+	}
+
+	schedule := &models.SettingBillingSchedule{SettingBillingID: setting.ID, BillingMonth: month}
+	if err := s.scheduleRepo.Save(schedule); err != nil {
+		s.logger.WithError(err).WithField("setting_billing_id", settingBillingID).Error("Failed to save setting billing schedule")
+		return nil, fmt.Errorf("failed to save setting billing schedule: %w", err)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"setting_billing_id": setting.ID,
+		"billing_month":      month,
+	}).Info("Yearly setting billing scheduled")
+
+	schedule.NamaBilling = &setting.NamaBilling
+	return schedule, nil
+}
+
+// GetYearlySchedules returns the schedules of the yearly setting billings, by month
+func (s *settingScheduleService) GetYearlySchedules() ([]*models.SettingBillingSchedule, error) {
+	schedules, err := s.scheduleRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get setting billing schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// DeleteYearlySchedule removes the schedule of a yearly setting billing, which stops it from
+// being generated. Billings already generated are kept.
+func (s *settingScheduleService) DeleteYearlySchedule(settingBillingID uint) error {
+	if _, err := s.scheduleRepo.GetBySettingID(settingBillingID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("schedule not found")
+		}
+		return fmt.Errorf("failed to get setting billing schedule: %w", err)
+	}
+
+	if err := s.scheduleRepo.Delete(settingBillingID); err != nil {
+		s.logger.WithError(err).WithField("setting_billing_id", settingBillingID).Error("Failed to delete setting billing schedule")
+		return fmt.Errorf("failed to delete setting billing schedule: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,468 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+)
+
+// ErrInvalidSettlementFile is returned when a settlement file cannot be parsed
+var ErrInvalidSettlementFile = errors.New("invalid settlement file")
+
+// SettlementService defines the interface for reconciling DOKU settlement reports
+type SettlementService interface {
+	ReconcileSettlement(file io.Reader, period *SettlementPeriod) (*SettlementReport, error)
+}
+
+// SettlementPeriod is the half-open range [From, To) of payment times a settlement file covers
+type SettlementPeriod struct {
+	From time.Time
+	To   time.Time
+}
+
+// SettlementRow represents one transaction of a DOKU settlement file
+type SettlementRow struct {
+	Line            int        `json:"line"`
+	InvoiceNumber   string     `json:"invoice_number"`
+	Amount          int64      `json:"amount"`
+	TransactionDate *time.Time `json:"transaction_date,omitempty"`
+}
+
+// SettlementReportRow represents one invoice in a settlement reconciliation report.
+// Settlement fields are empty for invoices missing in DOKU, transaction fields for invoices
+// missing in our database.
+type SettlementReportRow struct {
+	InvoiceNumber     string     `json:"invoice_number"`
+	Line              int        `json:"line,omitempty"`
+	SettlementAmount  int64      `json:"settlement_amount,omitempty"`
+	TransactionDate   *time.Time `json:"transaction_date,omitempty"`
+	TransactionID     uint       `json:"transaction_id,omitempty"`
+	TransactionAmount int64      `json:"transaction_amount,omitempty"`
+	TransactionStatus string     `json:"transaction_status,omitempty"`
+	PaidAt            *time.Time `json:"paid_at,omitempty"`
+}
+
+// SettlementReportSummary holds the row counts and settled amount of a reconciliation report
+type SettlementReportSummary struct {
+	TotalRows        int   `json:"total_rows"`
+	SettlementAmount int64 `json:"settlement_amount"`
+	Matched          int   `json:"matched"`
+	MissingInDB      int   `json:"missing_in_db"`
+	MissingInDoku    int   `json:"missing_in_doku"`
+	AmountMismatch   int   `json:"amount_mismatch"`
+	StatusMismatch   int   `json:"status_mismatch"`
+}
+
+// SettlementReport represents the result of reconciling a settlement file with our payment transactions
+type SettlementReport struct {
+	PeriodFrom     time.Time               `json:"period_from"`
+	PeriodTo       time.Time               `json:"period_to"`
+	Summary        SettlementReportSummary `json:"summary"`
+	Matched        []*SettlementReportRow  `json:"matched"`
+	MissingInDB    []*SettlementReportRow  `json:"missing_in_db"`
+	MissingInDoku  []*SettlementReportRow  `json:"missing_in_doku"`
+	AmountMismatch []*SettlementReportRow  `json:"amount_mismatch"`
+	StatusMismatch []*SettlementReportRow  `json:"status_mismatch"` // Settled in full while our transaction is not paid
+}
+
+// settlementService implements SettlementService
+type settlementService struct {
+	paymentRepo repository.PaymentRepository
+	logger      *logger.Logger
+}
+
+// NewSettlementService creates a new instance of SettlementService
+func NewSettlementService(paymentRepo repository.PaymentRepository, logger *logger.Logger) SettlementService {
+	return &settlementService{
+		paymentRepo: paymentRepo,
+		logger:      logger,
+	}
+}
+
+// ReconcileSettlement parses a DOKU settlement CSV and matches its rows against our payment
+// transactions by invoice number and amount. A row matches only when our transaction is paid;
+// one settled in full while ours is pending, expired, cancelled or refunded is a status mismatch.
+// Paid DOKU transactions in the period that the file does not contain are reported as missing in
+// DOKU. When period is nil it spans the calendar days of the file's transaction dates.
+func (s *settlementService) ReconcileSettlement(file io.Reader, period *SettlementPeriod) (*SettlementReport, error) {
+	rows, err := ParseSettlementCSV(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if period == nil {
+		period = settlementPeriodOf(rows)
+		if period == nil {
+			return nil, fmt.Errorf("%w: no transaction dates, a period is required", ErrInvalidSettlementFile)
+		}
+	}
+
+	invoiceNumbers := make([]string, 0, len(rows))
+	for _, row := range rows {
+		invoiceNumbers = append(invoiceNumbers, row.InvoiceNumber)
+	}
+
+	transactions, err := s.paymentRepo.GetTransactionsByInvoiceNumbers(invoiceNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment transactions: %w", err)
+	}
+	byInvoice := make(map[string]*models.PaymentTransaction, len(transactions))
+	for _, transaction := range transactions {
+		byInvoice[transaction.InvoiceNumber] = transaction
+	}
+
+	report := &SettlementReport{
+		PeriodFrom:     period.From,
+		PeriodTo:       period.To,
+		Matched:        []*SettlementReportRow{},
+		MissingInDB:    []*SettlementReportRow{},
+		MissingInDoku:  []*SettlementReportRow{},
+		AmountMismatch: []*SettlementReportRow{},
+		StatusMismatch: []*SettlementReportRow{},
+	}
+
+	settled := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		settled[row.InvoiceNumber] = true
+		report.Summary.SettlementAmount += row.Amount
+
+		reportRow := &SettlementReportRow{
+			InvoiceNumber:    row.InvoiceNumber,
+			Line:             row.Line,
+			SettlementAmount: row.Amount,
+			TransactionDate:  row.TransactionDate,
+		}
+
+		transaction, ok := byInvoice[row.InvoiceNumber]
+		if !ok {
+			report.MissingInDB = append(report.MissingInDB, reportRow)
+			continue
+		}
+
+		reportRow.TransactionID = transaction.ID
+		reportRow.TransactionAmount = transaction.Amount
+		reportRow.TransactionStatus = transaction.Status
+		reportRow.PaidAt = transaction.PaidAt
+
+		if transaction.Amount != row.Amount {
+			report.AmountMismatch = append(report.AmountMismatch, reportRow)
+			continue
+		}
+		if transaction.Status != models.PaymentStatusPaid {
+			report.StatusMismatch = append(report.StatusMismatch, reportRow)
+			continue
+		}
+		report.Matched = append(report.Matched, reportRow)
+	}
+
+	paid, err := s.paymentRepo.GetPaidTransactionsBetween(PaymentGatewayDoku, period.From, period.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get paid payment transactions: %w", err)
+	}
+	for _, transaction := range paid {
+		if settled[transaction.InvoiceNumber] {
+			continue
+		}
+		report.MissingInDoku = append(report.MissingInDoku, &SettlementReportRow{
+			InvoiceNumber:     transaction.InvoiceNumber,
+			TransactionID:     transaction.ID,
+			TransactionAmount: transaction.Amount,
+			TransactionStatus: transaction.Status,
+			PaidAt:            transaction.PaidAt,
+		})
+	}
+
+	report.Summary.TotalRows = len(rows)
+	report.Summary.Matched = len(report.Matched)
+	report.Summary.MissingInDB = len(report.MissingInDB)
+	report.Summary.MissingInDoku = len(report.MissingInDoku)
+	report.Summary.AmountMismatch = len(report.AmountMismatch)
+	report.Summary.StatusMismatch = len(report.StatusMismatch)
+
+	s.logger.WithFields(map[string]interface{}{
+		"period_from":     period.From,
+		"period_to":       period.To,
+		"total_rows":      report.Summary.TotalRows,
+		"matched":         report.Summary.Matched,
+		"missing_in_db":   report.Summary.MissingInDB,
+		"missing_in_doku": report.Summary.MissingInDoku,
+		"amount_mismatch": report.Summary.AmountMismatch,
+		"status_mismatch": report.Summary.StatusMismatch,
+	}).Info("Settlement reconciled")
+
+	return report, nil
+}
+
+// ParseSettlementPeriod builds a settlement period from inclusive YYYY-MM-DD days in WIB.
+// Both empty returns nil so the period is taken from the file; otherwise both are required.
+func ParseSettlementPeriod(from, to string) (*SettlementPeriod, error) {
+	if from == "" && to == "" {
+		return nil, nil
+	}
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("from and to must be given together")
+	}
+
+	fromDay, err := ParseWIBDate(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date: %w", err)
+	}
+	toDay, err := ParseWIBDate(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date: %w", err)
+	}
+	if toDay.Before(fromDay) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+
+	return &SettlementPeriod{From: fromDay, To: toDay.AddDate(0, 0, 1)}, nil
+}
+
+// settlementPeriodOf returns the calendar days (WIB) covered by the rows' transaction dates,
+// or nil when no row has a date
+func settlementPeriodOf(rows []*SettlementRow) *SettlementPeriod {
+	var first, last *time.Time
+	for _, row := range rows {
+		if row.TransactionDate == nil {
+			continue
+		}
+		if first == nil || row.TransactionDate.Before(*first) {
+			first = row.TransactionDate
+		}
+		if last == nil || row.TransactionDate.After(*last) {
+			last = row.TransactionDate
+		}
+	}
+	if first == nil {
+		return nil
+	}
+
+	from := startOfDay(first.In(dokuTimezone))
+	to := startOfDay(last.In(dokuTimezone)).AddDate(0, 0, 1)
+	return &SettlementPeriod{From: from, To: to}
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// settlementColumns lists the header names DOKU back office exports use for each column we read,
+// compared after lowercasing and dropping everything but letters and digits
+var settlementColumns = map[string][]string{
+	"invoice": {"invoicenumber", "invoice", "invoiceno", "transactionid"},
+	"amount":  {"amount", "transactionamount", "grossamount", "paidamount", "purchaseamount"},
+	"date":    {"transactiondate", "paymentdate", "date", "transactiontime", "paiddate"},
+}
+
+// settlementDateLayouts are the date formats accepted in the date column, in WIB unless zoned
+var settlementDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+	"02-01-2006 15:04:05",
+	"02-01-2006",
+}
+
+// ParseSettlementCSV parses a DOKU settlement CSV. The header row locates the invoice number,
+// amount and optional transaction date columns; comma and semicolon delimiters are accepted.
+// Rows of the same invoice are combined into one with the amounts summed.
+func ParseSettlementCSV(file io.Reader) ([]*SettlementRow, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settlement file: %w", err)
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if header, _, _ := strings.Cut(text, "\n"); strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidSettlementFile)
+	}
+	columns := locateSettlementColumns(header)
+	if columns["invoice"] < 0 || columns["amount"] < 0 {
+		return nil, fmt.Errorf("%w: invoice number and amount columns are required", ErrInvalidSettlementFile)
+	}
+
+	var rows []*SettlementRow
+	byInvoice := make(map[string]*SettlementRow)
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidSettlementFile, line, err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		invoiceNumber := strings.TrimSpace(field(record, columns["invoice"]))
+		if invoiceNumber == "" {
+			return nil, fmt.Errorf("%w: line %d: missing invoice number", ErrInvalidSettlementFile, line)
+		}
+
+		amount, err := parseSettlementAmount(field(record, columns["amount"]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidSettlementFile, line, err)
+		}
+
+		var transactionDate *time.Time
+		if value := strings.TrimSpace(field(record, columns["date"])); value != "" {
+			parsed, err := parseSettlementDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidSettlementFile, line, err)
+			}
+			transactionDate = &parsed
+		}
+
+		if existing, ok := byInvoice[invoiceNumber]; ok {
+			existing.Amount += amount
+			continue
+		}
+
+		row := &SettlementRow{
+			Line:            line,
+			InvoiceNumber:   invoiceNumber,
+			Amount:          amount,
+			TransactionDate: transactionDate,
+		}
+		byInvoice[invoiceNumber] = row
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no transactions", ErrInvalidSettlementFile)
+	}
+
+	return rows, nil
+}
+
+// locateSettlementColumns maps each settlement column to its index in header, or -1
+func locateSettlementColumns(header []string) map[string]int {
+	normalized := make([]string, len(header))
+	for i, name := range header {
+		normalized[i] = normalizeHeader(name)
+	}
+
+	columns := make(map[string]int, len(settlementColumns))
+	for column, names := range settlementColumns {
+		columns[column] = -1
+		for _, name := range names {
+			for i, headerName := range normalized {
+				if headerName == name {
+					columns[column] = i
+					break
+				}
+			}
+			if columns[column] >= 0 {
+				break
+			}
+		}
+	}
+
+	return columns
+}
+
+// normalizeHeader lowercases a header name and drops everything but letters and digits
+func normalizeHeader(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// field returns record[index], or "" when the column is absent
+func field(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return record[index]
+}
+
+// isBlankRecord reports whether every field of a CSV record is empty
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSettlementAmount parses a rupiah amount such as "150000", "150,000.00", "150.000" or
+// "Rp 150.000,00" and rounds it to whole rupiah
+func parseSettlementAmount(value string) (int64, error) {
+	cleaned := strings.TrimSpace(value)
+	cleaned = strings.TrimPrefix(strings.TrimPrefix(cleaned, "Rp"), "IDR")
+	cleaned = strings.ReplaceAll(strings.TrimSpace(cleaned), " ", "")
+	if cleaned == "" {
+		return 0, fmt.Errorf("missing amount")
+	}
+
+	lastDot := strings.LastIndex(cleaned, ".")
+	lastComma := strings.LastIndex(cleaned, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// The separator that comes last is the decimal one
+		if lastComma > lastDot {
+			cleaned = strings.ReplaceAll(cleaned, ".", "")
+			cleaned = strings.Replace(cleaned, ",", ".", 1)
+		} else {
+			cleaned = strings.ReplaceAll(cleaned, ",", "")
+		}
+	case lastDot >= 0 || lastComma >= 0:
+		separator := "."
+		if lastComma >= 0 {
+			separator = ","
+		}
+		// A single separator followed by three digits, or a repeated one, groups thousands
+		parts := strings.Split(cleaned, separator)
+		if len(parts) > 2 || len(parts[len(parts)-1]) == 3 {
+			cleaned = strings.Join(parts, "")
+		} else {
+			cleaned = strings.Join(parts, ".")
+		}
+	}
+
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return int64(math.Round(amount)), nil
+}
+
+// parseSettlementDate parses a settlement transaction date, in WIB unless it carries a zone
+func parseSettlementDate(value string) (time.Time, error) {
+	for _, layout := range settlementDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, dokuTimezone); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid transaction date %q", value)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ipl-be-svc/internal/models"
+)
+
+func TestParseSettlementAmount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"150000", 150000},
+		{"150,000.00", 150000},
+		{"150.000", 150000},
+		{"Rp 150.000,00", 150000},
+		{"1.250.000", 1250000},
+		{"IDR 99999.5", 100000},
+	}
+	for _, tt := range tests {
+		got, err := parseSettlementAmount(tt.value)
+		if err != nil {
+			t.Errorf("parseSettlementAmount(%q): %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSettlementAmount(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := parseSettlementAmount("abc"); err == nil {
+		t.Errorf("parseSettlementAmount(\"abc\") succeeded")
+	}
+}
+
+func TestParseSettlementCSV(t *testing.T) {
+	file := "\ufeffNo;Invoice Number;Transaction Date;Amount;Channel\n" +
+		"1;INV-1;2025-11-03 10:15:00;150.000;VA\n" +
+		"2;INV-2;03/11/2025 11:00;300.000;QRIS\n" +
+		";;;;\n" +
+		"3;INV-1;2025-11-03 10:16:00;1.000;VA\n"
+
+	rows, err := ParseSettlementCSV(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if rows[0].InvoiceNumber != "INV-1" || rows[0].Amount != 151000 || rows[0].Line != 2 {
+		t.Errorf("row 0 = %+v, want INV-1 151000 on line 2", rows[0])
+	}
+	wantDate := time.Date(2025, 11, 3, 11, 0, 0, 0, dokuTimezone)
+	if rows[1].TransactionDate == nil || !rows[1].TransactionDate.Equal(wantDate) {
+		t.Errorf("row 1 date = %v, want %v", rows[1].TransactionDate, wantDate)
+	}
+
+	_, err = ParseSettlementCSV(strings.NewReader("Invoice Number,Channel\nINV-1,VA\n"))
+	if !errors.Is(err, ErrInvalidSettlementFile) {
+		t.Errorf("missing amount column: err = %v, want ErrInvalidSettlementFile", err)
+	}
+}
+
+func TestReconcileSettlement(t *testing.T) {
+	billingRepo := newMemoryBillingRepository()
+	paymentRepo := newMemoryPaymentRepository(billingRepo)
+	svc := NewSettlementService(paymentRepo, newTestLogger())
+
+	paidAt := time.Date(2025, 11, 3, 9, 0, 0, 0, dokuTimezone)
+	for _, transaction := range []*models.PaymentTransaction{
+		{InvoiceNumber: "INV-MATCH", Amount: 150000},
+		{InvoiceNumber: "INV-MISMATCH", Amount: 300000},
+		{InvoiceNumber: "INV-NOT-SETTLED", Amount: 150000},
+		{InvoiceNumber: "INV-EXPIRED", Amount: 100000, Status: models.PaymentStatusExpired},
+	} {
+		transaction.Gateway = PaymentGatewayDoku
+		if transaction.Status == "" {
+			transaction.Status, transaction.PaidAt = models.PaymentStatusPaid, &paidAt
+		}
+		if err := paymentRepo.CreateTransaction(transaction, nil); err != nil {
+			t.Fatalf("create transaction: %v", err)
+		}
+	}
+
+	file := "invoice_number,amount,transaction_date\n" +
+		"INV-MATCH,150000,2025-11-03\n" +
+		"INV-MISMATCH,250000,2025-11-03\n" +
+		"INV-UNKNOWN,50000,2025-11-03\n" +
+		"INV-EXPIRED,100000,2025-11-03\n"
+
+	report, err := svc.ReconcileSettlement(strings.NewReader(file), nil)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	check := func(name string, rows []*SettlementReportRow, want string) {
+		if len(rows) != 1 || rows[0].InvoiceNumber != want {
+			t.Errorf("%s = %+v, want only %s", name, rows, want)
+		}
+	}
+	check("matched", report.Matched, "INV-MATCH")
+	check("amount mismatch", report.AmountMismatch, "INV-MISMATCH")
+	check("missing in db", report.MissingInDB, "INV-UNKNOWN")
+	check("missing in doku", report.MissingInDoku, "INV-NOT-SETTLED")
+	check("status mismatch", report.StatusMismatch, "INV-EXPIRED")
+
+	if report.Summary.TotalRows != 4 || report.Summary.SettlementAmount != 550000 || report.Summary.StatusMismatch != 1 {
+		t.Errorf("summary = %+v, want 4 rows totalling 550000 with 1 status mismatch", report.Summary)
+	}
+	if !report.PeriodFrom.Equal(time.Date(2025, 11, 3, 0, 0, 0, 0, dokuTimezone)) || !report.PeriodTo.Equal(time.Date(2025, 11, 4, 0, 0, 0, 0, dokuTimezone)) {
+		t.Errorf("period = %v - %v, want 3 November 2025", report.PeriodFrom, report.PeriodTo)
+	}
+
+	// A period that excludes the payment day reports nothing missing in DOKU
+	period, err := ParseSettlementPeriod("2025-11-04", "2025-11-05")
+	if err != nil {
+		t.Fatalf("parse period: %v", err)
+	}
+	report, err = svc.ReconcileSettlement(strings.NewReader(file), period)
+	if err != nil {
+		t.Fatalf("reconcile with period: %v", err)
+	}
+	if len(report.MissingInDoku) != 0 {
+		t.Errorf("missing in doku = %+v, want none", report.MissingInDoku)
+	}
+}
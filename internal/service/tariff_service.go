@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ipl-be-svc/internal/models"
+	"ipl-be-svc/internal/repository"
+	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidTariff is returned when a tariff request fails validation
+var ErrInvalidTariff = errors.New("invalid tariff")
+
+// TariffService defines the interface for managing the per-unit tariffs of setting billings
+type TariffService interface {
+	CreateTariff(req *TariffRequest) (*models.SettingBillingTariff, error)
+	GetTariffs(settingBillingID *uint) ([]*models.SettingBillingTariff, error)
+	UpdateTariff(id uint, req *TariffRequest) (*models.SettingBillingTariff, error)
+	DeleteTariff(id uint) error
+}
+
+// TariffRequest represents the request to create or replace a tariff
+type TariffRequest struct {
+	SettingBillingID uint                `json:"setting_billing_id" binding:"required" example:"1"`
+	Name             string              `json:"name" binding:"required" example:"Keamanan tipe 72"`
+	Priority         int                 `json:"priority" example:"10"`                            // Lower is tried first
+	HouseType        string              `json:"house_type" example:"72"`                          // Omitted matches any
+	Cluster          string              `json:"cluster" example:"Cluster Melati"`                 // Omitted matches any
+	MinLandArea      *int                `json:"min_land_area" example:"100"`                      // m², inclusive
+	MaxLandArea      *int                `json:"max_land_area" example:"200"`                      // m², inclusive
+	PricingType      string              `json:"pricing_type" binding:"required" example:"per_m2"` // flat, per_m2 or tiered
+	Amount           int64               `json:"amount" example:"1500"`                            // Rupiah for flat, rupiah per m² for per_m2
+	Tiers            []models.TariffTier `json:"tiers"`                                            // Land area brackets of a tiered tariff, the last open
+	IsActive         *bool               `json:"is_active" example:"true"`                         // Defaults to true
+}
+
+// tariffService implements TariffService
+type tariffService struct {
+	tariffRepo repository.TariffRepository
+	logger     *logger.Logger
+}
+
+// NewTariffService creates a new instance of TariffService
+func NewTariffService(tariffRepo repository.TariffRepository, logger *logger.Logger) TariffService {
+	return &tariffService{
+		tariffRepo: tariffRepo,
+		logger:     logger,
+	}
+}
+
+// CreateTariff creates a tariff of a setting billing. Billings generated from then on price with it.
+func (s *tariffService) CreateTariff(req *TariffRequest) (*models.SettingBillingTariff, error) {
+	tariff := &models.SettingBillingTariff{IsActive: true}
+	if err := applyTariffRequest(tariff, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.tariffRepo.Create(tariff); err != nil {
+		s.logger.WithError(err).WithField("setting_billing_id", tariff.SettingBillingID).Error("Failed to create tariff")
+		return nil, fmt.Errorf("failed to create tariff: %w", err)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"id":                 tariff.ID,
+		"setting_billing_id": tariff.SettingBillingID,
+		"pricing_type":       tariff.PricingType,
+	}).Info("Tariff created")
+
+	return tariff, nil
+}
+
+// GetTariffs returns the tariffs, of one setting billing when settingBillingID is set
+func (s *tariffService) GetTariffs(settingBillingID *uint) ([]*models.SettingBillingTariff, error) {
+	tariffs, err := s.tariffRepo.GetAll(settingBillingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tariffs: %w", err)
+	}
+	return tariffs, nil
+}
+
+// UpdateTariff replaces the settings of a tariff. Billings already generated keep their nominal.
+func (s *tariffService) UpdateTariff(id uint, req *TariffRequest) (*models.SettingBillingTariff, error) {
+	tariff, err := s.getTariff(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyTariffRequest(tariff, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.tariffRepo.Update(tariff); err != nil {
+		s.logger.WithError(err).WithField("id", id).Error("Failed to update tariff")
+		return nil, fmt.Errorf("failed to update tariff: %w", err)
+	}
+
+	return tariff, nil
+}
+
+// DeleteTariff deletes a tariff
+func (s *tariffService) DeleteTariff(id uint) error {
+	if _, err := s.getTariff(id); err != nil {
+		return err
+	}
+
+	if err := s.tariffRepo.Delete(id); err != nil {
+		s.logger.WithError(err).WithField("id", id).Error("Failed to delete tariff")
+		return fmt.Errorf("failed to delete tariff: %w", err)
+	}
+
+	return nil
+}
+
+// getTariff loads a tariff by ID
+func (s *tariffService) getTariff(id uint) (*models.SettingBillingTariff, error) {
+	tariff, err := s.tariffRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("tariff not found")
+		}
+		return nil, fmt.Errorf("failed to get tariff: %w", err)
+	}
+	return tariff, nil
+}
+
+// applyTariffRequest validates a tariff request and copies it onto tariff
+func applyTariffRequest(tariff *models.SettingBillingTariff, req *TariffRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTariff)
+	}
+	if req.SettingBillingID == 0 {
+		return fmt.Errorf("%w: setting_billing_id is required", ErrInvalidTariff)
+	}
+	if (req.MinLandArea != nil && *req.MinLandArea < 0) || (req.MaxLandArea != nil && *req.MaxLandArea < 0) {
+		return fmt.Errorf("%w: land area bounds cannot be negative", ErrInvalidTariff)
+	}
+	if req.MinLandArea != nil && req.MaxLandArea != nil && *req.MinLandArea > *req.MaxLandArea {
+		return fmt.Errorf("%w: min_land_area is above max_land_area", ErrInvalidTariff)
+	}
+
+	var tiers []models.TariffTier
+	switch req.PricingType {
+	case models.TariffPricingFlat, models.TariffPricingPerM2:
+		if req.Amount <= 0 {
+			return fmt.Errorf("%w: amount must be positive", ErrInvalidTariff)
+		}
+		if len(req.Tiers) > 0 {
+			return fmt.Errorf("%w: only a tiered tariff has tiers", ErrInvalidTariff)
+		}
+	case models.TariffPricingTiered:
+		if len(req.Tiers) == 0 {
+			return fmt.Errorf("%w: a tiered tariff needs tiers", ErrInvalidTariff)
+		}
+		for i, tier := range req.Tiers {
+			last := i == len(req.Tiers)-1
+			if tier.Amount <= 0 {
+				return fmt.Errorf("%w: tier amounts must be positive", ErrInvalidTariff)
+			}
+			if last != (tier.UpToArea == 0) {
+				return fmt.Errorf("%w: only the last tier is open, with up_to_area 0", ErrInvalidTariff)
+			}
+			if i > 0 && !last && tier.UpToArea <= req.Tiers[i-1].UpToArea {
+				return fmt.Errorf("%w: tiers must be in ascending land area", ErrInvalidTariff)
+			}
+			if tier.UpToArea < 0 {
+				return fmt.Errorf("%w: up_to_area cannot be negative", ErrInvalidTariff)
+			}
+		}
+		tiers = req.Tiers
+	default:
+		return fmt.Errorf("%w: pricing type must be %s, %s or %s", ErrInvalidTariff, models.TariffPricingFlat, models.TariffPricingPerM2, models.TariffPricingTiered)
+	}
+
+	tariff.SettingBillingID = req.SettingBillingID
+	tariff.Name = name
+	tariff.Priority = req.Priority
+	tariff.HouseType = strings.TrimSpace(req.HouseType)
+	tariff.Cluster = strings.TrimSpace(req.Cluster)
+	tariff.MinLandArea = req.MinLandArea
+	tariff.MaxLandArea = req.MaxLandArea
+	tariff.PricingType = req.PricingType
+	tariff.Amount = req.Amount
+	tariff.Tiers = tiers
+	if req.PricingType == models.TariffPricingTiered {
+		tariff.Amount = 0
+	}
+	if req.IsActive != nil {
+		tariff.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+// matchTariff returns the first of a setting's tariffs, in the order they are tried, that applies
+// to unit, or nil when none does. Tariffs priced by land area need a unit with a known land area.
+func matchTariff(tariffs []*models.SettingBillingTariff, settingID uint, unit *models.ResidentUnit) *models.SettingBillingTariff {
+	var houseType, cluster string
+	var landArea int
+	if unit != nil {
+		houseType, cluster, landArea = unit.HouseType, unit.Cluster, unit.LandArea
+	}
+
+	for _, tariff := range tariffs {
+		if tariff.SettingBillingID != settingID || !tariff.IsActive {
+			continue
+		}
+		if (tariff.HouseType != "" && !strings.EqualFold(tariff.HouseType, houseType)) || (tariff.Cluster != "" && !strings.EqualFold(tariff.Cluster, cluster)) {
+			continue
+		}
+		needsArea := tariff.MinLandArea != nil || tariff.MaxLandArea != nil || tariff.PricingType != models.TariffPricingFlat
+		if needsArea && landArea <= 0 {
+			continue
+		}
+		if (tariff.MinLandArea != nil && landArea < *tariff.MinLandArea) || (tariff.MaxLandArea != nil && landArea > *tariff.MaxLandArea) {
+			continue
+		}
+		return tariff
+	}
+	return nil
+}
+
+// calculateTariff returns the monthly nominal a tariff charges a unit of landArea m²
+func calculateTariff(tariff *models.SettingBillingTariff, landArea int) int64 {
+	switch tariff.PricingType {
+	case models.TariffPricingPerM2:
+		return tariff.Amount * int64(landArea)
+	case models.TariffPricingTiered:
+		for _, tier := range tariff.Tiers {
+			if tier.UpToArea == 0 || landArea <= tier.UpToArea {
+				return tier.Amount
+			}
+		}
+		return 0
+	default:
+		return tariff.Amount
+	}
+}
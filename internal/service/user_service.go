@@ -1,29 +1,54 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
 	"ipl-be-svc/internal/models"
 	"ipl-be-svc/internal/models/response"
 	"ipl-be-svc/internal/repository"
 	"ipl-be-svc/pkg/logger"
+
+	"gorm.io/gorm"
 )
 
+// ErrInvalidResidentUnit is returned when a resident unit request fails validation
+var ErrInvalidResidentUnit = errors.New("invalid resident unit")
+
 // UserService interface defines user service methods
 type UserService interface {
 	GetUserDetailByProfileID(profileID uint) (*models.UserDetail, error)
 	GetPenghuniUsers() ([]*response.PenghuniUserResponse, error)
+	GetResidentUnit(profileID uint) (*models.ResidentUnit, error)
+	SaveResidentUnit(profileID uint, req *ResidentUnitRequest) (*models.ResidentUnit, error)
+}
+
+// ResidentUnitRequest represents the house a resident profile occupies and since when. The
+// occupancy dates, YYYY-MM-DD, decide the months billed and how the first and last are prorated;
+// house type, cluster and land area decide the tariffs that price them.
+type ResidentUnitRequest struct {
+	HouseNumber   string `json:"house_number" binding:"required" example:"A-12"`
+	HouseType     string `json:"house_type" example:"45"`
+	Cluster       string `json:"cluster" example:"Cluster Melati"`
+	LandArea      int    `json:"land_area" example:"120"`             // m²; omitted if unknown
+	OccupiedFrom  string `json:"occupied_from" example:"2026-03-16"`  // Move-in day; omitted if unknown
+	OccupiedUntil string `json:"occupied_until" example:"2026-12-31"` // Last day before moving out; omitted while occupied
 }
 
 // userService implements UserService interface
 type userService struct {
 	userRepo repository.UserRepository
+	unitRepo repository.ResidentUnitRepository
 	logger   *logger.Logger
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo repository.UserRepository, logger *logger.Logger) UserService {
+func NewUserService(userRepo repository.UserRepository, unitRepo repository.ResidentUnitRepository, logger *logger.Logger) UserService {
 	return &userService{
 		userRepo: userRepo,
+		unitRepo: unitRepo,
 		logger:   logger,
 	}
 }
@@ -86,3 +111,79 @@ func (s *userService) GetPenghuniUsers() ([]*response.PenghuniUserResponse, erro
 
 	return penghuniUsers, nil
 }
+
+// GetResidentUnit gets the unit of a resident profile
+func (s *userService) GetResidentUnit(profileID uint) (*models.ResidentUnit, error) {
+	unit, err := s.unitRepo.GetByProfileID(profileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("resident unit not found")
+		}
+		return nil, fmt.Errorf("failed to get resident unit: %w", err)
+	}
+	return unit, nil
+}
+
+// SaveResidentUnit creates or replaces the unit of a resident profile
+func (s *userService) SaveResidentUnit(profileID uint, req *ResidentUnitRequest) (*models.ResidentUnit, error) {
+	houseNumber := strings.TrimSpace(req.HouseNumber)
+	if houseNumber == "" {
+		return nil, fmt.Errorf("%w: house number is required", ErrInvalidResidentUnit)
+	}
+	occupiedFrom, err := parseOccupancyDate("occupied_from", req.OccupiedFrom)
+	if err != nil {
+		return nil, err
+	}
+	occupiedUntil, err := parseOccupancyDate("occupied_until", req.OccupiedUntil)
+	if err != nil {
+		return nil, err
+	}
+	if req.LandArea < 0 {
+		return nil, fmt.Errorf("%w: land area cannot be negative", ErrInvalidResidentUnit)
+	}
+	if occupiedFrom != nil && occupiedUntil != nil && occupiedUntil.Before(*occupiedFrom) {
+		return nil, fmt.Errorf("%w: occupied_until is before occupied_from", ErrInvalidResidentUnit)
+	}
+
+	exists, err := s.unitRepo.ProfileExists(profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("profile not found")
+	}
+
+	unit, err := s.unitRepo.GetByProfileID(profileID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get resident unit: %w", err)
+		}
+		unit = &models.ResidentUnit{ProfileID: profileID}
+	}
+	unit.HouseNumber = houseNumber
+	unit.HouseType = strings.TrimSpace(req.HouseType)
+	unit.Cluster = strings.TrimSpace(req.Cluster)
+	unit.LandArea = req.LandArea
+	unit.OccupiedFrom = occupiedFrom
+	unit.OccupiedUntil = occupiedUntil
+
+	if err := s.unitRepo.Save(unit); err != nil {
+		s.logger.WithError(err).WithField("profile_id", profileID).Error("Failed to save resident unit")
+		return nil, fmt.Errorf("failed to save resident unit: %w", err)
+	}
+
+	return unit, nil
+}
+
+// parseOccupancyDate parses an optional YYYY-MM-DD occupancy date of a resident unit request
+func parseOccupancyDate(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %q is not YYYY-MM-DD", ErrInvalidResidentUnit, field, value)
+	}
+	return &date, nil
+}
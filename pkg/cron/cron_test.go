@@ -0,0 +1,52 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	from := time.Date(2026, 1, 31, 10, 30, 15, 0, wib) // a Saturday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 31, 10, 31, 0, 0, wib)},
+		{"0 1 * * *", time.Date(2026, 2, 1, 1, 0, 0, 0, wib)},
+		{"*/20 10 * * *", time.Date(2026, 1, 31, 10, 40, 0, 0, wib)},
+		{"0 0 1 * *", time.Date(2026, 2, 1, 0, 0, 0, 0, wib)},
+		{"0 9 * * 1-5", time.Date(2026, 2, 2, 9, 0, 0, 0, wib)},
+		{"0 0 15 3,6 *", time.Date(2026, 3, 15, 0, 0, 0, 0, wib)},
+		{"0 0 13 * 5", time.Date(2026, 2, 6, 0, 0, 0, 0, wib)}, // either the 13th or a Friday
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, wib)},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.expr, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q next = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestScheduleNext_NeverMatches(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("next = %v, want the zero time for a schedule that never matches", next)
+	}
+}
@@ -0,0 +1,52 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDocumentBytes(t *testing.T) {
+	doc := New("Kwitansi (test)", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	page := doc.AddPage()
+	page.Text(50, 800, HelveticaBold, 18, `Total (Rp) \ 150.000`)
+	page.Line(50, 790, 545, 790, 0.75)
+	doc.AddPage().TextRight(545, 800, Helvetica, 11, "Halaman 2")
+
+	out := doc.Bytes()
+	for _, want := range []string{"%PDF-1.4", "/Count 2", `(Total \(Rp\) \\ 150.000) Tj`, "/CreationDate (D:20260102030405Z)", "%%EOF"} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+
+	// Every xref entry must point at the start of its object
+	startxref := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	if len(entries) != 9 {
+		t.Fatalf("xref has %d objects, want 9", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if !bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))) {
+			t.Errorf("xref entry %d points at %q", i+1, out[offset:offset+10])
+		}
+	}
+
+	if !bytes.Equal(out, doc.Bytes()) {
+		t.Error("rendering twice gives different bytes")
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if got := TextWidth(Helvetica, 10, "00"); got != 11.12 {
+		t.Errorf("width of 00 = %v, want 11.12", got)
+	}
+	if got := TextWidth(Helvetica, 10, "é"); got != TextWidth(Helvetica, 10, "?") {
+		t.Errorf("unsupported characters are not measured as '?': %v", got)
+	}
+}